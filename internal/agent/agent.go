@@ -35,6 +35,7 @@ import (
 	"github.com/charmbracelet/crush/internal/agent/tools/mcp"
 	"github.com/charmbracelet/crush/internal/config"
 	"github.com/charmbracelet/crush/internal/csync"
+	"github.com/charmbracelet/crush/internal/lsp"
 	"github.com/charmbracelet/crush/internal/message"
 	"github.com/charmbracelet/crush/internal/permission"
 	"github.com/charmbracelet/crush/internal/session"
@@ -49,6 +50,10 @@ const (
 	largeContextWindowThreshold = 200_000
 	largeContextWindowBuffer    = 20_000
 	smallContextWindowRatio     = 0.2
+
+	// Limits of sending the errors introduced during a turn back to the model.
+	maxDiagnosticsFeedbackRounds = 2
+	maxDiagnosticsFeedbackErrors = 20
 )
 
 //go:embed templates/title.md
@@ -71,12 +76,20 @@ type SessionAgentCall struct {
 	TopK             *int64
 	FrequencyPenalty *float64
 	PresencePenalty  *float64
+
+	// diagnosticsSnapshot holds the diagnostics at the start of the turn,
+	// kept when the turn continues with a follow-up call.
+	diagnosticsSnapshot tools.DiagnosticsSnapshot
+	// diagnosticsFeedbackRounds counts the times introduced errors were sent
+	// back to the model during the turn.
+	diagnosticsFeedbackRounds int
 }
 
 type SessionAgent interface {
 	Run(context.Context, SessionAgentCall) (*fantasy.AgentResult, error)
 	SetModels(large Model, small Model)
 	SetTools(tools []fantasy.AgentTool)
+	Tools() []fantasy.AgentTool
 	SetSystemPrompt(systemPrompt string)
 	Cancel(sessionID string)
 	CancelAll()
@@ -107,6 +120,8 @@ type sessionAgent struct {
 	messages             message.Service
 	disableAutoSummarize bool
 	isYolo               bool
+	lspClients           *csync.Map[string, *lsp.Client]
+	diagnosticsFeedback  bool
 
 	messageQueue   *csync.Map[string, []SessionAgentCall]
 	activeRequests *csync.Map[string, context.CancelFunc]
@@ -123,6 +138,8 @@ type SessionAgentOptions struct {
 	Sessions             session.Service
 	Messages             message.Service
 	Tools                []fantasy.AgentTool
+	LSPClients           *csync.Map[string, *lsp.Client]
+	DiagnosticsFeedback  bool
 }
 
 func NewSessionAgent(
@@ -139,6 +156,8 @@ func NewSessionAgent(
 		disableAutoSummarize: opts.DisableAutoSummarize,
 		tools:                csync.NewSliceFrom(opts.Tools),
 		isYolo:               opts.IsYolo,
+		lspClients:           opts.LSPClients,
+		diagnosticsFeedback:  opts.DiagnosticsFeedback,
 		messageQueue:         csync.NewMap[string, []SessionAgentCall](),
 		activeRequests:       csync.NewMap[string, context.CancelFunc](),
 	}
@@ -225,6 +244,15 @@ func (a *sessionAgent) Run(ctx context.Context, call SessionAgentCall) (*fantasy
 	// Add the session to the context.
 	ctx = context.WithValue(ctx, tools.SessionIDContextKey, call.SessionID)
 
+	// Snapshot the diagnostics so the tools can tell the ones introduced
+	// during the turn.
+	if call.diagnosticsSnapshot == nil && a.lspClients != nil {
+		call.diagnosticsSnapshot = tools.NewDiagnosticsSnapshot(a.lspClients)
+	}
+	if call.diagnosticsSnapshot != nil {
+		ctx = context.WithValue(ctx, tools.DiagnosticsSnapshotContextKey, call.diagnosticsSnapshot)
+	}
+
 	genCtx, cancel := context.WithCancel(ctx)
 	a.activeRequests.Set(call.SessionID, cancel)
 
@@ -299,6 +327,7 @@ func (a *sessionAgent) Run(ctx context.Context, call SessionAgentCall) (*fantasy
 			}
 			callContext = context.WithValue(callContext, tools.MessageIDContextKey, assistantMsg.ID)
 			callContext = context.WithValue(callContext, tools.SupportsImagesContextKey, largeModel.CatwalkCfg.SupportsImages)
+			callContext = context.WithValue(callContext, tools.SupportsPDFContextKey, supportsPDFAttachments(largeModel))
 			callContext = context.WithValue(callContext, tools.ModelNameContextKey, largeModel.CatwalkCfg.Name)
 			currentAssistant = &assistantMsg
 			return callContext, prepared, err
@@ -543,6 +572,19 @@ func (a *sessionAgent) Run(ctx context.Context, call SessionAgentCall) (*fantasy
 		}
 	}
 
+	// Send the errors introduced during the turn back to the model before
+	// processing queued messages.
+	if a.diagnosticsFeedback && !shouldSummarize && call.diagnosticsSnapshot != nil && call.diagnosticsFeedbackRounds < maxDiagnosticsFeedbackRounds {
+		if errs := call.diagnosticsSnapshot.IntroducedErrors(a.lspClients); len(errs) > 0 {
+			existing, _ := a.messageQueue.Get(call.SessionID)
+			feedback := call
+			feedback.Prompt = diagnosticsFeedbackPrompt(errs)
+			feedback.Attachments = nil
+			feedback.diagnosticsFeedbackRounds++
+			a.messageQueue.Set(call.SessionID, append([]SessionAgentCall{feedback}, existing...))
+		}
+	}
+
 	// Release active request before processing queued messages.
 	a.activeRequests.Del(call.SessionID)
 	cancel()
@@ -557,6 +599,14 @@ func (a *sessionAgent) Run(ctx context.Context, call SessionAgentCall) (*fantasy
 	return a.Run(ctx, firstQueuedMessage)
 }
 
+// diagnosticsFeedbackPrompt asks the model to fix the errors it introduced.
+func diagnosticsFeedbackPrompt(errs []string) string {
+	if len(errs) > maxDiagnosticsFeedbackErrors {
+		errs = append(errs[:maxDiagnosticsFeedbackErrors:maxDiagnosticsFeedbackErrors], fmt.Sprintf("... and %d more errors", len(errs)-maxDiagnosticsFeedbackErrors))
+	}
+	return fmt.Sprintf("Your changes introduced the following errors. Fix them, or explain why they should be left as they are:\n\n%s", strings.Join(errs, "\n"))
+}
+
 func (a *sessionAgent) Summarize(ctx context.Context, sessionID string, opts fantasy.ProviderOptions) error {
 	if a.IsSessionBusy(sessionID) {
 		return ErrSessionBusy
@@ -1008,6 +1058,10 @@ func (a *sessionAgent) SetTools(tools []fantasy.AgentTool) {
 	a.tools.SetSlice(tools)
 }
 
+func (a *sessionAgent) Tools() []fantasy.AgentTool {
+	return a.tools.Copy()
+}
+
 func (a *sessionAgent) SetSystemPrompt(systemPrompt string) {
 	a.systemPrompt.Set(systemPrompt)
 }
@@ -1049,6 +1103,21 @@ func (a *sessionAgent) convertToToolResult(result fantasy.ToolResultContent) mes
 	return baseResult
 }
 
+// providerSupportsToolResultMedia returns whether the provider of the model
+// accepts media in tool results.
+func providerSupportsToolResultMedia(model Model) bool {
+	return model.ModelCfg.Provider == string(catwalk.InferenceProviderAnthropic) ||
+		model.ModelCfg.Provider == string(catwalk.InferenceProviderBedrock)
+}
+
+// supportsPDFAttachments returns whether PDFs returned by tools can be sent to
+// the model. Providers accepting media in tool results only accept images
+// there, while the others get the media as a file in a user message, which
+// can be a PDF.
+func supportsPDFAttachments(model Model) bool {
+	return model.CatwalkCfg.SupportsImages && !providerSupportsToolResultMedia(model)
+}
+
 // workaroundProviderMediaLimitations converts media content in tool results to
 // user messages for providers that don't natively support images in tool results.
 //
@@ -1071,10 +1140,7 @@ func (a *sessionAgent) convertToToolResult(result fantasy.ToolResultContent) mes
 //	BEFORE: [tool result: image data]
 //	AFTER:  [tool result: "Image loaded - see attached"], [user: image attachment]
 func (a *sessionAgent) workaroundProviderMediaLimitations(messages []fantasy.Message, largeModel Model) []fantasy.Message {
-	providerSupportsMedia := largeModel.ModelCfg.Provider == string(catwalk.InferenceProviderAnthropic) ||
-		largeModel.ModelCfg.Provider == string(catwalk.InferenceProviderBedrock)
-
-	if providerSupportsMedia {
+	if providerSupportsToolResultMedia(largeModel) {
 		return messages
 	}
 
@@ -107,7 +107,7 @@ func (c *coordinator) agenticFetchTool(_ context.Context, client *http.Client) (
 
 			if params.URL != "" {
 				// URL mode: fetch the URL content first.
-				content, err := tools.FetchURLAndConvert(ctx, client, params.URL)
+				content, err := tools.FetchURLAndConvert(ctx, client, c.fetchCache(), params.URL, params.Refresh)
 				if err != nil {
 					return fantasy.NewTextErrorResponse(fmt.Sprintf("Failed to fetch URL: %s", err)), nil
 				}
@@ -160,15 +160,15 @@ func (c *coordinator) agenticFetchTool(_ context.Context, client *http.Client) (
 				return fantasy.ToolResponse{}, errors.New("small model provider not configured")
 			}
 
-			webFetchTool := tools.NewWebFetchTool(tmpDir, client)
-			webSearchTool := tools.NewWebSearchTool(client)
+			webFetchTool := tools.NewWebFetchTool(tmpDir, client, c.fetchCache())
+			webSearchTool := tools.NewWebSearchTool(c.permissions, tmpDir, client, c.webSearchProvider)
 			fetchTools := []fantasy.AgentTool{
 				webFetchTool,
 				webSearchTool,
 				tools.NewGlobTool(tmpDir),
 				tools.NewGrepTool(tmpDir),
 				tools.NewSourcegraphTool(client),
-				tools.NewViewTool(c.lspClients, c.permissions, tmpDir),
+				tools.NewViewTool(c.lspClients, c.permissions, tmpDir, nil),
 			}
 
 			agent := NewSessionAgent(SessionAgentOptions{
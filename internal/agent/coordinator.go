@@ -12,6 +12,7 @@ import (
 	"maps"
 	"net/http"
 	"os"
+	"path/filepath"
 	"slices"
 	"strings"
 
@@ -20,6 +21,7 @@ import (
 	"github.com/charmbracelet/crush/internal/agent/hyper"
 	"github.com/charmbracelet/crush/internal/agent/prompt"
 	"github.com/charmbracelet/crush/internal/agent/tools"
+	"github.com/charmbracelet/crush/internal/codeindex"
 	"github.com/charmbracelet/crush/internal/config"
 	"github.com/charmbracelet/crush/internal/csync"
 	"github.com/charmbracelet/crush/internal/history"
@@ -56,6 +58,9 @@ type Coordinator interface {
 	Summarize(context.Context, string) error
 	Model() Model
 	UpdateModels(ctx context.Context) error
+	// Tools returns the tools available to the coder agent, once they are
+	// built.
+	Tools(ctx context.Context) ([]fantasy.AgentTool, error)
 }
 
 type coordinator struct {
@@ -65,9 +70,11 @@ type coordinator struct {
 	permissions permission.Service
 	history     history.Service
 	lspClients  *csync.Map[string, *lsp.Client]
+	codeIndex   *codeindex.Index
 
 	currentAgent SessionAgent
 	agents       map[string]SessionAgent
+	coderPrompt  *prompt.Prompt
 
 	readyWg errgroup.Group
 }
@@ -80,6 +87,7 @@ func NewCoordinator(
 	permissions permission.Service,
 	history history.Service,
 	lspClients *csync.Map[string, *lsp.Client],
+	codeIndex *codeindex.Index,
 ) (Coordinator, error) {
 	c := &coordinator{
 		cfg:         cfg,
@@ -88,6 +96,7 @@ func NewCoordinator(
 		permissions: permissions,
 		history:     history,
 		lspClients:  lspClients,
+		codeIndex:   codeIndex,
 		agents:      make(map[string]SessionAgent),
 	}
 
@@ -106,6 +115,7 @@ func NewCoordinator(
 	if err != nil {
 		return nil, err
 	}
+	c.coderPrompt = prompt
 	c.currentAgent = agent
 	c.agents[config.AgentCoder] = agent
 	return c, nil
@@ -339,6 +349,8 @@ func (c *coordinator) buildAgent(ctx context.Context, prompt *prompt.Prompt, age
 		c.sessions,
 		c.messages,
 		nil,
+		c.lspClients,
+		c.cfg.Options.DiagnosticsFeedback && !isSubAgent,
 	})
 
 	c.readyWg.Go(func() error {
@@ -392,21 +404,38 @@ func (c *coordinator) buildTools(ctx context.Context, agent config.Agent) ([]fan
 		tools.NewBashTool(c.permissions, c.cfg.WorkingDir(), c.cfg.Options.Attribution, modelName),
 		tools.NewJobOutputTool(),
 		tools.NewJobKillTool(),
+		tools.NewJobWaitTool(),
+		tools.NewJobListTool(),
+		tools.NewGitStatusTool(c.cfg.WorkingDir()),
+		tools.NewGitDiffTool(c.cfg.WorkingDir()),
+		tools.NewGitLogTool(c.cfg.WorkingDir()),
+		tools.NewGitBlameTool(c.cfg.WorkingDir()),
+		tools.NewGitCommitTool(c.permissions, c.cfg.WorkingDir(), c.cfg.Options.Attribution, modelName),
 		tools.NewDownloadTool(c.permissions, c.cfg.WorkingDir(), nil),
-		tools.NewEditTool(c.lspClients, c.permissions, c.history, c.cfg.WorkingDir()),
-		tools.NewMultiEditTool(c.lspClients, c.permissions, c.history, c.cfg.WorkingDir()),
-		tools.NewFetchTool(c.permissions, c.cfg.WorkingDir(), nil),
+		tools.NewEditTool(c.lspClients, c.permissions, c.history, c.cfg.WorkingDir(), c.cfg.AdditionalDirectories(), c.cfg.Tools.Format),
+		tools.NewMultiEditTool(c.lspClients, c.permissions, c.history, c.cfg.WorkingDir(), c.cfg.AdditionalDirectories(), c.cfg.Tools.Format),
+		tools.NewApplyPatchTool(c.lspClients, c.permissions, c.history, c.cfg.WorkingDir(), c.cfg.AdditionalDirectories()),
+		tools.NewNotebookEditTool(c.permissions, c.history, c.cfg.WorkingDir(), c.cfg.AdditionalDirectories()),
+		tools.NewFetchTool(c.permissions, c.cfg.WorkingDir(), nil, c.fetchCache()),
+		tools.NewWebSearchTool(c.permissions, c.cfg.WorkingDir(), nil, c.webSearchProvider),
 		tools.NewGlobTool(c.cfg.WorkingDir()),
 		tools.NewGrepTool(c.cfg.WorkingDir()),
-		tools.NewLsTool(c.permissions, c.cfg.WorkingDir(), c.cfg.Tools.Ls),
+		tools.NewLsTool(c.permissions, c.cfg.WorkingDir(), c.cfg.AdditionalDirectories(), c.cfg.Tools.Ls),
 		tools.NewSourcegraphTool(nil),
+		tools.NewSymbolsTool(c.codeIndex, c.cfg.WorkingDir()),
 		tools.NewTodosTool(c.sessions),
-		tools.NewViewTool(c.lspClients, c.permissions, c.cfg.WorkingDir(), c.cfg.Options.SkillsPaths...),
-		tools.NewWriteTool(c.lspClients, c.permissions, c.history, c.cfg.WorkingDir()),
+		tools.NewViewTool(c.lspClients, c.permissions, c.cfg.WorkingDir(), c.cfg.AdditionalDirectories(), c.cfg.Options.SkillsPaths...),
+		tools.NewWriteTool(c.lspClients, c.permissions, c.history, c.cfg.WorkingDir(), c.cfg.AdditionalDirectories(), c.cfg.Tools.Format),
 	)
 
 	if len(c.cfg.LSP) > 0 {
-		allTools = append(allTools, tools.NewDiagnosticsTool(c.lspClients), tools.NewReferencesTool(c.lspClients), tools.NewLSPRestartTool(c.lspClients))
+		allTools = append(allTools,
+			tools.NewDiagnosticsTool(c.lspClients),
+			tools.NewReferencesTool(c.lspClients),
+			tools.NewHoverTool(c.lspClients),
+			tools.NewRenameTool(c.lspClients, c.permissions, c.history, c.cfg.WorkingDir(), c.cfg.AdditionalDirectories()),
+			tools.NewLSPRestartTool(c.lspClients),
+		)
 	}
 
 	var filteredTools []fantasy.AgentTool
@@ -444,6 +473,46 @@ func (c *coordinator) buildTools(ctx context.Context, agent config.Agent) ([]fan
 	return filteredTools, nil
 }
 
+// fetchCache returns the cache of the fetch tools, or nil if it's disabled.
+func (c *coordinator) fetchCache() *tools.FetchCache {
+	return tools.NewFetchCache(filepath.Join(c.cfg.Options.DataDirectory, "cache", "fetch"), c.cfg.Tools.Fetch)
+}
+
+// webSearchProvider returns the search provider configured for the
+// web_search tool. A misconfigured provider fails every search with the
+// configuration error rather than silently searching somewhere else.
+func (c *coordinator) webSearchProvider(client *http.Client) tools.SearchProvider {
+	cfg := c.cfg.Tools.WebSearch
+	name := cmp.Or(cfg.Provider, tools.SearchProviderDuckDuckGo)
+	if cfg.APIKey != "" {
+		apiKey, err := c.cfg.Resolve(cfg.APIKey)
+		if err != nil {
+			slog.Error("Failed to resolve the web search API key", "error", err)
+			return misconfiguredSearchProvider{name: name, err: fmt.Errorf("failed to resolve api_key: %w", err)}
+		}
+		cfg.APIKey = apiKey
+	}
+	provider, err := tools.NewSearchProvider(cfg, client)
+	if err != nil {
+		slog.Error("Invalid web search configuration", "error", err)
+		return misconfiguredSearchProvider{name: name, err: err}
+	}
+	return provider
+}
+
+// misconfiguredSearchProvider is a search provider whose configuration is
+// invalid.
+type misconfiguredSearchProvider struct {
+	name string
+	err  error
+}
+
+func (p misconfiguredSearchProvider) Name() string { return p.name }
+
+func (p misconfiguredSearchProvider) Search(context.Context, string, int) ([]tools.SearchResult, error) {
+	return nil, p.err
+}
+
 // TODO: when we support multiple agents we need to change this so that we pass in the agent specific model config
 func (c *coordinator) buildAgentModels(ctx context.Context, isSubAgent bool) (Model, Model, error) {
 	largeModelCfg, ok := c.cfg.Models[config.SelectedModelTypeLarge]
@@ -820,9 +889,23 @@ func (c *coordinator) UpdateModels(ctx context.Context) error {
 		return err
 	}
 	c.currentAgent.SetTools(tools)
+
+	// The system prompt describes the workspace, which may have changed too.
+	systemPrompt, err := c.coderPrompt.Build(ctx, large.Model.Provider(), large.Model.Model(), *c.cfg)
+	if err != nil {
+		return err
+	}
+	c.currentAgent.SetSystemPrompt(systemPrompt)
 	return nil
 }
 
+func (c *coordinator) Tools(ctx context.Context) ([]fantasy.AgentTool, error) {
+	if err := c.readyWg.Wait(); err != nil {
+		return nil, err
+	}
+	return c.currentAgent.Tools(), nil
+}
+
 func (c *coordinator) QueuedPrompts(sessionID string) int {
 	return c.currentAgent.QueuedPrompts(sessionID)
 }
@@ -27,16 +27,19 @@ type Prompt struct {
 }
 
 type PromptDat struct {
-	Provider      string
-	Model         string
-	Config        config.Config
-	WorkingDir    string
-	IsGitRepo     bool
-	Platform      string
-	Date          string
-	GitStatus     string
-	ContextFiles  []ContextFile
-	AvailSkillXML string
+	Provider   string
+	Model      string
+	Config     config.Config
+	WorkingDir string
+	// AdditionalDirs are the project directories besides the working
+	// directory.
+	AdditionalDirs []string
+	IsGitRepo      bool
+	Platform       string
+	Date           string
+	GitStatus      string
+	ContextFiles   []ContextFile
+	AvailSkillXML  string
 }
 
 type ContextFile struct {
@@ -176,16 +179,24 @@ func (p *Prompt) promptData(ctx context.Context, provider, model string, cfg con
 		}
 	}
 
+	var additionalDirs []string
+	if p.workingDir == "" || p.workingDir == cfg.WorkingDir() {
+		for _, dir := range cfg.AdditionalDirectories() {
+			additionalDirs = append(additionalDirs, filepath.ToSlash(dir))
+		}
+	}
+
 	isGit := isGitRepo(cfg.WorkingDir())
 	data := PromptDat{
-		Provider:      provider,
-		Model:         model,
-		Config:        cfg,
-		WorkingDir:    filepath.ToSlash(workingDir),
-		IsGitRepo:     isGit,
-		Platform:      platform,
-		Date:          p.now().Format("1/2/2006"),
-		AvailSkillXML: availSkillXML,
+		Provider:       provider,
+		Model:          model,
+		Config:         cfg,
+		WorkingDir:     filepath.ToSlash(workingDir),
+		AdditionalDirs: additionalDirs,
+		IsGitRepo:      isGit,
+		Platform:       platform,
+		Date:           p.now().Format("1/2/2006"),
+		AvailSkillXML:  availSkillXML,
 	}
 	if isGit {
 		var err error
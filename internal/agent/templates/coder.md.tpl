@@ -353,6 +353,9 @@ Adapt verbosity to match the work completed:
 
 <env>
 Working directory: {{.WorkingDir}}
+{{- if .AdditionalDirs}}
+Additional project directories: {{range $i, $dir := .AdditionalDirs}}{{if $i}}, {{end}}{{$dir}}{{end}}
+{{- end}}
 Is directory a git repo: {{if .IsGitRepo}}yes{{else}}no{{end}}
 Platform: {{.Platform}}
 Today's date: {{.Date}}
@@ -368,6 +371,8 @@ Git status (snapshot at conversation start - may be outdated):
 Diagnostics (lint/typecheck) included in tool output.
 - Fix issues in files you changed
 - Ignore issues in files you didn't touch (unless user asks)
+- Navigate code with lsp_hover and lsp_references rather than grep
+- Rename symbols with lsp_rename rather than editing each file by hand
 </lsp>
 {{end}}
 {{- if .AvailSkillXML}}
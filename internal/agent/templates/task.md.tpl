@@ -8,6 +8,9 @@ You are an agent for Crush. Given the user's prompt, you should use the tools av
 
 <env>
 Working directory: {{.WorkingDir}}
+{{- if .AdditionalDirs}}
+Additional project directories: {{range $i, $dir := .AdditionalDirs}}{{if $i}}, {{end}}{{$dir}}{{end}}
+{{- end}}
 Is directory a git repo: {{if .IsGitRepo}} yes {{else}} no {{end}}
 Platform: {{.Platform}}
 Today's date: {{.Date}}
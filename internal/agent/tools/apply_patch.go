@@ -0,0 +1,181 @@
+package tools
+
+import (
+	"context"
+	_ "embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"time"
+
+	"charm.land/fantasy"
+	"github.com/charmbracelet/crush/internal/csync"
+	"github.com/charmbracelet/crush/internal/diff"
+	"github.com/charmbracelet/crush/internal/filepathext"
+	"github.com/charmbracelet/crush/internal/filetracker"
+	"github.com/charmbracelet/crush/internal/fsext"
+	"github.com/charmbracelet/crush/internal/history"
+	"github.com/charmbracelet/crush/internal/lsp"
+	"github.com/charmbracelet/crush/internal/lsp/util"
+	"github.com/charmbracelet/crush/internal/permission"
+)
+
+type ApplyPatchParams struct {
+	Patch string `json:"patch" description:"The patch to apply: a unified diff, or the structured format starting with *** Begin Patch and ending with *** End Patch"`
+}
+
+const ApplyPatchToolName = "apply_patch"
+
+//go:embed apply_patch.md
+var applyPatchDescription []byte
+
+func NewApplyPatchTool(lspClients *csync.Map[string, *lsp.Client], permissions permission.Service, files history.Service, workingDir string, additionalDirs []string) fantasy.AgentTool {
+	return fantasy.NewAgentTool(
+		ApplyPatchToolName,
+		string(applyPatchDescription),
+		func(ctx context.Context, params ApplyPatchParams, call fantasy.ToolCall) (fantasy.ToolResponse, error) {
+			if params.Patch == "" {
+				return fantasy.NewTextErrorResponse("patch is required"), nil
+			}
+
+			sessionID := GetSessionFromContext(ctx)
+			if sessionID == "" {
+				return fantasy.ToolResponse{}, fmt.Errorf("session ID is required for applying a patch")
+			}
+
+			patches, err := diff.ParsePatch(params.Patch)
+			if err != nil {
+				return fantasy.NewTextErrorResponse(fmt.Sprintf("invalid patch: %s", err)), nil
+			}
+
+			// Compute every change before writing anything, so the patch is
+			// either applied entirely or not at all.
+			changes, err := patchChanges(workingDir, patches)
+			if err != nil {
+				return fantasy.NewTextErrorResponse(fmt.Sprintf("patch not applied, no file was changed: %s", err)), nil
+			}
+
+			edit := workspaceEditContext{ctx, lspClients, permissions, files, workingDir, additionalDirs}
+			description := fmt.Sprintf("Apply patch to %d file(s)", len(changes))
+			return applyFileChanges(edit, sessionID, ApplyPatchToolName, description, changes, call)
+		})
+}
+
+// patchChanges applies the patches to the files in memory and returns the
+// resulting changes. Patches to a file already changed by a previous patch
+// apply on top of it.
+func patchChanges(workingDir string, patches []diff.FilePatch) ([]util.FileChange, error) {
+	var changes []util.FileChange
+	indexes := make(map[string]int)
+	// current returns the content of the file after the previous patches,
+	// and whether it exists.
+	current := func(path string) (string, bool, error) {
+		if idx, ok := indexes[path]; ok {
+			return changes[idx].NewContent, !changes[idx].Deleted, nil
+		}
+		content, err := readFileForPatch(path)
+		if errors.Is(err, fs.ErrNotExist) {
+			return "", false, nil
+		}
+		return content, err == nil, err
+	}
+	set := func(change util.FileChange) {
+		if idx, ok := indexes[change.Path]; ok {
+			// Keep the original content and state of the file.
+			change.OldContent = changes[idx].OldContent
+			change.Created = changes[idx].Created && !change.Deleted
+			changes[idx] = change
+			return
+		}
+		indexes[change.Path] = len(changes)
+		changes = append(changes, change)
+	}
+
+	for _, patch := range patches {
+		switch {
+		case patch.IsCreate():
+			path := filepathext.SmartJoin(workingDir, patch.NewPath)
+			if _, exists, err := current(path); err != nil {
+				return nil, err
+			} else if exists {
+				return nil, fmt.Errorf("cannot add %s: the file already exists", patch.NewPath)
+			}
+			content, err := patch.Apply("")
+			if err != nil {
+				return nil, err
+			}
+			set(util.FileChange{Path: path, NewContent: content, Created: true})
+
+		case patch.IsDelete():
+			path := filepathext.SmartJoin(workingDir, patch.OldPath)
+			content, exists, err := current(path)
+			if err != nil {
+				return nil, err
+			} else if !exists {
+				return nil, fmt.Errorf("cannot delete %s: the file does not exist", patch.OldPath)
+			}
+			set(util.FileChange{Path: path, OldContent: content, Deleted: true})
+
+		default:
+			oldPath := filepathext.SmartJoin(workingDir, patch.OldPath)
+			newPath := filepathext.SmartJoin(workingDir, patch.NewPath)
+			content, exists, err := current(oldPath)
+			if err != nil {
+				return nil, err
+			} else if !exists {
+				return nil, fmt.Errorf("cannot update %s: the file does not exist", patch.OldPath)
+			}
+
+			unixContent, isCrlf := fsext.ToUnixLineEndings(content)
+			newContent, err := patch.Apply(unixContent)
+			if err != nil {
+				return nil, err
+			}
+			if isCrlf {
+				newContent, _ = fsext.ToWindowsLineEndings(newContent)
+			}
+
+			if oldPath == newPath {
+				set(util.FileChange{Path: oldPath, OldContent: content, NewContent: newContent})
+				continue
+			}
+			if _, exists, err := current(newPath); err != nil {
+				return nil, err
+			} else if exists {
+				return nil, fmt.Errorf("cannot move %s to %s: the file already exists", patch.OldPath, patch.NewPath)
+			}
+			set(util.FileChange{Path: oldPath, OldContent: content, Deleted: true})
+			set(util.FileChange{Path: newPath, NewContent: newContent, Created: true})
+		}
+	}
+	return changes, nil
+}
+
+// readFileForPatch reads a file the patch changes, making sure it was read
+// before and hasn't been modified since.
+func readFileForPatch(path string) (string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", err
+	}
+	if info.IsDir() {
+		return "", fmt.Errorf("path is a directory, not a file: %s", path)
+	}
+
+	lastRead := filetracker.LastReadTime(path)
+	if lastRead.IsZero() {
+		return "", fmt.Errorf("you must read %s before patching it. Use the View tool first", path)
+	}
+	if modTime := info.ModTime(); modTime.After(lastRead) {
+		return "", fmt.Errorf("file %s has been modified since it was last read (mod time: %s, last read: %s)",
+			path, modTime.Format(time.RFC3339), lastRead.Format(time.RFC3339),
+		)
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read file: %w", err)
+	}
+	return string(content), nil
+}
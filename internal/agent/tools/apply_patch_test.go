@@ -0,0 +1,86 @@
+package tools
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"charm.land/fantasy"
+	"github.com/charmbracelet/crush/internal/csync"
+	"github.com/charmbracelet/crush/internal/filetracker"
+	"github.com/charmbracelet/crush/internal/history"
+	"github.com/charmbracelet/crush/internal/lsp"
+	"github.com/charmbracelet/crush/internal/permission"
+	"github.com/charmbracelet/crush/internal/pubsub"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApplyPatchTool(t *testing.T) {
+	t.Parallel()
+
+	tmpDir := t.TempDir()
+	a := filepath.Join(tmpDir, "a.go")
+	b := filepath.Join(tmpDir, "b.go")
+	require.NoError(t, os.WriteFile(a, []byte("package main\n\nfunc Foo() {}\n"), 0o644))
+	require.NoError(t, os.WriteFile(b, []byte("package main\n\nvar x = Foo()\n"), 0o644))
+
+	tool := NewApplyPatchTool(
+		csync.NewMap[string, *lsp.Client](),
+		&mockPermissionService{Broker: pubsub.NewBroker[permission.PermissionRequest]()},
+		&mockHistoryService{Broker: pubsub.NewBroker[history.File]()},
+		tmpDir,
+		nil,
+	)
+	run := func(patch string) fantasy.ToolResponse {
+		return runTool(t, tool, ApplyPatchParams{Patch: patch})
+	}
+
+	patch := `--- a/a.go
++++ b/a.go
+@@ -3 +3 @@
+-func Foo() {}
++func Bar() {}
+--- a/b.go
++++ b/b.go
+@@ -3 +3 @@
+-var x = Foo()
++var x = Bar()
+--- /dev/null
++++ b/c.go
+@@ -0,0 +1 @@
++package main
+`
+
+	resp := run(patch)
+	require.True(t, resp.IsError)
+	require.Contains(t, resp.Content, "you must read")
+
+	filetracker.RecordRead(a)
+	filetracker.RecordRead(b)
+
+	// A hunk that doesn't match leaves every file untouched.
+	resp = run("--- a/a.go\n+++ b/a.go\n@@ -3 +3 @@\n-func Foo() {}\n+func Bar() {}\n--- a/b.go\n+++ b/b.go\n@@ -3 +3 @@\n-var y = 1\n+var y = 2\n")
+	require.True(t, resp.IsError)
+	require.Contains(t, resp.Content, "hunk 1 of b.go does not match")
+	content, err := os.ReadFile(a)
+	require.NoError(t, err)
+	require.Equal(t, "package main\n\nfunc Foo() {}\n", string(content))
+
+	resp = run(patch)
+	require.False(t, resp.IsError, resp.Content)
+	require.Contains(t, resp.Content, "Changed 3 file(s)")
+
+	content, err = os.ReadFile(a)
+	require.NoError(t, err)
+	require.Equal(t, "package main\n\nfunc Bar() {}\n", string(content))
+	content, err = os.ReadFile(b)
+	require.NoError(t, err)
+	require.Equal(t, "package main\n\nvar x = Bar()\n", string(content))
+	content, err = os.ReadFile(filepath.Join(tmpDir, "c.go"))
+	require.NoError(t, err)
+	require.Equal(t, "package main\n", string(content))
+
+	resp = run("*** Begin Patch\n*** Add File: c.go\n+package c\n*** End Patch")
+	require.True(t, resp.IsError)
+	require.Contains(t, resp.Content, "already exists")
+}
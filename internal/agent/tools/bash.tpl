@@ -26,7 +26,9 @@ Common shell builtins and core utils available on Windows.
 <background_execution>
 - Set run_in_background=true to run commands in a separate background shell
 - Returns a shell ID for managing the background process
-- Use job_output tool to view current output from background shell
+- Use job_output tool to view current output from background shell, passing the returned cursor to only see new output
+- Use job_wait tool to wait for a pattern in the output (e.g. a dev server's "listening on" line) or for the shell to exit, instead of polling job_output
+- Use job_list tool to list the background shells and their status
 - Use job_kill tool to terminate a background shell
 - IMPORTANT: NEVER use `&` at the end of commands to run in background - use run_in_background parameter instead
 - Commands that should run in background:
@@ -46,11 +48,11 @@ Common shell builtins and core utils available on Windows.
 When user asks to create git commit:
 
 1. Single message with three tool_use blocks (IMPORTANT for speed):
-   - git status (untracked files)
-   - git diff (staged/unstaged changes)
-   - git log (recent commit message style)
+   - git_status (staged, unstaged and untracked files)
+   - git_diff (staged/unstaged changes)
+   - git_log (recent commit message style)
 
-2. Add relevant untracked files to staging. Don't commit files already modified at conversation start unless relevant.
+2. Pick the relevant files to commit, including untracked ones. Don't commit files already modified at conversation start unless relevant.
 
 3. Analyze staged changes in <commit_analysis> tags:
    - List changed/added files, summarize nature (feature/enhancement/bug fix/refactoring/test/docs)
@@ -60,29 +62,13 @@ When user asks to create git commit:
    - Use clear language, accurate reflection ("add"=new feature, "update"=enhancement, "fix"=bug fix)
    - Avoid generic messages, review draft
 
-4. Create commit{{ if or (eq .Attribution.TrailerStyle "assisted-by") (eq .Attribution.TrailerStyle "co-authored-by")}} with attribution{{ end }} using HEREDOC:
-   git commit -m "$(cat <<'EOF'
-   Commit message here.
-
-{{ if .Attribution.GeneratedWith }}
-   💘 Generated with Crush
-{{ end}}
-{{if eq .Attribution.TrailerStyle "assisted-by" }}
-
-   Assisted-by: {{ .ModelName }} via Crush <crush@charm.land>
-{{ else if eq .Attribution.TrailerStyle "co-authored-by" }}
-
-   Co-Authored-By: Crush <crush@charm.land>
-{{ end }}
-
-   EOF
-   )"
+4. Create commit with the git_commit tool, passing the files to stage. It adds the configured attribution to the message itself, so don't add any.
 
-5. If pre-commit hook fails, retry ONCE. If fails again, hook preventing commit. If succeeds but files modified, MUST amend.
+5. If pre-commit hook fails, retry ONCE. If fails again, hook preventing commit. If succeeds but files modified, commit the modified files too.
 
-6. Run git status to verify.
+6. Run git_status to verify.
 
-Notes: Use "git commit -am" when possible, don't stage unrelated files, NEVER update config, don't push, no -i flags, no empty commits, return empty response.
+Notes: Use all=true on git_commit when possible, don't stage unrelated files, NEVER update config, don't push, no empty commits, return empty response.
 </git_commits>
 
 <pull_requests>
@@ -33,7 +33,7 @@ func NewDiagnosticsTool(lspClients *csync.Map[string, *lsp.Client]) fantasy.Agen
 				return fantasy.NewTextErrorResponse("no LSP clients available"), nil
 			}
 			notifyLSPs(ctx, lspClients, params.FilePath)
-			output := getDiagnostics(params.FilePath, lspClients)
+			output := getDiagnostics(ctx, params.FilePath, lspClients)
 			return fantasy.NewTextResponse(output), nil
 		})
 }
@@ -52,44 +52,56 @@ func notifyLSPs(ctx context.Context, lsps *csync.Map[string, *lsp.Client], filep
 	}
 }
 
-func getDiagnostics(filePath string, lsps *csync.Map[string, *lsp.Client]) string {
+// getDiagnostics formats the diagnostics of the file and of the project.
+// When the context holds a snapshot of the diagnostics taken at the start of
+// the turn, the diagnostics introduced since then are listed on their own.
+func getDiagnostics(ctx context.Context, filePath string, lsps *csync.Map[string, *lsp.Client]) string {
+	newDiagnostics := []string{}
 	fileDiagnostics := []string{}
 	projectDiagnostics := []string{}
 
-	for lspName, client := range lsps.Seq2() {
-		for location, diags := range client.GetDiagnostics() {
-			path, err := location.Path()
-			if err != nil {
-				slog.Error("Failed to convert diagnostic location URI to path", "uri", location, "error", err)
-				continue
-			}
-			isCurrentFile := path == filePath
-			for _, diag := range diags {
-				formattedDiag := formatDiagnostic(path, diag, lspName)
-				if isCurrentFile {
-					fileDiagnostics = append(fileDiagnostics, formattedDiag)
-				} else {
-					projectDiagnostics = append(projectDiagnostics, formattedDiag)
-				}
-			}
-		}
+	var isNew func(lspName, path string, diag protocol.Diagnostic) bool
+	if snapshot := GetDiagnosticsSnapshotFromContext(ctx); snapshot != nil {
+		isNew = snapshot.newDiagnostics()
 	}
 
+	walkDiagnostics(lsps, func(lspName, path string, diag protocol.Diagnostic) {
+		formattedDiag := formatDiagnostic(path, diag, lspName)
+		switch {
+		case isNew != nil && isNew(lspName, path, diag):
+			newDiagnostics = append(newDiagnostics, formattedDiag)
+		case path == filePath:
+			fileDiagnostics = append(fileDiagnostics, formattedDiag)
+		default:
+			projectDiagnostics = append(projectDiagnostics, formattedDiag)
+		}
+	})
+
+	sortDiagnostics(newDiagnostics)
 	sortDiagnostics(fileDiagnostics)
 	sortDiagnostics(projectDiagnostics)
 
 	var output strings.Builder
+	writeDiagnostics(&output, "new_diagnostics", newDiagnostics)
 	writeDiagnostics(&output, "file_diagnostics", fileDiagnostics)
 	writeDiagnostics(&output, "project_diagnostics", projectDiagnostics)
 
-	if len(fileDiagnostics) > 0 || len(projectDiagnostics) > 0 {
+	if len(newDiagnostics) > 0 || len(fileDiagnostics) > 0 || len(projectDiagnostics) > 0 {
 		fileErrors := countSeverity(fileDiagnostics, "Error")
 		fileWarnings := countSeverity(fileDiagnostics, "Warn")
 		projectErrors := countSeverity(projectDiagnostics, "Error")
 		projectWarnings := countSeverity(projectDiagnostics, "Warn")
 		output.WriteString("\n<diagnostic_summary>\n")
-		fmt.Fprintf(&output, "Current file: %d errors, %d warnings\n", fileErrors, fileWarnings)
-		fmt.Fprintf(&output, "Project: %d errors, %d warnings\n", projectErrors, projectWarnings)
+		if isNew != nil {
+			newErrors := countSeverity(newDiagnostics, "Error")
+			newWarnings := countSeverity(newDiagnostics, "Warn")
+			fmt.Fprintf(&output, "New this turn: %d errors, %d warnings\n", newErrors, newWarnings)
+			fmt.Fprintf(&output, "Current file, pre-existing: %d errors, %d warnings\n", fileErrors, fileWarnings)
+			fmt.Fprintf(&output, "Project, pre-existing: %d errors, %d warnings\n", projectErrors, projectWarnings)
+		} else {
+			fmt.Fprintf(&output, "Current file: %d errors, %d warnings\n", fileErrors, fileWarnings)
+			fmt.Fprintf(&output, "Project: %d errors, %d warnings\n", projectErrors, projectWarnings)
+		}
 		output.WriteString("</diagnostic_summary>\n")
 	}
 
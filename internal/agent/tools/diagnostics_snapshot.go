@@ -0,0 +1,91 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/charmbracelet/crush/internal/csync"
+	"github.com/charmbracelet/crush/internal/lsp"
+	"github.com/charmbracelet/x/powernap/pkg/lsp/protocol"
+)
+
+// DiagnosticsSnapshot counts the diagnostics reported by the LSP servers at
+// some point, such as the start of a turn, to tell the diagnostics introduced
+// since then from the pre-existing ones. Positions are left out of the key so
+// diagnostics moved by an edit aren't reported as new.
+type DiagnosticsSnapshot map[string]int
+
+// NewDiagnosticsSnapshot returns a snapshot of the current diagnostics of all
+// the LSP clients.
+func NewDiagnosticsSnapshot(lsps *csync.Map[string, *lsp.Client]) DiagnosticsSnapshot {
+	snapshot := DiagnosticsSnapshot{}
+	walkDiagnostics(lsps, func(lspName, path string, diag protocol.Diagnostic) {
+		snapshot[diagnosticKey(lspName, path, diag)]++
+	})
+	return snapshot
+}
+
+// newDiagnostics returns a function telling whether a diagnostic is missing
+// from the snapshot. Each diagnostic of the snapshot accounts for a single
+// current diagnostic, so duplicates are told apart.
+func (s DiagnosticsSnapshot) newDiagnostics() func(lspName, path string, diag protocol.Diagnostic) bool {
+	remaining := make(map[string]int, len(s))
+	for key, count := range s {
+		remaining[key] = count
+	}
+	return func(lspName, path string, diag protocol.Diagnostic) bool {
+		key := diagnosticKey(lspName, path, diag)
+		if remaining[key] > 0 {
+			remaining[key]--
+			return false
+		}
+		return true
+	}
+}
+
+// IntroducedErrors returns the errors reported by the LSP servers that are
+// not in the snapshot, formatted for the model.
+func (s DiagnosticsSnapshot) IntroducedErrors(lsps *csync.Map[string, *lsp.Client]) []string {
+	isNew := s.newDiagnostics()
+	var errs []string
+	walkDiagnostics(lsps, func(lspName, path string, diag protocol.Diagnostic) {
+		if isNew(lspName, path, diag) && diag.Severity == protocol.SeverityError {
+			errs = append(errs, formatDiagnostic(path, diag, lspName))
+		}
+	})
+	return sortDiagnostics(errs)
+}
+
+type diagnosticsSnapshotContextKey string
+
+// DiagnosticsSnapshotContextKey is the key for the snapshot of the
+// diagnostics taken at the start of the turn in the context.
+const DiagnosticsSnapshotContextKey diagnosticsSnapshotContextKey = "diagnostics_snapshot"
+
+// GetDiagnosticsSnapshotFromContext returns the snapshot of the diagnostics
+// taken at the start of the turn, or nil if there is none.
+func GetDiagnosticsSnapshotFromContext(ctx context.Context) DiagnosticsSnapshot {
+	snapshot, _ := ctx.Value(DiagnosticsSnapshotContextKey).(DiagnosticsSnapshot)
+	return snapshot
+}
+
+// walkDiagnostics calls fn for every diagnostic of every LSP client.
+func walkDiagnostics(lsps *csync.Map[string, *lsp.Client], fn func(lspName, path string, diag protocol.Diagnostic)) {
+	for lspName, client := range lsps.Seq2() {
+		for location, diags := range client.GetDiagnostics() {
+			path, err := location.Path()
+			if err != nil {
+				slog.Error("Failed to convert diagnostic location URI to path", "uri", location, "error", err)
+				continue
+			}
+			for _, diag := range diags {
+				fn(lspName, path, diag)
+			}
+		}
+	}
+}
+
+func diagnosticKey(lspName, path string, diag protocol.Diagnostic) string {
+	return fmt.Sprintf("%s\x00%s\x00%d\x00%s\x00%v\x00%s", lspName, path, diag.Severity, diag.Source, diag.Code, diag.Message)
+}
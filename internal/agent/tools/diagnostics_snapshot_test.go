@@ -0,0 +1,37 @@
+package tools
+
+import (
+	"testing"
+
+	"github.com/charmbracelet/x/powernap/pkg/lsp/protocol"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiagnosticsSnapshot(t *testing.T) {
+	t.Parallel()
+
+	unused := protocol.Diagnostic{
+		Severity: protocol.SeverityWarning,
+		Source:   "compiler",
+		Message:  "x declared and not used",
+		Range:    protocol.Range{Start: protocol.Position{Line: 3}},
+	}
+	undefined := protocol.Diagnostic{
+		Severity: protocol.SeverityError,
+		Source:   "compiler",
+		Message:  "undefined: y",
+	}
+
+	snapshot := DiagnosticsSnapshot{diagnosticKey("gopls", "/a.go", unused): 1}
+
+	isNew := snapshot.newDiagnostics()
+	moved := unused
+	moved.Range.Start.Line = 10
+	require.False(t, isNew("gopls", "/a.go", moved), "moved diagnostics are not new")
+	require.True(t, isNew("gopls", "/a.go", unused), "duplicates of known diagnostics are new")
+	require.True(t, isNew("gopls", "/a.go", undefined))
+	require.True(t, isNew("gopls", "/b.go", unused), "same diagnostic in another file is new")
+
+	// Each call starts over.
+	require.False(t, snapshot.newDiagnostics()("gopls", "/a.go", unused))
+}
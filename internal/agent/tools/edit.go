@@ -3,6 +3,7 @@ package tools
 import (
 	"context"
 	_ "embed"
+	"encoding/json"
 	"fmt"
 	"log/slog"
 	"os"
@@ -11,6 +12,7 @@ import (
 	"time"
 
 	"charm.land/fantasy"
+	"github.com/charmbracelet/crush/internal/config"
 	"github.com/charmbracelet/crush/internal/csync"
 	"github.com/charmbracelet/crush/internal/diff"
 	"github.com/charmbracelet/crush/internal/filepathext"
@@ -44,10 +46,7 @@ type EditResponseMetadata struct {
 
 const EditToolName = "edit"
 
-var (
-	oldStringNotFoundErr        = fantasy.NewTextErrorResponse("old_string not found in file. Make sure it matches exactly, including whitespace and line breaks.")
-	oldStringMultipleMatchesErr = fantasy.NewTextErrorResponse("old_string appears multiple times in the file. Please provide more context to ensure a unique match, or set replace_all to true")
-)
+var oldStringNotFoundErr = fantasy.NewTextErrorResponse("old_string not found in file. Make sure it matches exactly, including whitespace and line breaks.")
 
 //go:embed edit.md
 var editDescription []byte
@@ -57,9 +56,23 @@ type editContext struct {
 	permissions permission.Service
 	files       history.Service
 	workingDir  string
+	// additionalDirs are the workspace roots besides the working directory.
+	additionalDirs []string
+}
+
+// permissionPath returns the path permission to change a file is asked for:
+// the workspace root the file is in, so that allowing it for the session
+// covers the whole root, or the file itself when it's outside the workspace.
+func permissionPath(filePath, workingDir string, additionalDirs []string) string {
+	for _, root := range append([]string{workingDir}, additionalDirs...) {
+		if config.IsInRoots(filePath, root) {
+			return root
+		}
+	}
+	return filePath
 }
 
-func NewEditTool(lspClients *csync.Map[string, *lsp.Client], permissions permission.Service, files history.Service, workingDir string) fantasy.AgentTool {
+func NewEditTool(lspClients *csync.Map[string, *lsp.Client], permissions permission.Service, files history.Service, workingDir string, additionalDirs []string, formatCfg config.ToolFormat) fantasy.AgentTool {
 	return fantasy.NewAgentTool(
 		EditToolName,
 		string(editDescription),
@@ -73,7 +86,7 @@ func NewEditTool(lspClients *csync.Map[string, *lsp.Client], permissions permiss
 			var response fantasy.ToolResponse
 			var err error
 
-			editCtx := editContext{ctx, permissions, files, workingDir}
+			editCtx := editContext{ctx, permissions, files, workingDir, additionalDirs}
 
 			if params.OldString == "" {
 				response, err = createNewFile(editCtx, params.FilePath, params.NewString, call)
@@ -92,15 +105,32 @@ func NewEditTool(lspClients *csync.Map[string, *lsp.Client], permissions permiss
 				return response, nil
 			}
 
+			formatted, formatNote := formatAfterWrite(ctx, files, formatCfg, workingDir, params.FilePath)
+			if formatted != "" {
+				response = withFormattedContent(response, formatted, strings.TrimPrefix(params.FilePath, workingDir))
+			}
 			notifyLSPs(ctx, lspClients, params.FilePath)
 
 			text := fmt.Sprintf("<result>\n%s\n</result>\n", response.Content)
-			text += getDiagnostics(params.FilePath, lspClients)
+			text += formatNote
+			text += getDiagnostics(ctx, params.FilePath, lspClients)
 			response.Content = text
 			return response, nil
 		})
 }
 
+// withFormattedContent updates the metadata of an edit response to the
+// content of the file after it was formatted.
+func withFormattedContent(response fantasy.ToolResponse, formatted, displayPath string) fantasy.ToolResponse {
+	var meta EditResponseMetadata
+	if err := json.Unmarshal([]byte(response.Metadata), &meta); err != nil {
+		return response
+	}
+	meta.NewContent = formatted
+	_, meta.Additions, meta.Removals = diff.GenerateDiff(meta.OldContent, formatted, displayPath)
+	return fantasy.WithResponseMetadata(response, meta)
+}
+
 func createNewFile(edit editContext, filePath, content string, call fantasy.ToolCall) (fantasy.ToolResponse, error) {
 	fileInfo, err := os.Stat(filePath)
 	if err == nil {
@@ -130,7 +160,7 @@ func createNewFile(edit editContext, filePath, content string, call fantasy.Tool
 	p, err := edit.permissions.Request(edit.ctx,
 		permission.CreatePermissionRequest{
 			SessionID:   sessionID,
-			Path:        fsext.PathOrPrefix(filePath, edit.workingDir),
+			Path:        permissionPath(filePath, edit.workingDir, edit.additionalDirs),
 			ToolCallID:  call.ID,
 			ToolName:    EditToolName,
 			Action:      "write",
@@ -215,7 +245,7 @@ func deleteContent(edit editContext, filePath, oldString string, replaceAll bool
 
 	oldContent, isCrlf := fsext.ToUnixLineEndings(string(content))
 
-	var newContent string
+	var newContent, strategy string
 
 	if replaceAll {
 		newContent = strings.ReplaceAll(oldContent, oldString, "")
@@ -223,17 +253,12 @@ func deleteContent(edit editContext, filePath, oldString string, replaceAll bool
 			return oldStringNotFoundErr, nil
 		}
 	} else {
-		index := strings.Index(oldContent, oldString)
-		if index == -1 {
-			return oldStringNotFoundErr, nil
-		}
-
-		lastIndex := strings.LastIndex(oldContent, oldString)
-		if index != lastIndex {
-			return fantasy.NewTextErrorResponse("old_string appears multiple times in the file. Please provide more context to ensure a unique match, or set replace_all to true"), nil
+		match, err := findEditMatch(oldContent, oldString, "")
+		if err != nil {
+			return fantasy.NewTextErrorResponse(err.Error()), nil
 		}
-
-		newContent = oldContent[:index] + oldContent[index+len(oldString):]
+		newContent = oldContent[:match.start] + oldContent[match.end:]
+		strategy = match.strategy
 	}
 
 	sessionID := GetSessionFromContext(edit.ctx)
@@ -251,7 +276,7 @@ func deleteContent(edit editContext, filePath, oldString string, replaceAll bool
 	p, err := edit.permissions.Request(edit.ctx,
 		permission.CreatePermissionRequest{
 			SessionID:   sessionID,
-			Path:        fsext.PathOrPrefix(filePath, edit.workingDir),
+			Path:        permissionPath(filePath, edit.workingDir, edit.additionalDirs),
 			ToolCallID:  call.ID,
 			ToolName:    EditToolName,
 			Action:      "write",
@@ -305,7 +330,7 @@ func deleteContent(edit editContext, filePath, oldString string, replaceAll bool
 	filetracker.RecordRead(filePath)
 
 	return fantasy.WithResponseMetadata(
-		fantasy.NewTextResponse("Content deleted from file: "+filePath),
+		fantasy.NewTextResponse(editResultText("Content deleted from file: "+filePath, strategy)),
 		EditResponseMetadata{
 			OldContent: oldContent,
 			NewContent: newContent,
@@ -348,22 +373,17 @@ func replaceContent(edit editContext, filePath, oldString, newString string, rep
 
 	oldContent, isCrlf := fsext.ToUnixLineEndings(string(content))
 
-	var newContent string
+	var newContent, strategy string
 
 	if replaceAll {
 		newContent = strings.ReplaceAll(oldContent, oldString, newString)
 	} else {
-		index := strings.Index(oldContent, oldString)
-		if index == -1 {
-			return oldStringNotFoundErr, nil
-		}
-
-		lastIndex := strings.LastIndex(oldContent, oldString)
-		if index != lastIndex {
-			return oldStringMultipleMatchesErr, nil
+		match, err := findEditMatch(oldContent, oldString, newString)
+		if err != nil {
+			return fantasy.NewTextErrorResponse(err.Error()), nil
 		}
-
-		newContent = oldContent[:index] + newString + oldContent[index+len(oldString):]
+		newContent = oldContent[:match.start] + match.newString + oldContent[match.end:]
+		strategy = match.strategy
 	}
 
 	if oldContent == newContent {
@@ -383,7 +403,7 @@ func replaceContent(edit editContext, filePath, oldString, newString string, rep
 	p, err := edit.permissions.Request(edit.ctx,
 		permission.CreatePermissionRequest{
 			SessionID:   sessionID,
-			Path:        fsext.PathOrPrefix(filePath, edit.workingDir),
+			Path:        permissionPath(filePath, edit.workingDir, edit.additionalDirs),
 			ToolCallID:  call.ID,
 			ToolName:    EditToolName,
 			Action:      "write",
@@ -437,7 +457,7 @@ func replaceContent(edit editContext, filePath, oldString, newString string, rep
 	filetracker.RecordRead(filePath)
 
 	return fantasy.WithResponseMetadata(
-		fantasy.NewTextResponse("Content replaced in file: "+filePath),
+		fantasy.NewTextResponse(editResultText("Content replaced in file: "+filePath, strategy)),
 		EditResponseMetadata{
 			OldContent: oldContent,
 			NewContent: newContent,
@@ -445,3 +465,12 @@ func replaceContent(edit editContext, filePath, oldString, newString string, rep
 			Removals:   removals,
 		}), nil
 }
+
+// editResultText returns the result of an edit, noting how old_string matched
+// when it didn't match exactly.
+func editResultText(result, strategy string) string {
+	if strategy == "" {
+		return result
+	}
+	return fmt.Sprintf("%s (old_string matched %s; view the file to check the result)", result, strategy)
+}
@@ -0,0 +1,156 @@
+package tools
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/crush/internal/diff"
+)
+
+// minClosestSimilarity is the similarity above which the closest lines are
+// shown when old_string is not found.
+const minClosestSimilarity = 0.5
+
+var errOldStringMultipleMatches = errors.New("old_string appears multiple times in the file. Please provide more context to ensure a unique match, or set replace_all to true")
+
+// editMatch is where old_string was found in the content.
+type editMatch struct {
+	start, end int
+	// newString is the text replacing the match: new_string, re-indented
+	// when old_string matched lines with a different indentation.
+	newString string
+	// strategy describes how old_string matched when it didn't match
+	// exactly, empty otherwise.
+	strategy string
+}
+
+// findEditMatch finds the unique occurrence of old_string in the content.
+// When there is no exact match, it looks for whole lines matching old_string
+// with each of [diff.LineMatchers], then for a unique block of lines similar
+// enough to it.
+func findEditMatch(content, oldString, newString string) (editMatch, error) {
+	if index := strings.Index(content, oldString); index != -1 {
+		if strings.LastIndex(content, oldString) != index {
+			return editMatch{}, errOldStringMultipleMatches
+		}
+		return editMatch{start: index, end: index + len(oldString), newString: newString}, nil
+	}
+
+	lines := strings.Split(content, "\n")
+	oldLines := strings.Split(strings.TrimSuffix(oldString, "\n"), "\n")
+	if strings.TrimSpace(oldString) == "" || len(oldLines) > len(lines) {
+		return editMatch{}, oldStringNotFoundError(lines, nil, 0)
+	}
+
+	for _, matcher := range diff.LineMatchers {
+		var found []int
+		for i := 0; i+len(oldLines) <= len(lines); i++ {
+			if diff.LinesMatch(lines[i:i+len(oldLines)], oldLines, matcher.Match) {
+				found = append(found, i)
+			}
+		}
+		switch len(found) {
+		case 0:
+			continue
+		case 1:
+			return lineEditMatch(content, lines, oldLines, found[0], oldString, newString, matcher.Description), nil
+		default:
+			return editMatch{}, errOldStringMultipleMatches
+		}
+	}
+
+	best, score, unique := diff.ClosestLines(lines, oldLines)
+	if best != -1 && score >= diff.FuzzyMatchThreshold && unique && len(oldLines) > 1 {
+		description := fmt.Sprintf("approximately (%.0f%% similar)", score*100)
+		return lineEditMatch(content, lines, oldLines, best, oldString, newString, description), nil
+	}
+	if score < minClosestSimilarity {
+		best = -1
+	}
+	return editMatch{}, oldStringNotFoundError(lines, oldLines, best)
+}
+
+// lineEditMatch returns the match of old_string on the lines of the file
+// starting at the given line.
+func lineEditMatch(content string, lines, oldLines []string, line int, oldString, newString, strategy string) editMatch {
+	start := 0
+	for _, l := range lines[:line] {
+		start += len(l) + 1
+	}
+	end := start
+	for _, l := range lines[line : line+len(oldLines)] {
+		end += len(l) + 1
+	}
+	// Only include the newline after the last line if old_string has one.
+	if !strings.HasSuffix(oldString, "\n") || end > len(content) {
+		end--
+	}
+	return editMatch{
+		start:     start,
+		end:       end,
+		newString: reindent(newString, oldLines, lines[line:line+len(oldLines)]),
+		strategy:  strategy,
+	}
+}
+
+// reindent changes the indentation of new_string the way the indentation of
+// old_string differs from the one of the lines it matched, when every
+// indentation of old_string matched a single one in the file.
+func reindent(newString string, oldLines, fileLines []string) string {
+	indents := make(map[string]string)
+	changed := false
+	for i, oldLine := range oldLines {
+		if strings.TrimSpace(oldLine) == "" || strings.TrimSpace(fileLines[i]) == "" {
+			continue
+		}
+		o, f := indentation(oldLine), indentation(fileLines[i])
+		if existing, ok := indents[o]; ok && existing != f {
+			return newString
+		}
+		indents[o] = f
+		changed = changed || o != f
+	}
+	if !changed {
+		return newString
+	}
+
+	newLines := strings.Split(newString, "\n")
+	for i, line := range newLines {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		indent := indentation(line)
+		if f, ok := indents[indent]; ok {
+			newLines[i] = f + line[len(indent):]
+			continue
+		}
+		// Lines indented deeper than any line of old_string keep the extra
+		// indentation after the longest known one.
+		longest := ""
+		for o := range indents {
+			if strings.HasPrefix(indent, o) && len(o) >= len(longest) {
+				longest = o
+			}
+		}
+		if f, ok := indents[longest]; ok {
+			newLines[i] = f + line[len(longest):]
+		}
+	}
+	return strings.Join(newLines, "\n")
+}
+
+func indentation(line string) string {
+	return line[:len(line)-len(strings.TrimLeft(line, " \t"))]
+}
+
+// oldStringNotFoundError returns the error for an old_string that wasn't
+// found, showing the lines of the file closest to it, if any.
+func oldStringNotFoundError(lines, oldLines []string, closest int) error {
+	msg := "old_string not found in file. Make sure it matches exactly, including whitespace and line breaks."
+	if closest == -1 || len(oldLines) == 0 {
+		return errors.New(msg)
+	}
+	block := strings.Join(lines[closest:closest+len(oldLines)], "\n")
+	return fmt.Errorf("%s The closest lines in the file are:\n%s", msg, addLineNumbers(block, closest+1))
+}
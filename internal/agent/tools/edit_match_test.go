@@ -0,0 +1,79 @@
+package tools
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFindEditMatch(t *testing.T) {
+	t.Parallel()
+
+	content := "func main() {\n\tif ok {\n\t\trun()  \n\t}\n\tstop()\n}\n"
+
+	tests := []struct {
+		name      string
+		oldString string
+		newString string
+		want      string
+		strategy  string
+		err       string
+	}{
+		{
+			name:      "exact",
+			oldString: "\tstop()\n",
+			newString: "\tstop(true)\n",
+			want:      "func main() {\n\tif ok {\n\t\trun()  \n\t}\n\tstop(true)\n}\n",
+		},
+		{
+			name:      "trailing whitespace",
+			oldString: "\tif ok {\n\t\trun()\n\t}",
+			newString: "\tif ok {\n\t\trun(1)\n\t}",
+			want:      "func main() {\n\tif ok {\n\t\trun(1)\n\t}\n\tstop()\n}\n",
+			strategy:  "ignoring trailing whitespace",
+		},
+		{
+			name:      "indentation",
+			oldString: "    if ok {\n        run()\n    }\n",
+			newString: "    if ok {\n        run()\n        wait()\n    }\n",
+			want:      "func main() {\n\tif ok {\n\t\trun()\n\t\twait()\n\t}\n\tstop()\n}\n",
+			strategy:  "ignoring indentation",
+		},
+		{
+			name:      "approximately",
+			oldString: "\tif ok {\n\t\trun( )\n\t}\n\tstop();",
+			newString: "\tstop()",
+			want:      "func main() {\n\tstop()\n}\n",
+			strategy:  "approximately (92% similar)",
+		},
+		{
+			name:      "multiple matches",
+			oldString: "()",
+			err:       "appears multiple times",
+		},
+		{
+			name:      "not found shows the closest lines",
+			oldString: "\tif ok {\n\t\tgo()\n\t}",
+			err:       "The closest lines in the file are:\n     2|\tif ok {\n     3|\t\trun()  \n     4|\t}",
+		},
+		{
+			name:      "not found",
+			oldString: "package main",
+			err:       "old_string not found in file.",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			match, err := findEditMatch(content, tt.oldString, tt.newString)
+			if tt.err != "" {
+				require.ErrorContains(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, content[:match.start]+match.newString+content[match.end:])
+			require.Equal(t, tt.strategy, match.strategy)
+		})
+	}
+}
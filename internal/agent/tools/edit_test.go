@@ -0,0 +1,60 @@
+package tools
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/charmbracelet/crush/internal/config"
+	"github.com/charmbracelet/crush/internal/csync"
+	"github.com/charmbracelet/crush/internal/filetracker"
+	"github.com/charmbracelet/crush/internal/history"
+	"github.com/charmbracelet/crush/internal/lsp"
+	"github.com/charmbracelet/crush/internal/permission"
+	"github.com/charmbracelet/crush/internal/pubsub"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEditToolSessionPermissionCoversAdditionalRoot(t *testing.T) {
+	t.Parallel()
+
+	workingDir, root := t.TempDir(), t.TempDir()
+	a := filepath.Join(root, "a", "a.go")
+	b := filepath.Join(root, "b", "b.go")
+	for _, path := range []string{a, b} {
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		require.NoError(t, os.WriteFile(path, []byte("package main\n"), 0o644))
+		filetracker.RecordRead(path)
+	}
+
+	permissions := permission.NewPermissionService(workingDir, false, nil)
+	requests := permissions.Subscribe(t.Context())
+	var asked []string
+	go func() {
+		for event := range requests {
+			asked = append(asked, event.Payload.Path)
+			permissions.GrantPersistent(event.Payload)
+		}
+	}()
+
+	tool := NewEditTool(
+		csync.NewMap[string, *lsp.Client](),
+		permissions,
+		&mockHistoryService{Broker: pubsub.NewBroker[history.File]()},
+		workingDir,
+		[]string{root},
+		config.ToolFormat{},
+	)
+
+	resp := runTool(t, tool, EditParams{FilePath: a, OldString: "package main", NewString: "package a"})
+	require.False(t, resp.IsError, resp.Content)
+	// Allowing the first file for the session allows the whole root.
+	resp = runTool(t, tool, EditParams{FilePath: b, OldString: "package main", NewString: "package b"})
+	require.False(t, resp.IsError, resp.Content)
+	require.Equal(t, []string{root}, asked)
+
+	require.Equal(t, root, permissionPath(a, workingDir, []string{root}))
+	require.Equal(t, workingDir, permissionPath(filepath.Join(workingDir, "c.go"), workingDir, []string{root}))
+	outside := filepath.Join(t.TempDir(), "d.go")
+	require.Equal(t, outside, permissionPath(outside, workingDir, []string{root}))
+}
@@ -3,8 +3,8 @@ package tools
 import (
 	"context"
 	_ "embed"
+	"errors"
 	"fmt"
-	"io"
 	"net/http"
 	"strings"
 	"time"
@@ -21,7 +21,7 @@ const FetchToolName = "fetch"
 //go:embed fetch.md
 var fetchDescription []byte
 
-func NewFetchTool(permissions permission.Service, workingDir string, client *http.Client) fantasy.AgentTool {
+func NewFetchTool(permissions permission.Service, workingDir string, client *http.Client, cache *FetchCache) fantasy.AgentTool {
 	if client == nil {
 		client = &http.Client{
 			Timeout: 30 * time.Second,
@@ -94,81 +94,121 @@ func NewFetchTool(permissions permission.Service, workingDir string, client *htt
 
 			req.Header.Set("User-Agent", "crush/1.0")
 
-			resp, err := client.Do(req)
+			page, err := cache.Fetch(client, req, params.Refresh)
 			if err != nil {
-				return fantasy.ToolResponse{}, fmt.Errorf("failed to fetch URL: %w", err)
+				return fantasy.NewTextErrorResponse(err.Error()), nil
 			}
-			defer resp.Body.Close()
 
-			if resp.StatusCode != http.StatusOK {
-				return fantasy.NewTextErrorResponse(fmt.Sprintf("Request failed with status code: %d", resp.StatusCode)), nil
+			if page.StatusCode != http.StatusOK {
+				return fantasy.NewTextErrorResponse(fmt.Sprintf("Request failed with status code: %d", page.StatusCode)), nil
 			}
 
-			// maxFetchResponseSizeBytes is the maximum size of response body to read (5MB)
-			const maxFetchResponseSizeBytes = int64(5 * 1024 * 1024)
-
-			maxSize := maxFetchResponseSizeBytes
-			body, err := io.ReadAll(io.LimitReader(resp.Body, maxSize))
-			if err != nil {
-				return fantasy.NewTextErrorResponse("Failed to read response body: " + err.Error()), nil
-			}
-
-			content := string(body)
-
-			validUTF8 := utf8.ValidString(content)
-			if !validUTF8 {
+			if !utf8.ValidString(page.Body) {
 				return fantasy.NewTextErrorResponse("Response content is not valid UTF-8"), nil
 			}
-			contentType := resp.Header.Get("Content-Type")
 
-			switch format {
-			case "text":
-				if strings.Contains(contentType, "text/html") {
-					text, err := extractTextFromHTML(content)
-					if err != nil {
-						return fantasy.NewTextErrorResponse("Failed to extract text from HTML: " + err.Error()), nil
-					}
-					content = text
+			isHTML := strings.Contains(page.ContentType, "text/html")
+			narrowed := params.Section != "" || params.Selector != ""
+			var content string
+			if narrowed {
+				fragment, err := narrowPage(page, params.Section, params.Selector)
+				if err != nil {
+					return fantasy.NewTextErrorResponse(err.Error()), nil
 				}
-
-			case "markdown":
-				if strings.Contains(contentType, "text/html") {
-					markdown, err := convertHTMLToMarkdown(content)
+				content, err = convertFetchedContent(fragment, page.ContentType, format)
+				if err != nil {
+					return fantasy.NewTextErrorResponse(err.Error()), nil
+				}
+			} else {
+				// Conversions of whole pages are cached per format.
+				kind := "fetch_" + format
+				var ok bool
+				content, ok = page.Converted[kind]
+				if !ok {
+					content, err = convertFetchedContent(page.Body, page.ContentType, format)
 					if err != nil {
-						return fantasy.NewTextErrorResponse("Failed to convert HTML to Markdown: " + err.Error()), nil
+						return fantasy.NewTextErrorResponse(err.Error()), nil
 					}
-					content = markdown
+					cache.SetConverted(page, kind, content)
 				}
+			}
 
-				content = "```\n" + content + "\n```"
+			// Large pages get a table of contents instead, so only the
+			// sections that matter are fetched.
+			var headings []pageHeading
+			if isHTML && !narrowed && params.Page == 0 && len(content) > FetchPageSize {
+				if doc, err := parseFetchedHTML(page.Body); err == nil {
+					headings = pageHeadings(doc)
+				}
+			}
+			content, note, err := paginateContent(content, params.Page, headings)
+			if err != nil {
+				return fantasy.NewTextErrorResponse(err.Error()), nil
+			}
 
-			case "html":
-				// return only the body of the HTML document
-				if strings.Contains(contentType, "text/html") {
-					doc, err := goquery.NewDocumentFromReader(strings.NewReader(content))
-					if err != nil {
-						return fantasy.NewTextErrorResponse("Failed to parse HTML: " + err.Error()), nil
-					}
-					body, err := doc.Find("body").Html()
-					if err != nil {
-						return fantasy.NewTextErrorResponse("Failed to extract body from HTML: " + err.Error()), nil
-					}
-					if body == "" {
-						return fantasy.NewTextErrorResponse("No body content found in HTML"), nil
-					}
-					content = "<html>\n<body>\n" + body + "\n</body>\n</html>"
+			var result strings.Builder
+			if content != "" {
+				if format == "markdown" {
+					content = "```\n" + content + "\n```"
 				}
+				result.WriteString(content)
 			}
-			// truncate content if it exceeds max read size
-			if int64(len(content)) > MaxReadSize {
-				content = content[:MaxReadSize]
-				content += fmt.Sprintf("\n\n[Content truncated to %d bytes]", MaxReadSize)
+			if note != "" {
+				if result.Len() > 0 {
+					result.WriteString("\n\n")
+				}
+				result.WriteString(note)
+			}
+			if page.Stale {
+				fmt.Fprintf(&result, "\n\n[Offline: served from a cached copy fetched at %s]", page.FetchedAt.Format(time.RFC3339))
 			}
 
-			return fantasy.NewTextResponse(content), nil
+			return fantasy.NewTextResponse(result.String()), nil
 		})
 }
 
+// convertFetchedContent converts fetched content to the given format: text,
+// markdown or html.
+func convertFetchedContent(content, contentType, format string) (string, error) {
+	switch format {
+	case "text":
+		if strings.Contains(contentType, "text/html") {
+			text, err := extractTextFromHTML(content)
+			if err != nil {
+				return "", fmt.Errorf("failed to extract text from HTML: %w", err)
+			}
+			content = text
+		}
+
+	case "markdown":
+		if strings.Contains(contentType, "text/html") {
+			markdown, err := convertHTMLToMarkdown(content)
+			if err != nil {
+				return "", fmt.Errorf("failed to convert HTML to Markdown: %w", err)
+			}
+			content = markdown
+		}
+
+	case "html":
+		// return only the body of the HTML document
+		if strings.Contains(contentType, "text/html") {
+			doc, err := goquery.NewDocumentFromReader(strings.NewReader(content))
+			if err != nil {
+				return "", fmt.Errorf("failed to parse HTML: %w", err)
+			}
+			body, err := doc.Find("body").Html()
+			if err != nil {
+				return "", fmt.Errorf("failed to extract body from HTML: %w", err)
+			}
+			if body == "" {
+				return "", errors.New("no body content found in HTML")
+			}
+			content = "<html>\n<body>\n" + body + "\n</body>\n</html>"
+		}
+	}
+	return content, nil
+}
+
 func extractTextFromHTML(html string) (string, error) {
 	doc, err := goquery.NewDocumentFromReader(strings.NewReader(html))
 	if err != nil {
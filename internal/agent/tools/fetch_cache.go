@@ -0,0 +1,366 @@
+package tools
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/crush/internal/config"
+)
+
+const (
+	// DefaultFetchCacheTTL is how long a fetched page stays fresh when the
+	// server doesn't say how long to cache it.
+	DefaultFetchCacheTTL = time.Hour
+
+	// maxFetchResponseSize is the maximum size of a fetched response body.
+	maxFetchResponseSize = 5 * 1024 * 1024
+
+	// maxFetchCacheSize is the size above which the least recently used
+	// pages are evicted from the cache.
+	maxFetchCacheSize = 100 * 1024 * 1024
+)
+
+// CachedPage is a page fetched through a [FetchCache].
+type CachedPage struct {
+	URL          string    `json:"url"`
+	StatusCode   int       `json:"status_code"`
+	ContentType  string    `json:"content_type,omitempty"`
+	Body         string    `json:"body"`
+	ETag         string    `json:"etag,omitempty"`
+	LastModified string    `json:"last_modified,omitempty"`
+	FetchedAt    time.Time `json:"fetched_at"`
+	ExpiresAt    time.Time `json:"expires_at"`
+	// Revalidate is set when the server asked for the page to be revalidated
+	// before every use.
+	Revalidate bool `json:"revalidate,omitempty"`
+	// Converted holds conversions of the body, such as markdown, by kind.
+	Converted map[string]string `json:"converted,omitempty"`
+
+	// FromCache is set when the page was served from the cache, without
+	// downloading it again.
+	FromCache bool `json:"-"`
+	// Stale is set when the page was served from the cache after it
+	// expired, in offline mode.
+	Stale bool `json:"-"`
+
+	stored bool
+}
+
+// FetchCache is an on-disk HTTP cache for the fetch tools. It honours the
+// Cache-Control, Expires, ETag and Last-Modified headers, and keeps the
+// conversions of the pages along with them. A nil *FetchCache fetches
+// everything from the network.
+type FetchCache struct {
+	dir     string
+	ttl     time.Duration
+	maxSize int64
+	offline bool
+	now     func() time.Time
+}
+
+// NewFetchCache returns a fetch cache storing pages in dir, or nil if the
+// cache is disabled.
+func NewFetchCache(dir string, cfg config.ToolFetch) *FetchCache {
+	if cfg.DisableCache {
+		return nil
+	}
+	ttl := DefaultFetchCacheTTL
+	if cfg.CacheTTL > 0 {
+		ttl = time.Duration(cfg.CacheTTL) * time.Second
+	}
+	return &FetchCache{
+		dir:     dir,
+		ttl:     ttl,
+		maxSize: maxFetchCacheSize,
+		offline: cfg.Offline,
+		now:     time.Now,
+	}
+}
+
+// Fetch returns the page of the request, from the cache if it's fresh. A
+// stale page is revalidated with the server when possible. With refresh,
+// the page is always downloaded again. Only successful responses are
+// cached; the caller checks the status code of the page.
+func (c *FetchCache) Fetch(client *http.Client, req *http.Request, refresh bool) (*CachedPage, error) {
+	if c == nil {
+		page, err := doFetch(client, req)
+		if err != nil {
+			return nil, err
+		}
+		return page.CachedPage, nil
+	}
+
+	url := req.URL.String()
+	cached, err := c.load(url)
+	if err != nil {
+		return nil, err
+	}
+
+	if c.offline {
+		if cached == nil {
+			return nil, fmt.Errorf("%s is not in the fetch cache and fetching is offline", url)
+		}
+		cached.FromCache = true
+		cached.Stale = !c.now().Before(cached.ExpiresAt)
+		return cached, nil
+	}
+
+	if cached != nil && !refresh {
+		if !cached.Revalidate && c.now().Before(cached.ExpiresAt) {
+			cached.FromCache = true
+			return cached, nil
+		}
+		if cached.ETag != "" {
+			req.Header.Set("If-None-Match", cached.ETag)
+		}
+		if cached.LastModified != "" {
+			req.Header.Set("If-Modified-Since", cached.LastModified)
+		}
+	}
+
+	page, err := doFetch(client, req)
+	if err != nil {
+		return nil, err
+	}
+
+	if page.StatusCode == http.StatusNotModified && cached != nil {
+		var store bool
+		cached.ExpiresAt, cached.Revalidate, store = c.expiry(page.header)
+		cached.FetchedAt = c.now()
+		cached.FromCache = true
+		if store {
+			c.store(cached)
+		} else {
+			c.remove(cached)
+		}
+		return cached, nil
+	}
+	if page.StatusCode != http.StatusOK {
+		return page.CachedPage, nil
+	}
+
+	var store bool
+	page.FetchedAt = c.now()
+	page.ExpiresAt, page.Revalidate, store = c.expiry(page.header)
+	if store {
+		c.store(page.CachedPage)
+	} else if cached != nil {
+		c.remove(cached)
+	}
+	return page.CachedPage, nil
+}
+
+// store saves the page in the cache. Failing to do so doesn't fail the
+// fetch, it only means the page is downloaded again next time.
+func (c *FetchCache) store(page *CachedPage) {
+	if err := c.save(page); err != nil {
+		slog.Warn("Failed to cache fetched page", "url", page.URL, "error", err)
+		return
+	}
+	c.evict()
+}
+
+// remove deletes a page the server no longer allows to be stored.
+func (c *FetchCache) remove(page *CachedPage) {
+	if err := os.Remove(c.path(page.URL)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		slog.Warn("Failed to remove cached page", "url", page.URL, "error", err)
+	}
+	page.stored = false
+}
+
+// evict deletes the least recently used pages until the cache fits in its
+// maximum size. Pages are touched when they're loaded, so their
+// modification time is when they were last used.
+func (c *FetchCache) evict() {
+	entries, err := os.ReadDir(c.dir)
+	if err != nil {
+		slog.Warn("Failed to read the fetch cache", "error", err)
+		return
+	}
+	type cacheFile struct {
+		path    string
+		size    int64
+		modTime time.Time
+	}
+	var files []cacheFile
+	var total int64
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".json" {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		files = append(files, cacheFile{path: filepath.Join(c.dir, entry.Name()), size: info.Size(), modTime: info.ModTime()})
+		total += info.Size()
+	}
+	if total <= c.maxSize {
+		return
+	}
+
+	slices.SortFunc(files, func(a, b cacheFile) int {
+		return a.modTime.Compare(b.modTime)
+	})
+	for _, f := range files {
+		if total <= c.maxSize {
+			break
+		}
+		if err := os.Remove(f.path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			slog.Warn("Failed to evict cached page", "path", f.path, "error", err)
+			continue
+		}
+		total -= f.size
+	}
+}
+
+// SetConverted records a conversion of the page, so later fetches of the
+// page can reuse it.
+func (c *FetchCache) SetConverted(page *CachedPage, kind, content string) {
+	if page.Converted == nil {
+		page.Converted = make(map[string]string)
+	}
+	page.Converted[kind] = content
+	if c == nil || !page.stored {
+		return
+	}
+	c.store(page)
+}
+
+// expiry returns when a response with the given headers expires, whether it
+// must be revalidated before every use and whether it may be stored at all.
+func (c *FetchCache) expiry(header http.Header) (time.Time, bool, bool) {
+	now := c.now()
+	var noStore, revalidate bool
+	maxAge := -1
+	for directive := range strings.SplitSeq(strings.Join(header.Values("Cache-Control"), ","), ",") {
+		name, value, _ := strings.Cut(strings.TrimSpace(directive), "=")
+		switch strings.ToLower(name) {
+		case "no-store":
+			noStore = true
+		case "no-cache":
+			revalidate = true
+		case "max-age":
+			if seconds, err := strconv.Atoi(strings.Trim(value, `"`)); err == nil && seconds >= 0 {
+				maxAge = seconds
+			}
+		}
+	}
+	if noStore {
+		return now, false, false
+	}
+	if maxAge >= 0 {
+		return now.Add(time.Duration(maxAge) * time.Second), revalidate, true
+	}
+	if expires := header.Get("Expires"); expires != "" {
+		// An invalid date, such as "0", means the response already expired.
+		t, err := http.ParseTime(expires)
+		if err != nil {
+			return now, revalidate, true
+		}
+		return t, revalidate, true
+	}
+	return now.Add(c.ttl), revalidate, true
+}
+
+func (c *FetchCache) path(url string) string {
+	sum := sha256.Sum256([]byte(url))
+	return filepath.Join(c.dir, hex.EncodeToString(sum[:])+".json")
+}
+
+func (c *FetchCache) load(url string) (*CachedPage, error) {
+	data, err := os.ReadFile(c.path(url))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the fetch cache: %w", err)
+	}
+	var page CachedPage
+	if err := json.Unmarshal(data, &page); err != nil || page.URL != url {
+		// Ignore corrupt entries, they're overwritten by the next fetch.
+		return nil, nil
+	}
+	page.stored = true
+	// Touch the entry so eviction keeps the pages in use.
+	now := c.now()
+	_ = os.Chtimes(c.path(url), now, now)
+	return &page, nil
+}
+
+func (c *FetchCache) save(page *CachedPage) error {
+	if err := os.MkdirAll(c.dir, 0o755); err != nil {
+		return fmt.Errorf("failed to create the fetch cache: %w", err)
+	}
+	data, err := json.Marshal(page)
+	if err != nil {
+		return fmt.Errorf("failed to encode the cached page: %w", err)
+	}
+
+	// Write to a temporary file first so concurrent readers never see a
+	// partially written entry.
+	tmp, err := os.CreateTemp(c.dir, "page-*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to write the fetch cache: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write the fetch cache: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write the fetch cache: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), c.path(page.URL)); err != nil {
+		return fmt.Errorf("failed to write the fetch cache: %w", err)
+	}
+	page.stored = true
+	return nil
+}
+
+// fetchedPage is a downloaded page along with its response headers.
+type fetchedPage struct {
+	*CachedPage
+	header http.Header
+}
+
+func doFetch(client *http.Client, req *http.Request) (fetchedPage, error) {
+	resp, err := client.Do(req)
+	if err != nil {
+		return fetchedPage{}, fmt.Errorf("failed to fetch URL: %w", err)
+	}
+	defer resp.Body.Close()
+
+	page := fetchedPage{
+		CachedPage: &CachedPage{
+			URL:          req.URL.String(),
+			StatusCode:   resp.StatusCode,
+			ContentType:  resp.Header.Get("Content-Type"),
+			ETag:         resp.Header.Get("ETag"),
+			LastModified: resp.Header.Get("Last-Modified"),
+		},
+		header: resp.Header,
+	}
+	if resp.StatusCode != http.StatusOK {
+		return page, nil
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxFetchResponseSize))
+	if err != nil {
+		return fetchedPage{}, fmt.Errorf("failed to read response body: %w", err)
+	}
+	page.Body = string(body)
+	return page, nil
+}
@@ -0,0 +1,201 @@
+package tools
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/charmbracelet/crush/internal/config"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFetchCache(t *testing.T) {
+	// The subtests share the cache and the request count, so they run in
+	// order.
+	var hits atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		switch r.URL.Path {
+		case "/fresh":
+			w.Header().Set("Cache-Control", "max-age=60")
+		case "/etag":
+			w.Header().Set("Cache-Control", "no-cache")
+			w.Header().Set("ETag", `"v1"`)
+			if r.Header.Get("If-None-Match") == `"v1"` {
+				w.WriteHeader(http.StatusNotModified)
+				return
+			}
+		case "/no-store":
+			w.Header().Set("Cache-Control", "no-store")
+		case "/revoked":
+			// Cacheable at first, then no longer stored once revalidated.
+			w.Header().Set("ETag", `"v1"`)
+			if r.Header.Get("If-None-Match") == `"v1"` {
+				w.Header().Set("Cache-Control", "no-store")
+				w.WriteHeader(http.StatusNotModified)
+				return
+			}
+			w.Header().Set("Cache-Control", "no-cache")
+		case "/missing":
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html")
+		_, _ = io.WriteString(w, "<html><body><h1>Docs</h1><p>"+r.URL.Path+"</p></body></html>")
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	cache := NewFetchCache(dir, config.ToolFetch{})
+	fetch := func(t *testing.T, cache *FetchCache, path string, refresh bool) (*CachedPage, int32) {
+		t.Helper()
+		req, err := http.NewRequestWithContext(t.Context(), http.MethodGet, server.URL+path, nil)
+		require.NoError(t, err)
+		before := hits.Load()
+		page, err := cache.Fetch(server.Client(), req, refresh)
+		require.NoError(t, err)
+		return page, hits.Load() - before
+	}
+
+	t.Run("fresh", func(t *testing.T) {
+		page, requests := fetch(t, cache, "/fresh", false)
+		require.Equal(t, int32(1), requests)
+		require.False(t, page.FromCache)
+		require.Contains(t, page.Body, "<h1>Docs</h1>")
+
+		page, requests = fetch(t, cache, "/fresh", false)
+		require.Equal(t, int32(0), requests)
+		require.True(t, page.FromCache)
+		require.Contains(t, page.Body, "<h1>Docs</h1>")
+
+		page, requests = fetch(t, cache, "/fresh", true)
+		require.Equal(t, int32(1), requests)
+		require.False(t, page.FromCache)
+	})
+
+	t.Run("revalidate", func(t *testing.T) {
+		_, requests := fetch(t, cache, "/etag", false)
+		require.Equal(t, int32(1), requests)
+
+		page, requests := fetch(t, cache, "/etag", false)
+		require.Equal(t, int32(1), requests)
+		require.True(t, page.FromCache)
+		require.Equal(t, http.StatusOK, page.StatusCode)
+		require.Contains(t, page.Body, "/etag")
+	})
+
+	t.Run("not stored", func(t *testing.T) {
+		_, requests := fetch(t, cache, "/no-store", false)
+		require.Equal(t, int32(1), requests)
+		_, requests = fetch(t, cache, "/no-store", false)
+		require.Equal(t, int32(1), requests)
+
+		_, requests = fetch(t, cache, "/revoked", false)
+		require.Equal(t, int32(1), requests)
+		page, requests := fetch(t, cache, "/revoked", false)
+		require.Equal(t, int32(1), requests)
+		require.True(t, page.FromCache)
+		require.Contains(t, page.Body, "/revoked")
+		require.NoFileExists(t, cache.path(server.URL+"/revoked"))
+
+		page, _ = fetch(t, cache, "/missing", false)
+		require.Equal(t, http.StatusNotFound, page.StatusCode)
+		_, requests = fetch(t, cache, "/missing", false)
+		require.Equal(t, int32(1), requests)
+	})
+
+	t.Run("converted", func(t *testing.T) {
+		content, err := FetchURLAndConvert(t.Context(), server.Client(), cache, server.URL+"/fresh", false)
+		require.NoError(t, err)
+		require.Equal(t, "# Docs\n\n/fresh", content)
+
+		page, requests := fetch(t, cache, "/fresh", false)
+		require.Equal(t, int32(0), requests)
+		require.Equal(t, content, page.Converted[convertedMarkdown])
+	})
+
+	t.Run("offline", func(t *testing.T) {
+		offline := NewFetchCache(dir, config.ToolFetch{Offline: true})
+		offline.now = func() time.Time { return time.Now().Add(time.Hour) }
+
+		page, requests := fetch(t, offline, "/fresh", true)
+		require.Equal(t, int32(0), requests)
+		require.True(t, page.FromCache)
+		require.True(t, page.Stale)
+
+		req, err := http.NewRequestWithContext(t.Context(), http.MethodGet, server.URL+"/other", nil)
+		require.NoError(t, err)
+		_, err = offline.Fetch(server.Client(), req, false)
+		require.ErrorContains(t, err, "not in the fetch cache")
+	})
+}
+
+func TestFetchCacheExpiry(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	cache := NewFetchCache(t.TempDir(), config.ToolFetch{CacheTTL: 600})
+	cache.now = func() time.Time { return now }
+
+	tests := []struct {
+		name       string
+		header     http.Header
+		expires    time.Time
+		revalidate bool
+		store      bool
+	}{
+		{name: "default ttl", header: http.Header{}, expires: now.Add(10 * time.Minute), store: true},
+		{name: "max-age", header: http.Header{"Cache-Control": {"public, max-age=120"}}, expires: now.Add(2 * time.Minute), store: true},
+		{name: "no-cache", header: http.Header{"Cache-Control": {"no-cache"}}, expires: now.Add(10 * time.Minute), revalidate: true, store: true},
+		{name: "no-store", header: http.Header{"Cache-Control": {"no-store"}}, expires: now},
+		{name: "no-store wins", header: http.Header{"Cache-Control": {"max-age=60", "no-store"}}, expires: now},
+		{name: "max-age with no-cache", header: http.Header{"Cache-Control": {"max-age=60, no-cache"}}, expires: now.Add(time.Minute), revalidate: true, store: true},
+		{name: "max-age over expires", header: http.Header{"Cache-Control": {"max-age=0"}, "Expires": {"Sun, 01 Jun 2025 13:00:00 GMT"}}, expires: now, store: true},
+		{name: "expires", header: http.Header{"Expires": {"Sun, 01 Jun 2025 13:00:00 GMT"}}, expires: now.Add(time.Hour), store: true},
+		{name: "invalid expires", header: http.Header{"Expires": {"0"}}, expires: now, store: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			expires, revalidate, store := cache.expiry(tt.header)
+			require.True(t, tt.expires.Equal(expires), "expected %s, got %s", tt.expires, expires)
+			require.Equal(t, tt.revalidate, revalidate)
+			require.Equal(t, tt.store, store)
+		})
+	}
+
+	require.Nil(t, NewFetchCache(t.TempDir(), config.ToolFetch{DisableCache: true}))
+}
+
+func TestFetchCacheEviction(t *testing.T) {
+	t.Parallel()
+
+	cache := NewFetchCache(t.TempDir(), config.ToolFetch{})
+	now := time.Now()
+	for i, url := range []string{"https://a.example", "https://b.example", "https://c.example"} {
+		require.NoError(t, cache.save(&CachedPage{URL: url, Body: strings.Repeat("x", 100)}))
+		modTime := now.Add(time.Duration(i) * time.Minute)
+		require.NoError(t, os.Chtimes(cache.path(url), modTime, modTime))
+	}
+
+	// Loading a page makes it the most recently used one.
+	cache.now = func() time.Time { return now.Add(time.Hour) }
+	page, err := cache.load("https://a.example")
+	require.NoError(t, err)
+	require.NotNil(t, page)
+
+	info, err := os.Stat(cache.path("https://a.example"))
+	require.NoError(t, err)
+	cache.maxSize = 2 * info.Size()
+	cache.evict()
+
+	require.FileExists(t, cache.path("https://a.example"))
+	require.NoFileExists(t, cache.path("https://b.example"))
+	require.FileExists(t, cache.path("https://c.example"))
+}
@@ -6,7 +6,6 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
-	"io"
 	"net/http"
 	"regexp"
 	"strings"
@@ -19,13 +18,29 @@ import (
 // BrowserUserAgent is a realistic browser User-Agent for better compatibility.
 const BrowserUserAgent = "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36"
 
+// convertedMarkdown is the kind of the conversions of [FetchURLAndConvert]
+// in the fetch cache.
+const convertedMarkdown = "markdown"
+
 var multipleNewlinesRe = regexp.MustCompile(`\n{3,}`)
 
 // FetchURLAndConvert fetches a URL and converts HTML content to markdown.
-func FetchURLAndConvert(ctx context.Context, client *http.Client, url string) (string, error) {
+// Pages and their markdown are cached in cache, if not nil, and refresh
+// forces a new download.
+func FetchURLAndConvert(ctx context.Context, client *http.Client, cache *FetchCache, url string, refresh bool) (string, error) {
+	page, err := fetchPage(ctx, client, cache, url, refresh)
+	if err != nil {
+		return "", err
+	}
+	return pageMarkdown(cache, page)
+}
+
+// fetchPage fetches a URL with browser headers, failing if the request
+// wasn't successful or the content isn't valid UTF-8.
+func fetchPage(ctx context.Context, client *http.Client, cache *FetchCache, url string, refresh bool) (*CachedPage, error) {
 	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 	if err != nil {
-		return "", fmt.Errorf("failed to create request: %w", err)
+		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
 
 	// Use realistic browser headers for better compatibility.
@@ -33,30 +48,39 @@ func FetchURLAndConvert(ctx context.Context, client *http.Client, url string) (s
 	req.Header.Set("Accept", "text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8")
 	req.Header.Set("Accept-Language", "en-US,en;q=0.5")
 
-	resp, err := client.Do(req)
+	page, err := cache.Fetch(client, req, refresh)
 	if err != nil {
-		return "", fmt.Errorf("failed to fetch URL: %w", err)
+		return nil, err
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("request failed with status code: %d", resp.StatusCode)
+	if page.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("request failed with status code: %d", page.StatusCode)
 	}
 
-	maxSize := int64(5 * 1024 * 1024) // 5MB
-	body, err := io.ReadAll(io.LimitReader(resp.Body, maxSize))
-	if err != nil {
-		return "", fmt.Errorf("failed to read response body: %w", err)
+	if !utf8.ValidString(page.Body) {
+		return nil, errors.New("response content is not valid UTF-8")
 	}
+	return page, nil
+}
 
-	content := string(body)
-
-	if !utf8.ValidString(content) {
-		return "", errors.New("response content is not valid UTF-8")
+// pageMarkdown returns the markdown of a fetched page, converting it only
+// if it isn't cached yet.
+func pageMarkdown(cache *FetchCache, page *CachedPage) (string, error) {
+	if content, ok := page.Converted[convertedMarkdown]; ok {
+		return content, nil
 	}
 
-	contentType := resp.Header.Get("Content-Type")
+	content, err := convertToMarkdown(page.Body, page.ContentType)
+	if err != nil {
+		return "", err
+	}
+	cache.SetConverted(page, convertedMarkdown, content)
+	return content, nil
+}
 
+// convertToMarkdown converts HTML content to markdown and formats JSON
+// content. Other content is returned as is.
+func convertToMarkdown(content, contentType string) (string, error) {
 	// Convert HTML to markdown for better AI processing.
 	if strings.Contains(contentType, "text/html") {
 		// Remove noisy elements before conversion.
@@ -74,7 +98,6 @@ func FetchURLAndConvert(ctx context.Context, client *http.Client, url string) (s
 		}
 		// If formatting fails, keep original content.
 	}
-
 	return content, nil
 }
 
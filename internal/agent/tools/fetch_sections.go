@@ -0,0 +1,311 @@
+package tools
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/PuerkitoBio/goquery"
+	"golang.org/x/net/html"
+)
+
+// FetchPageSize is the maximum size of a page of fetched content. Content
+// larger than this is split into numbered pages.
+const FetchPageSize = LargeContentThreshold
+
+// maxTableOfContentsEntries is the maximum number of headings listed in the
+// table of contents of a page.
+const maxTableOfContentsEntries = 200
+
+// pageHeading is a heading of a fetched HTML page.
+type pageHeading struct {
+	level  int
+	text   string
+	anchor string
+}
+
+// parseFetchedHTML parses a fetched HTML page.
+func parseFetchedHTML(content string) (*goquery.Document, error) {
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(content))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse HTML: %w", err)
+	}
+	return doc, nil
+}
+
+// pageHeadings returns the headings of the body of doc, skipping those of
+// navigation and sidebars. Headings without an id get the anchor GitHub
+// would give them.
+func pageHeadings(doc *goquery.Document) []pageHeading {
+	var headings []pageHeading
+	doc.Find("body").Find("h1, h2, h3, h4, h5, h6").Each(func(_ int, s *goquery.Selection) {
+		if s.Closest("nav, aside, footer").Length() > 0 {
+			return
+		}
+		text := strings.Join(strings.Fields(s.Text()), " ")
+		if text == "" {
+			return
+		}
+		headings = append(headings, pageHeading{
+			level:  headingLevel(s.Nodes[0]),
+			text:   text,
+			anchor: headingAnchor(s, text),
+		})
+	})
+	return headings
+}
+
+// headingAnchor returns the anchor linking to a heading: its id, the id or
+// name of an anchor inside it, or the slug of its text.
+func headingAnchor(s *goquery.Selection, text string) string {
+	if id, ok := s.Attr("id"); ok && id != "" {
+		return id
+	}
+	if id, ok := s.Find("[id]").First().Attr("id"); ok && id != "" {
+		return id
+	}
+	if name, ok := s.Find("a[name]").First().Attr("name"); ok && name != "" {
+		return name
+	}
+	return slugify(text)
+}
+
+// narrowPage returns an HTML document holding only the given section or the
+// elements matching the given CSS selector of a fetched HTML page.
+func narrowPage(page *CachedPage, section, selector string) (string, error) {
+	if !strings.Contains(page.ContentType, "text/html") {
+		return "", errors.New("section and selector are only supported for HTML pages")
+	}
+	doc, err := parseFetchedHTML(page.Body)
+	if err != nil {
+		return "", err
+	}
+	return extractHTML(doc, section, selector)
+}
+
+// extractHTML returns an HTML document holding only the given section or
+// the elements matching the given CSS selector of doc. A section is a
+// heading anchor, with or without the leading #, an element id or the text
+// of a heading.
+func extractHTML(doc *goquery.Document, section, selector string) (string, error) {
+	var nodes []*html.Node
+	if selector != "" {
+		nodes = doc.Find(selector).Nodes
+		if len(nodes) == 0 {
+			return "", fmt.Errorf("no elements match the selector %q", selector)
+		}
+	} else {
+		var err error
+		nodes, err = findSection(doc, section)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString("<html>\n<body>\n")
+	for _, n := range nodes {
+		if err := html.Render(&buf, n); err != nil {
+			return "", fmt.Errorf("failed to render HTML: %w", err)
+		}
+		buf.WriteString("\n")
+	}
+	buf.WriteString("</body>\n</html>")
+	return buf.String(), nil
+}
+
+// findSection returns the nodes making up a section of doc. A heading's
+// section runs up to the next heading of the same or a higher level; any
+// other element is a section of its own.
+func findSection(doc *goquery.Document, section string) ([]*html.Node, error) {
+	anchor := strings.TrimPrefix(strings.TrimSpace(section), "#")
+	if anchor == "" {
+		return nil, errors.New("section is empty")
+	}
+
+	body := doc.Find("body")
+	target := body.Find("[id]").FilterFunction(func(_ int, s *goquery.Selection) bool {
+		return s.AttrOr("id", "") == anchor
+	}).First()
+	if target.Length() == 0 {
+		target = body.Find("a[name]").FilterFunction(func(_ int, s *goquery.Selection) bool {
+			return s.AttrOr("name", "") == anchor
+		}).First()
+	}
+	if target.Length() == 0 {
+		slug := slugify(anchor)
+		target = body.Find("h1, h2, h3, h4, h5, h6").FilterFunction(func(_ int, s *goquery.Selection) bool {
+			text := strings.Join(strings.Fields(s.Text()), " ")
+			return strings.EqualFold(text, anchor) || (slug != "" && slugify(text) == slug)
+		}).First()
+	}
+	if target.Length() == 0 {
+		return nil, fmt.Errorf("section %q not found on the page", section)
+	}
+
+	// Anchors are often inside their heading, or an empty element right
+	// before it.
+	if heading := target.Closest("h1, h2, h3, h4, h5, h6"); heading.Length() > 0 {
+		target = heading
+	} else if strings.TrimSpace(target.Text()) == "" {
+		if next := target.Next(); next.Is("h1, h2, h3, h4, h5, h6") {
+			target = next
+		}
+	}
+
+	node := target.Nodes[0]
+	level := headingLevel(node)
+	if level == 0 {
+		return []*html.Node{node}, nil
+	}
+
+	// Headings are often wrapped in elements of their own, so once the
+	// siblings of the heading run out, carry on with those of its parents.
+	nodes := []*html.Node{node}
+	for n := node; n != nil && !isElement(n, "body"); n = n.Parent {
+		for s := n.NextSibling; s != nil; s = s.NextSibling {
+			if endsSection(s, level) {
+				return nodes, nil
+			}
+			nodes = append(nodes, s)
+		}
+	}
+	return nodes, nil
+}
+
+// endsSection reports whether n is, or contains, a heading of the given
+// level or higher.
+func endsSection(n *html.Node, level int) bool {
+	if l := headingLevel(n); l > 0 && l <= level {
+		return true
+	}
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if endsSection(c, level) {
+			return true
+		}
+	}
+	return false
+}
+
+// headingLevel returns the level of a heading element, or 0 if n is not a
+// heading.
+func headingLevel(n *html.Node) int {
+	if n.Type != html.ElementNode || len(n.Data) != 2 || n.Data[0] != 'h' {
+		return 0
+	}
+	if level := int(n.Data[1] - '0'); level >= 1 && level <= 6 {
+		return level
+	}
+	return 0
+}
+
+func isElement(n *html.Node, tag string) bool {
+	return n.Type == html.ElementNode && n.Data == tag
+}
+
+// slugify returns the anchor GitHub gives to a heading with the given text.
+func slugify(text string) string {
+	var slug strings.Builder
+	for _, r := range strings.ToLower(strings.TrimSpace(text)) {
+		switch {
+		case unicode.IsLetter(r), unicode.IsDigit(r), r == '_', r == '-':
+			slug.WriteRune(r)
+		case r == ' ':
+			slug.WriteRune('-')
+		}
+	}
+	return slug.String()
+}
+
+// splitPages splits content into pages of at most size bytes, breaking
+// between lines where possible. The pages add up to content.
+func splitPages(content string, size int) []string {
+	var pages []string
+	for len(content) > size {
+		end := strings.LastIndexByte(content[:size], '\n') + 1
+		if end == 0 {
+			// No line break to split at, so split the line itself, without
+			// cutting a UTF-8 sequence in half.
+			end = size
+			for end > 0 && !utf8.RuneStart(content[end]) {
+				end--
+			}
+		}
+		pages = append(pages, content[:end])
+		content = content[end:]
+	}
+	return append(pages, content)
+}
+
+// tableOfContents lists the headings of a page along with their anchors
+// and, when their text can be found in the content, the page they're on.
+func tableOfContents(headings []pageHeading, pages []string) string {
+	if len(headings) == 0 {
+		return ""
+	}
+	minLevel := 6
+	for _, h := range headings {
+		minLevel = min(minLevel, h.level)
+	}
+
+	var toc strings.Builder
+	toc.WriteString("Table of contents:\n")
+	// Headings appear in order, so each is searched for after the previous
+	// one.
+	var page, offset int
+	for i, h := range headings {
+		if i == maxTableOfContentsEntries {
+			fmt.Fprintf(&toc, "... and %d more headings\n", len(headings)-i)
+			break
+		}
+		fmt.Fprintf(&toc, "%s- %s (#%s", strings.Repeat("  ", h.level-minLevel), h.text, h.anchor)
+		if p, o, ok := findInPages(pages, page, offset, h.text); ok {
+			page, offset = p, o
+			fmt.Fprintf(&toc, ", page %d", page+1)
+		}
+		toc.WriteString(")\n")
+	}
+	return toc.String()
+}
+
+// findInPages returns the page and offset of the first occurrence of text
+// in pages, starting at the given page and offset.
+func findInPages(pages []string, page, offset int, text string) (int, int, bool) {
+	for ; page < len(pages); page, offset = page+1, 0 {
+		if i := strings.Index(pages[page][offset:], text); i >= 0 {
+			return page, offset + i + len(text), true
+		}
+	}
+	return 0, 0, false
+}
+
+// paginateContent returns the given page of content, along with a note
+// telling which page it is. Page 0 stands for the whole content, or, when
+// it's larger than [FetchPageSize], for its table of contents if it has
+// headings and its first page otherwise.
+func paginateContent(content string, page int, headings []pageHeading) (string, string, error) {
+	pages := splitPages(content, FetchPageSize)
+	if page < 0 || page > len(pages) {
+		return "", "", fmt.Errorf("page %d is out of range, the content has %d pages", page, len(pages))
+	}
+	if len(pages) == 1 {
+		return content, "", nil
+	}
+
+	if page == 0 {
+		if toc := tableOfContents(headings, pages); toc != "" {
+			note := fmt.Sprintf("The content is too large to return at once: it has %d pages of up to %d bytes. Request a section with the section parameter, or a page with the page parameter.\n\n%s", len(pages), FetchPageSize, toc)
+			return "", note, nil
+		}
+		page = 1
+	}
+
+	note := fmt.Sprintf("[Page %d of %d.", page, len(pages))
+	if page < len(pages) {
+		note += fmt.Sprintf(" Use page=%d for the next page.", page+1)
+	}
+	return pages[page-1], note + "]", nil
+}
@@ -0,0 +1,182 @@
+package tools
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/crush/internal/permission"
+	"github.com/charmbracelet/crush/internal/pubsub"
+	"github.com/stretchr/testify/require"
+)
+
+const sectionsPage = `<html><body>
+<nav><h2>Menu</h2></nav>
+<h1>Guide</h1>
+<p>Intro</p>
+<div class="heading"><h2 id="install">Installation</h2></div>
+<p>Run the installer.</p>
+<h3><a name="linux"></a>On Linux</h3>
+<p>Use the package.</p>
+<h2>Usage &amp; Tips</h2>
+<p class="tip">Read the docs.</p>
+<p class="tip">Ask for help.</p>
+</body></html>`
+
+func TestExtractHTML(t *testing.T) {
+	t.Parallel()
+
+	doc, err := parseFetchedHTML(sectionsPage)
+	require.NoError(t, err)
+
+	require.Equal(t, []pageHeading{
+		{level: 1, text: "Guide", anchor: "guide"},
+		{level: 2, text: "Installation", anchor: "install"},
+		{level: 3, text: "On Linux", anchor: "linux"},
+		{level: 2, text: "Usage & Tips", anchor: "usage--tips"},
+	}, pageHeadings(doc))
+
+	tests := []struct {
+		name     string
+		section  string
+		selector string
+		contains []string
+		excludes []string
+	}{
+		{
+			name:     "heading id",
+			section:  "#install",
+			contains: []string{"Installation", "Run the installer.", "On Linux", "Use the package."},
+			excludes: []string{"Intro", "Usage"},
+		},
+		{
+			name:     "named anchor",
+			section:  "linux",
+			contains: []string{"On Linux", "Use the package."},
+			excludes: []string{"Run the installer.", "Usage"},
+		},
+		{
+			name:     "heading text",
+			section:  "usage & tips",
+			contains: []string{"Usage &amp; Tips", "Read the docs.", "Ask for help."},
+			excludes: []string{"Installation"},
+		},
+		{
+			name:     "slug",
+			section:  "#usage--tips",
+			contains: []string{"Read the docs."},
+		},
+		{
+			name:     "selector",
+			selector: "p.tip",
+			contains: []string{"Read the docs.", "Ask for help."},
+			excludes: []string{"Usage", "Intro"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			content, err := extractHTML(doc, tt.section, tt.selector)
+			require.NoError(t, err)
+			for _, s := range tt.contains {
+				require.Contains(t, content, s)
+			}
+			for _, s := range tt.excludes {
+				require.NotContains(t, content, s)
+			}
+		})
+	}
+
+	_, err = extractHTML(doc, "#missing", "")
+	require.ErrorContains(t, err, `section "#missing" not found`)
+	_, err = extractHTML(doc, "", "table")
+	require.ErrorContains(t, err, `no elements match the selector "table"`)
+}
+
+func TestPaginateContent(t *testing.T) {
+	t.Parallel()
+
+	line := strings.Repeat("x", 99) + "\n"
+	content := "# Start\n" + strings.Repeat(line, FetchPageSize/100) + "# End\n" + line
+	pages := splitPages(content, FetchPageSize)
+	require.Len(t, pages, 2)
+	require.Equal(t, content, strings.Join(pages, ""))
+	for _, page := range pages {
+		require.LessOrEqual(t, len(page), FetchPageSize)
+		require.True(t, strings.HasSuffix(page, "\n"))
+	}
+
+	long := strings.Repeat("é", FetchPageSize)
+	for _, page := range splitPages(long, FetchPageSize) {
+		require.True(t, strings.HasPrefix(page, "é"))
+	}
+
+	headings := []pageHeading{
+		{level: 1, text: "Start", anchor: "start"},
+		{level: 2, text: "End", anchor: "end"},
+	}
+	body, note, err := paginateContent(content, 0, headings)
+	require.NoError(t, err)
+	require.Empty(t, body)
+	require.Contains(t, note, "it has 2 pages")
+	require.Contains(t, note, "Table of contents:\n- Start (#start, page 1)\n  - End (#end, page 2)\n")
+
+	body, note, err = paginateContent(content, 0, nil)
+	require.NoError(t, err)
+	require.Equal(t, pages[0], body)
+	require.Equal(t, "[Page 1 of 2. Use page=2 for the next page.]", note)
+
+	body, note, err = paginateContent(content, 2, headings)
+	require.NoError(t, err)
+	require.Equal(t, pages[1], body)
+	require.Equal(t, "[Page 2 of 2.]", note)
+
+	_, _, err = paginateContent(content, 3, nil)
+	require.ErrorContains(t, err, "page 3 is out of range, the content has 2 pages")
+
+	body, note, err = paginateContent("small", 0, headings)
+	require.NoError(t, err)
+	require.Equal(t, "small", body)
+	require.Empty(t, note)
+}
+
+func TestFetchToolSections(t *testing.T) {
+	t.Parallel()
+
+	large := "<h1>Big</h1>" + strings.Repeat("<p>"+strings.Repeat("x", 99)+"</p>", FetchPageSize/100) + `<h2 id="end">End</h2><p>Done</p>`
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		if r.URL.Path == "/large" {
+			_, _ = io.WriteString(w, "<html><body>"+large+"</body></html>")
+			return
+		}
+		_, _ = io.WriteString(w, sectionsPage)
+	}))
+	defer server.Close()
+
+	tool := NewFetchTool(
+		&mockPermissionService{Broker: pubsub.NewBroker[permission.PermissionRequest]()},
+		t.TempDir(),
+		server.Client(),
+		nil,
+	)
+	resp := runTool(t, tool, FetchParams{URL: server.URL, Format: "text", Section: "#install"})
+	require.False(t, resp.IsError, resp.Content)
+	require.Equal(t, "Installation Run the installer. On Linux Use the package.", resp.Content)
+
+	resp = runTool(t, tool, FetchParams{URL: server.URL + "/large", Format: "markdown"})
+	require.False(t, resp.IsError, resp.Content)
+	require.Contains(t, resp.Content, "Table of contents:\n- Big (#big, page 1)\n  - End (#end, page 2)\n")
+
+	resp = runTool(t, tool, FetchParams{URL: server.URL + "/large", Format: "markdown", Page: 2})
+	require.False(t, resp.IsError, resp.Content)
+	require.True(t, strings.HasPrefix(resp.Content, "```\n"))
+	require.Contains(t, resp.Content, "## End")
+	require.True(t, strings.HasSuffix(resp.Content, "```\n\n[Page 2 of 2.]"))
+
+	resp = runTool(t, tool, FetchParams{URL: server.URL, Format: "text", Section: "#missing"})
+	require.True(t, resp.IsError)
+}
@@ -6,7 +6,7 @@ const AgenticFetchToolName = "agentic_fetch"
 // WebFetchToolName is the name of the web_fetch tool.
 const WebFetchToolName = "web_fetch"
 
-// WebSearchToolName is the name of the web_search tool for sub-agents.
+// WebSearchToolName is the name of the web_search tool.
 const WebSearchToolName = "web_search"
 
 // LargeContentThreshold is the size threshold for saving content to a file.
@@ -14,19 +14,25 @@ const LargeContentThreshold = 50000 // 50KB
 
 // AgenticFetchParams defines the parameters for the agentic fetch tool.
 type AgenticFetchParams struct {
-	URL    string `json:"url,omitempty" description:"The URL to fetch content from (optional - if not provided, the agent will search the web)"`
-	Prompt string `json:"prompt" description:"The prompt describing what information to find or extract"`
+	URL     string `json:"url,omitempty" description:"The URL to fetch content from (optional - if not provided, the agent will search the web)"`
+	Prompt  string `json:"prompt" description:"The prompt describing what information to find or extract"`
+	Refresh bool   `json:"refresh,omitempty" description:"If true, bypass the cache and fetch the page again. Default is false."`
 }
 
 // AgenticFetchPermissionsParams defines the permission parameters for the agentic fetch tool.
 type AgenticFetchPermissionsParams struct {
-	URL    string `json:"url,omitempty"`
-	Prompt string `json:"prompt"`
+	URL     string `json:"url,omitempty"`
+	Prompt  string `json:"prompt"`
+	Refresh bool   `json:"refresh,omitempty"`
 }
 
 // WebFetchParams defines the parameters for the web_fetch tool.
 type WebFetchParams struct {
-	URL string `json:"url" description:"The URL to fetch content from"`
+	URL      string `json:"url" description:"The URL to fetch content from"`
+	Refresh  bool   `json:"refresh,omitempty" description:"If true, bypass the cache and fetch the page again. Default is false."`
+	Section  string `json:"section,omitempty" description:"Optional heading anchor (e.g. #installation), element id or heading text of the section of an HTML page to return"`
+	Selector string `json:"selector,omitempty" description:"Optional CSS selector of the elements of an HTML page to return"`
+	Page     int    `json:"page,omitempty" description:"Optional page number (starting at 1) of content larger than 50KB"`
 }
 
 // WebSearchParams defines the parameters for the web_search tool.
@@ -37,14 +43,22 @@ type WebSearchParams struct {
 
 // FetchParams defines the parameters for the simple fetch tool.
 type FetchParams struct {
-	URL     string `json:"url" description:"The URL to fetch content from"`
-	Format  string `json:"format" description:"The format to return the content in (text, markdown, or html)"`
-	Timeout int    `json:"timeout,omitempty" description:"Optional timeout in seconds (max 120)"`
+	URL      string `json:"url" description:"The URL to fetch content from"`
+	Format   string `json:"format" description:"The format to return the content in (text, markdown, or html)"`
+	Timeout  int    `json:"timeout,omitempty" description:"Optional timeout in seconds (max 120)"`
+	Refresh  bool   `json:"refresh,omitempty" description:"If true, bypass the cache and fetch the page again. Default is false."`
+	Section  string `json:"section,omitempty" description:"Optional heading anchor (e.g. #installation), element id or heading text of the section of an HTML page to return"`
+	Selector string `json:"selector,omitempty" description:"Optional CSS selector of the elements of an HTML page to return"`
+	Page     int    `json:"page,omitempty" description:"Optional page number (starting at 1) of content larger than 50KB"`
 }
 
 // FetchPermissionsParams defines the permission parameters for the simple fetch tool.
 type FetchPermissionsParams struct {
-	URL     string `json:"url"`
-	Format  string `json:"format"`
-	Timeout int    `json:"timeout,omitempty"`
+	URL      string `json:"url"`
+	Format   string `json:"format"`
+	Timeout  int    `json:"timeout,omitempty"`
+	Refresh  bool   `json:"refresh,omitempty"`
+	Section  string `json:"section,omitempty"`
+	Selector string `json:"selector,omitempty"`
+	Page     int    `json:"page,omitempty"`
 }
@@ -0,0 +1,82 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/crush/internal/config"
+	"github.com/charmbracelet/crush/internal/diff"
+	"github.com/charmbracelet/crush/internal/filetracker"
+	"github.com/charmbracelet/crush/internal/history"
+)
+
+const (
+	// formatTimeout is how long a formatter may run.
+	formatTimeout = 30 * time.Second
+	// maxFormatDiffLines is the longest formatting diff reported to the model.
+	maxFormatDiffLines = 50
+)
+
+// formatFile formats the file with the external formatter configured for its
+// file type. It returns the name of the formatter used, or an empty string if
+// none handles the file.
+func formatFile(ctx context.Context, formatCfg config.ToolFormat, workingDir, filePath string) (string, error) {
+	name, formatter, ok := formatCfg.FormatterFor(filePath)
+	if !ok {
+		return "", nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, formatTimeout)
+	defer cancel()
+
+	args := append(formatter.Args[:len(formatter.Args):len(formatter.Args)], filePath)
+	cmd := exec.CommandContext(ctx, formatter.Command, args...)
+	cmd.Dir = workingDir
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return name, fmt.Errorf("%s failed: %w: %s", name, err, strings.TrimSpace(string(out)))
+	}
+	return name, nil
+}
+
+// formatAfterWrite formats a file an edit tool just wrote, when enabled, and
+// records the formatted content. It returns the formatted content, or an
+// empty string if the file did not change, and a note telling the model how
+// the file changed, or why it could not be formatted.
+func formatAfterWrite(ctx context.Context, files history.Service, formatCfg config.ToolFormat, workingDir, filePath string) (formatted, note string) {
+	if !formatCfg.OnWrite {
+		return "", ""
+	}
+
+	before, err := os.ReadFile(filePath)
+	if err != nil {
+		return "", ""
+	}
+	name, err := formatFile(ctx, formatCfg, workingDir, filePath)
+	if err != nil {
+		slog.Warn("Failed to format file", "path", filePath, "formatter", name, "error", err)
+		return "", fmt.Sprintf("\n<format>\nFailed to format the file with %s: %s\n</format>\n", name, err)
+	}
+	after, err := os.ReadFile(filePath)
+	if err != nil || string(before) == string(after) {
+		return "", ""
+	}
+
+	filetracker.RecordWrite(filePath)
+	filetracker.RecordRead(filePath)
+	if sessionID := GetSessionFromContext(ctx); sessionID != "" {
+		if _, err := files.CreateVersion(ctx, sessionID, filePath, string(after)); err != nil {
+			slog.Error("Error creating file history version", "error", err)
+		}
+	}
+
+	formatDiff, _, _ := diff.GenerateDiff(string(before), string(after), strings.TrimPrefix(filePath, workingDir))
+	if lines := strings.Count(formatDiff, "\n"); lines > maxFormatDiffLines {
+		return string(after), fmt.Sprintf("\n<format>\nThe file was reformatted by %s (%d diff lines). View it again before editing it.\n</format>\n", name, lines)
+	}
+	return string(after), fmt.Sprintf("\n<format>\nThe file was reformatted by %s:\n%s</format>\n", name, formatDiff)
+}
@@ -0,0 +1,105 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/charmbracelet/crush/internal/config"
+	"github.com/charmbracelet/crush/internal/csync"
+	"github.com/charmbracelet/crush/internal/history"
+	"github.com/charmbracelet/crush/internal/lsp"
+	"github.com/charmbracelet/crush/internal/permission"
+	"github.com/charmbracelet/crush/internal/pubsub"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFormatAfterWrite(t *testing.T) {
+	t.Parallel()
+
+	gofmt, err := exec.LookPath("gofmt")
+	if err != nil {
+		t.Skip("gofmt not found")
+	}
+
+	tmpDir := t.TempDir()
+	ctx := context.WithValue(t.Context(), SessionIDContextKey, "session")
+	files := &mockHistoryService{Broker: pubsub.NewBroker[history.File]()}
+	formatCfg := config.ToolFormat{
+		OnWrite: true,
+		Formatters: map[string]config.FormatterConfig{
+			"gofmt": {Command: gofmt, Args: []string{"-w"}, FileTypes: []string{"go"}},
+		},
+	}
+
+	t.Run("reformatted", func(t *testing.T) {
+		t.Parallel()
+
+		path := filepath.Join(tmpDir, "a.go")
+		require.NoError(t, os.WriteFile(path, []byte("package a\nfunc  A( ) {}\n"), 0o644))
+
+		formatted, note := formatAfterWrite(ctx, files, formatCfg, tmpDir, path)
+		require.Contains(t, note, "reformatted by gofmt")
+		require.Contains(t, note, "+func A() {}")
+
+		content, err := os.ReadFile(path)
+		require.NoError(t, err)
+		require.Equal(t, "package a\n\nfunc A() {}\n", string(content))
+		require.Equal(t, string(content), formatted)
+	})
+
+	t.Run("edit metadata", func(t *testing.T) {
+		t.Parallel()
+
+		tool := NewEditTool(
+			csync.NewMap[string, *lsp.Client](),
+			&mockPermissionService{Broker: pubsub.NewBroker[permission.PermissionRequest]()},
+			files,
+			tmpDir,
+			nil,
+			formatCfg,
+		)
+		path := filepath.Join(tmpDir, "e.go")
+		resp := runTool(t, tool, EditParams{FilePath: path, NewString: "package e\nfunc  E( ) {}\n"})
+		require.False(t, resp.IsError, resp.Content)
+
+		// The metadata shows the file as the formatter left it.
+		var meta EditResponseMetadata
+		require.NoError(t, json.Unmarshal([]byte(resp.Metadata), &meta))
+		require.Equal(t, "package e\n\nfunc E() {}\n", meta.NewContent)
+		require.Equal(t, 3, meta.Additions)
+	})
+
+	t.Run("already formatted", func(t *testing.T) {
+		t.Parallel()
+
+		path := filepath.Join(tmpDir, "b.go")
+		require.NoError(t, os.WriteFile(path, []byte("package b\n"), 0o644))
+		formatted, note := formatAfterWrite(ctx, files, formatCfg, tmpDir, path)
+		require.Empty(t, formatted)
+		require.Empty(t, note)
+	})
+
+	t.Run("no formatter", func(t *testing.T) {
+		t.Parallel()
+
+		path := filepath.Join(tmpDir, "c.txt")
+		require.NoError(t, os.WriteFile(path, []byte("a  b\n"), 0o644))
+		formatted, note := formatAfterWrite(ctx, files, formatCfg, tmpDir, path)
+		require.Empty(t, formatted)
+		require.Empty(t, note)
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		t.Parallel()
+
+		path := filepath.Join(tmpDir, "d.go")
+		require.NoError(t, os.WriteFile(path, []byte("package d\nfunc  D( ) {}\n"), 0o644))
+		formatted, note := formatAfterWrite(ctx, files, config.ToolFormat{Formatters: formatCfg.Formatters}, tmpDir, path)
+		require.Empty(t, formatted)
+		require.Empty(t, note)
+	})
+}
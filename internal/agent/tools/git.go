@@ -0,0 +1,83 @@
+package tools
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// maxGitListEntries is the maximum number of entries listed per section in
+// the output of the git tools.
+const maxGitListEntries = 200
+
+// runGit runs git in dir and returns what it printed. When stdin isn't
+// empty, it is fed to git. Errors hold the message git printed.
+func runGit(ctx context.Context, dir, stdin string, args ...string) (string, error) {
+	args = append([]string{"-c", "core.quotepath=off", "-c", "color.ui=false"}, args...)
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = dir
+	// Reading the status must not take the index lock away from the user.
+	cmd.Env = append(os.Environ(), "GIT_OPTIONAL_LOCKS=0", "GIT_TERMINAL_PROMPT=0")
+	if stdin != "" {
+		cmd.Stdin = strings.NewReader(stdin)
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if errors.Is(err, exec.ErrNotFound) {
+			return "", errors.New("git is not installed")
+		}
+		// Some commands, such as commit, explain their failures on stdout.
+		msg := strings.TrimSpace(stderr.String())
+		if msg == "" {
+			msg = strings.TrimSpace(stdout.String())
+		}
+		if msg == "" {
+			msg = err.Error()
+		}
+		return stdout.String(), errors.New(msg)
+	}
+	return stdout.String(), nil
+}
+
+// validateGitRef returns an error if ref could be mistaken for an option
+// by git.
+func validateGitRef(ref string) error {
+	if strings.HasPrefix(ref, "-") {
+		return fmt.Errorf("invalid ref %q", ref)
+	}
+	return nil
+}
+
+// gitPathspec returns the arguments limiting a git command to path, if any.
+func gitPathspec(path string) []string {
+	if path == "" {
+		return nil
+	}
+	return []string{"--", path}
+}
+
+// writeGitList writes a titled list of entries, stopping at
+// maxGitListEntries.
+func writeGitList(b *strings.Builder, title string, entries []string) {
+	if len(entries) == 0 {
+		return
+	}
+	if b.Len() > 0 {
+		b.WriteString("\n")
+	}
+	fmt.Fprintf(b, "%s (%d):\n", title, len(entries))
+	for i, entry := range entries {
+		if i == maxGitListEntries {
+			fmt.Fprintf(b, "  ... and %d more\n", len(entries)-i)
+			break
+		}
+		fmt.Fprintf(b, "  %s\n", entry)
+	}
+}
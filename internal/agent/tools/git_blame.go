@@ -0,0 +1,170 @@
+package tools
+
+import (
+	"context"
+	_ "embed"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"charm.land/fantasy"
+)
+
+const GitBlameToolName = "git_blame"
+
+// maxGitBlameLines is the maximum number of lines blamed at once.
+const maxGitBlameLines = 500
+
+//go:embed git_blame.md
+var gitBlameDescription []byte
+
+type GitBlameParams struct {
+	FilePath  string `json:"file_path" description:"The path to the file to blame"`
+	StartLine int    `json:"start_line,omitempty" description:"The first line to blame (1-based, default 1)"`
+	EndLine   int    `json:"end_line,omitempty" description:"The last line to blame (default: 200 lines after start_line)"`
+	Ref       string `json:"ref,omitempty" description:"Blame the file as of this commit or branch instead of the working tree"`
+}
+
+// gitBlameCommit is a commit blamed for some lines.
+type gitBlameCommit struct {
+	author  string
+	date    string
+	summary string
+}
+
+var gitBlameOnlyLinesRe = regexp.MustCompile(`has only (\d+) lines?`)
+
+func NewGitBlameTool(workingDir string) fantasy.AgentTool {
+	return fantasy.NewParallelAgentTool(
+		GitBlameToolName,
+		string(gitBlameDescription),
+		func(ctx context.Context, params GitBlameParams, call fantasy.ToolCall) (fantasy.ToolResponse, error) {
+			if params.FilePath == "" {
+				return fantasy.NewTextErrorResponse("file_path is required"), nil
+			}
+			if err := validateGitRef(params.Ref); err != nil {
+				return fantasy.NewTextErrorResponse(err.Error()), nil
+			}
+			start := max(params.StartLine, 1)
+			end := params.EndLine
+			if end <= 0 {
+				end = start + 199
+			}
+			if end < start {
+				return fantasy.NewTextErrorResponse("end_line must not be before start_line"), nil
+			}
+			end = min(end, start+maxGitBlameLines-1)
+
+			blame := func(end int) (string, error) {
+				args := []string{"blame", "--porcelain", fmt.Sprintf("-L%d,%d", start, end)}
+				if params.Ref != "" {
+					args = append(args, params.Ref)
+				}
+				return runGit(ctx, workingDir, "", append(args, "--", params.FilePath)...)
+			}
+			out, err := blame(end)
+			if err != nil {
+				// Git refuses ranges going past the end of the file, so
+				// retry up to its last line.
+				m := gitBlameOnlyLinesRe.FindStringSubmatch(err.Error())
+				if m == nil {
+					return fantasy.NewTextErrorResponse(err.Error()), nil
+				}
+				lines, _ := strconv.Atoi(m[1])
+				if start > lines {
+					return fantasy.NewTextErrorResponse(fmt.Sprintf("start_line %d is past the end of the file, which has %d lines", start, lines)), nil
+				}
+				end = lines
+				if out, err = blame(end); err != nil {
+					return fantasy.NewTextErrorResponse(err.Error()), nil
+				}
+			}
+
+			return fantasy.NewTextResponse(formatGitBlame(out, params.FilePath, start, end)), nil
+		})
+}
+
+// formatGitBlame formats the output of git blame --porcelain: the commits
+// blamed, followed by the lines prefixed with their commit.
+func formatGitBlame(out, path string, start, end int) string {
+	commits := make(map[string]*gitBlameCommit)
+	var order []string
+	type blameLine struct {
+		hash    string
+		num     int
+		content string
+	}
+	var lines []blameLine
+	var current *gitBlameCommit
+	var hash string
+	var lineNum int
+
+	for line := range strings.SplitSeq(out, "\n") {
+		if content, ok := strings.CutPrefix(line, "\t"); ok {
+			lines = append(lines, blameLine{hash, lineNum, content})
+			continue
+		}
+		key, value, _ := strings.Cut(line, " ")
+		if (len(key) == 40 || len(key) == 64) && isHex(key) {
+			// <hash> <original line> <final line> [<lines in group>]
+			fields := strings.Fields(value)
+			if len(fields) >= 2 {
+				lineNum, _ = strconv.Atoi(fields[1])
+			}
+			hash = key[:8]
+			if current = commits[hash]; current == nil {
+				current = &gitBlameCommit{}
+				commits[hash] = current
+				order = append(order, hash)
+			}
+			continue
+		}
+		if current == nil {
+			continue
+		}
+		switch key {
+		case "author":
+			current.author = value
+		case "author-time":
+			if sec, err := strconv.ParseInt(value, 10, 64); err == nil {
+				current.date = time.Unix(sec, 0).UTC().Format(time.DateOnly)
+			}
+		case "summary":
+			current.summary = value
+		}
+	}
+
+	// Recent versions of git stop at the end of the file instead of
+	// failing, so the range reported is the one actually blamed.
+	if len(lines) > 0 {
+		end = lines[len(lines)-1].num
+	}
+	width := len(strconv.Itoa(end))
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Blame of %s, lines %d-%d\n\nCommits:\n", path, start, end)
+	for _, hash := range order {
+		c := commits[hash]
+		if strings.Trim(hash, "0") == "" {
+			fmt.Fprintf(&b, "  %s (not committed yet)\n", hash)
+			continue
+		}
+		fmt.Fprintf(&b, "  %s %s %s: %s\n", hash, c.date, c.author, c.summary)
+	}
+	b.WriteString("\n")
+	for _, line := range lines {
+		fmt.Fprintf(&b, "%s %*d| %s\n", line.hash, width, line.num, line.content)
+	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+func isHex(s string) bool {
+	for _, r := range s {
+		if !strings.ContainsRune("0123456789abcdef", r) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,134 @@
+package tools
+
+import (
+	"context"
+	_ "embed"
+	"fmt"
+	"strings"
+
+	"charm.land/fantasy"
+	"github.com/charmbracelet/crush/internal/config"
+	"github.com/charmbracelet/crush/internal/permission"
+)
+
+const GitCommitToolName = "git_commit"
+
+//go:embed git_commit.md
+var gitCommitDescription []byte
+
+type GitCommitParams struct {
+	Message string   `json:"message" description:"The commit message, without any attribution"`
+	Files   []string `json:"files,omitempty" description:"Files to stage before committing, including new and deleted files"`
+	All     bool     `json:"all,omitempty" description:"Stage every modified and deleted tracked file before committing, like git commit -a"`
+}
+
+type GitCommitPermissionsParams struct {
+	Message string   `json:"message"`
+	Files   []string `json:"files,omitempty"`
+	All     bool     `json:"all,omitempty"`
+}
+
+type GitCommitResponseMetadata struct {
+	Hash    string `json:"hash"`
+	Branch  string `json:"branch,omitempty"`
+	Message string `json:"message"`
+}
+
+const (
+	generatedWithCrush = "💘 Generated with Crush"
+	crushAuthor        = "Crush <crush@charm.land>"
+)
+
+func NewGitCommitTool(permissions permission.Service, workingDir string, attribution *config.Attribution, modelName string) fantasy.AgentTool {
+	return fantasy.NewAgentTool(
+		GitCommitToolName,
+		string(gitCommitDescription),
+		func(ctx context.Context, params GitCommitParams, call fantasy.ToolCall) (fantasy.ToolResponse, error) {
+			if strings.TrimSpace(params.Message) == "" {
+				return fantasy.NewTextErrorResponse("message is required"), nil
+			}
+
+			sessionID := GetSessionFromContext(ctx)
+			if sessionID == "" {
+				return fantasy.ToolResponse{}, fmt.Errorf("session ID is required for creating a commit")
+			}
+
+			message := commitMessage(params.Message, attribution, modelName)
+			subject, _, _ := strings.Cut(message, "\n")
+			p, err := permissions.Request(ctx,
+				permission.CreatePermissionRequest{
+					SessionID:   sessionID,
+					Path:        workingDir,
+					ToolCallID:  call.ID,
+					ToolName:    GitCommitToolName,
+					Action:      "commit",
+					Description: fmt.Sprintf("Create commit: %s", subject),
+					Params: GitCommitPermissionsParams{
+						Message: message,
+						Files:   params.Files,
+						All:     params.All,
+					},
+				},
+			)
+			if err != nil {
+				return fantasy.ToolResponse{}, err
+			}
+			if !p {
+				return fantasy.ToolResponse{}, permission.ErrorPermissionDenied
+			}
+
+			if len(params.Files) > 0 {
+				args := append([]string{"add", "--all", "--"}, params.Files...)
+				if _, err := runGit(ctx, workingDir, "", args...); err != nil {
+					return fantasy.NewTextErrorResponse(fmt.Sprintf("Failed to stage files: %s", err)), nil
+				}
+			}
+
+			args := []string{"commit", "--file=-"}
+			if params.All {
+				args = append(args, "--all")
+			}
+			out, err := runGit(ctx, workingDir, message, args...)
+			if err != nil {
+				return fantasy.NewTextErrorResponse(fmt.Sprintf("Failed to commit: %s", err)), nil
+			}
+
+			metadata := GitCommitResponseMetadata{Message: message}
+			if hash, err := runGit(ctx, workingDir, "", "rev-parse", "--short", "HEAD"); err == nil {
+				metadata.Hash = strings.TrimSpace(hash)
+			}
+			if branch, err := runGit(ctx, workingDir, "", "branch", "--show-current"); err == nil {
+				metadata.Branch = strings.TrimSpace(branch)
+			}
+			return fantasy.WithResponseMetadata(fantasy.NewTextResponse(strings.TrimSpace(out)), metadata), nil
+		})
+}
+
+// commitMessage returns message followed by the attribution configured in
+// attribution, unless the message already has it.
+func commitMessage(message string, attribution *config.Attribution, modelName string) string {
+	message = strings.TrimSpace(message)
+	if attribution == nil {
+		return message
+	}
+
+	if attribution.GeneratedWith && !strings.Contains(message, generatedWithCrush) {
+		message += "\n\n" + generatedWithCrush
+	}
+
+	var trailer string
+	switch attribution.TrailerStyle {
+	case config.TrailerStyleAssistedBy:
+		if modelName != "" {
+			trailer = fmt.Sprintf("Assisted-by: %s via %s", modelName, crushAuthor)
+		} else {
+			trailer = "Assisted-by: " + crushAuthor
+		}
+	case config.TrailerStyleCoAuthoredBy:
+		trailer = "Co-Authored-By: " + crushAuthor
+	}
+	if trailer != "" && !strings.Contains(message, trailer) {
+		message += "\n\n" + trailer
+	}
+	return message
+}
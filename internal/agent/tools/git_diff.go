@@ -0,0 +1,260 @@
+package tools
+
+import (
+	"context"
+	_ "embed"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"charm.land/fantasy"
+)
+
+const GitDiffToolName = "git_diff"
+
+//go:embed git_diff.md
+var gitDiffDescription []byte
+
+type GitDiffParams struct {
+	Path     string `json:"path,omitempty" description:"File or directory to limit the diff to"`
+	Staged   bool   `json:"staged,omitempty" description:"Show the staged changes instead of the unstaged ones"`
+	Ref      string `json:"ref,omitempty" description:"Commit, branch or range (e.g. HEAD~3, main...HEAD) to diff against"`
+	Hunk     int    `json:"hunk,omitempty" description:"Only show this hunk (starting at 1) of the file given by path"`
+	Context  *int   `json:"context,omitempty" description:"Number of context lines around each change (default 3)"`
+	StatOnly bool   `json:"stat_only,omitempty" description:"Only list the changed files with their number of added and removed lines"`
+}
+
+type GitDiffResponseMetadata struct {
+	Files     int `json:"files"`
+	Additions int `json:"additions"`
+	Deletions int `json:"deletions"`
+}
+
+// gitDiffFile is a file of a unified diff.
+type gitDiffFile struct {
+	path      string
+	oldPath   string
+	status    string
+	binary    bool
+	hunks     []gitDiffHunk
+	additions int
+	deletions int
+}
+
+// gitDiffHunk is a hunk of a unified diff: its @@ header and its lines.
+type gitDiffHunk struct {
+	header string
+	lines  []string
+}
+
+func NewGitDiffTool(workingDir string) fantasy.AgentTool {
+	return fantasy.NewParallelAgentTool(
+		GitDiffToolName,
+		string(gitDiffDescription),
+		func(ctx context.Context, params GitDiffParams, call fantasy.ToolCall) (fantasy.ToolResponse, error) {
+			if err := validateGitRef(params.Ref); err != nil {
+				return fantasy.NewTextErrorResponse(err.Error()), nil
+			}
+			if params.Hunk < 0 || (params.Context != nil && *params.Context < 0) {
+				return fantasy.NewTextErrorResponse("hunk and context must not be negative"), nil
+			}
+			if params.Hunk > 0 && params.Path == "" {
+				return fantasy.NewTextErrorResponse("hunk requires the path of a file"), nil
+			}
+
+			args := []string{"diff", "--no-ext-diff", "--find-renames"}
+			if params.Context != nil {
+				args = append(args, "--unified="+strconv.Itoa(*params.Context))
+			}
+			if params.Staged {
+				args = append(args, "--cached")
+			}
+			if params.Ref != "" {
+				args = append(args, params.Ref)
+			}
+			args = append(args, gitPathspec(params.Path)...)
+			out, err := runGit(ctx, workingDir, "", args...)
+			if err != nil {
+				return fantasy.NewTextErrorResponse(err.Error()), nil
+			}
+
+			files := parseGitDiff(out)
+			var metadata GitDiffResponseMetadata
+			for _, f := range files {
+				metadata.Files++
+				metadata.Additions += f.additions
+				metadata.Deletions += f.deletions
+			}
+
+			var output string
+			if params.Hunk > 0 {
+				output, err = formatGitDiffHunk(files, params.Hunk)
+				if err != nil {
+					return fantasy.NewTextErrorResponse(err.Error()), nil
+				}
+			} else {
+				output = formatGitDiff(files, params.StatOnly)
+			}
+			return fantasy.WithResponseMetadata(fantasy.NewTextResponse(output), metadata), nil
+		})
+}
+
+// parseGitDiff parses the output of git diff into its files.
+func parseGitDiff(out string) []gitDiffFile {
+	var files []gitDiffFile
+	var file *gitDiffFile
+	var hunk *gitDiffHunk
+	for line := range strings.SplitSeq(out, "\n") {
+		if strings.HasPrefix(line, "diff --git ") {
+			files = append(files, gitDiffFile{status: "modified"})
+			file = &files[len(files)-1]
+			hunk = nil
+			// The paths are only ambiguous when they contain spaces, and
+			// the ---/+++ lines, if any, override them.
+			rest := strings.TrimPrefix(line, "diff --git ")
+			if half := (len(rest) - 1) / 2; half > 2 {
+				file.path = strings.TrimPrefix(rest[:half], "a/")
+			}
+			continue
+		}
+		if file == nil {
+			continue
+		}
+
+		if hunk != nil && line != "" && strings.ContainsRune(" +-\\", rune(line[0])) {
+			hunk.lines = append(hunk.lines, line)
+			switch line[0] {
+			case '+':
+				file.additions++
+			case '-':
+				file.deletions++
+			}
+			continue
+		}
+
+		switch {
+		case strings.HasPrefix(line, "@@"):
+			file.hunks = append(file.hunks, gitDiffHunk{header: line})
+			hunk = &file.hunks[len(file.hunks)-1]
+		case strings.HasPrefix(line, "new file mode"):
+			file.status = "added"
+		case strings.HasPrefix(line, "deleted file mode"):
+			file.status = "deleted"
+		case strings.HasPrefix(line, "old mode"):
+			file.status = "mode changed"
+		case strings.HasPrefix(line, "rename from "):
+			file.status = "renamed"
+			file.oldPath = strings.TrimPrefix(line, "rename from ")
+		case strings.HasPrefix(line, "rename to "):
+			file.path = strings.TrimPrefix(line, "rename to ")
+		case strings.HasPrefix(line, "copy from "):
+			file.status = "copied"
+			file.oldPath = strings.TrimPrefix(line, "copy from ")
+		case strings.HasPrefix(line, "copy to "):
+			file.path = strings.TrimPrefix(line, "copy to ")
+		case strings.HasPrefix(line, "Binary files "):
+			file.binary = true
+		case strings.HasPrefix(line, "--- "):
+			if path := diffFilePath(line[4:], "a/"); path != "" {
+				file.path = path
+			}
+		case strings.HasPrefix(line, "+++ "):
+			if path := diffFilePath(line[4:], "b/"); path != "" {
+				file.path = path
+			}
+		}
+	}
+	return files
+}
+
+// diffFilePath returns the path of a ---/+++ line of a diff, or "" for
+// /dev/null.
+func diffFilePath(s, prefix string) string {
+	s, _, _ = strings.Cut(s, "\t")
+	if s == "/dev/null" {
+		return ""
+	}
+	return strings.TrimPrefix(s, prefix)
+}
+
+func (f gitDiffFile) summary() string {
+	name := f.path
+	if f.oldPath != "" {
+		name = f.oldPath + " -> " + f.path
+	}
+	if f.binary {
+		return fmt.Sprintf("%s (%s, binary)", name, f.status)
+	}
+	hunks := "hunks"
+	if len(f.hunks) == 1 {
+		hunks = "hunk"
+	}
+	return fmt.Sprintf("%s (%s, +%d -%d, %d %s)", name, f.status, f.additions, f.deletions, len(f.hunks), hunks)
+}
+
+func writeGitDiffHunk(b *strings.Builder, hunk gitDiffHunk, n, total int) {
+	fmt.Fprintf(b, "Hunk %d/%d: %s\n", n, total, hunk.header)
+	for _, line := range hunk.lines {
+		b.WriteString(line)
+		b.WriteString("\n")
+	}
+}
+
+// formatGitDiff lists the files of a diff, followed by their hunks unless
+// statOnly is set. Hunks are left out once the output grows past
+// MaxOutputLength.
+func formatGitDiff(files []gitDiffFile, statOnly bool) string {
+	if len(files) == 0 {
+		return "No changes"
+	}
+
+	var b strings.Builder
+	var additions, deletions int
+	for _, f := range files {
+		additions += f.additions
+		deletions += f.deletions
+	}
+	fmt.Fprintf(&b, "%d files changed, %d insertions(+), %d deletions(-)\n\n", len(files), additions, deletions)
+	for _, f := range files {
+		b.WriteString(f.summary())
+		b.WriteString("\n")
+	}
+	if statOnly {
+		return strings.TrimSuffix(b.String(), "\n")
+	}
+
+	for i, f := range files {
+		if len(f.hunks) == 0 {
+			continue
+		}
+		var section strings.Builder
+		fmt.Fprintf(&section, "\n=== %s ===\n", f.path)
+		for j, hunk := range f.hunks {
+			writeGitDiffHunk(&section, hunk, j+1, len(f.hunks))
+		}
+		if b.Len()+section.Len() > MaxOutputLength {
+			fmt.Fprintf(&b, "\n[Diff truncated: the hunks of %d files are not shown. Use path, and hunk, to see them.]", len(files)-i)
+			return b.String()
+		}
+		b.WriteString(section.String())
+	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+// formatGitDiffHunk returns the n-th hunk of the only file of a diff.
+func formatGitDiffHunk(files []gitDiffFile, n int) (string, error) {
+	switch {
+	case len(files) == 0:
+		return "", errors.New("no changes")
+	case len(files) > 1:
+		return "", fmt.Errorf("path matches %d changed files, hunk requires a single file", len(files))
+	case n > len(files[0].hunks):
+		return "", fmt.Errorf("hunk %d is out of range, %s has %d hunks", n, files[0].path, len(files[0].hunks))
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "=== %s ===\n", files[0].path)
+	writeGitDiffHunk(&b, files[0].hunks[n-1], n, len(files[0].hunks))
+	return strings.TrimSuffix(b.String(), "\n"), nil
+}
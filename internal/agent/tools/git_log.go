@@ -0,0 +1,142 @@
+package tools
+
+import (
+	"context"
+	_ "embed"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"charm.land/fantasy"
+)
+
+const GitLogToolName = "git_log"
+
+const (
+	defaultGitLogLimit = 20
+	maxGitLogLimit     = 100
+)
+
+//go:embed git_log.md
+var gitLogDescription []byte
+
+type GitLogParams struct {
+	Ref    string `json:"ref,omitempty" description:"Commit, branch or range (e.g. main..HEAD) to list the commits of (defaults to HEAD)"`
+	Path   string `json:"path,omitempty" description:"Only list the commits changing this file or directory"`
+	Limit  int    `json:"limit,omitempty" description:"Maximum number of commits to list (default 20, max 100)"`
+	Author string `json:"author,omitempty" description:"Only list the commits of authors matching this pattern"`
+	Since  string `json:"since,omitempty" description:"Only list the commits more recent than this date (e.g. 2024-01-31, 2 weeks ago)"`
+	Grep   string `json:"grep,omitempty" description:"Only list the commits whose message matches this pattern"`
+	Stat   bool   `json:"stat,omitempty" description:"List the files changed by each commit"`
+}
+
+// gitLogFormat separates the commits with a record separator and their
+// fields with a unit separator. The body is terminated by a group
+// separator, since it is followed by the file stats, if any.
+const gitLogFormat = "--format=%x1e%H%x1f%an <%ae>%x1f%ad%x1f%s%x1f%b%x1d"
+
+func NewGitLogTool(workingDir string) fantasy.AgentTool {
+	return fantasy.NewParallelAgentTool(
+		GitLogToolName,
+		string(gitLogDescription),
+		func(ctx context.Context, params GitLogParams, call fantasy.ToolCall) (fantasy.ToolResponse, error) {
+			if err := validateGitRef(params.Ref); err != nil {
+				return fantasy.NewTextErrorResponse(err.Error()), nil
+			}
+			limit := params.Limit
+			if limit <= 0 {
+				limit = defaultGitLogLimit
+			}
+			limit = min(limit, maxGitLogLimit)
+
+			args := []string{"log", gitLogFormat, "--date=short", "--max-count=" + strconv.Itoa(limit)}
+			if params.Stat {
+				args = append(args, "--numstat")
+			}
+			if params.Author != "" {
+				args = append(args, "--author="+params.Author)
+			}
+			if params.Since != "" {
+				args = append(args, "--since="+params.Since)
+			}
+			if params.Grep != "" {
+				args = append(args, "--regexp-ignore-case", "--grep="+params.Grep)
+			}
+			if params.Ref != "" {
+				args = append(args, params.Ref)
+			}
+			args = append(args, gitPathspec(params.Path)...)
+			out, err := runGit(ctx, workingDir, "", args...)
+			if err != nil {
+				return fantasy.NewTextErrorResponse(err.Error()), nil
+			}
+
+			return fantasy.NewTextResponse(formatGitLog(out, limit)), nil
+		})
+}
+
+// formatGitLog formats the output of git log with [gitLogFormat].
+func formatGitLog(out string, limit int) string {
+	var b strings.Builder
+	var count int
+	for record := range strings.SplitSeq(out, "\x1e") {
+		message, stats, _ := strings.Cut(record, "\x1d")
+		fields := strings.SplitN(message, "\x1f", 5)
+		if len(fields) != 5 {
+			continue
+		}
+		hash, author, date, subject, body := fields[0], fields[1], fields[2], fields[3], strings.TrimSpace(fields[4])
+		if len(hash) > 10 {
+			hash = hash[:10]
+		}
+
+		var section strings.Builder
+		if count > 0 {
+			section.WriteString("\n")
+		}
+		fmt.Fprintf(&section, "%s %s %s\n    %s\n", hash, date, author, subject)
+		if body != "" {
+			section.WriteString("\n")
+			for line := range strings.SplitSeq(body, "\n") {
+				section.WriteString(strings.TrimRight("    "+line, " "))
+				section.WriteString("\n")
+			}
+		}
+		if files := formatGitNumstat(stats); files != "" {
+			section.WriteString("\n")
+			section.WriteString(files)
+		}
+
+		if b.Len()+section.Len() > MaxOutputLength {
+			fmt.Fprintf(&b, "\n[Output truncated after %d commits. Use a smaller limit, or narrow down with ref, path or since.]", count)
+			return b.String()
+		}
+		b.WriteString(section.String())
+		count++
+	}
+
+	if count == 0 {
+		return "No commits found"
+	}
+	if count == limit {
+		fmt.Fprintf(&b, "\n[Showing the first %d commits. Raise limit, or narrow down with ref, path or since, to see others.]", count)
+	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+// formatGitNumstat formats the --numstat lines of a commit.
+func formatGitNumstat(stats string) string {
+	var b strings.Builder
+	for line := range strings.SplitSeq(strings.TrimSpace(stats), "\n") {
+		fields := strings.SplitN(line, "\t", 3)
+		if len(fields) != 3 {
+			continue
+		}
+		if fields[0] == "-" {
+			fmt.Fprintf(&b, "    %s (binary)\n", fields[2])
+			continue
+		}
+		fmt.Fprintf(&b, "    %s (+%s -%s)\n", fields[2], fields[0], fields[1])
+	}
+	return b.String()
+}
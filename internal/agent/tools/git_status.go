@@ -0,0 +1,187 @@
+package tools
+
+import (
+	"context"
+	_ "embed"
+	"fmt"
+	"strings"
+
+	"charm.land/fantasy"
+)
+
+const GitStatusToolName = "git_status"
+
+//go:embed git_status.md
+var gitStatusDescription []byte
+
+type GitStatusParams struct {
+	Path string `json:"path,omitempty" description:"File or directory to limit the status to (defaults to the whole repository)"`
+}
+
+type GitStatusResponseMetadata struct {
+	Branch     string `json:"branch"`
+	Upstream   string `json:"upstream,omitempty"`
+	Ahead      int    `json:"ahead,omitempty"`
+	Behind     int    `json:"behind,omitempty"`
+	Staged     int    `json:"staged"`
+	Unstaged   int    `json:"unstaged"`
+	Untracked  int    `json:"untracked"`
+	Conflicted int    `json:"conflicted"`
+}
+
+// gitStatus is the parsed output of git status --porcelain=v2.
+type gitStatus struct {
+	GitStatusResponseMetadata
+	oid        string
+	staged     []string
+	unstaged   []string
+	untracked  []string
+	conflicted []string
+}
+
+func NewGitStatusTool(workingDir string) fantasy.AgentTool {
+	return fantasy.NewParallelAgentTool(
+		GitStatusToolName,
+		string(gitStatusDescription),
+		func(ctx context.Context, params GitStatusParams, call fantasy.ToolCall) (fantasy.ToolResponse, error) {
+			args := append([]string{"status", "--porcelain=v2", "--branch", "-z", "--untracked-files=normal"}, gitPathspec(params.Path)...)
+			out, err := runGit(ctx, workingDir, "", args...)
+			if err != nil {
+				return fantasy.NewTextErrorResponse(err.Error()), nil
+			}
+
+			status := parseGitStatus(out)
+			return fantasy.WithResponseMetadata(
+				fantasy.NewTextResponse(formatGitStatus(status)),
+				status.GitStatusResponseMetadata,
+			), nil
+		})
+}
+
+// parseGitStatus parses the output of git status --porcelain=v2 --branch -z.
+func parseGitStatus(out string) gitStatus {
+	var status gitStatus
+	entries := strings.Split(out, "\x00")
+	for i := 0; i < len(entries); i++ {
+		entry := entries[i]
+		switch {
+		case strings.HasPrefix(entry, "# branch.oid "):
+			status.oid = strings.TrimPrefix(entry, "# branch.oid ")
+		case strings.HasPrefix(entry, "# branch.head "):
+			status.Branch = strings.TrimPrefix(entry, "# branch.head ")
+		case strings.HasPrefix(entry, "# branch.upstream "):
+			status.Upstream = strings.TrimPrefix(entry, "# branch.upstream ")
+		case strings.HasPrefix(entry, "# branch.ab "):
+			_, _ = fmt.Sscanf(strings.TrimPrefix(entry, "# branch.ab "), "+%d -%d", &status.Ahead, &status.Behind)
+		case strings.HasPrefix(entry, "1 "):
+			// 1 XY sub mH mI mW hH hI path
+			fields := strings.SplitN(entry, " ", 9)
+			if len(fields) == 9 {
+				status.addChange(fields[1], fields[8], "")
+			}
+		case strings.HasPrefix(entry, "2 "):
+			// 2 XY sub mH mI mW hH hI Xscore path, followed by the
+			// original path.
+			fields := strings.SplitN(entry, " ", 10)
+			if len(fields) == 10 && i+1 < len(entries) {
+				i++
+				status.addChange(fields[1], fields[9], entries[i])
+			}
+		case strings.HasPrefix(entry, "u "):
+			// u XY sub m1 m2 m3 mW h1 h2 h3 path
+			fields := strings.SplitN(entry, " ", 11)
+			if len(fields) == 11 {
+				status.conflicted = append(status.conflicted, gitConflictName(fields[1])+": "+fields[10])
+			}
+		case strings.HasPrefix(entry, "? "):
+			status.untracked = append(status.untracked, strings.TrimPrefix(entry, "? "))
+		}
+	}
+	status.Staged = len(status.staged)
+	status.Unstaged = len(status.unstaged)
+	status.Untracked = len(status.untracked)
+	status.Conflicted = len(status.conflicted)
+	return status
+}
+
+func (s *gitStatus) addChange(xy, path, origPath string) {
+	if len(xy) != 2 {
+		return
+	}
+	name := path
+	if origPath != "" {
+		name = origPath + " -> " + path
+	}
+	if xy[0] != '.' {
+		s.staged = append(s.staged, gitChangeName(xy[0])+": "+name)
+	}
+	if xy[1] != '.' {
+		s.unstaged = append(s.unstaged, gitChangeName(xy[1])+": "+name)
+	}
+}
+
+func gitChangeName(c byte) string {
+	switch c {
+	case 'M':
+		return "modified"
+	case 'A':
+		return "added"
+	case 'D':
+		return "deleted"
+	case 'R':
+		return "renamed"
+	case 'C':
+		return "copied"
+	case 'T':
+		return "type changed"
+	default:
+		return string(c)
+	}
+}
+
+func gitConflictName(xy string) string {
+	switch xy {
+	case "DD":
+		return "both deleted"
+	case "AU":
+		return "added by us"
+	case "UD":
+		return "deleted by them"
+	case "UA":
+		return "added by them"
+	case "DU":
+		return "deleted by us"
+	case "AA":
+		return "both added"
+	default:
+		return "both modified"
+	}
+}
+
+func formatGitStatus(status gitStatus) string {
+	var b strings.Builder
+	switch {
+	case status.Branch == "(detached)" && len(status.oid) >= 7:
+		fmt.Fprintf(&b, "Branch: (detached at %s)\n", status.oid[:7])
+	case status.oid == "(initial)":
+		fmt.Fprintf(&b, "Branch: %s (no commits yet)\n", status.Branch)
+	default:
+		fmt.Fprintf(&b, "Branch: %s\n", status.Branch)
+	}
+	if status.Upstream != "" {
+		fmt.Fprintf(&b, "Upstream: %s (ahead %d, behind %d)\n", status.Upstream, status.Ahead, status.Behind)
+	}
+
+	var changes strings.Builder
+	writeGitList(&changes, "Conflicts", status.conflicted)
+	writeGitList(&changes, "Staged changes", status.staged)
+	writeGitList(&changes, "Unstaged changes", status.unstaged)
+	writeGitList(&changes, "Untracked files", status.untracked)
+	if changes.Len() == 0 {
+		b.WriteString("\nWorking tree clean")
+		return b.String()
+	}
+	b.WriteString("\n")
+	b.WriteString(strings.TrimSuffix(changes.String(), "\n"))
+	return b.String()
+}
@@ -0,0 +1,240 @@
+package tools
+
+import (
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/crush/internal/config"
+	"github.com/charmbracelet/crush/internal/permission"
+	"github.com/charmbracelet/crush/internal/pubsub"
+	"github.com/stretchr/testify/require"
+)
+
+// newGitRepo creates a repository with a single commit of a.txt.
+func newGitRepo(t *testing.T) string {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	dir := t.TempDir()
+	git := func(args ...string) {
+		t.Helper()
+		_, err := runGit(t.Context(), dir, "", args...)
+		require.NoError(t, err)
+	}
+	git("init", "--initial-branch=main")
+	git("config", "user.name", "Test")
+	git("config", "user.email", "test@example.com")
+	git("config", "commit.gpgsign", "false")
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "a.txt"), []byte("one\ntwo\nthree\n"), 0o644))
+	git("add", "a.txt")
+	git("commit", "--message=Add a.txt")
+	return dir
+}
+
+func TestParseGitStatus(t *testing.T) {
+	t.Parallel()
+
+	out := strings.Join([]string{
+		"# branch.oid 0123456789abcdef0123456789abcdef01234567",
+		"# branch.head main",
+		"# branch.upstream origin/main",
+		"# branch.ab +2 -1",
+		"1 M. N... 100644 100644 100644 aaa bbb staged.go",
+		"1 .M N... 100644 100644 100644 aaa bbb unstaged.go",
+		"2 R. N... 100644 100644 100644 aaa bbb R100 new.go",
+		"old.go",
+		"u UU N... 100644 100644 100644 100644 aaa bbb ccc conflict.go",
+		"? new file.txt",
+		"",
+	}, "\x00")
+
+	status := parseGitStatus(out)
+	require.Equal(t, GitStatusResponseMetadata{
+		Branch:     "main",
+		Upstream:   "origin/main",
+		Ahead:      2,
+		Behind:     1,
+		Staged:     2,
+		Unstaged:   1,
+		Untracked:  1,
+		Conflicted: 1,
+	}, status.GitStatusResponseMetadata)
+	require.Equal(t, []string{"modified: staged.go", "renamed: old.go -> new.go"}, status.staged)
+
+	require.Equal(t, `Branch: main
+Upstream: origin/main (ahead 2, behind 1)
+
+Conflicts (1):
+  both modified: conflict.go
+
+Staged changes (2):
+  modified: staged.go
+  renamed: old.go -> new.go
+
+Unstaged changes (1):
+  modified: unstaged.go
+
+Untracked files (1):
+  new file.txt`, formatGitStatus(status))
+
+	clean := parseGitStatus("# branch.oid (initial)\x00# branch.head main\x00")
+	require.Equal(t, "Branch: main (no commits yet)\n\nWorking tree clean", formatGitStatus(clean))
+}
+
+func TestParseGitDiff(t *testing.T) {
+	t.Parallel()
+
+	out := `diff --git a/a.go b/a.go
+index 1111111..2222222 100644
+--- a/a.go
++++ b/a.go
+@@ -1,3 +1,3 @@ package main
+ package main
+-var x = 1
++var x = 2
+@@ -10,2 +10,3 @@ func main() {
+ 	foo()
++	bar()
+ }
+diff --git a/new.go b/new.go
+new file mode 100644
+index 0000000..3333333
+--- /dev/null
++++ b/new.go
+@@ -0,0 +1 @@
++package main
+diff --git a/old name.go b/new name.go
+similarity index 100%
+rename from old name.go
+rename to new name.go
+diff --git a/logo.png b/logo.png
+index 4444444..5555555 100644
+Binary files a/logo.png and b/logo.png differ
+`
+
+	files := parseGitDiff(out)
+	require.Len(t, files, 4)
+	require.Equal(t, "a.go (modified, +2 -1, 2 hunks)", files[0].summary())
+	require.Equal(t, "new.go (added, +1 -0, 1 hunk)", files[1].summary())
+	require.Equal(t, "old name.go -> new name.go (renamed, +0 -0, 0 hunks)", files[2].summary())
+	require.Equal(t, "logo.png (modified, binary)", files[3].summary())
+
+	stat := formatGitDiff(files, true)
+	require.True(t, strings.HasPrefix(stat, "4 files changed, 3 insertions(+), 1 deletions(-)\n\n"))
+	require.NotContains(t, stat, "Hunk")
+
+	full := formatGitDiff(files, false)
+	require.Contains(t, full, "=== a.go ===\nHunk 1/2: @@ -1,3 +1,3 @@ package main\n package main\n-var x = 1\n+var x = 2\nHunk 2/2:")
+	require.Contains(t, full, "=== new.go ===\nHunk 1/1: @@ -0,0 +1 @@\n+package main")
+
+	hunk, err := formatGitDiffHunk(files[:1], 2)
+	require.NoError(t, err)
+	require.Equal(t, "=== a.go ===\nHunk 2/2: @@ -10,2 +10,3 @@ func main() {\n \tfoo()\n+\tbar()\n }", hunk)
+
+	_, err = formatGitDiffHunk(files[:1], 3)
+	require.EqualError(t, err, "hunk 3 is out of range, a.go has 2 hunks")
+	_, err = formatGitDiffHunk(files, 1)
+	require.EqualError(t, err, "path matches 4 changed files, hunk requires a single file")
+	require.Equal(t, "No changes", formatGitDiff(nil, false))
+}
+
+func TestFormatGitLog(t *testing.T) {
+	t.Parallel()
+
+	out := "\x1e0123456789abcdef\x1fAlice <alice@example.com>\x1f2024-05-01\x1fFix the parser\x1fIt choked on empty input.\n\x1d\n\n3\t1\tparser.go\n-\t-\tlogo.png\n" +
+		"\x1efedcba9876543210\x1fBob <bob@example.com>\x1f2024-04-30\x1fInitial commit\x1f\x1d\n"
+
+	require.Equal(t, `0123456789 2024-05-01 Alice <alice@example.com>
+    Fix the parser
+
+    It choked on empty input.
+
+    parser.go (+3 -1)
+    logo.png (binary)
+
+fedcba9876 2024-04-30 Bob <bob@example.com>
+    Initial commit`, formatGitLog(out, 20))
+
+	require.Contains(t, formatGitLog(out, 2), "[Showing the first 2 commits.")
+	require.Equal(t, "No commits found", formatGitLog("", 20))
+}
+
+func TestCommitMessage(t *testing.T) {
+	t.Parallel()
+
+	require.Equal(t, "Fix it", commitMessage("Fix it\n", nil, "model"))
+	require.Equal(t, "Fix it", commitMessage("Fix it", &config.Attribution{TrailerStyle: config.TrailerStyleNone}, "model"))
+	require.Equal(t,
+		"Fix it\n\n💘 Generated with Crush\n\nAssisted-by: model via Crush <crush@charm.land>",
+		commitMessage("Fix it", &config.Attribution{TrailerStyle: config.TrailerStyleAssistedBy, GeneratedWith: true}, "model"),
+	)
+
+	// The attribution isn't repeated when the message already has it.
+	message := "Fix it\n\nCo-Authored-By: Crush <crush@charm.land>"
+	require.Equal(t, message, commitMessage(message, &config.Attribution{TrailerStyle: config.TrailerStyleCoAuthoredBy}, "model"))
+}
+
+func TestGitTools(t *testing.T) {
+	t.Parallel()
+
+	dir := newGitRepo(t)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "a.txt"), []byte("one\n2\nthree\n"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "b.txt"), []byte("new\n"), 0o644))
+
+	resp := runTool(t, NewGitStatusTool(dir), GitStatusParams{})
+	require.False(t, resp.IsError, resp.Content)
+	require.Contains(t, resp.Content, "Branch: main")
+	require.Contains(t, resp.Content, "Unstaged changes (1):\n  modified: a.txt")
+	require.Contains(t, resp.Content, "Untracked files (1):\n  b.txt")
+
+	resp = runTool(t, NewGitDiffTool(dir), GitDiffParams{Path: "a.txt", Hunk: 1})
+	require.False(t, resp.IsError, resp.Content)
+	require.Contains(t, resp.Content, "-two\n+2")
+
+	noContext := 0
+	resp = runTool(t, NewGitDiffTool(dir), GitDiffParams{Path: "a.txt", Context: &noContext})
+	require.False(t, resp.IsError, resp.Content)
+	require.Contains(t, resp.Content, "Hunk 1/1: @@ -2 +2 @@ one\n-two\n+2")
+	require.NotContains(t, resp.Content, "three")
+
+	resp = runTool(t, NewGitDiffTool(dir), GitDiffParams{Ref: "--output=x"})
+	require.True(t, resp.IsError)
+
+	commit := NewGitCommitTool(
+		&mockPermissionService{Broker: pubsub.NewBroker[permission.PermissionRequest]()},
+		dir,
+		&config.Attribution{TrailerStyle: config.TrailerStyleAssistedBy},
+		"model",
+	)
+	resp = runTool(t, commit, GitCommitParams{Message: "Update a.txt and add b.txt", Files: []string{"b.txt"}, All: true})
+	require.False(t, resp.IsError, resp.Content)
+	var metadata GitCommitResponseMetadata
+	require.NoError(t, json.Unmarshal([]byte(resp.Metadata), &metadata))
+	require.Equal(t, "main", metadata.Branch)
+	require.NotEmpty(t, metadata.Hash)
+
+	resp = runTool(t, NewGitStatusTool(dir), GitStatusParams{})
+	require.Contains(t, resp.Content, "Working tree clean")
+
+	resp = runTool(t, NewGitLogTool(dir), GitLogParams{Stat: true})
+	require.False(t, resp.IsError, resp.Content)
+	require.Contains(t, resp.Content, "    Update a.txt and add b.txt\n\n    Assisted-by: model via Crush <crush@charm.land>\n\n    a.txt (+1 -1)\n    b.txt (+1 -0)")
+	require.Contains(t, resp.Content, "    Add a.txt")
+
+	resp = runTool(t, NewGitBlameTool(dir), GitBlameParams{FilePath: "a.txt", StartLine: 2, EndLine: 10})
+	require.False(t, resp.IsError, resp.Content)
+	require.Contains(t, resp.Content, "Blame of a.txt, lines 2-3")
+	require.Contains(t, resp.Content, metadata.Hash[:7])
+	require.Contains(t, resp.Content, "Test: Update a.txt and add b.txt")
+	require.Contains(t, resp.Content, "2| 2\n")
+
+	resp = runTool(t, NewGitBlameTool(dir), GitBlameParams{FilePath: "a.txt", StartLine: 5})
+	require.True(t, resp.IsError)
+	require.Contains(t, resp.Content, "past the end of the file")
+}
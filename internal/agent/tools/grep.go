@@ -1,8 +1,8 @@
 package tools
 
 import (
-	"bufio"
 	"bytes"
+	"cmp"
 	"context"
 	_ "embed"
 	"encoding/json"
@@ -13,6 +13,7 @@ import (
 	"os/exec"
 	"path/filepath"
 	"regexp"
+	"slices"
 	"sort"
 	"strings"
 	"sync"
@@ -73,18 +74,46 @@ var (
 )
 
 type GrepParams struct {
-	Pattern     string `json:"pattern" description:"The regex pattern to search for in file contents"`
-	Path        string `json:"path,omitempty" description:"The directory to search in. Defaults to the current working directory."`
-	Include     string `json:"include,omitempty" description:"File pattern to include in the search (e.g. \"*.js\", \"*.{ts,tsx}\")"`
-	LiteralText bool   `json:"literal_text,omitempty" description:"If true, the pattern will be treated as literal text with special regex characters escaped. Default is false."`
+	Pattern         string `json:"pattern" description:"The regex pattern to search for in file contents"`
+	Path            string `json:"path,omitempty" description:"The directory to search in. Defaults to the current working directory."`
+	Include         string `json:"include,omitempty" description:"File pattern to include in the search (e.g. \"*.js\", \"*.{ts,tsx}\")"`
+	Type            string `json:"type,omitempty" description:"File type to search (e.g. \"go\", \"py\", \"js\", \"ts\", \"rust\"). More efficient than include for standard file types."`
+	LiteralText     bool   `json:"literal_text,omitempty" description:"If true, the pattern will be treated as literal text with special regex characters escaped. Default is false."`
+	CaseInsensitive bool   `json:"case_insensitive,omitempty" description:"If true, the search is case insensitive. Default is false."`
+	Multiline       bool   `json:"multiline,omitempty" description:"If true, the pattern can span lines and . matches newlines. Default is false."`
+	ContextBefore   int    `json:"context_before,omitempty" description:"Number of lines to show before each match (content mode only)"`
+	ContextAfter    int    `json:"context_after,omitempty" description:"Number of lines to show after each match (content mode only)"`
+	OutputMode      string `json:"output_mode,omitempty" description:"\"content\" (default) shows matching lines, \"files_with_matches\" shows only file paths, \"count\" shows the number of matches per file"`
+	Offset          int    `json:"offset,omitempty" description:"Number of results to skip, for paging. Default is 0."`
+	Limit           int    `json:"limit,omitempty" description:"Maximum number of results to return: matches in content mode, files otherwise. Default is 100."`
 }
 
 type grepMatch struct {
-	path     string
-	modTime  time.Time
-	lineNum  int
-	charNum  int
+	path    string
+	modTime time.Time
+	lineNum int
+	charNum int
+	// lineText is the matching line, or the matching lines joined by
+	// newlines for multiline matches.
 	lineText string
+	// before and after are the context lines around the match.
+	before []string
+	after  []string
+}
+
+// lineCount returns the number of lines the match spans.
+func (m grepMatch) lineCount() int {
+	return strings.Count(m.lineText, "\n") + 1
+}
+
+// grepOptions holds the search options besides the pattern itself.
+type grepOptions struct {
+	include         string
+	fileType        string
+	caseInsensitive bool
+	multiline       bool
+	contextBefore   int
+	contextAfter    int
 }
 
 type GrepResponseMetadata struct {
@@ -95,6 +124,14 @@ type GrepResponseMetadata struct {
 const (
 	GrepToolName        = "grep"
 	maxGrepContentWidth = 500
+	// maxGrepMatches caps the number of matches collected by a search.
+	maxGrepMatches = 5000
+
+	grepOutputContent = "content"
+	grepOutputFiles   = "files_with_matches"
+	grepOutputCount   = "count"
+
+	defaultGrepLimit = 100
 )
 
 //go:embed grep.md
@@ -121,6 +158,17 @@ func NewGrepTool(workingDir string) fantasy.AgentTool {
 				return fantasy.NewTextErrorResponse("pattern is required"), nil
 			}
 
+			outputMode := cmp.Or(params.OutputMode, grepOutputContent)
+			switch outputMode {
+			case grepOutputContent, grepOutputFiles, grepOutputCount:
+			default:
+				return fantasy.NewTextErrorResponse(fmt.Sprintf("invalid output_mode %q: must be content, files_with_matches or count", params.OutputMode)), nil
+			}
+			if params.ContextBefore < 0 || params.ContextAfter < 0 || params.Offset < 0 || params.Limit < 0 {
+				return fantasy.NewTextErrorResponse("context_before, context_after, offset and limit must not be negative"), nil
+			}
+			limit := cmp.Or(params.Limit, defaultGrepLimit)
+
 			// If literal_text is true, escape the pattern
 			searchPattern := params.Pattern
 			if params.LiteralText {
@@ -132,48 +180,35 @@ func NewGrepTool(workingDir string) fantasy.AgentTool {
 				searchPath = workingDir
 			}
 
-			matches, truncated, err := searchFiles(ctx, searchPattern, searchPath, params.Include, 100)
+			opts := grepOptions{
+				include:         params.Include,
+				fileType:        params.Type,
+				caseInsensitive: params.CaseInsensitive,
+				multiline:       params.Multiline,
+			}
+			if outputMode == grepOutputContent {
+				opts.contextBefore = params.ContextBefore
+				opts.contextAfter = params.ContextAfter
+			}
+
+			matches, err := searchAllFiles(ctx, searchPattern, searchPath, opts)
 			if err != nil {
 				return fantasy.NewTextErrorResponse(fmt.Sprintf("error searching files: %v", err)), nil
 			}
 
-			var output strings.Builder
-			if len(matches) == 0 {
-				output.WriteString("No files found")
-			} else {
-				fmt.Fprintf(&output, "Found %d matches\n", len(matches))
-
-				currentFile := ""
-				for _, match := range matches {
-					if currentFile != match.path {
-						if currentFile != "" {
-							output.WriteString("\n")
-						}
-						currentFile = match.path
-						fmt.Fprintf(&output, "%s:\n", filepath.ToSlash(match.path))
-					}
-					if match.lineNum > 0 {
-						lineText := match.lineText
-						if len(lineText) > maxGrepContentWidth {
-							lineText = lineText[:maxGrepContentWidth] + "..."
-						}
-						if match.charNum > 0 {
-							fmt.Fprintf(&output, "  Line %d, Char %d: %s\n", match.lineNum, match.charNum, lineText)
-						} else {
-							fmt.Fprintf(&output, "  Line %d: %s\n", match.lineNum, lineText)
-						}
-					} else {
-						fmt.Fprintf(&output, "  %s\n", match.path)
-					}
-				}
-
-				if truncated {
-					output.WriteString("\n(Results are truncated. Consider using a more specific path or pattern.)")
-				}
+			var output string
+			var truncated bool
+			switch outputMode {
+			case grepOutputFiles:
+				output, truncated = formatGrepFiles(matches, params.Offset, limit, false)
+			case grepOutputCount:
+				output, truncated = formatGrepFiles(matches, params.Offset, limit, true)
+			default:
+				output, truncated = formatGrepContent(matches, params.Offset, limit, opts)
 			}
 
 			return fantasy.WithResponseMetadata(
-				fantasy.NewTextResponse(output.String()),
+				fantasy.NewTextResponse(output),
 				GrepResponseMetadata{
 					NumberOfMatches: len(matches),
 					Truncated:       truncated,
@@ -182,18 +217,151 @@ func NewGrepTool(workingDir string) fantasy.AgentTool {
 		})
 }
 
-func searchFiles(ctx context.Context, pattern, rootPath, include string, limit int) ([]grepMatch, bool, error) {
-	matches, err := searchWithRipgrep(ctx, pattern, rootPath, include)
-	if err != nil {
-		matches, err = searchFilesWithRegex(pattern, rootPath, include)
-		if err != nil {
-			return nil, false, err
+// formatGrepContent lists the matching lines grouped by file, with their
+// context lines if any were requested.
+func formatGrepContent(matches []grepMatch, offset, limit int, opts grepOptions) (string, bool) {
+	if len(matches) == 0 {
+		return "No files found", false
+	}
+
+	page, truncated := pageOf(matches, offset, limit)
+	var output strings.Builder
+	fmt.Fprintf(&output, "Found %d matches\n", len(matches))
+	if len(page) == 0 {
+		fmt.Fprintf(&output, "\n(No matches after offset %d.)", offset)
+		return output.String(), false
+	}
+
+	// With context or multiline matches, lines are shown the way grep shows
+	// them: "12:" for matching lines and "12-" for context lines.
+	blocks := opts.contextBefore > 0 || opts.contextAfter > 0 || opts.multiline
+
+	currentFile := ""
+	lastLine := 0
+	for _, match := range page {
+		if currentFile != match.path {
+			if currentFile != "" {
+				output.WriteString("\n")
+			}
+			currentFile = match.path
+			lastLine = 0
+			fmt.Fprintf(&output, "%s:\n", filepath.ToSlash(match.path))
+		}
+		if match.lineNum <= 0 {
+			fmt.Fprintf(&output, "  %s\n", match.path)
+			continue
+		}
+
+		if !blocks {
+			lineText := truncateGrepLine(strings.TrimSpace(match.lineText))
+			if match.charNum > 0 {
+				fmt.Fprintf(&output, "  Line %d, Char %d: %s\n", match.lineNum, match.charNum, lineText)
+			} else {
+				fmt.Fprintf(&output, "  Line %d: %s\n", match.lineNum, lineText)
+			}
+			continue
+		}
+
+		first := match.lineNum - len(match.before)
+		if lastLine > 0 && first > lastLine+1 {
+			output.WriteString("  --\n")
+		}
+		writeLine := func(num int, sep, text string) {
+			// Lines already shown around the previous match are skipped.
+			if num <= lastLine {
+				return
+			}
+			fmt.Fprintf(&output, "  %d%s %s\n", num, sep, truncateGrepLine(text))
+			lastLine = num
+		}
+		for i, line := range match.before {
+			writeLine(first+i, "-", line)
+		}
+		for i, line := range strings.Split(match.lineText, "\n") {
+			writeLine(match.lineNum+i, ":", line)
+		}
+		after := match.lineNum + match.lineCount()
+		for i, line := range match.after {
+			writeLine(after+i, "-", line)
 		}
 	}
 
-	sort.Slice(matches, func(i, j int) bool {
-		return matches[i].modTime.After(matches[j].modTime)
-	})
+	if truncated {
+		fmt.Fprintf(&output, "\n(Showing matches %d-%d of %d. Use offset=%d to see more, or a more specific path or pattern.)", offset+1, offset+len(page), len(matches), offset+len(page))
+	}
+	return output.String(), truncated
+}
+
+// grepFile is a file with matches and its number of matches.
+type grepFile struct {
+	path    string
+	matches int
+}
+
+// formatGrepFiles lists the files with matches, in the order of the
+// matches, along with their number of matches if counts is set.
+func formatGrepFiles(matches []grepMatch, offset, limit int, counts bool) (string, bool) {
+	var files []grepFile
+	indexes := make(map[string]int)
+	for _, match := range matches {
+		idx, ok := indexes[match.path]
+		if !ok {
+			idx = len(files)
+			indexes[match.path] = idx
+			files = append(files, grepFile{path: match.path})
+		}
+		files[idx].matches++
+	}
+	if len(files) == 0 {
+		return "No files found", false
+	}
+
+	page, truncated := pageOf(files, offset, limit)
+	var output strings.Builder
+	if counts {
+		fmt.Fprintf(&output, "Found %d matches in %d files\n", len(matches), len(files))
+	} else {
+		fmt.Fprintf(&output, "Found %d files\n", len(files))
+	}
+	if len(page) == 0 {
+		fmt.Fprintf(&output, "\n(No files after offset %d.)", offset)
+		return output.String(), false
+	}
+	for _, file := range page {
+		if counts {
+			fmt.Fprintf(&output, "%s: %d\n", filepath.ToSlash(file.path), file.matches)
+		} else {
+			fmt.Fprintf(&output, "%s\n", filepath.ToSlash(file.path))
+		}
+	}
+	if truncated {
+		fmt.Fprintf(&output, "\n(Showing files %d-%d of %d. Use offset=%d to see more.)", offset+1, offset+len(page), len(files), offset+len(page))
+	}
+	return output.String(), truncated
+}
+
+// pageOf returns at most limit items starting at offset, and whether more
+// items follow them.
+func pageOf[T any](items []T, offset, limit int) ([]T, bool) {
+	if offset >= len(items) {
+		return nil, false
+	}
+	end := min(offset+limit, len(items))
+	return items[offset:end], end < len(items)
+}
+
+func truncateGrepLine(line string) string {
+	if len(line) > maxGrepContentWidth {
+		return line[:maxGrepContentWidth] + "..."
+	}
+	return line
+}
+
+func searchFiles(ctx context.Context, pattern, rootPath string, opts grepOptions, limit int) ([]grepMatch, bool, error) {
+	matches, err := searchAllFiles(ctx, pattern, rootPath, opts)
+	if err != nil {
+		return nil, false, err
+	}
 
 	truncated := len(matches) > limit
 	if truncated {
@@ -203,8 +371,26 @@ func searchFiles(ctx context.Context, pattern, rootPath, include string, limit i
 	return matches, truncated, nil
 }
 
-func searchWithRipgrep(ctx context.Context, pattern, path, include string) ([]grepMatch, error) {
-	cmd := getRgSearchCmd(ctx, pattern, path, include)
+// searchAllFiles returns every match of the pattern, most recently modified
+// files first, using ripgrep when available.
+func searchAllFiles(ctx context.Context, pattern, rootPath string, opts grepOptions) ([]grepMatch, error) {
+	matches, err := searchWithRipgrep(ctx, pattern, rootPath, opts)
+	if err != nil {
+		matches, err = searchFilesWithRegex(pattern, rootPath, opts)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Stable, so the matches of a file stay in line order.
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i].modTime.After(matches[j].modTime)
+	})
+	return matches, nil
+}
+
+func searchWithRipgrep(ctx context.Context, pattern, path string, opts grepOptions) ([]grepMatch, error) {
+	cmd := getRgSearchCmd(ctx, pattern, path, opts)
 	if cmd == nil {
 		return nil, fmt.Errorf("ripgrep not found in $PATH")
 	}
@@ -226,6 +412,9 @@ func searchWithRipgrep(ctx context.Context, pattern, path, include string) ([]gr
 	}
 
 	var matches []grepMatch
+	// Lines seen per file, matching or context, to attach context to matches.
+	fileLines := make(map[string]map[int]string)
+	modTimes := make(map[string]time.Time)
 	for line := range bytes.SplitSeq(bytes.TrimSpace(output), []byte{'\n'}) {
 		if len(line) == 0 {
 			continue
@@ -234,25 +423,65 @@ func searchWithRipgrep(ctx context.Context, pattern, path, include string) ([]gr
 		if err := json.Unmarshal(line, &match); err != nil {
 			continue
 		}
-		if match.Type != "match" {
+		if match.Type != "match" && match.Type != "context" {
 			continue
 		}
+
+		path := match.Data.Path.Text
+		lines := strings.Split(strings.TrimSuffix(match.Data.Lines.Text, "\n"), "\n")
+		for i := range lines {
+			lines[i] = strings.TrimSuffix(lines[i], "\r")
+		}
+		if fileLines[path] == nil {
+			fileLines[path] = make(map[int]string)
+		}
+		for i, l := range lines {
+			fileLines[path][match.Data.LineNumber+i] = l
+		}
+		if match.Type != "match" || len(matches) >= maxGrepMatches {
+			continue
+		}
+
 		for _, m := range match.Data.Submatches {
-			fi, err := os.Stat(match.Data.Path.Text)
-			if err != nil {
-				continue // Skip files we can't access
+			modTime, ok := modTimes[path]
+			if !ok {
+				fi, err := os.Stat(path)
+				if err != nil {
+					break // Skip files we can't access
+				}
+				modTime = fi.ModTime()
+				modTimes[path] = modTime
 			}
 			matches = append(matches, grepMatch{
-				path:     match.Data.Path.Text,
-				modTime:  fi.ModTime(),
+				path:     path,
+				modTime:  modTime,
 				lineNum:  match.Data.LineNumber,
 				charNum:  m.Start + 1, // ensure 1-based
-				lineText: strings.TrimSpace(match.Data.Lines.Text),
+				lineText: strings.Join(lines, "\n"),
 			})
 			// only get the first match of each line
 			break
 		}
 	}
+
+	if opts.contextBefore > 0 || opts.contextAfter > 0 {
+		for i, match := range matches {
+			lines := fileLines[match.path]
+			for n := match.lineNum - opts.contextBefore; n < match.lineNum; n++ {
+				if text, ok := lines[n]; ok {
+					matches[i].before = append(matches[i].before, text)
+				}
+			}
+			after := match.lineNum + match.lineCount()
+			for n := after; n < after+opts.contextAfter; n++ {
+				text, ok := lines[n]
+				if !ok {
+					break
+				}
+				matches[i].after = append(matches[i].after, text)
+			}
+		}
+	}
 	return matches, nil
 }
 
@@ -272,9 +501,20 @@ type ripgrepMatch struct {
 	} `json:"data"`
 }
 
-func searchFilesWithRegex(pattern, rootPath, include string) ([]grepMatch, error) {
+func searchFilesWithRegex(pattern, rootPath string, opts grepOptions) ([]grepMatch, error) {
 	matches := []grepMatch{}
 
+	var flags string
+	if opts.caseInsensitive {
+		flags += "i"
+	}
+	if opts.multiline {
+		flags += "s"
+	}
+	if flags != "" {
+		pattern = "(?" + flags + ")" + pattern
+	}
+
 	// Use cached regex compilation
 	regex, err := searchRegexCache.get(pattern)
 	if err != nil {
@@ -282,14 +522,22 @@ func searchFilesWithRegex(pattern, rootPath, include string) ([]grepMatch, error
 	}
 
 	var includePattern *regexp.Regexp
-	if include != "" {
-		regexPattern := globToRegex(include)
+	if opts.include != "" {
+		regexPattern := globToRegex(opts.include)
 		includePattern, err = globRegexCache.get(regexPattern)
 		if err != nil {
 			return nil, fmt.Errorf("invalid include pattern: %w", err)
 		}
 	}
 
+	var typeGlobs []string
+	if opts.fileType != "" {
+		var ok bool
+		if typeGlobs, ok = grepFileTypes[opts.fileType]; !ok {
+			return nil, fmt.Errorf("unrecognized file type: %s", opts.fileType)
+		}
+	}
+
 	// Create walker with gitignore and crushignore support
 	walker := fsext.NewFastGlobWalker(rootPath)
 
@@ -320,24 +568,24 @@ func searchFilesWithRegex(pattern, rootPath, include string) ([]grepMatch, error
 		if includePattern != nil && !includePattern.MatchString(path) {
 			return nil
 		}
+		if typeGlobs != nil && !slices.ContainsFunc(typeGlobs, func(glob string) bool {
+			ok, _ := filepath.Match(glob, base)
+			return ok
+		}) {
+			return nil
+		}
 
-		match, lineNum, charNum, lineText, err := fileContainsPattern(path, regex)
+		fileMatches, err := fileMatchesOf(path, regex, opts)
 		if err != nil {
 			return nil // Skip files we can't read
 		}
 
-		if match {
-			matches = append(matches, grepMatch{
-				path:     path,
-				modTime:  info.ModTime(),
-				lineNum:  lineNum,
-				charNum:  charNum,
-				lineText: lineText,
-			})
-
-			if len(matches) >= 200 {
-				return filepath.SkipAll
-			}
+		for _, match := range fileMatches {
+			match.modTime = info.ModTime()
+			matches = append(matches, match)
+		}
+		if len(matches) >= maxGrepMatches {
+			return filepath.SkipAll
 		}
 
 		return nil
@@ -349,30 +597,87 @@ func searchFilesWithRegex(pattern, rootPath, include string) ([]grepMatch, error
 	return matches, nil
 }
 
-func fileContainsPattern(filePath string, pattern *regexp.Regexp) (bool, int, int, string, error) {
+// fileMatchesOf returns the lines of a text file matching the pattern, with
+// their context lines. Like ripgrep, only the first match of a line counts.
+func fileMatchesOf(filePath string, pattern *regexp.Regexp, opts grepOptions) ([]grepMatch, error) {
 	// Only search text files.
 	if !isTextFile(filePath) {
-		return false, 0, 0, "", nil
+		return nil, nil
 	}
 
-	file, err := os.Open(filePath)
+	content, err := os.ReadFile(filePath)
 	if err != nil {
-		return false, 0, 0, "", err
+		return nil, err
+	}
+	text := strings.ReplaceAll(string(content), "\r\n", "\n")
+	lines := strings.Split(strings.TrimSuffix(text, "\n"), "\n")
+
+	newMatch := func(start, end, charNum int) grepMatch {
+		return grepMatch{
+			path:     filePath,
+			lineNum:  start + 1,
+			charNum:  charNum,
+			lineText: strings.Join(lines[start:end+1], "\n"),
+			before:   lines[max(0, start-opts.contextBefore):start],
+			after:    lines[end+1 : min(len(lines), end+1+opts.contextAfter)],
+		}
 	}
-	defer file.Close()
 
-	scanner := bufio.NewScanner(file)
-	lineNum := 0
-	for scanner.Scan() {
-		lineNum++
-		line := scanner.Text()
-		if loc := pattern.FindStringIndex(line); loc != nil {
-			charNum := loc[0] + 1
-			return true, lineNum, charNum, line, nil
+	var matches []grepMatch
+	if !opts.multiline {
+		for i, line := range lines {
+			if loc := pattern.FindStringIndex(line); loc != nil {
+				matches = append(matches, newMatch(i, i, loc[0]+1))
+			}
 		}
+		return matches, nil
 	}
 
-	return false, 0, 0, "", scanner.Err()
+	lastLine := -1
+	for _, loc := range pattern.FindAllStringIndex(text, -1) {
+		start := strings.Count(text[:loc[0]], "\n")
+		if start <= lastLine || start >= len(lines) {
+			continue
+		}
+		end := start
+		if loc[1] > loc[0] {
+			end = min(strings.Count(text[:loc[1]-1], "\n"), len(lines)-1)
+		}
+		lineStart := strings.LastIndex(text[:loc[0]], "\n") + 1
+		matches = append(matches, newMatch(start, end, loc[0]-lineStart+1))
+		lastLine = end
+	}
+	return matches, nil
+}
+
+// grepFileTypes maps the most common ripgrep file types to their globs, for
+// searches without ripgrep.
+var grepFileTypes = map[string][]string{
+	"c":          {"*.c", "*.h"},
+	"cpp":        {"*.cpp", "*.cc", "*.cxx", "*.hpp", "*.hh", "*.hxx", "*.h"},
+	"cs":         {"*.cs"},
+	"css":        {"*.css", "*.scss", "*.sass", "*.less"},
+	"go":         {"*.go"},
+	"html":       {"*.html", "*.htm"},
+	"java":       {"*.java"},
+	"js":         {"*.js", "*.jsx", "*.mjs", "*.cjs", "*.vue"},
+	"json":       {"*.json"},
+	"kotlin":     {"*.kt", "*.kts"},
+	"lua":        {"*.lua"},
+	"markdown":   {"*.md", "*.markdown", "*.mdx"},
+	"md":         {"*.md", "*.markdown", "*.mdx"},
+	"php":        {"*.php"},
+	"py":         {"*.py", "*.pyi"},
+	"ruby":       {"*.rb", "Gemfile", "Rakefile"},
+	"rust":       {"*.rs"},
+	"sh":         {"*.sh", "*.bash", "*.zsh"},
+	"sql":        {"*.sql"},
+	"swift":      {"*.swift"},
+	"toml":       {"*.toml"},
+	"ts":         {"*.ts", "*.tsx", "*.cts", "*.mts"},
+	"typescript": {"*.ts", "*.tsx", "*.cts", "*.mts"},
+	"yaml":       {"*.yaml", "*.yml"},
+	"zig":        {"*.zig"},
 }
 
 // isTextFile checks if a file is a text file by examining its MIME type.
@@ -85,9 +85,11 @@ func TestGrepWithIgnoreFiles(t *testing.T) {
 
 	// Test both implementations
 	for name, fn := range map[string]func(pattern, path, include string) ([]grepMatch, error){
-		"regex": searchFilesWithRegex,
+		"regex": func(pattern, path, include string) ([]grepMatch, error) {
+			return searchFilesWithRegex(pattern, path, grepOptions{include: include})
+		},
 		"rg": func(pattern, path, include string) ([]grepMatch, error) {
-			return searchWithRipgrep(t.Context(), pattern, path, include)
+			return searchWithRipgrep(t.Context(), pattern, path, grepOptions{include: include})
 		},
 	} {
 		t.Run(name, func(t *testing.T) {
@@ -145,9 +147,11 @@ func TestSearchImplementations(t *testing.T) {
 	require.NoError(t, os.WriteFile(filepath.Join(tempDir, ".crushignore"), []byte("file5.txt\n"), 0o644))
 
 	for name, fn := range map[string]func(pattern, path, include string) ([]grepMatch, error){
-		"regex": searchFilesWithRegex,
+		"regex": func(pattern, path, include string) ([]grepMatch, error) {
+			return searchFilesWithRegex(pattern, path, grepOptions{include: include})
+		},
 		"rg": func(pattern, path, include string) ([]grepMatch, error) {
-			return searchWithRipgrep(t.Context(), pattern, path, include)
+			return searchWithRipgrep(t.Context(), pattern, path, grepOptions{include: include})
 		},
 	} {
 		t.Run(name, func(t *testing.T) {
@@ -396,9 +400,11 @@ func TestColumnMatch(t *testing.T) {
 
 	// Test both implementations
 	for name, fn := range map[string]func(pattern, path, include string) ([]grepMatch, error){
-		"regex": searchFilesWithRegex,
+		"regex": func(pattern, path, include string) ([]grepMatch, error) {
+			return searchFilesWithRegex(pattern, path, grepOptions{include: include})
+		},
 		"rg": func(pattern, path, include string) ([]grepMatch, error) {
-			return searchWithRipgrep(t.Context(), pattern, path, include)
+			return searchWithRipgrep(t.Context(), pattern, path, grepOptions{include: include})
 		},
 	} {
 		t.Run(name, func(t *testing.T) {
@@ -419,3 +425,94 @@ func TestColumnMatch(t *testing.T) {
 		})
 	}
 }
+
+func TestSearchOptions(t *testing.T) {
+	t.Parallel()
+
+	tempDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(tempDir, "main.go"), []byte("package main\n\nfunc main() {\n\tprintln(\"Hello\")\n}\n\nfunc helper() {\n\tprintln(\"hello\")\n}\n"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(tempDir, "notes.txt"), []byte("hello notes\n"), 0o644))
+
+	for name, fn := range map[string]func(pattern string, opts grepOptions) ([]grepMatch, error){
+		"regex": func(pattern string, opts grepOptions) ([]grepMatch, error) {
+			return searchFilesWithRegex(pattern, tempDir, opts)
+		},
+		"rg": func(pattern string, opts grepOptions) ([]grepMatch, error) {
+			return searchWithRipgrep(t.Context(), pattern, tempDir, opts)
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			if name == "rg" && getRg() == "" {
+				t.Skip("rg is not in $PATH")
+			}
+
+			matches, err := fn("hello", grepOptions{})
+			require.NoError(t, err)
+			require.Len(t, matches, 2)
+
+			matches, err = fn("hello", grepOptions{caseInsensitive: true, fileType: "go"})
+			require.NoError(t, err)
+			require.Len(t, matches, 2)
+			require.Equal(t, 4, matches[0].lineNum)
+			require.Equal(t, 8, matches[1].lineNum)
+
+			matches, err = fn("Hello", grepOptions{contextBefore: 1, contextAfter: 2})
+			require.NoError(t, err)
+			require.Len(t, matches, 1)
+			require.Equal(t, []string{"func main() {"}, matches[0].before)
+			require.Equal(t, []string{"}", ""}, matches[0].after)
+
+			matches, err = fn(`func helper\(\) \{.*?\}`, grepOptions{multiline: true})
+			require.NoError(t, err)
+			require.Len(t, matches, 1)
+			require.Equal(t, 7, matches[0].lineNum)
+			require.Equal(t, 3, matches[0].lineCount())
+		})
+	}
+}
+
+func TestFormatGrepResults(t *testing.T) {
+	t.Parallel()
+
+	matches := []grepMatch{
+		{path: "a.go", lineNum: 3, charNum: 1, lineText: "three", before: []string{"one", "two"}, after: []string{"four"}},
+		{path: "a.go", lineNum: 5, charNum: 1, lineText: "five\nsix", before: []string{"four"}},
+		{path: "a.go", lineNum: 10, charNum: 1, lineText: "ten"},
+		{path: "b.go", lineNum: 1, charNum: 2, lineText: "  one"},
+	}
+
+	output, truncated := formatGrepContent(matches, 0, 100, grepOptions{contextBefore: 2, contextAfter: 1})
+	require.False(t, truncated)
+	require.Equal(t, `Found 4 matches
+a.go:
+  1- one
+  2- two
+  3: three
+  4- four
+  5: five
+  6: six
+  --
+  10: ten
+
+b.go:
+  1:   one
+`, output)
+
+	output, truncated = formatGrepContent(matches, 3, 1, grepOptions{})
+	require.False(t, truncated)
+	require.Equal(t, "Found 4 matches\nb.go:\n  Line 1, Char 2: one\n", output)
+
+	output, truncated = formatGrepContent(matches, 0, 2, grepOptions{})
+	require.True(t, truncated)
+	require.Contains(t, output, "(Showing matches 1-2 of 4. Use offset=2 to see more")
+
+	output, truncated = formatGrepFiles(matches, 0, 100, false)
+	require.False(t, truncated)
+	require.Equal(t, "Found 2 files\na.go\nb.go\n", output)
+
+	output, truncated = formatGrepFiles(matches, 0, 1, true)
+	require.True(t, truncated)
+	require.Equal(t, "Found 4 matches in 2 files\na.go: 3\n\n(Showing files 1-1 of 2. Use offset=1 to see more.)", output)
+}
@@ -0,0 +1,87 @@
+package tools
+
+import (
+	"context"
+	_ "embed"
+	"fmt"
+	"strings"
+	"time"
+
+	"charm.land/fantasy"
+	"github.com/charmbracelet/crush/internal/shell"
+)
+
+const (
+	JobListToolName = "job_list"
+)
+
+//go:embed job_list.md
+var jobListDescription []byte
+
+type JobListParams struct{}
+
+type JobListResponseMetadata struct {
+	Jobs []JobInfo `json:"jobs"`
+}
+
+// JobInfo describes a background shell.
+type JobInfo struct {
+	ShellID          string `json:"shell_id"`
+	Command          string `json:"command"`
+	Description      string `json:"description"`
+	WorkingDirectory string `json:"working_directory"`
+	Done             bool   `json:"done"`
+	ExitCode         int    `json:"exit_code,omitempty"`
+	StartTime        int64  `json:"start_time"`
+	EndTime          int64  `json:"end_time,omitempty"`
+}
+
+func NewJobListTool() fantasy.AgentTool {
+	return fantasy.NewParallelAgentTool(
+		JobListToolName,
+		string(jobListDescription),
+		func(ctx context.Context, params JobListParams, call fantasy.ToolCall) (fantasy.ToolResponse, error) {
+			shells := shell.GetBackgroundShellManager().Shells()
+			if len(shells) == 0 {
+				return fantasy.WithResponseMetadata(
+					fantasy.NewTextResponse("No background jobs"),
+					JobListResponseMetadata{Jobs: []JobInfo{}},
+				), nil
+			}
+
+			now := time.Now()
+			metadata := JobListResponseMetadata{Jobs: make([]JobInfo, 0, len(shells))}
+			var b strings.Builder
+			for _, bgShell := range shells {
+				info := JobInfo{
+					ShellID:          bgShell.ID,
+					Command:          bgShell.Command,
+					Description:      bgShell.Description,
+					WorkingDirectory: bgShell.WorkingDir,
+					StartTime:        bgShell.StartedAt.Unix(),
+				}
+
+				status := fmt.Sprintf("running for %s", now.Sub(bgShell.StartedAt).Round(time.Second))
+				if bgShell.IsDone() {
+					completedAt := bgShell.CompletedAt()
+					info.Done = true
+					info.ExitCode = shell.ExitCode(bgShell.ExitErr())
+					info.EndTime = completedAt.Unix()
+					status = fmt.Sprintf("exited with code %d, %s ago", info.ExitCode, now.Sub(completedAt).Round(time.Second))
+				}
+				metadata.Jobs = append(metadata.Jobs, info)
+
+				fmt.Fprintf(&b, "%s: %s\n", bgShell.ID, status)
+				if bgShell.Description != "" {
+					fmt.Fprintf(&b, "  Description: %s\n", bgShell.Description)
+				}
+				fmt.Fprintf(&b, "  Command: %s\n", bgShell.Command)
+				fmt.Fprintf(&b, "  Working directory: %s\n", bgShell.WorkingDir)
+			}
+
+			return fantasy.WithResponseMetadata(
+				fantasy.NewTextResponse(strings.TrimSuffix(b.String(), "\n")),
+				metadata,
+			), nil
+		})
+}
@@ -19,6 +19,7 @@ var jobOutputDescription []byte
 
 type JobOutputParams struct {
 	ShellID string `json:"shell_id" description:"The ID of the background shell to retrieve output from"`
+	Cursor  int    `json:"cursor,omitempty" description:"Only return the output written after this cursor, as returned by a previous call (default 0, the whole output)"`
 }
 
 type JobOutputResponseMetadata struct {
@@ -27,6 +28,7 @@ type JobOutputResponseMetadata struct {
 	Description      string `json:"description"`
 	Done             bool   `json:"done"`
 	WorkingDirectory string `json:"working_directory"`
+	Cursor           int    `json:"cursor"`
 }
 
 func NewJobOutputTool() fantasy.AgentTool {
@@ -44,42 +46,48 @@ func NewJobOutputTool() fantasy.AgentTool {
 				return fantasy.NewTextErrorResponse(fmt.Sprintf("background shell not found: %s", params.ShellID)), nil
 			}
 
-			stdout, stderr, done, err := bgShell.GetOutput()
-
-			var outputParts []string
-			if stdout != "" {
-				outputParts = append(outputParts, stdout)
+			if params.Cursor < 0 {
+				return fantasy.NewTextErrorResponse("cursor must not be negative"), nil
 			}
-			if stderr != "" {
-				outputParts = append(outputParts, stderr)
+
+			// Check whether the shell is done before reading the output, so
+			// that a completed status comes with the whole output.
+			done := bgShell.IsDone()
+			output, start, cursor := bgShell.ReadOutput(params.Cursor)
+			output = truncateOutput(strings.TrimSuffix(output, "\n"))
+			if dropped := start - params.Cursor; dropped > 0 {
+				output = fmt.Sprintf("[%d bytes of earlier output were dropped]\n%s", dropped, output)
 			}
 
 			status := "running"
 			if done {
 				status = "completed"
-				if err != nil {
-					exitCode := shell.ExitCode(err)
-					if exitCode != 0 {
-						outputParts = append(outputParts, fmt.Sprintf("Exit code %d", exitCode))
+				if exitCode := shell.ExitCode(bgShell.ExitErr()); exitCode != 0 {
+					if output != "" {
+						output += "\n"
 					}
+					output += fmt.Sprintf("Exit code %d", exitCode)
 				}
 			}
 
-			output := strings.Join(outputParts, "\n")
-
 			metadata := JobOutputResponseMetadata{
 				ShellID:          params.ShellID,
 				Command:          bgShell.Command,
 				Description:      bgShell.Description,
 				Done:             done,
 				WorkingDirectory: bgShell.WorkingDir,
+				Cursor:           cursor,
 			}
 
 			if output == "" {
-				output = BashNoOutput
+				if params.Cursor > 0 {
+					output = "No new output"
+				} else {
+					output = BashNoOutput
+				}
 			}
 
-			result := fmt.Sprintf("Status: %s\n\n%s", status, output)
+			result := fmt.Sprintf("Status: %s\nCursor: %d\n\n%s", status, cursor, output)
 			return fantasy.WithResponseMetadata(fantasy.NewTextResponse(result), metadata), nil
 		})
 }
@@ -2,6 +2,7 @@ package tools
 
 import (
 	"context"
+	"encoding/json"
 	"testing"
 	"time"
 
@@ -327,3 +328,99 @@ func TestBackgroundShell_AutoBackground(t *testing.T) {
 		require.Equal(t, bgShell.ID, retrieved.ID)
 	})
 }
+
+func TestJobOutputTool_Cursor(t *testing.T) {
+	t.Parallel()
+
+	bgManager := shell.GetBackgroundShellManager()
+	bgShell, err := bgManager.Start(context.Background(), t.TempDir(), nil, "echo 'first' && echo 'second' >&2", "")
+	require.NoError(t, err)
+	defer bgManager.Kill(bgShell.ID)
+	bgShell.Wait()
+
+	tool := NewJobOutputTool()
+	resp := runTool(t, tool, JobOutputParams{ShellID: bgShell.ID})
+	require.False(t, resp.IsError, resp.Content)
+	require.Equal(t, "Status: completed\nCursor: 13\n\nfirst\nsecond", resp.Content)
+
+	var meta JobOutputResponseMetadata
+	require.NoError(t, json.Unmarshal([]byte(resp.Metadata), &meta))
+	require.Equal(t, 13, meta.Cursor)
+
+	resp = runTool(t, tool, JobOutputParams{ShellID: bgShell.ID, Cursor: 6})
+	require.Equal(t, "Status: completed\nCursor: 13\n\nsecond", resp.Content)
+
+	resp = runTool(t, tool, JobOutputParams{ShellID: bgShell.ID, Cursor: meta.Cursor})
+	require.Equal(t, "Status: completed\nCursor: 13\n\nNo new output", resp.Content)
+}
+
+func TestJobWaitTool(t *testing.T) {
+	t.Parallel()
+
+	bgManager := shell.GetBackgroundShellManager()
+	bgShell, err := bgManager.Start(context.Background(), t.TempDir(), nil, "echo 'compiling' && sleep 0.2 && echo 'listening on :3000' && sleep 0.2 && exit 3", "dev server")
+	require.NoError(t, err)
+	defer bgManager.Kill(bgShell.ID)
+
+	tool := NewJobWaitTool()
+	resp := runTool(t, tool, JobWaitParams{ShellID: bgShell.ID, Pattern: `listening on \S+`, Timeout: 5})
+	require.False(t, resp.IsError, resp.Content)
+	require.Equal(t, "Status: matched \"listening on :3000\"\nCursor: 28\n\ncompiling\nlistening on :3000", resp.Content)
+
+	var meta JobWaitResponseMetadata
+	require.NoError(t, json.Unmarshal([]byte(resp.Metadata), &meta))
+	require.True(t, meta.Matched)
+	require.False(t, meta.Done)
+
+	resp = runTool(t, tool, JobWaitParams{ShellID: bgShell.ID, Cursor: meta.Cursor, Timeout: 5})
+	require.False(t, resp.IsError, resp.Content)
+	require.Equal(t, "Status: completed with exit code 3\nCursor: 29\n\nno output", resp.Content)
+
+	resp = runTool(t, tool, JobWaitParams{ShellID: bgShell.ID, Pattern: "("})
+	require.True(t, resp.IsError)
+	require.Contains(t, resp.Content, "invalid pattern")
+}
+
+func TestJobWaitTool_Timeout(t *testing.T) {
+	t.Parallel()
+
+	bgManager := shell.GetBackgroundShellManager()
+	bgShell, err := bgManager.Start(context.Background(), t.TempDir(), nil, "echo 'waiting' && sleep 10", "")
+	require.NoError(t, err)
+	defer bgManager.Kill(bgShell.ID)
+
+	resp := runTool(t, NewJobWaitTool(), JobWaitParams{ShellID: bgShell.ID, Pattern: "never", Timeout: 1})
+	require.False(t, resp.IsError, resp.Content)
+	require.Contains(t, resp.Content, "Status: timed out after 1s, still running")
+
+	var meta JobWaitResponseMetadata
+	require.NoError(t, json.Unmarshal([]byte(resp.Metadata), &meta))
+	require.True(t, meta.TimedOut)
+	require.Equal(t, 8, meta.Cursor)
+}
+
+func TestJobListTool(t *testing.T) {
+	t.Parallel()
+
+	bgManager := shell.GetBackgroundShellManager()
+	bgShell, err := bgManager.Start(context.Background(), t.TempDir(), nil, "sleep 10", "long sleep")
+	require.NoError(t, err)
+	defer bgManager.Kill(bgShell.ID)
+
+	resp := runTool(t, NewJobListTool(), JobListParams{})
+	require.False(t, resp.IsError, resp.Content)
+	require.Contains(t, resp.Content, bgShell.ID+": running for")
+	require.Contains(t, resp.Content, "  Description: long sleep\n  Command: sleep 10")
+
+	var meta JobListResponseMetadata
+	require.NoError(t, json.Unmarshal([]byte(resp.Metadata), &meta))
+	var found bool
+	for _, job := range meta.Jobs {
+		if job.ShellID == bgShell.ID {
+			found = true
+			require.False(t, job.Done)
+			require.Equal(t, "sleep 10", job.Command)
+		}
+	}
+	require.True(t, found)
+}
@@ -0,0 +1,118 @@
+package tools
+
+import (
+	"context"
+	_ "embed"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"charm.land/fantasy"
+	"github.com/charmbracelet/crush/internal/shell"
+)
+
+const (
+	JobWaitToolName = "job_wait"
+
+	defaultJobWaitTimeout = 30 * time.Second
+	maxJobWaitTimeout     = 10 * time.Minute
+)
+
+//go:embed job_wait.md
+var jobWaitDescription []byte
+
+type JobWaitParams struct {
+	ShellID string `json:"shell_id" description:"The ID of the background shell to wait for"`
+	Pattern string `json:"pattern,omitempty" description:"Regular expression to wait for in the output. When empty, wait for the shell to exit"`
+	Cursor  int    `json:"cursor,omitempty" description:"Only look for the pattern in the output written after this cursor, as returned by job_output or a previous call (default 0)"`
+	Timeout int    `json:"timeout,omitempty" description:"Maximum number of seconds to wait (default 30, max 600)"`
+}
+
+type JobWaitResponseMetadata struct {
+	ShellID     string `json:"shell_id"`
+	Command     string `json:"command"`
+	Description string `json:"description"`
+	Pattern     string `json:"pattern,omitempty"`
+	Matched     bool   `json:"matched"`
+	Done        bool   `json:"done"`
+	TimedOut    bool   `json:"timed_out"`
+	Cursor      int    `json:"cursor"`
+}
+
+func NewJobWaitTool() fantasy.AgentTool {
+	return fantasy.NewAgentTool(
+		JobWaitToolName,
+		string(jobWaitDescription),
+		func(ctx context.Context, params JobWaitParams, call fantasy.ToolCall) (fantasy.ToolResponse, error) {
+			if params.ShellID == "" {
+				return fantasy.NewTextErrorResponse("missing shell_id"), nil
+			}
+			if params.Cursor < 0 || params.Timeout < 0 {
+				return fantasy.NewTextErrorResponse("cursor and timeout must not be negative"), nil
+			}
+
+			var re *regexp.Regexp
+			if params.Pattern != "" {
+				var err error
+				if re, err = regexp.Compile(params.Pattern); err != nil {
+					return fantasy.NewTextErrorResponse(fmt.Sprintf("invalid pattern: %s", err)), nil
+				}
+			}
+
+			bgShell, ok := shell.GetBackgroundShellManager().Get(params.ShellID)
+			if !ok {
+				return fantasy.NewTextErrorResponse(fmt.Sprintf("background shell not found: %s", params.ShellID)), nil
+			}
+
+			timeout := defaultJobWaitTimeout
+			if params.Timeout > 0 {
+				timeout = min(time.Duration(params.Timeout)*time.Second, maxJobWaitTimeout)
+			}
+			waitCtx, cancel := context.WithTimeout(ctx, timeout)
+			defer cancel()
+
+			match, cursor, matched, err := bgShell.WaitForOutput(waitCtx, re, params.Cursor)
+			timedOut := errors.Is(err, context.DeadlineExceeded) && ctx.Err() == nil
+			if err != nil && !timedOut {
+				return fantasy.ToolResponse{}, err
+			}
+
+			metadata := JobWaitResponseMetadata{
+				ShellID:     params.ShellID,
+				Command:     bgShell.Command,
+				Description: bgShell.Description,
+				Pattern:     params.Pattern,
+				Matched:     matched,
+				Done:        bgShell.IsDone(),
+				TimedOut:    timedOut,
+				Cursor:      cursor,
+			}
+
+			var status string
+			switch {
+			case matched:
+				status = fmt.Sprintf("matched %q", match)
+			case metadata.Done:
+				status = "completed"
+				if exitCode := shell.ExitCode(bgShell.ExitErr()); exitCode != 0 {
+					status = fmt.Sprintf("completed with exit code %d", exitCode)
+				}
+				if re != nil {
+					status += ", without matching the pattern"
+				}
+			default:
+				status = fmt.Sprintf("timed out after %s, still running", timeout)
+			}
+
+			// Show the output read while waiting, up to the end of the match.
+			output, start, _ := bgShell.ReadOutput(params.Cursor)
+			output = strings.TrimSuffix(output[:min(max(cursor-start, 0), len(output))], "\n")
+			if output == "" {
+				output = BashNoOutput
+			}
+			result := fmt.Sprintf("Status: %s\nCursor: %d\n\n%s", status, cursor, truncateOutput(output))
+			return fantasy.WithResponseMetadata(fantasy.NewTextResponse(result), metadata), nil
+		})
+}
@@ -55,7 +55,7 @@ const (
 //go:embed ls.md
 var lsDescription []byte
 
-func NewLsTool(permissions permission.Service, workingDir string, lsConfig config.ToolLs) fantasy.AgentTool {
+func NewLsTool(permissions permission.Service, workingDir string, additionalDirs []string, lsConfig config.ToolLs) fantasy.AgentTool {
 	return fantasy.NewAgentTool(
 		LSToolName,
 		string(lsDescription),
@@ -67,19 +67,14 @@ func NewLsTool(permissions permission.Service, workingDir string, lsConfig confi
 
 			searchPath = filepathext.SmartJoin(workingDir, searchPath)
 
-			// Check if directory is outside working directory and request permission if needed
-			absWorkingDir, err := filepath.Abs(workingDir)
-			if err != nil {
-				return fantasy.NewTextErrorResponse(fmt.Sprintf("error resolving working directory: %v", err)), nil
-			}
-
+			// Check if directory is outside the working and additional
+			// directories and request permission if needed
 			absSearchPath, err := filepath.Abs(searchPath)
 			if err != nil {
 				return fantasy.NewTextErrorResponse(fmt.Sprintf("error resolving search path: %v", err)), nil
 			}
 
-			relPath, err := filepath.Rel(absWorkingDir, absSearchPath)
-			if err != nil || strings.HasPrefix(relPath, "..") {
+			if !config.IsInRoots(absSearchPath, append([]string{workingDir}, additionalDirs...)...) {
 				// Directory is outside working directory, request permission
 				sessionID := GetSessionFromContext(ctx)
 				if sessionID == "" {
@@ -0,0 +1,86 @@
+package tools
+
+import (
+	"context"
+	_ "embed"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+
+	"charm.land/fantasy"
+	"github.com/charmbracelet/crush/internal/csync"
+	"github.com/charmbracelet/crush/internal/lsp"
+)
+
+type HoverParams struct {
+	Symbol string `json:"symbol" description:"The symbol name to get documentation and type information for"`
+	Path   string `json:"path,omitempty" description:"The directory or file to search the symbol in. Defaults to the current working directory."`
+}
+
+const HoverToolName = "lsp_hover"
+
+// maxHoverResults is the maximum number of distinct hover results returned
+// for a symbol.
+const maxHoverResults = 5
+
+//go:embed lsp_hover.md
+var hoverDescription []byte
+
+func NewHoverTool(lspClients *csync.Map[string, *lsp.Client]) fantasy.AgentTool {
+	return fantasy.NewAgentTool(
+		HoverToolName,
+		string(hoverDescription),
+		func(ctx context.Context, params HoverParams, call fantasy.ToolCall) (fantasy.ToolResponse, error) {
+			if params.Symbol == "" {
+				return fantasy.NewTextErrorResponse("symbol is required"), nil
+			}
+
+			if lspClients.Len() == 0 {
+				return fantasy.NewTextErrorResponse("no LSP clients available"), nil
+			}
+
+			positions, err := findSymbolPositions(ctx, lspClients, params.Symbol, params.Path)
+			if err != nil {
+				return fantasy.NewTextErrorResponse(err.Error()), nil
+			}
+			if len(positions) == 0 {
+				return fantasy.NewTextResponse(fmt.Sprintf("Symbol '%s' not found", params.Symbol)), nil
+			}
+
+			// The same symbol is usually matched many times, only keep
+			// distinct results.
+			var hovers []string
+			var output strings.Builder
+			var allErrs error
+			for _, pos := range positions {
+				if len(hovers) == maxHoverResults {
+					break
+				}
+				hover, err := pos.client.Hover(ctx, pos.path, pos.line, pos.char)
+				if err != nil {
+					if isNoIdentifierError(err) {
+						continue
+					}
+					slog.Error("Failed to get hover", "error", err, "symbol", params.Symbol, "path", pos.path, "line", pos.line, "char", pos.char)
+					allErrs = errors.Join(allErrs, err)
+					continue
+				}
+				hover = strings.TrimSpace(hover)
+				if hover == "" || slices.Contains(hovers, hover) {
+					continue
+				}
+				hovers = append(hovers, hover)
+				fmt.Fprintf(&output, "%s:%d:%d\n%s\n\n", pos.path, pos.line, pos.char, hover)
+			}
+
+			if len(hovers) > 0 {
+				return fantasy.NewTextResponse(strings.TrimSpace(output.String())), nil
+			}
+			if allErrs != nil {
+				return fantasy.NewTextErrorResponse(allErrs.Error()), nil
+			}
+			return fantasy.NewTextResponse(fmt.Sprintf("No hover information found for symbol '%s'", params.Symbol)), nil
+		})
+}
@@ -0,0 +1,171 @@
+package tools
+
+import (
+	"context"
+	_ "embed"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"slices"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"charm.land/fantasy"
+	"github.com/charmbracelet/crush/internal/csync"
+	"github.com/charmbracelet/crush/internal/filepathext"
+	"github.com/charmbracelet/crush/internal/history"
+	"github.com/charmbracelet/crush/internal/lsp"
+	"github.com/charmbracelet/crush/internal/lsp/util"
+	"github.com/charmbracelet/crush/internal/permission"
+	"github.com/charmbracelet/x/powernap/pkg/lsp/protocol"
+)
+
+type RenameParams struct {
+	FilePath string `json:"file_path" description:"The path to a file containing the symbol"`
+	Symbol   string `json:"symbol" description:"The current name of the symbol to rename (e.g., function name, type name, method name)"`
+	NewName  string `json:"new_name" description:"The new name of the symbol"`
+	Line     int    `json:"line,omitempty" description:"The 1-based line the symbol is on. Required when the name matches several symbols in the file"`
+}
+
+const RenameToolName = "lsp_rename"
+
+//go:embed lsp_rename.md
+var renameDescription []byte
+
+func NewRenameTool(lspClients *csync.Map[string, *lsp.Client], permissions permission.Service, files history.Service, workingDir string, additionalDirs []string) fantasy.AgentTool {
+	return fantasy.NewAgentTool(
+		RenameToolName,
+		string(renameDescription),
+		func(ctx context.Context, params RenameParams, call fantasy.ToolCall) (fantasy.ToolResponse, error) {
+			if params.FilePath == "" {
+				return fantasy.NewTextErrorResponse("file_path is required"), nil
+			}
+			if params.Symbol == "" {
+				return fantasy.NewTextErrorResponse("symbol is required"), nil
+			}
+			if params.NewName == "" {
+				return fantasy.NewTextErrorResponse("new_name is required"), nil
+			}
+
+			filePath := filepathext.SmartJoin(workingDir, params.FilePath)
+			client := clientForFile(lspClients, filePath)
+			if client == nil {
+				return fantasy.NewTextErrorResponse(fmt.Sprintf("no LSP client handles %s", params.FilePath)), nil
+			}
+
+			content, err := os.ReadFile(filePath)
+			if err != nil {
+				return fantasy.NewTextErrorResponse(fmt.Sprintf("failed to read file: %s", err)), nil
+			}
+			lines := strings.Split(string(content), "\n")
+			if params.Line > len(lines) {
+				return fantasy.NewTextErrorResponse(fmt.Sprintf("line %d is past the end of the file (%d lines)", params.Line, len(lines))), nil
+			}
+
+			positions := symbolPositionsInFile(lines, filePath, params.Symbol, params.Line)
+			targets, err := renameTargets(positions, func(pos symbolPosition) ([]protocol.Location, error) {
+				return client.FindReferences(ctx, filePath, pos.line, pos.char, true)
+			})
+			switch {
+			case len(targets) == 1:
+				editCtx := workspaceEditContext{ctx, lspClients, permissions, files, workingDir, additionalDirs}
+				description := fmt.Sprintf("Rename %s to %s", params.Symbol, params.NewName)
+				return applyWorkspaceEdit(editCtx, RenameToolName, description, renameEdit(targets[0].locations, params.NewName), call)
+			case len(targets) > 1:
+				var output strings.Builder
+				fmt.Fprintf(&output, "Symbol '%s' matches %d different symbols in %s. Set line to the one to rename:\n", params.Symbol, len(targets), params.FilePath)
+				for _, target := range targets {
+					fmt.Fprintf(&output, "  Line %d: %s\n", target.pos.line, strings.TrimSpace(lines[target.pos.line-1]))
+				}
+				return fantasy.NewTextErrorResponse(strings.TrimSpace(output.String())), nil
+			case err != nil:
+				slog.Error("Failed to find references", "error", err, "symbol", params.Symbol, "path", filePath)
+				return fantasy.NewTextErrorResponse(fmt.Sprintf("failed to rename symbol: %s", err)), nil
+			}
+			return fantasy.NewTextErrorResponse(fmt.Sprintf("Symbol '%s' not found in %s", params.Symbol, params.FilePath)), nil
+		})
+}
+
+// renameTarget is one of the symbols matching the name in the file, with its
+// first occurrence in the file and every reference to it.
+type renameTarget struct {
+	pos       symbolPosition
+	locations []protocol.Location
+}
+
+// renameTargets finds the references of the occurrences of the symbol, and
+// returns the distinct symbols they belong to. Occurrences already among
+// the references of a symbol are not looked up again.
+func renameTargets(positions []symbolPosition, references func(symbolPosition) ([]protocol.Location, error)) ([]renameTarget, error) {
+	var targets []renameTarget
+	var allErrs error
+	for _, pos := range positions {
+		if slices.ContainsFunc(targets, func(target renameTarget) bool {
+			return slices.ContainsFunc(target.locations, pos.at)
+		}) {
+			continue
+		}
+		locations, err := references(pos)
+		if err != nil {
+			if !isNoIdentifierError(err) {
+				allErrs = errors.Join(allErrs, err)
+			}
+			continue
+		}
+		if len(locations) > 0 {
+			targets = append(targets, renameTarget{pos: pos, locations: locations})
+		}
+	}
+	return targets, allErrs
+}
+
+// renameEdit returns the workspace edit replacing every reference of a
+// symbol, including its declaration, with the new name. powernap doesn't
+// expose textDocument/rename, so the edit is built from the references.
+func renameEdit(locations []protocol.Location, newName string) protocol.WorkspaceEdit {
+	changes := make(map[protocol.DocumentURI][]protocol.TextEdit)
+	for _, loc := range cleanupLocations(locations) {
+		changes[loc.URI] = append(changes[loc.URI], protocol.TextEdit{Range: loc.Range, NewText: newName})
+	}
+	return protocol.WorkspaceEdit{Changes: changes}
+}
+
+// symbolPositionsInFile returns the 1-based positions of the symbol in the
+// lines of the file, only looking at the given line if it's set. Characters
+// are counted in UTF-16 code units, like LSP positions.
+func symbolPositionsInFile(lines []string, filePath, symbol string, line int) []symbolPosition {
+	var positions []symbolPosition
+	for i, text := range lines {
+		if line > 0 && i+1 != line {
+			continue
+		}
+		offset := 0
+		for {
+			idx := strings.Index(text[offset:], symbol)
+			if idx == -1 {
+				break
+			}
+			start, end := offset+idx, offset+idx+len(symbol)
+			offset = end
+			// Skip matches inside longer identifiers, such as Foo in FooBar.
+			if r, _ := utf8.DecodeLastRuneInString(text[:start]); start > 0 && isIdentifierRune(r) {
+				continue
+			}
+			if r, _ := utf8.DecodeRuneInString(text[end:]); end < len(text) && isIdentifierRune(r) {
+				continue
+			}
+			positions = append(positions, symbolPosition{
+				path: filePath,
+				line: i + 1,
+				char: util.UTF16Column(text, start+getSymbolOffset(symbol)) + 1,
+			})
+		}
+	}
+	return positions
+}
+
+func isIdentifierRune(r rune) bool {
+	return r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
@@ -0,0 +1,99 @@
+package tools
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/x/powernap/pkg/lsp/protocol"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRenameEdit(t *testing.T) {
+	t.Parallel()
+
+	at := func(uri protocol.DocumentURI, line, start, end uint32) protocol.Location {
+		return protocol.Location{URI: uri, Range: protocol.Range{
+			Start: protocol.Position{Line: line, Character: start},
+			End:   protocol.Position{Line: line, Character: end},
+		}}
+	}
+	a, b := protocol.DocumentURI("file:///tmp/a.go"), protocol.DocumentURI("file:///tmp/b.go")
+
+	// Locations reported twice are only renamed once.
+	edit := renameEdit([]protocol.Location{at(b, 4, 1, 4), at(a, 2, 5, 8), at(a, 0, 5, 8), at(a, 2, 5, 8)}, "Bar")
+	require.Equal(t, map[protocol.DocumentURI][]protocol.TextEdit{
+		a: {
+			{Range: at(a, 0, 5, 8).Range, NewText: "Bar"},
+			{Range: at(a, 2, 5, 8).Range, NewText: "Bar"},
+		},
+		b: {{Range: at(b, 4, 1, 4).Range, NewText: "Bar"}},
+	}, edit.Changes)
+}
+
+func TestSymbolPositionsInFile(t *testing.T) {
+	t.Parallel()
+
+	lines := strings.Split("func Foo() {}\nvar x = pkg.Foo() + Foo() + FooBar() + myFoo\n// é🙂 Foo\n", "\n")
+
+	positions := symbolPositionsInFile(lines, "a.go", "Foo", 0)
+	require.Len(t, positions, 4)
+
+	positions = symbolPositionsInFile(lines, "a.go", "pkg.Foo", 2)
+	require.Len(t, positions, 1)
+	require.Equal(t, 2, positions[0].line)
+	require.Equal(t, 13, positions[0].char)
+
+	// Characters are counted in UTF-16 code units.
+	positions = symbolPositionsInFile(lines, "a.go", "Foo", 3)
+	require.Len(t, positions, 1)
+	require.Equal(t, 8, positions[0].char)
+}
+
+func TestRenameTargets(t *testing.T) {
+	t.Parallel()
+
+	path := "/tmp/a.go"
+	uri := protocol.URIFromPath(path)
+	at := func(line, char uint32) protocol.Location {
+		return protocol.Location{URI: uri, Range: protocol.Range{
+			Start: protocol.Position{Line: line, Character: char},
+			End:   protocol.Position{Line: line, Character: char + 3},
+		}}
+	}
+	// Foo is a function on lines 1 and 3, and a local variable on line 5.
+	function := []protocol.Location{at(0, 5), at(2, 1)}
+	local := []protocol.Location{at(4, 1)}
+	positions := []symbolPosition{
+		{path: path, line: 1, char: 6},
+		{path: path, line: 2, char: 4},
+		{path: path, line: 3, char: 2},
+		{path: path, line: 5, char: 2},
+	}
+
+	var looked []int
+	references := func(pos symbolPosition) ([]protocol.Location, error) {
+		looked = append(looked, pos.line)
+		switch pos.line {
+		case 1, 3:
+			return function, nil
+		case 5:
+			return local, nil
+		}
+		return nil, errors.New("no identifier found")
+	}
+
+	targets, err := renameTargets(positions, references)
+	require.NoError(t, err)
+	require.Len(t, targets, 2)
+	require.Equal(t, 1, targets[0].pos.line)
+	require.Equal(t, function, targets[0].locations)
+	require.Equal(t, 5, targets[1].pos.line)
+	// Line 3 is a reference of the function found from line 1.
+	require.Equal(t, []int{1, 2, 5}, looked)
+
+	targets, err = renameTargets(positions[3:], references)
+	require.NoError(t, err)
+	require.Len(t, targets, 1)
+	require.Equal(t, local, targets[0].locations)
+}
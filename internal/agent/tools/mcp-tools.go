@@ -25,6 +25,13 @@ func GetMCPTools(permissions permission.Service, wd string) []*Tool {
 	return result
 }
 
+// MCPResponseMetadata holds the content blocks and structured content
+// returned by an MCP tool.
+type MCPResponseMetadata struct {
+	Blocks     []mcp.ContentBlock `json:"blocks,omitempty"`
+	Structured any                `json:"structured,omitempty"`
+}
+
 // Tool is a tool from a MCP.
 type Tool struct {
 	mcpName         string
@@ -107,11 +114,22 @@ func (m *Tool) Run(ctx context.Context, params fantasy.ToolCall) (fantasy.ToolRe
 		return fantasy.ToolResponse{}, permission.ErrorPermissionDenied
 	}
 
-	result, err := mcp.RunTool(ctx, m.mcpName, m.tool.Name, params.Input)
+	result, err := mcp.RunTool(ctx, m.mcpName, m.tool.Name, params.ID, params.Input)
 	if err != nil {
+		if ctx.Err() != nil {
+			return fantasy.ToolResponse{}, err
+		}
 		return fantasy.NewTextErrorResponse(err.Error()), nil
 	}
 
+	metadata := MCPResponseMetadata{
+		Blocks:     result.Blocks,
+		Structured: result.Structured,
+	}
+	if result.IsError {
+		return fantasy.WithResponseMetadata(fantasy.NewTextErrorResponse(result.Content), metadata), nil
+	}
+
 	switch result.Type {
 	case "image", "media":
 		if !GetSupportsImagesFromContext(ctx) {
@@ -126,8 +144,8 @@ func (m *Tool) Run(ctx context.Context, params fantasy.ToolCall) (fantasy.ToolRe
 			response = fantasy.NewMediaResponse(result.Data, result.MediaType)
 		}
 		response.Content = result.Content
-		return response, nil
+		return fantasy.WithResponseMetadata(response, metadata), nil
 	default:
-		return fantasy.NewTextResponse(result.Content), nil
+		return fantasy.WithResponseMetadata(fantasy.NewTextResponse(result.Content), metadata), nil
 	}
 }
@@ -130,6 +130,8 @@ func Close() error {
 	case <-time.After(5 * time.Second):
 	}
 	broker.Shutdown()
+	progressBroker.Shutdown()
+	closeLogs()
 	return nil
 }
 
@@ -256,6 +258,9 @@ func updateState(name string, state State, err error, client *mcp.ClientSession,
 		info.ConnectedAt = time.Now()
 	case StateError:
 		sessions.Del(name)
+		if err != nil {
+			addLog(name, "error", "crush", err.Error())
+		}
 	}
 	states.Set(name, info)
 
@@ -283,6 +288,12 @@ func createSession(ctx context.Context, name string, m config.MCPConfig, resolve
 		return nil, err
 	}
 
+	// Capture the stderr of stdio servers so it shows up in the MCP logs.
+	stderr := newStderrWriter(name)
+	if ct, ok := transport.(*mcp.CommandTransport); ok {
+		ct.Command.Stderr = stderr
+	}
+
 	client := mcp.NewClient(
 		&mcp.Implementation{
 			Name:    "crush",
@@ -303,7 +314,10 @@ func createSession(ctx context.Context, name string, m config.MCPConfig, resolve
 				})
 			},
 			LoggingMessageHandler: func(_ context.Context, req *mcp.LoggingMessageRequest) {
-				slog.Info("MCP log", "name", name, "data", req.Params.Data)
+				handleLoggingMessage(name, req.Params)
+			},
+			ProgressNotificationHandler: func(_ context.Context, req *mcp.ProgressNotificationClientRequest) {
+				handleProgress(name, req.Params)
 			},
 		},
 	)
@@ -311,6 +325,7 @@ func createSession(ctx context.Context, name string, m config.MCPConfig, resolve
 	session, err := client.Connect(mcpCtx, transport, nil)
 	if err != nil {
 		err = maybeStdioErr(err, transport)
+		_ = stderr.Close()
 		updateState(name, StateError, maybeTimeoutErr(err, timeout), nil, Counts{})
 		slog.Error("MCP client failed to initialize", "error", err, "name", name)
 		cancel()
@@ -320,6 +335,18 @@ func createSession(ctx context.Context, name string, m config.MCPConfig, resolve
 
 	cancelTimer.Stop()
 	slog.Info("MCP client initialized", "name", name)
+	// The session ends once the server exited and its stderr was read.
+	go func() {
+		_ = session.Wait()
+		_ = stderr.Close()
+	}()
+	addLog(name, "info", "crush", "connected")
+
+	if session.InitializeResult().Capabilities.Logging != nil {
+		if err := session.SetLoggingLevel(ctx, &mcp.SetLoggingLevelParams{Level: "info"}); err != nil {
+			slog.Debug("Failed to set MCP logging level", "name", name, "error", err)
+		}
+	}
 	return session, nil
 }
 
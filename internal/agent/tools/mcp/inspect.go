@@ -0,0 +1,78 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/charmbracelet/crush/internal/config"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// ServerInfo describes what an MCP server offers right after connecting to
+// it.
+type ServerInfo struct {
+	Name         string              `json:"name"`
+	Server       *mcp.Implementation `json:"server,omitempty"`
+	Instructions string              `json:"instructions,omitempty"`
+	Tools        []*Tool             `json:"tools"`
+	Prompts      []*Prompt           `json:"prompts"`
+}
+
+// Inspect connects to the given MCP server, lists its tools and prompts, and
+// disconnects. The server does not need to be initialized through
+// [Initialize].
+func Inspect(ctx context.Context, name string, m config.MCPConfig, resolver config.VariableResolver) (ServerInfo, error) {
+	session, err := createSession(ctx, name, m, resolver)
+	if err != nil {
+		return ServerInfo{}, err
+	}
+	defer session.Close()
+
+	tools, err := getTools(ctx, session)
+	if err != nil {
+		return ServerInfo{}, fmt.Errorf("error listing tools: %w", err)
+	}
+	prompts, err := getPrompts(ctx, session)
+	if err != nil {
+		return ServerInfo{}, fmt.Errorf("error listing prompts: %w", err)
+	}
+
+	init := session.InitializeResult()
+	return ServerInfo{
+		Name:         name,
+		Server:       init.ServerInfo,
+		Instructions: init.Instructions,
+		Tools:        filterDisabledTools(name, tools),
+		Prompts:      prompts,
+	}, nil
+}
+
+// Call connects to the given MCP server, runs a single tool with the given
+// JSON input, and disconnects. The server does not need to be initialized
+// through [Initialize].
+func Call(ctx context.Context, name string, m config.MCPConfig, resolver config.VariableResolver, toolName, input string) (ToolResult, error) {
+	var args map[string]any
+	if err := json.Unmarshal([]byte(input), &args); err != nil {
+		return ToolResult{}, fmt.Errorf("error parsing parameters: %s", err)
+	}
+
+	session, err := createSession(ctx, name, m, resolver)
+	if err != nil {
+		return ToolResult{}, err
+	}
+	defer session.Close()
+
+	tools, err := getTools(ctx, session)
+	if err != nil {
+		return ToolResult{}, fmt.Errorf("error listing tools: %w", err)
+	}
+	tool := &Tool{Name: toolName}
+	for _, t := range tools {
+		if t.Name == toolName {
+			tool = t
+			break
+		}
+	}
+	return callTool(ctx, session, tool, "", args)
+}
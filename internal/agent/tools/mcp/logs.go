@@ -0,0 +1,268 @@
+package mcp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/charmbracelet/crush/internal/config"
+	"github.com/charmbracelet/crush/internal/csync"
+	"github.com/charmbracelet/crush/internal/pubsub"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"gopkg.in/natefinch/lumberjack.v2"
+)
+
+// maxLogEntries is the number of log entries kept in memory per MCP server.
+const maxLogEntries = 500
+
+// LogLevelStderr is the level used for lines a stdio MCP server writes to its
+// standard error.
+const LogLevelStderr = "stderr"
+
+// LogEntry is a single log line received from an MCP server, either through
+// an MCP logging notification or from the server's stderr.
+type LogEntry struct {
+	Name    string    `json:"name"`
+	Time    time.Time `json:"time"`
+	Level   string    `json:"level"`
+	Logger  string    `json:"logger,omitempty"`
+	Message string    `json:"message"`
+}
+
+// IsError returns true if the entry has an error level or above.
+func (e LogEntry) IsError() bool {
+	switch mcp.LoggingLevel(e.Level) {
+	case "error", "critical", "alert", "emergency":
+		return true
+	}
+	return false
+}
+
+// IsWarning returns true if the entry has a warning level.
+func (e LogEntry) IsWarning() bool {
+	return e.Level == "warning"
+}
+
+// slogLevel maps the MCP logging level to the matching slog level name.
+func (e LogEntry) slogLevel() string {
+	switch {
+	case e.IsError():
+		return "ERROR"
+	case e.IsWarning():
+		return "WARN"
+	case e.Level == "debug":
+		return "DEBUG"
+	default:
+		return "INFO"
+	}
+}
+
+var (
+	logBuffers = csync.NewMap[string, *logBuffer]()
+	logBroker  = pubsub.NewBroker[LogEntry]()
+	logsClosed atomic.Bool
+)
+
+// SubscribeLogs returns a channel for MCP server log entries.
+func SubscribeLogs(ctx context.Context) <-chan pubsub.Event[LogEntry] {
+	return logBroker.Subscribe(ctx)
+}
+
+// Logs returns the buffered log entries of the given MCP server, oldest
+// first.
+func Logs(name string) []LogEntry {
+	buf, ok := logBuffers.Get(name)
+	if !ok {
+		return nil
+	}
+	return buf.entries()
+}
+
+// LogFile returns the path of the file the logs of the given MCP server are
+// written to.
+func LogFile(dataDir, name string) string {
+	return filepath.Join(dataDir, "logs", "mcp", name+".log")
+}
+
+// logBuffer is a fixed size ring buffer of log entries that also mirrors
+// every entry to a log file.
+type logBuffer struct {
+	mu    sync.Mutex
+	items []LogEntry
+	start int
+	file  io.WriteCloser
+}
+
+func newLogBuffer(name string) *logBuffer {
+	buf := &logBuffer{
+		items: make([]LogEntry, 0, maxLogEntries),
+	}
+	if cfg := config.Get(); cfg != nil && cfg.Options != nil && cfg.Options.DataDirectory != "" {
+		buf.file = &lumberjack.Logger{
+			Filename:   LogFile(cfg.Options.DataDirectory, name),
+			MaxSize:    5, // Max size in MB
+			MaxBackups: 0,
+			MaxAge:     30,
+			Compress:   false,
+		}
+	}
+	return buf
+}
+
+func (b *logBuffer) append(entry LogEntry) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if len(b.items) < maxLogEntries {
+		b.items = append(b.items, entry)
+	} else {
+		b.items[b.start] = entry
+		b.start = (b.start + 1) % maxLogEntries
+	}
+
+	if b.file == nil {
+		return
+	}
+	// Use the same keys as the slog JSON handler so the file can be read
+	// with the same tooling as crush.log.
+	fields := map[string]string{
+		"time":      entry.Time.Format(time.RFC3339Nano),
+		"level":     entry.slogLevel(),
+		"msg":       entry.Message,
+		"mcp":       entry.Name,
+		"mcp_level": entry.Level,
+	}
+	if entry.Logger != "" {
+		fields["logger"] = entry.Logger
+	}
+	line, err := json.Marshal(fields)
+	if err != nil {
+		return
+	}
+	if _, err := b.file.Write(append(line, '\n')); err != nil {
+		slog.Debug("Failed to write MCP log", "name", entry.Name, "error", err)
+	}
+}
+
+func (b *logBuffer) entries() []LogEntry {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	result := make([]LogEntry, 0, len(b.items))
+	result = append(result, b.items[b.start:]...)
+	return append(result, b.items[:b.start]...)
+}
+
+func (b *logBuffer) close() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.file == nil {
+		return nil
+	}
+	// Writing to a closed lumberjack logger reopens the file, so stop
+	// writing to it.
+	file := b.file
+	b.file = nil
+	return file.Close()
+}
+
+// addLog records a log entry for the given MCP server and publishes it. It
+// does nothing once the logs are closed.
+func addLog(name, level, logger, message string) {
+	if logsClosed.Load() {
+		return
+	}
+	entry := LogEntry{
+		Name:    name,
+		Time:    time.Now(),
+		Level:   level,
+		Logger:  logger,
+		Message: message,
+	}
+	logBuffers.GetOrSet(name, func() *logBuffer {
+		return newLogBuffer(name)
+	}).append(entry)
+	logBroker.Publish(pubsub.CreatedEvent, entry)
+}
+
+// handleLoggingMessage records an MCP logging notification.
+func handleLoggingMessage(name string, params *mcp.LoggingMessageParams) {
+	var message string
+	switch data := params.Data.(type) {
+	case string:
+		message = data
+	default:
+		bts, err := json.Marshal(data)
+		if err != nil {
+			message = fmt.Sprintf("%v", data)
+		} else {
+			message = string(bts)
+		}
+	}
+	slog.Debug("MCP log", "name", name, "level", params.Level, "data", message)
+	addLog(name, string(params.Level), params.Logger, message)
+}
+
+// stderrWriter records every line written to it as a log entry of the given
+// MCP server.
+type stderrWriter struct {
+	name string
+	mu   sync.Mutex
+	buf  []byte
+}
+
+func newStderrWriter(name string) *stderrWriter {
+	return &stderrWriter{name: name}
+}
+
+// Write implements [io.Writer].
+func (w *stderrWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.buf = append(w.buf, p...)
+	for {
+		idx := bytes.IndexByte(w.buf, '\n')
+		if idx < 0 {
+			break
+		}
+		w.addLine(w.buf[:idx])
+		w.buf = w.buf[idx+1:]
+	}
+	return len(p), nil
+}
+
+// Close records the last line written when it doesn't end with a newline.
+// It's called once the server exited.
+func (w *stderrWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.addLine(w.buf)
+	w.buf = nil
+	return nil
+}
+
+func (w *stderrWriter) addLine(line []byte) {
+	line = bytes.TrimRight(line, "\r")
+	if len(bytes.TrimSpace(line)) > 0 {
+		addLog(w.name, LogLevelStderr, "", string(line))
+	}
+}
+
+func closeLogs() {
+	logsClosed.Store(true)
+	for name, buf := range logBuffers.Seq2() {
+		if err := buf.close(); err != nil {
+			slog.Debug("Failed to close MCP log file", "name", name, "error", err)
+		}
+	}
+	logBroker.Shutdown()
+}
@@ -0,0 +1,63 @@
+package mcp
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"gopkg.in/natefinch/lumberjack.v2"
+)
+
+func TestLogBufferWrapsAround(t *testing.T) {
+	t.Parallel()
+
+	buf := &logBuffer{}
+	for i := range maxLogEntries + 10 {
+		buf.append(LogEntry{Message: fmt.Sprintf("line %d", i)})
+	}
+
+	entries := buf.entries()
+	require.Len(t, entries, maxLogEntries)
+	require.Equal(t, "line 10", entries[0].Message)
+	require.Equal(t, fmt.Sprintf("line %d", maxLogEntries+9), entries[len(entries)-1].Message)
+}
+
+func TestStderrWriterSplitsLines(t *testing.T) {
+	t.Parallel()
+
+	const name = "test-stderr-writer"
+	logBuffers.Del(name)
+	w := newStderrWriter(name)
+	_, err := w.Write([]byte("first line\nsecond "))
+	require.NoError(t, err)
+	_, err = w.Write([]byte("line\r\n\nlast"))
+	require.NoError(t, err)
+
+	entries := Logs(name)
+	require.Len(t, entries, 2)
+	require.Equal(t, "first line", entries[0].Message)
+	require.Equal(t, "second line", entries[1].Message)
+	require.Equal(t, LogLevelStderr, entries[1].Level)
+
+	// The last line is kept until the server exits.
+	require.NoError(t, w.Close())
+	entries = Logs(name)
+	require.Len(t, entries, 3)
+	require.Equal(t, "last", entries[2].Message)
+}
+
+func TestLogBufferClose(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "server.log")
+	buf := &logBuffer{file: &lumberjack.Logger{Filename: path}}
+	buf.append(LogEntry{Message: "first"})
+	require.FileExists(t, path)
+
+	require.NoError(t, buf.close())
+	require.NoError(t, os.Remove(path))
+	buf.append(LogEntry{Message: "second"})
+	require.NoFileExists(t, path)
+}
@@ -0,0 +1,54 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/charmbracelet/crush/internal/pubsub"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// ToolProgress is a progress notification sent by an MCP server while one of
+// its tools is running.
+type ToolProgress struct {
+	// ToolCallID is the ID of the tool call the progress is for, which is
+	// also the progress token sent to the server.
+	ToolCallID string
+	Name       string
+	Progress   float64
+	// Total is zero if the server does not know the total.
+	Total   float64
+	Message string
+}
+
+// Percent returns the progress as a percentage, or -1 if the total is not
+// known.
+func (p ToolProgress) Percent() float64 {
+	if p.Total <= 0 {
+		return -1
+	}
+	return min(100, max(0, p.Progress/p.Total*100))
+}
+
+var progressBroker = pubsub.NewBroker[ToolProgress]()
+
+// SubscribeProgress returns a channel for the progress of running MCP tool
+// calls.
+func SubscribeProgress(ctx context.Context) <-chan pubsub.Event[ToolProgress] {
+	return progressBroker.Subscribe(ctx)
+}
+
+// handleProgress publishes a progress notification received from the given
+// MCP server.
+func handleProgress(name string, params *mcp.ProgressNotificationParams) {
+	if params == nil || params.ProgressToken == nil {
+		return
+	}
+	progressBroker.Publish(pubsub.UpdatedEvent, ToolProgress{
+		ToolCallID: fmt.Sprint(params.ProgressToken),
+		Name:       name,
+		Progress:   params.Progress,
+		Total:      params.Total,
+		Message:    params.Message,
+	})
+}
@@ -0,0 +1,107 @@
+package mcp
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/require"
+)
+
+func connectTestServer(t *testing.T, name string, server *mcp.Server) *mcp.ClientSession {
+	t.Helper()
+
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+	serverSession, err := server.Connect(t.Context(), serverTransport, nil)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = serverSession.Close() })
+
+	client := mcp.NewClient(&mcp.Implementation{Name: "crush-test"}, &mcp.ClientOptions{
+		ProgressNotificationHandler: func(_ context.Context, req *mcp.ProgressNotificationClientRequest) {
+			handleProgress(name, req.Params)
+		},
+	})
+	session, err := client.Connect(t.Context(), clientTransport, nil)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = session.Close() })
+	return session
+}
+
+func TestCallToolProgress(t *testing.T) {
+	t.Parallel()
+
+	server := mcp.NewServer(&mcp.Implementation{Name: "slow"}, nil)
+	server.AddTool(&mcp.Tool{Name: "index", InputSchema: map[string]any{"type": "object"}}, func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		err := req.Session.NotifyProgress(ctx, &mcp.ProgressNotificationParams{
+			ProgressToken: req.Params.GetProgressToken(),
+			Progress:      3,
+			Total:         4,
+			Message:       "indexing",
+		})
+		if err != nil {
+			return nil, err
+		}
+		return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: "done"}}}, nil
+	})
+
+	events := SubscribeProgress(t.Context())
+	session := connectTestServer(t, "slow", server)
+
+	result, err := callTool(t.Context(), session, &Tool{Name: "index"}, "call-progress", nil)
+	require.NoError(t, err)
+	require.Equal(t, "done", result.Content)
+
+	for {
+		select {
+		case event := <-events:
+			if event.Payload.ToolCallID != "call-progress" {
+				continue
+			}
+			require.Equal(t, "slow", event.Payload.Name)
+			require.Equal(t, "indexing", event.Payload.Message)
+			require.InDelta(t, 75, event.Payload.Percent(), 0.001)
+			return
+		case <-time.After(5 * time.Second):
+			t.Fatal("no progress notification received")
+		}
+	}
+}
+
+func TestCallToolCancel(t *testing.T) {
+	t.Parallel()
+
+	started := make(chan struct{})
+	cancelled := make(chan struct{})
+	server := mcp.NewServer(&mcp.Implementation{Name: "slow"}, nil)
+	server.AddTool(&mcp.Tool{Name: "wait", InputSchema: map[string]any{"type": "object"}}, func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		close(started)
+		<-ctx.Done()
+		close(cancelled)
+		return nil, ctx.Err()
+	})
+
+	session := connectTestServer(t, "slow", server)
+
+	ctx, cancel := context.WithCancel(t.Context())
+	go func() {
+		<-started
+		cancel()
+	}()
+	_, err := callTool(ctx, session, &Tool{Name: "wait"}, "call-cancel", nil)
+	require.ErrorIs(t, err, context.Canceled)
+
+	select {
+	case <-cancelled:
+	case <-time.After(5 * time.Second):
+		t.Fatal("server was not notified of the cancellation")
+	}
+}
+
+func TestToolProgressPercent(t *testing.T) {
+	t.Parallel()
+
+	require.Equal(t, float64(-1), ToolProgress{Progress: 3}.Percent())
+	require.Equal(t, float64(50), ToolProgress{Progress: 1, Total: 2}.Percent())
+	require.Equal(t, float64(100), ToolProgress{Progress: 3, Total: 2}.Percent())
+}
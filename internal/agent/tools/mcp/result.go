@@ -0,0 +1,196 @@
+package mcp
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// Content block types of a [ToolResult].
+const (
+	BlockText         = "text"
+	BlockImage        = "image"
+	BlockAudio        = "audio"
+	BlockResourceLink = "resource_link"
+	BlockResource     = "resource"
+)
+
+// ContentBlock describes a single content block returned by an MCP tool.
+// Binary data is not kept, only its size.
+type ContentBlock struct {
+	Type        string `json:"type"`
+	Text        string `json:"text,omitempty"`
+	URI         string `json:"uri,omitempty"`
+	Name        string `json:"name,omitempty"`
+	Title       string `json:"title,omitempty"`
+	Description string `json:"description,omitempty"`
+	MIMEType    string `json:"mime_type,omitempty"`
+	Size        int64  `json:"size,omitempty"`
+}
+
+// String returns the text sent to the model for the block.
+func (b ContentBlock) String() string {
+	switch b.Type {
+	case BlockText:
+		return b.Text
+	case BlockImage, BlockAudio:
+		return fmt.Sprintf("[%s: %s, %d bytes]", b.Type, b.MIMEType, b.Size)
+	case BlockResourceLink:
+		var sb strings.Builder
+		sb.WriteString("[resource link: ")
+		if b.Title != "" {
+			sb.WriteString(b.Title + " ")
+		} else if b.Name != "" {
+			sb.WriteString(b.Name + " ")
+		}
+		sb.WriteString(b.URI)
+		if b.MIMEType != "" {
+			sb.WriteString(" (" + b.MIMEType + ")")
+		}
+		sb.WriteString("]")
+		if b.Description != "" {
+			sb.WriteString("\n" + b.Description)
+		}
+		return sb.String()
+	case BlockResource:
+		if b.Text != "" {
+			return fmt.Sprintf("[resource: %s]\n%s", b.URI, b.Text)
+		}
+		return fmt.Sprintf("[resource: %s, %s, %d bytes]", b.URI, b.MIMEType, b.Size)
+	default:
+		return b.Text
+	}
+}
+
+// convertResult converts the result of an MCP tool call, validating its
+// structured content against the output schema of the tool, if any.
+func convertResult(tool *Tool, result *mcp.CallToolResult) ToolResult {
+	out := ToolResult{
+		Type:       "text",
+		Structured: result.StructuredContent,
+		IsError:    result.IsError,
+	}
+
+	var parts []string
+	hasText := false
+	for _, v := range result.Content {
+		var block ContentBlock
+		switch content := v.(type) {
+		case *mcp.TextContent:
+			block = ContentBlock{Type: BlockText, Text: content.Text}
+			hasText = true
+		case *mcp.ImageContent:
+			block = ContentBlock{Type: BlockImage, MIMEType: content.MIMEType, Size: int64(len(content.Data))}
+			// Only one media block can be sent to the model, the first image
+			// wins over audio.
+			if out.Type != "image" {
+				out.Type = "image"
+				out.Data = encodeData(content.Data)
+				out.MediaType = content.MIMEType
+			}
+		case *mcp.AudioContent:
+			block = ContentBlock{Type: BlockAudio, MIMEType: content.MIMEType, Size: int64(len(content.Data))}
+			if out.Type == "text" {
+				out.Type = "media"
+				out.Data = encodeData(content.Data)
+				out.MediaType = content.MIMEType
+			}
+		case *mcp.ResourceLink:
+			block = ContentBlock{
+				Type:        BlockResourceLink,
+				URI:         content.URI,
+				Name:        content.Name,
+				Title:       content.Title,
+				Description: content.Description,
+				MIMEType:    content.MIMEType,
+			}
+			if content.Size != nil {
+				block.Size = *content.Size
+			}
+		case *mcp.EmbeddedResource:
+			if content.Resource == nil {
+				continue
+			}
+			block = ContentBlock{
+				Type:     BlockResource,
+				URI:      content.Resource.URI,
+				MIMEType: content.Resource.MIMEType,
+				Text:     content.Resource.Text,
+				Size:     int64(len(content.Resource.Blob)),
+			}
+		default:
+			bts, err := json.Marshal(v)
+			if err != nil {
+				continue
+			}
+			block = ContentBlock{Type: BlockText, Text: string(bts)}
+		}
+		out.Blocks = append(out.Blocks, block)
+		parts = append(parts, block.String())
+	}
+
+	// Servers should send a text version of the structured content, but if
+	// they don't, the model still needs to see it.
+	if !hasText && result.StructuredContent != nil {
+		if bts, err := json.Marshal(result.StructuredContent); err == nil {
+			parts = append(parts, string(bts))
+		}
+	}
+
+	if !out.IsError {
+		if err := validateStructured(tool, result.StructuredContent); err != nil {
+			out.IsError = true
+			parts = append(parts, err.Error())
+		}
+	}
+
+	out.Content = strings.Join(parts, "\n")
+	return out
+}
+
+// validateStructured validates the structured content of a tool result
+// against the output schema of the tool.
+func validateStructured(tool *Tool, structured any) error {
+	if tool == nil || tool.OutputSchema == nil {
+		return nil
+	}
+	if structured == nil {
+		return fmt.Errorf("tool %s has an output schema but returned no structured content", tool.Name)
+	}
+
+	bts, err := json.Marshal(tool.OutputSchema)
+	if err != nil {
+		return fmt.Errorf("invalid output schema for tool %s: %w", tool.Name, err)
+	}
+	var schema jsonschema.Schema
+	if err := json.Unmarshal(bts, &schema); err != nil {
+		return fmt.Errorf("invalid output schema for tool %s: %w", tool.Name, err)
+	}
+	resolved, err := schema.Resolve(nil)
+	if err != nil {
+		return fmt.Errorf("invalid output schema for tool %s: %w", tool.Name, err)
+	}
+
+	// Round trip the content so it only contains plain JSON values.
+	if bts, err = json.Marshal(structured); err != nil {
+		return fmt.Errorf("invalid structured content: %w", err)
+	}
+	var instance any
+	if err := json.Unmarshal(bts, &instance); err != nil {
+		return fmt.Errorf("invalid structured content: %w", err)
+	}
+	if err := resolved.Validate(instance); err != nil {
+		return fmt.Errorf("structured content does not match the output schema of tool %s: %w", tool.Name, err)
+	}
+	return nil
+}
+
+// encodeData base64 encodes media data, as the SDK decodes it from the wire
+// while the providers expect it encoded.
+func encodeData(data []byte) []byte {
+	return []byte(base64.StdEncoding.EncodeToString(data))
+}
@@ -0,0 +1,89 @@
+package mcp
+
+import (
+	"encoding/base64"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConvertResult(t *testing.T) {
+	t.Parallel()
+
+	t.Run("keeps every block", func(t *testing.T) {
+		t.Parallel()
+
+		size := int64(42)
+		result := convertResult(&Tool{Name: "fetch"}, &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{Text: "hello"},
+				&mcp.ImageContent{Data: []byte("png"), MIMEType: "image/png"},
+				&mcp.ImageContent{Data: []byte("jpeg"), MIMEType: "image/jpeg"},
+				&mcp.ResourceLink{URI: "file:///a.txt", Name: "a.txt", MIMEType: "text/plain", Size: &size},
+				&mcp.EmbeddedResource{Resource: &mcp.ResourceContents{URI: "file:///b.txt", Text: "bee"}},
+			},
+		})
+
+		require.False(t, result.IsError)
+		require.Equal(t, "image", result.Type)
+		require.Equal(t, "image/png", result.MediaType)
+		require.Equal(t, base64.StdEncoding.EncodeToString([]byte("png")), string(result.Data))
+		require.Len(t, result.Blocks, 5)
+		require.Equal(t, BlockResourceLink, result.Blocks[3].Type)
+		require.Equal(t, int64(42), result.Blocks[3].Size)
+		require.Equal(t, "hello\n"+
+			"[image: image/png, 3 bytes]\n"+
+			"[image: image/jpeg, 4 bytes]\n"+
+			"[resource link: a.txt file:///a.txt (text/plain)]\n"+
+			"[resource: file:///b.txt]\nbee", result.Content)
+	})
+
+	t.Run("flags tool errors", func(t *testing.T) {
+		t.Parallel()
+
+		result := convertResult(&Tool{Name: "fail"}, &mcp.CallToolResult{
+			Content: []mcp.Content{&mcp.TextContent{Text: "boom"}},
+			IsError: true,
+		})
+		require.True(t, result.IsError)
+		require.Equal(t, "boom", result.Content)
+	})
+
+	t.Run("structured content without text", func(t *testing.T) {
+		t.Parallel()
+
+		result := convertResult(&Tool{Name: "weather"}, &mcp.CallToolResult{
+			StructuredContent: map[string]any{"temp": 21},
+		})
+		require.False(t, result.IsError)
+		require.Equal(t, `{"temp":21}`, result.Content)
+		require.Equal(t, map[string]any{"temp": 21}, result.Structured)
+	})
+}
+
+func TestValidateStructured(t *testing.T) {
+	t.Parallel()
+
+	tool := &Tool{
+		Name: "weather",
+		OutputSchema: map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"temp": map[string]any{"type": "number"},
+			},
+			"required": []any{"temp"},
+		},
+	}
+
+	require.NoError(t, validateStructured(&Tool{Name: "plain"}, nil))
+	require.NoError(t, validateStructured(tool, map[string]any{"temp": 21.5}))
+	require.ErrorContains(t, validateStructured(tool, nil), "no structured content")
+	require.ErrorContains(t, validateStructured(tool, map[string]any{"temp": "warm"}), "does not match")
+
+	result := convertResult(tool, &mcp.CallToolResult{
+		Content:           []mcp.Content{&mcp.TextContent{Text: `{"temp":"warm"}`}},
+		StructuredContent: map[string]any{"temp": "warm"},
+	})
+	require.True(t, result.IsError)
+}
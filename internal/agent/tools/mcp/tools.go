@@ -7,7 +7,6 @@ import (
 	"iter"
 	"log/slog"
 	"slices"
-	"strings"
 
 	"github.com/charmbracelet/crush/internal/config"
 	"github.com/charmbracelet/crush/internal/csync"
@@ -18,10 +17,20 @@ type Tool = mcp.Tool
 
 // ToolResult represents the result of running an MCP tool.
 type ToolResult struct {
-	Type      string
-	Content   string
-	Data      []byte
-	MediaType string
+	// Type is "image" or "media" when Data holds the first image or audio
+	// block, and "text" otherwise.
+	Type string `json:"type"`
+	// Content is the text sent to the model, describing every block.
+	Content   string `json:"content"`
+	Data      []byte `json:"data,omitempty"`
+	MediaType string `json:"media_type,omitempty"`
+	// Blocks describes every content block returned by the tool, in order.
+	Blocks []ContentBlock `json:"blocks,omitempty"`
+	// Structured is the structured content returned by the tool, if any.
+	Structured any `json:"structured,omitempty"`
+	// IsError is true if the tool reported an error, or if its structured
+	// content does not match its output schema.
+	IsError bool `json:"is_error,omitempty"`
 }
 
 var allTools = csync.NewMap[string, []*Tool]()
@@ -31,8 +40,10 @@ func Tools() iter.Seq2[string, []*Tool] {
 	return allTools.Seq2()
 }
 
-// RunTool runs an MCP tool with the given input parameters.
-func RunTool(ctx context.Context, name, toolName string, input string) (ToolResult, error) {
+// RunTool runs an MCP tool with the given input parameters. The tool call ID
+// is used as the progress token, so progress notifications can be matched to
+// the call. Cancelling the context sends a cancellation to the server.
+func RunTool(ctx context.Context, name, toolName, toolCallID, input string) (ToolResult, error) {
 	var args map[string]any
 	if err := json.Unmarshal([]byte(input), &args); err != nil {
 		return ToolResult{}, fmt.Errorf("error parsing parameters: %s", err)
@@ -42,68 +53,43 @@ func RunTool(ctx context.Context, name, toolName string, input string) (ToolResu
 	if err != nil {
 		return ToolResult{}, err
 	}
-	result, err := c.CallTool(ctx, &mcp.CallToolParams{
-		Name:      toolName,
-		Arguments: args,
-	})
-	if err != nil {
-		return ToolResult{}, err
-	}
-
-	if len(result.Content) == 0 {
-		return ToolResult{Type: "text", Content: ""}, nil
-	}
+	return callTool(ctx, c, findTool(name, toolName), toolCallID, args)
+}
 
-	var textParts []string
-	var imageData []byte
-	var imageMimeType string
-	var audioData []byte
-	var audioMimeType string
-
-	for _, v := range result.Content {
-		switch content := v.(type) {
-		case *mcp.TextContent:
-			textParts = append(textParts, content.Text)
-		case *mcp.ImageContent:
-			if imageData == nil {
-				imageData = content.Data
-				imageMimeType = content.MIMEType
-			}
-		case *mcp.AudioContent:
-			if audioData == nil {
-				audioData = content.Data
-				audioMimeType = content.MIMEType
-			}
-		default:
-			textParts = append(textParts, fmt.Sprintf("%v", v))
+// findTool returns the given tool of the MCP server, or a tool without
+// schemas if it is not known.
+func findTool(name, toolName string) *Tool {
+	tools, _ := allTools.Get(name)
+	for _, tool := range tools {
+		if tool.Name == toolName {
+			return tool
 		}
 	}
+	return &Tool{Name: toolName}
+}
 
-	textContent := strings.Join(textParts, "\n")
-
-	// MCP SDK returns Data as already base64-encoded, so we use it directly.
-	if imageData != nil {
-		return ToolResult{
-			Type:      "image",
-			Content:   textContent,
-			Data:      imageData,
-			MediaType: imageMimeType,
-		}, nil
+// callTool calls the given tool on the session and converts its result. If
+// progressToken is not empty, the server is asked to report progress with
+// it.
+func callTool(ctx context.Context, c *mcp.ClientSession, tool *Tool, progressToken string, args map[string]any) (ToolResult, error) {
+	params := &mcp.CallToolParams{
+		Name:      tool.Name,
+		Arguments: args,
 	}
-
-	if audioData != nil {
-		return ToolResult{
-			Type:      "media",
-			Content:   textContent,
-			Data:      audioData,
-			MediaType: audioMimeType,
-		}, nil
+	if progressToken != "" {
+		// SetProgressToken only works on an existing meta map.
+		params.Meta = mcp.Meta{}
+		params.SetProgressToken(progressToken)
 	}
-
-	return ToolResult{
-		Type:    "text",
-		Content: textContent,
-	}, nil
+	result, err := c.CallTool(ctx, params)
+	if err != nil {
+		// The SDK already notified the server of the cancellation.
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ToolResult{}, ctxErr
+		}
+		return ToolResult{}, err
+	}
+	return convertResult(tool, result), nil
 }
 
 // RefreshTools gets the updated list of tools from the MCP and updates the
@@ -3,6 +3,7 @@ package tools
 import (
 	"context"
 	_ "embed"
+	"encoding/json"
 	"fmt"
 	"log/slog"
 	"os"
@@ -11,6 +12,7 @@ import (
 	"time"
 
 	"charm.land/fantasy"
+	"github.com/charmbracelet/crush/internal/config"
 	"github.com/charmbracelet/crush/internal/csync"
 	"github.com/charmbracelet/crush/internal/diff"
 	"github.com/charmbracelet/crush/internal/filepathext"
@@ -58,7 +60,7 @@ const MultiEditToolName = "multiedit"
 //go:embed multiedit.md
 var multieditDescription []byte
 
-func NewMultiEditTool(lspClients *csync.Map[string, *lsp.Client], permissions permission.Service, files history.Service, workingDir string) fantasy.AgentTool {
+func NewMultiEditTool(lspClients *csync.Map[string, *lsp.Client], permissions permission.Service, files history.Service, workingDir string, additionalDirs []string, formatCfg config.ToolFormat) fantasy.AgentTool {
 	return fantasy.NewAgentTool(
 		MultiEditToolName,
 		string(multieditDescription),
@@ -81,7 +83,7 @@ func NewMultiEditTool(lspClients *csync.Map[string, *lsp.Client], permissions pe
 			var response fantasy.ToolResponse
 			var err error
 
-			editCtx := editContext{ctx, permissions, files, workingDir}
+			editCtx := editContext{ctx, permissions, files, workingDir, additionalDirs}
 			// Handle file creation case (first edit has empty old_string)
 			if len(params.Edits) > 0 && params.Edits[0].OldString == "" {
 				response, err = processMultiEditWithCreation(editCtx, params, call)
@@ -97,17 +99,35 @@ func NewMultiEditTool(lspClients *csync.Map[string, *lsp.Client], permissions pe
 				return response, nil
 			}
 
+			formatted, formatNote := formatAfterWrite(ctx, files, formatCfg, workingDir, params.FilePath)
+			if formatted != "" {
+				response = withFormattedMultiEditContent(response, formatted, strings.TrimPrefix(params.FilePath, workingDir))
+			}
+
 			// Notify LSP clients about the change
 			notifyLSPs(ctx, lspClients, params.FilePath)
 
 			// Wait for LSP diagnostics and add them to the response
 			text := fmt.Sprintf("<result>\n%s\n</result>\n", response.Content)
-			text += getDiagnostics(params.FilePath, lspClients)
+			text += formatNote
+			text += getDiagnostics(ctx, params.FilePath, lspClients)
 			response.Content = text
 			return response, nil
 		})
 }
 
+// withFormattedMultiEditContent updates the metadata of a multiedit response
+// to the content of the file after it was formatted.
+func withFormattedMultiEditContent(response fantasy.ToolResponse, formatted, displayPath string) fantasy.ToolResponse {
+	var meta MultiEditResponseMetadata
+	if err := json.Unmarshal([]byte(response.Metadata), &meta); err != nil {
+		return response
+	}
+	meta.NewContent = formatted
+	_, meta.Additions, meta.Removals = diff.GenerateDiff(meta.OldContent, formatted, displayPath)
+	return fantasy.WithResponseMetadata(response, meta)
+}
+
 func validateEdits(edits []MultiEditOperation) error {
 	for i, edit := range edits {
 		// Only the first edit can have empty old_string (for file creation)
@@ -175,7 +195,7 @@ func processMultiEditWithCreation(edit editContext, params MultiEditParams, call
 	}
 	p, err := edit.permissions.Request(edit.ctx, permission.CreatePermissionRequest{
 		SessionID:   sessionID,
-		Path:        fsext.PathOrPrefix(params.FilePath, edit.workingDir),
+		Path:        permissionPath(params.FilePath, edit.workingDir, edit.additionalDirs),
 		ToolCallID:  call.ID,
 		ToolName:    MultiEditToolName,
 		Action:      "write",
@@ -319,7 +339,7 @@ func processMultiEditExistingFile(edit editContext, params MultiEditParams, call
 	}
 	p, err := edit.permissions.Request(edit.ctx, permission.CreatePermissionRequest{
 		SessionID:   sessionID,
-		Path:        fsext.PathOrPrefix(params.FilePath, edit.workingDir),
+		Path:        permissionPath(params.FilePath, edit.workingDir, edit.additionalDirs),
 		ToolCallID:  call.ID,
 		ToolName:    MultiEditToolName,
 		Action:      "write",
@@ -411,17 +431,12 @@ func applyEditToContent(content string, edit MultiEditOperation) (string, error)
 			return "", fmt.Errorf("old_string not found in content. Make sure it matches exactly, including whitespace and line breaks")
 		}
 	} else {
-		index := strings.Index(content, edit.OldString)
-		if index == -1 {
-			return "", fmt.Errorf("old_string not found in content. Make sure it matches exactly, including whitespace and line breaks")
-		}
-
-		lastIndex := strings.LastIndex(content, edit.OldString)
-		if index != lastIndex {
-			return "", fmt.Errorf("old_string appears multiple times in the content. Please provide more context to ensure a unique match, or set replace_all to true")
+		match, err := findEditMatch(content, edit.OldString, edit.NewString)
+		if err != nil {
+			return "", err
 		}
 
-		newContent = content[:index] + edit.NewString + content[index+len(edit.OldString):]
+		newContent = content[:match.start] + match.newString + content[match.end:]
 		replacementCount = 1
 	}
 
@@ -2,10 +2,13 @@ package tools
 
 import (
 	"context"
+	"encoding/json"
 	"os"
 	"path/filepath"
 	"testing"
 
+	"charm.land/fantasy"
+	"github.com/charmbracelet/crush/internal/config"
 	"github.com/charmbracelet/crush/internal/csync"
 	"github.com/charmbracelet/crush/internal/filetracker"
 	"github.com/charmbracelet/crush/internal/history"
@@ -77,6 +80,17 @@ func (m *mockHistoryService) DeleteSessionFiles(ctx context.Context, sessionID s
 	return nil
 }
 
+// runTool runs tool with params within a session.
+func runTool(t *testing.T, tool fantasy.AgentTool, params any) fantasy.ToolResponse {
+	t.Helper()
+	input, err := json.Marshal(params)
+	require.NoError(t, err)
+	ctx := context.WithValue(t.Context(), SessionIDContextKey, "session")
+	resp, err := tool.Run(ctx, fantasy.ToolCall{ID: "call", Name: tool.Info().Name, Input: string(input)})
+	require.NoError(t, err)
+	return resp
+}
+
 func TestApplyEditToContentPartialSuccess(t *testing.T) {
 	t.Parallel()
 
@@ -117,7 +131,7 @@ func TestMultiEditSequentialApplication(t *testing.T) {
 	files := &mockHistoryService{Broker: pubsub.NewBroker[history.File]()}
 
 	// Create multiedit tool.
-	_ = NewMultiEditTool(lspClients, permissions, files, tmpDir)
+	_ = NewMultiEditTool(lspClients, permissions, files, tmpDir, nil, config.ToolFormat{})
 
 	// Simulate reading the file first.
 	filetracker.RecordRead(testFile)
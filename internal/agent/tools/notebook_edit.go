@@ -0,0 +1,414 @@
+package tools
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"maps"
+	"os"
+	"strings"
+	"time"
+
+	"charm.land/fantasy"
+	"github.com/charmbracelet/crush/internal/diff"
+	"github.com/charmbracelet/crush/internal/filepathext"
+	"github.com/charmbracelet/crush/internal/filetracker"
+	"github.com/charmbracelet/crush/internal/history"
+	"github.com/charmbracelet/crush/internal/permission"
+	"github.com/google/uuid"
+)
+
+type NotebookEditParams struct {
+	FilePath  string `json:"file_path" description:"The absolute path to the notebook (.ipynb) to modify"`
+	CellID    string `json:"cell_id,omitempty" description:"The ID of the cell to edit; with insert, the new cell is inserted after it"`
+	CellIndex int    `json:"cell_index,omitempty" description:"The 0-based index of the cell to edit when cell_id is not given; with insert, the index of the new cell. Use -1 with clear_outputs to clear the outputs of every cell"`
+	EditMode  string `json:"edit_mode,omitempty" description:"The edit to make: replace (default), insert, delete or clear_outputs"`
+	CellType  string `json:"cell_type,omitempty" description:"The type of the cell: code, markdown or raw. Defaults to code when inserting; changes the type of the cell when replacing"`
+	NewSource string `json:"new_source,omitempty" description:"The new source of the cell, for replace and insert"`
+}
+
+type NotebookEditPermissionsParams struct {
+	FilePath    string `json:"file_path"`
+	Description string `json:"description"`
+	OldContent  string `json:"old_content,omitempty"`
+	NewContent  string `json:"new_content,omitempty"`
+}
+
+type NotebookEditResponseMetadata struct {
+	Additions int `json:"additions"`
+	Removals  int `json:"removals"`
+	// OldContent and NewContent are the rendered cells that changed, not
+	// the JSON of the notebook.
+	OldContent string `json:"old_content,omitempty"`
+	NewContent string `json:"new_content,omitempty"`
+}
+
+const (
+	NotebookEditToolName = "notebook_edit"
+
+	notebookEditReplace      = "replace"
+	notebookEditInsert       = "insert"
+	notebookEditDelete       = "delete"
+	notebookEditClearOutputs = "clear_outputs"
+)
+
+//go:embed notebook_edit.md
+var notebookEditDescription []byte
+
+func NewNotebookEditTool(permissions permission.Service, files history.Service, workingDir string, additionalDirs []string) fantasy.AgentTool {
+	return fantasy.NewAgentTool(
+		NotebookEditToolName,
+		string(notebookEditDescription),
+		func(ctx context.Context, params NotebookEditParams, call fantasy.ToolCall) (fantasy.ToolResponse, error) {
+			if params.FilePath == "" {
+				return fantasy.NewTextErrorResponse("file_path is required"), nil
+			}
+			if getDocumentKind(params.FilePath) != documentNotebook {
+				return fantasy.NewTextErrorResponse("file_path must be a Jupyter notebook (.ipynb). Use the Edit tool for other files"), nil
+			}
+			switch params.CellType {
+			case "", "code", "markdown", "raw":
+			default:
+				return fantasy.NewTextErrorResponse(fmt.Sprintf("invalid cell_type %q: use code, markdown or raw", params.CellType)), nil
+			}
+
+			sessionID := GetSessionFromContext(ctx)
+			if sessionID == "" {
+				return fantasy.ToolResponse{}, fmt.Errorf("session ID is required for editing a notebook")
+			}
+
+			filePath := filepathext.SmartJoin(workingDir, params.FilePath)
+			fileInfo, err := os.Stat(filePath)
+			if err != nil {
+				if os.IsNotExist(err) {
+					return fantasy.NewTextErrorResponse(fmt.Sprintf("file not found: %s", filePath)), nil
+				}
+				return fantasy.ToolResponse{}, fmt.Errorf("failed to access file: %w", err)
+			}
+			if fileInfo.IsDir() {
+				return fantasy.NewTextErrorResponse(fmt.Sprintf("path is a directory, not a file: %s", filePath)), nil
+			}
+
+			lastRead := filetracker.LastReadTime(filePath)
+			if lastRead.IsZero() {
+				return fantasy.NewTextErrorResponse("you must read the notebook before editing it. Use the View tool first"), nil
+			}
+			if modTime := fileInfo.ModTime(); modTime.After(lastRead) {
+				return fantasy.NewTextErrorResponse(
+					fmt.Sprintf("file %s has been modified since it was last read (mod time: %s, last read: %s)",
+						filePath, modTime.Format(time.RFC3339), lastRead.Format(time.RFC3339),
+					)), nil
+			}
+
+			content, err := os.ReadFile(filePath)
+			if err != nil {
+				return fantasy.ToolResponse{}, fmt.Errorf("failed to read file: %w", err)
+			}
+
+			result, err := editNotebook(content, params)
+			if err != nil {
+				return fantasy.NewTextErrorResponse(err.Error()), nil
+			}
+			oldContent, newContent := string(content), string(result.content)
+			if oldContent == newContent {
+				return fantasy.NewTextErrorResponse("new content is the same as old content. No changes made."), nil
+			}
+
+			_, additions, removals := diff.GenerateDiff(result.oldCells, result.newCells, strings.TrimPrefix(filePath, workingDir))
+
+			p, err := permissions.Request(ctx,
+				permission.CreatePermissionRequest{
+					SessionID:   sessionID,
+					Path:        permissionPath(filePath, workingDir, additionalDirs),
+					ToolCallID:  call.ID,
+					ToolName:    NotebookEditToolName,
+					Action:      "write",
+					Description: fmt.Sprintf("%s in notebook %s", result.description, filePath),
+					Params: NotebookEditPermissionsParams{
+						FilePath:    filePath,
+						Description: result.description,
+						OldContent:  result.oldCells,
+						NewContent:  result.newCells,
+					},
+				},
+			)
+			if err != nil {
+				return fantasy.ToolResponse{}, err
+			}
+			if !p {
+				return fantasy.ToolResponse{}, permission.ErrorPermissionDenied
+			}
+
+			if err := os.WriteFile(filePath, result.content, 0o644); err != nil {
+				return fantasy.ToolResponse{}, fmt.Errorf("failed to write file: %w", err)
+			}
+
+			// Check if file exists in history
+			file, err := files.GetByPathAndSession(ctx, filePath, sessionID)
+			if err != nil {
+				if _, err = files.Create(ctx, sessionID, filePath, oldContent); err != nil {
+					return fantasy.ToolResponse{}, fmt.Errorf("error creating file history: %w", err)
+				}
+			}
+			if file.Content != oldContent {
+				// User manually changed the content; store an intermediate version
+				if _, err = files.CreateVersion(ctx, sessionID, filePath, oldContent); err != nil {
+					slog.Debug("Error creating file history version", "error", err)
+				}
+			}
+			// Store the new version
+			if _, err = files.CreateVersion(ctx, sessionID, filePath, newContent); err != nil {
+				slog.Error("Error creating file history version", "error", err)
+			}
+
+			filetracker.RecordWrite(filePath)
+			filetracker.RecordRead(filePath)
+
+			return fantasy.WithResponseMetadata(
+				fantasy.NewTextResponse(fmt.Sprintf("<result>\n%s in notebook: %s\n</result>\n", result.description, filePath)),
+				NotebookEditResponseMetadata{
+					OldContent: result.oldCells,
+					NewContent: result.newCells,
+					Additions:  additions,
+					Removals:   removals,
+				},
+			), nil
+		})
+}
+
+// notebookEdit is the result of an edit of a notebook.
+type notebookEdit struct {
+	// content is the JSON of the edited notebook.
+	content []byte
+	// oldCells and newCells are the rendered cells before and after the
+	// edit.
+	oldCells, newCells string
+	description        string
+}
+
+// editNotebook edits the JSON of a notebook, keeping the fields it doesn't
+// know about and its indentation.
+func editNotebook(content []byte, params NotebookEditParams) (notebookEdit, error) {
+	decoder := json.NewDecoder(bytes.NewReader(content))
+	decoder.UseNumber()
+	var nb map[string]any
+	if err := decoder.Decode(&nb); err != nil {
+		return notebookEdit{}, fmt.Errorf("invalid notebook: %w", err)
+	}
+	cells, ok := nb["cells"].([]any)
+	if !ok && nb["cells"] != nil {
+		return notebookEdit{}, fmt.Errorf("invalid notebook: cells is not a list")
+	}
+
+	var edit notebookEdit
+	switch cmp.Or(params.EditMode, notebookEditReplace) {
+	case notebookEditReplace:
+		index, cell, err := findNotebookCell(cells, params)
+		if err != nil {
+			return notebookEdit{}, err
+		}
+		edit.oldCells = renderNotebookCellJSON(index, cell)
+		cell = maps.Clone(cell)
+		if params.CellType != "" {
+			setCellType(cell, params.CellType)
+		}
+		cell["source"] = sourceLines(params.NewSource)
+		if cell["cell_type"] == "code" {
+			// The outputs are those of the previous source.
+			cell["outputs"] = []any{}
+			cell["execution_count"] = nil
+		}
+		cells[index] = cell
+		edit.newCells = renderNotebookCellJSON(index, cell)
+		edit.description = fmt.Sprintf("Replaced cell %s", cellName(index, cell))
+
+	case notebookEditInsert:
+		index := params.CellIndex
+		if params.CellID != "" {
+			found, _, err := findNotebookCell(cells, params)
+			if err != nil {
+				return notebookEdit{}, err
+			}
+			index = found + 1
+		}
+		if index < 0 || index > len(cells) {
+			return notebookEdit{}, fmt.Errorf("cell_index %d is out of range: the notebook has %d cell(s)", index, len(cells))
+		}
+		cell := map[string]any{
+			"metadata": map[string]any{},
+			"source":   sourceLines(params.NewSource),
+		}
+		if notebookHasCellIDs(nb, cells) {
+			cell["id"] = strings.ReplaceAll(uuid.NewString(), "-", "")[:8]
+		}
+		setCellType(cell, cmp.Or(params.CellType, "code"))
+		cells = append(cells[:index], append([]any{cell}, cells[index:]...)...)
+		edit.newCells = renderNotebookCellJSON(index, cell)
+		edit.description = fmt.Sprintf("Inserted cell %s", cellName(index, cell))
+
+	case notebookEditDelete:
+		index, cell, err := findNotebookCell(cells, params)
+		if err != nil {
+			return notebookEdit{}, err
+		}
+		edit.oldCells = renderNotebookCellJSON(index, cell)
+		cells = append(cells[:index], cells[index+1:]...)
+		edit.description = fmt.Sprintf("Deleted cell %s", cellName(index, cell))
+
+	case notebookEditClearOutputs:
+		if params.CellID == "" && params.CellIndex == -1 {
+			var oldCells, newCells strings.Builder
+			for i, c := range cells {
+				cell, _ := c.(map[string]any)
+				if cell == nil || cell["cell_type"] != "code" {
+					continue
+				}
+				oldCells.WriteString(renderNotebookCellJSON(i, cell))
+				cell = maps.Clone(cell)
+				cell["outputs"] = []any{}
+				cell["execution_count"] = nil
+				cells[i] = cell
+				newCells.WriteString(renderNotebookCellJSON(i, cell))
+			}
+			edit.oldCells, edit.newCells = oldCells.String(), newCells.String()
+			edit.description = "Cleared the outputs of every cell"
+			break
+		}
+		index, cell, err := findNotebookCell(cells, params)
+		if err != nil {
+			return notebookEdit{}, err
+		}
+		if cell["cell_type"] != "code" {
+			return notebookEdit{}, fmt.Errorf("cell %s is not a code cell and has no outputs", cellName(index, cell))
+		}
+		edit.oldCells = renderNotebookCellJSON(index, cell)
+		cell = maps.Clone(cell)
+		cell["outputs"] = []any{}
+		cell["execution_count"] = nil
+		cells[index] = cell
+		edit.newCells = renderNotebookCellJSON(index, cell)
+		edit.description = fmt.Sprintf("Cleared the outputs of cell %s", cellName(index, cell))
+
+	default:
+		return notebookEdit{}, fmt.Errorf("invalid edit_mode %q: use replace, insert, delete or clear_outputs", params.EditMode)
+	}
+	nb["cells"] = cells
+
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", notebookIndent(content))
+	if err := encoder.Encode(nb); err != nil {
+		return notebookEdit{}, fmt.Errorf("failed to encode notebook: %w", err)
+	}
+	edit.content = buf.Bytes()
+	if !bytes.HasSuffix(content, []byte("\n")) {
+		edit.content = bytes.TrimSuffix(edit.content, []byte("\n"))
+	}
+	return edit, nil
+}
+
+// findNotebookCell returns the cell with the ID of the params if any, or the
+// one at their index.
+func findNotebookCell(cells []any, params NotebookEditParams) (int, map[string]any, error) {
+	if params.CellID != "" {
+		for i, c := range cells {
+			if cell, ok := c.(map[string]any); ok && cell["id"] == params.CellID {
+				return i, cell, nil
+			}
+		}
+		return 0, nil, fmt.Errorf("cell %q not found in the notebook. View the notebook to find the IDs of its cells", params.CellID)
+	}
+	if params.CellIndex < 0 || params.CellIndex >= len(cells) {
+		return 0, nil, fmt.Errorf("cell_index %d is out of range: the notebook has %d cell(s)", params.CellIndex, len(cells))
+	}
+	cell, ok := cells[params.CellIndex].(map[string]any)
+	if !ok {
+		return 0, nil, fmt.Errorf("invalid notebook: cell %d is not an object", params.CellIndex)
+	}
+	return params.CellIndex, cell, nil
+}
+
+// setCellType changes the type of a cell, adding or removing the fields only
+// code cells have.
+func setCellType(cell map[string]any, cellType string) {
+	cell["cell_type"] = cellType
+	if cellType == "code" {
+		if _, ok := cell["outputs"]; !ok {
+			cell["outputs"] = []any{}
+		}
+		if _, ok := cell["execution_count"]; !ok {
+			cell["execution_count"] = nil
+		}
+		return
+	}
+	delete(cell, "outputs")
+	delete(cell, "execution_count")
+}
+
+// notebookHasCellIDs returns whether the cells of the notebook have IDs,
+// which nbformat requires from version 4.5.
+func notebookHasCellIDs(nb map[string]any, cells []any) bool {
+	for _, c := range cells {
+		if cell, ok := c.(map[string]any); ok {
+			if _, ok := cell["id"]; ok {
+				return true
+			}
+		}
+	}
+	major, _ := nb["nbformat"].(json.Number)
+	minor, _ := nb["nbformat_minor"].(json.Number)
+	majorVersion, _ := major.Int64()
+	minorVersion, _ := minor.Int64()
+	return majorVersion > 4 || (majorVersion == 4 && minorVersion >= 5)
+}
+
+// sourceLines splits a source into lines the way nbformat stores them, each
+// line keeping its newline.
+func sourceLines(source string) []any {
+	lines := []any{}
+	for _, line := range strings.SplitAfter(source, "\n") {
+		if line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
+
+// notebookIndent returns the indentation of the JSON of a notebook, one
+// space by default as written by Jupyter.
+func notebookIndent(content []byte) string {
+	_, rest, ok := bytes.Cut(content, []byte("\n"))
+	if !ok {
+		return " "
+	}
+	indent := rest[:len(rest)-len(bytes.TrimLeft(rest, " \t"))]
+	if len(indent) == 0 {
+		return " "
+	}
+	return string(indent)
+}
+
+func cellName(index int, cell map[string]any) string {
+	if id, ok := cell["id"].(string); ok && id != "" {
+		return fmt.Sprintf("%d (id %s)", index, id)
+	}
+	return fmt.Sprintf("%d", index)
+}
+
+// renderNotebookCellJSON renders a cell the way the view tool does.
+func renderNotebookCellJSON(index int, cell map[string]any) string {
+	data, err := json.Marshal(cell)
+	if err != nil {
+		return ""
+	}
+	var c notebookCell
+	if err := json.Unmarshal(data, &c); err != nil {
+		return ""
+	}
+	return renderNotebookCell(index, c)
+}
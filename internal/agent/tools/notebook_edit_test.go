@@ -0,0 +1,255 @@
+package tools
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"charm.land/fantasy"
+	"github.com/charmbracelet/crush/internal/filetracker"
+	"github.com/charmbracelet/crush/internal/history"
+	"github.com/charmbracelet/crush/internal/permission"
+	"github.com/charmbracelet/crush/internal/pubsub"
+	"github.com/stretchr/testify/require"
+)
+
+// testNotebook is formatted the way Jupyter writes notebooks.
+const testNotebook = `{
+ "cells": [
+  {
+   "cell_type": "markdown",
+   "id": "intro",
+   "metadata": {},
+   "source": [
+    "# Analysis <draft>"
+   ]
+  },
+  {
+   "cell_type": "code",
+   "execution_count": 1,
+   "id": "load",
+   "metadata": {
+    "tags": [
+     "setup"
+    ]
+   },
+   "outputs": [
+    {
+     "name": "stdout",
+     "output_type": "stream",
+     "text": [
+      "loaded\n"
+     ]
+    }
+   ],
+   "source": [
+    "data = load()\n",
+    "print(\"loaded\")"
+   ]
+  }
+ ],
+ "metadata": {
+  "kernelspec": {
+   "display_name": "Python 3",
+   "language": "python",
+   "name": "python3"
+  }
+ },
+ "nbformat": 4,
+ "nbformat_minor": 5
+}
+`
+
+func TestEditNotebook(t *testing.T) {
+	t.Parallel()
+
+	t.Run("replace", func(t *testing.T) {
+		t.Parallel()
+
+		edit, err := editNotebook([]byte(testNotebook), NotebookEditParams{
+			CellID:    "load",
+			EditMode:  notebookEditReplace,
+			NewSource: "data = load(\"file.csv\")\n",
+		})
+		require.NoError(t, err)
+		require.Equal(t, "Replaced cell 1 (id load)", edit.description)
+		require.Contains(t, edit.oldCells, "<output>\nloaded\n</output>")
+		require.Equal(t, "<cell index=1 id=\"load\" type=\"code\">\ndata = load(\"file.csv\")\n</cell>\n", edit.newCells)
+
+		// Only the source and the outputs of the cell change.
+		expected := `{
+ "cells": [
+  {
+   "cell_type": "markdown",
+   "id": "intro",
+   "metadata": {},
+   "source": [
+    "# Analysis <draft>"
+   ]
+  },
+  {
+   "cell_type": "code",
+   "execution_count": null,
+   "id": "load",
+   "metadata": {
+    "tags": [
+     "setup"
+    ]
+   },
+   "outputs": [],
+   "source": [
+    "data = load(\"file.csv\")\n"
+   ]
+  }
+ ],
+ "metadata": {
+  "kernelspec": {
+   "display_name": "Python 3",
+   "language": "python",
+   "name": "python3"
+  }
+ },
+ "nbformat": 4,
+ "nbformat_minor": 5
+}
+`
+		require.Equal(t, expected, string(edit.content))
+	})
+
+	t.Run("change cell type", func(t *testing.T) {
+		t.Parallel()
+
+		edit, err := editNotebook([]byte(testNotebook), NotebookEditParams{
+			CellIndex: 1,
+			CellType:  "markdown",
+			NewSource: "Load the data",
+		})
+		require.NoError(t, err)
+		cells := decodeCells(t, edit.content)
+		require.Equal(t, "markdown", cells[1]["cell_type"])
+		require.NotContains(t, cells[1], "outputs")
+		require.NotContains(t, cells[1], "execution_count")
+	})
+
+	t.Run("insert", func(t *testing.T) {
+		t.Parallel()
+
+		edit, err := editNotebook([]byte(testNotebook), NotebookEditParams{
+			CellID:    "intro",
+			EditMode:  notebookEditInsert,
+			NewSource: "import pandas\nimport numpy\n",
+		})
+		require.NoError(t, err)
+		require.Empty(t, edit.oldCells)
+		cells := decodeCells(t, edit.content)
+		require.Len(t, cells, 3)
+		require.Equal(t, "code", cells[1]["cell_type"])
+		require.Equal(t, []any{"import pandas\n", "import numpy\n"}, cells[1]["source"])
+		require.Equal(t, []any{}, cells[1]["outputs"])
+		require.Len(t, cells[1]["id"], 8)
+		require.Equal(t, "load", cells[2]["id"])
+
+		edit, err = editNotebook([]byte(testNotebook), NotebookEditParams{
+			CellIndex: 2,
+			EditMode:  notebookEditInsert,
+			CellType:  "markdown",
+			NewSource: "The end",
+		})
+		require.NoError(t, err)
+		require.Equal(t, "markdown", decodeCells(t, edit.content)[2]["cell_type"])
+
+		_, err = editNotebook([]byte(testNotebook), NotebookEditParams{CellIndex: 3, EditMode: notebookEditInsert})
+		require.ErrorContains(t, err, "out of range")
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		t.Parallel()
+
+		edit, err := editNotebook([]byte(testNotebook), NotebookEditParams{CellIndex: 0, EditMode: notebookEditDelete})
+		require.NoError(t, err)
+		require.Empty(t, edit.newCells)
+		cells := decodeCells(t, edit.content)
+		require.Len(t, cells, 1)
+		require.Equal(t, "load", cells[0]["id"])
+	})
+
+	t.Run("clear outputs", func(t *testing.T) {
+		t.Parallel()
+
+		edit, err := editNotebook([]byte(testNotebook), NotebookEditParams{CellIndex: -1, EditMode: notebookEditClearOutputs})
+		require.NoError(t, err)
+		require.Equal(t, "Cleared the outputs of every cell", edit.description)
+		cells := decodeCells(t, edit.content)
+		require.Equal(t, []any{}, cells[1]["outputs"])
+		require.Nil(t, cells[1]["execution_count"])
+		require.Equal(t, []any{"data = load()\n", "print(\"loaded\")"}, cells[1]["source"])
+
+		_, err = editNotebook([]byte(testNotebook), NotebookEditParams{CellID: "intro", EditMode: notebookEditClearOutputs})
+		require.ErrorContains(t, err, "not a code cell")
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := editNotebook([]byte(testNotebook), NotebookEditParams{CellID: "missing"})
+		require.ErrorContains(t, err, `cell "missing" not found`)
+		_, err = editNotebook([]byte(testNotebook), NotebookEditParams{CellIndex: 5, EditMode: notebookEditDelete})
+		require.ErrorContains(t, err, "out of range")
+		_, err = editNotebook([]byte(testNotebook), NotebookEditParams{EditMode: "move"})
+		require.ErrorContains(t, err, "invalid edit_mode")
+		_, err = editNotebook([]byte("{"), NotebookEditParams{})
+		require.ErrorContains(t, err, "invalid notebook")
+	})
+}
+
+func TestNotebookEditTool(t *testing.T) {
+	t.Parallel()
+
+	tmpDir := t.TempDir()
+	path := filepath.Join(tmpDir, "analysis.ipynb")
+	require.NoError(t, os.WriteFile(path, []byte(testNotebook), 0o644))
+
+	tool := NewNotebookEditTool(
+		&mockPermissionService{Broker: pubsub.NewBroker[permission.PermissionRequest]()},
+		&mockHistoryService{Broker: pubsub.NewBroker[history.File]()},
+		tmpDir,
+		nil,
+	)
+	run := func(params NotebookEditParams) fantasy.ToolResponse {
+		return runTool(t, tool, params)
+	}
+
+	params := NotebookEditParams{FilePath: "analysis.ipynb", CellID: "intro", NewSource: "# Analysis"}
+	resp := run(params)
+	require.True(t, resp.IsError)
+	require.Contains(t, resp.Content, "you must read the notebook")
+
+	filetracker.RecordRead(path)
+	resp = run(params)
+	require.False(t, resp.IsError, resp.Content)
+	require.Contains(t, resp.Content, "Replaced cell 0 (id intro)")
+
+	var meta NotebookEditResponseMetadata
+	require.NoError(t, json.Unmarshal([]byte(resp.Metadata), &meta))
+	require.Equal(t, 1, meta.Additions)
+	require.Equal(t, 1, meta.Removals)
+
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.Equal(t, "# Analysis", decodeCells(t, content)[0]["source"].([]any)[0])
+
+	resp = run(NotebookEditParams{FilePath: "notes.md", NewSource: "text"})
+	require.True(t, resp.IsError)
+	require.Contains(t, resp.Content, "must be a Jupyter notebook")
+}
+
+func decodeCells(t *testing.T, content []byte) []map[string]any {
+	t.Helper()
+
+	var nb struct {
+		Cells []map[string]any `json:"cells"`
+	}
+	require.NoError(t, json.Unmarshal(content, &nb))
+	return nb.Cells
+}
@@ -49,7 +49,7 @@ func NewReferencesTool(lspClients *csync.Map[string, *lsp.Client]) fantasy.Agent
 
 			workingDir := cmp.Or(params.Path, ".")
 
-			matches, _, err := searchFiles(ctx, regexp.QuoteMeta(params.Symbol), workingDir, "", 100)
+			matches, _, err := searchFiles(ctx, regexp.QuoteMeta(params.Symbol), workingDir, grepOptions{}, 100)
 			if err != nil {
 				return fantasy.NewTextErrorResponse(fmt.Sprintf("failed to search for symbol: %s", err)), nil
 			}
@@ -97,14 +97,7 @@ func find(ctx context.Context, lspClients *csync.Map[string, *lsp.Client], symbo
 		return nil, fmt.Errorf("failed to get absolute path: %s", err)
 	}
 
-	var client *lsp.Client
-	for c := range lspClients.Seq() {
-		if c.HandlesFile(absPath) {
-			client = c
-			break
-		}
-	}
-
+	client := clientForFile(lspClients, absPath)
 	if client == nil {
 		slog.Warn("No LSP clients to handle", "path", match.path)
 		return nil, nil
@@ -119,6 +112,71 @@ func find(ctx context.Context, lspClients *csync.Map[string, *lsp.Client], symbo
 	)
 }
 
+// symbolPosition is a position of a symbol, found by name, in a file handled
+// by an LSP client. Line and character are 1-based, and the character points
+// to the symbol name rather than its qualifier.
+type symbolPosition struct {
+	client *lsp.Client
+	path   string
+	line   int
+	char   int
+}
+
+// at returns whether the location starts at the position.
+func (p symbolPosition) at(loc protocol.Location) bool {
+	path, err := loc.URI.Path()
+	return err == nil && path == p.path &&
+		int(loc.Range.Start.Line) == p.line-1 &&
+		int(loc.Range.Start.Character) == p.char-1
+}
+
+// findSymbolPositions searches for the symbol by name in the given directory
+// or file, and returns its positions in files handled by an LSP client.
+func findSymbolPositions(ctx context.Context, lspClients *csync.Map[string, *lsp.Client], symbol, path string) ([]symbolPosition, error) {
+	matches, _, err := searchFiles(ctx, regexp.QuoteMeta(symbol), cmp.Or(path, "."), grepOptions{}, 100)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search for symbol: %w", err)
+	}
+
+	var positions []symbolPosition
+	for _, match := range matches {
+		absPath, err := filepath.Abs(match.path)
+		if err != nil {
+			continue
+		}
+		client := clientForFile(lspClients, absPath)
+		if client == nil {
+			slog.Warn("No LSP clients to handle", "path", match.path)
+			continue
+		}
+		positions = append(positions, symbolPosition{
+			client: client,
+			path:   absPath,
+			line:   match.lineNum,
+			char:   match.charNum + getSymbolOffset(symbol),
+		})
+	}
+	return positions, nil
+}
+
+// isNoIdentifierError returns whether the LSP server found no identifier at
+// a position, which happens when grep matched a comment, a string value, or
+// something else that's irrelevant.
+func isNoIdentifierError(err error) bool {
+	return strings.Contains(err.Error(), "no identifier found")
+}
+
+// clientForFile returns the LSP client handling the given file, or nil if
+// there is none.
+func clientForFile(lspClients *csync.Map[string, *lsp.Client], path string) *lsp.Client {
+	for c := range lspClients.Seq() {
+		if c.HandlesFile(path) {
+			return c
+		}
+	}
+	return nil
+}
+
 // getSymbolOffset returns the character offset to the actual symbol name
 // in a qualified symbol (e.g., "Bar" in "foo.Bar" or "method" in "Class::method").
 func getSymbolOffset(symbol string) int {
@@ -168,16 +226,22 @@ func groupByFilename(locations []protocol.Location) map[string][]protocol.Locati
 }
 
 func formatReferences(locations []protocol.Location) string {
+	return formatLocations("reference", locations)
+}
+
+// formatLocations formats locations grouped by file, naming them with the
+// given noun.
+func formatLocations(noun string, locations []protocol.Location) string {
 	fileRefs := groupByFilename(locations)
 	files := slices.Collect(maps.Keys(fileRefs))
 	sort.Strings(files)
 
 	var output strings.Builder
-	output.WriteString(fmt.Sprintf("Found %d reference(s) in %d file(s):\n\n", len(locations), len(files)))
+	output.WriteString(fmt.Sprintf("Found %d %s(s) in %d file(s):\n\n", len(locations), noun, len(files)))
 
 	for _, file := range files {
 		refs := fileRefs[file]
-		output.WriteString(fmt.Sprintf("%s (%d reference(s)):\n", file, len(refs)))
+		output.WriteString(fmt.Sprintf("%s (%d %s(s)):\n", file, len(refs), noun))
 		for _, ref := range refs {
 			line := ref.Range.Start.Line + 1
 			char := ref.Range.Start.Character + 1
@@ -5,6 +5,7 @@ import (
 	"log/slog"
 	"os/exec"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"sync"
 
@@ -37,17 +38,33 @@ func getRgCmd(ctx context.Context, globPattern string) *exec.Cmd {
 	return exec.CommandContext(ctx, name, args...)
 }
 
-func getRgSearchCmd(ctx context.Context, pattern, path, include string) *exec.Cmd {
+func getRgSearchCmd(ctx context.Context, pattern, path string, opts grepOptions) *exec.Cmd {
 	name := getRg()
 	if name == "" {
 		return nil
 	}
 	// Use -n to show line numbers, -0 for null separation to handle Windows paths
-	args := []string{"--json", "-H", "-n", "-0", pattern}
-	if include != "" {
-		args = append(args, "--glob", include)
+	args := []string{"--json", "-H", "-n", "-0"}
+	if opts.include != "" {
+		args = append(args, "--glob", opts.include)
 	}
-	args = append(args, path)
+	if opts.fileType != "" {
+		args = append(args, "--type", opts.fileType)
+	}
+	if opts.caseInsensitive {
+		args = append(args, "--ignore-case")
+	}
+	if opts.multiline {
+		args = append(args, "--multiline", "--multiline-dotall")
+	}
+	if opts.contextBefore > 0 {
+		args = append(args, "--before-context", strconv.Itoa(opts.contextBefore))
+	}
+	if opts.contextAfter > 0 {
+		args = append(args, "--after-context", strconv.Itoa(opts.contextAfter))
+	}
+	// Use -e so patterns starting with a dash aren't taken for flags.
+	args = append(args, "-e", pattern, path)
 
 	return exec.CommandContext(ctx, name, args...)
 }
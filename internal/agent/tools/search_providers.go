@@ -0,0 +1,341 @@
+package tools
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/crush/internal/config"
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// Web search providers.
+const (
+	SearchProviderDuckDuckGo = "duckduckgo"
+	SearchProviderSearxNG    = "searxng"
+	SearchProviderBrave      = "brave"
+	SearchProviderTavily     = "tavily"
+	SearchProviderKagi       = "kagi"
+)
+
+const (
+	braveSearchURL  = "https://api.search.brave.com/res/v1/web/search"
+	tavilySearchURL = "https://api.tavily.com/search"
+	kagiSearchURL   = "https://kagi.com/api/v0/search"
+
+	// maxSearchResponseSize is the maximum size of a search API response.
+	maxSearchResponseSize = 5 * 1024 * 1024
+)
+
+// SearchProvider is a web search backend.
+type SearchProvider interface {
+	// Name returns the name of the provider, as used in the config.
+	Name() string
+	// Search returns at most maxResults results for the query.
+	Search(ctx context.Context, query string, maxResults int) ([]SearchResult, error)
+}
+
+// newSearchClient returns the HTTP client used for searching when none is
+// given.
+func newSearchClient() *http.Client {
+	return &http.Client{
+		Timeout: 30 * time.Second,
+		Transport: &http.Transport{
+			MaxIdleConns:        100,
+			MaxIdleConnsPerHost: 10,
+			IdleConnTimeout:     90 * time.Second,
+		},
+	}
+}
+
+// NewSearchProvider returns the search provider configured by cfg, which
+// defaults to DuckDuckGo. The API key of cfg must already be resolved.
+func NewSearchProvider(cfg config.ToolWebSearch, client *http.Client) (SearchProvider, error) {
+	if client == nil {
+		client = newSearchClient()
+	}
+
+	switch strings.ToLower(cmp.Or(cfg.Provider, SearchProviderDuckDuckGo)) {
+	case SearchProviderDuckDuckGo:
+		return duckDuckGoProvider{client: client}, nil
+	case SearchProviderSearxNG:
+		if cfg.BaseURL == "" {
+			return nil, fmt.Errorf("the %s search provider requires a base_url", SearchProviderSearxNG)
+		}
+		return searxNGProvider{client: client, baseURL: strings.TrimSuffix(cfg.BaseURL, "/")}, nil
+	case SearchProviderBrave:
+		if cfg.APIKey == "" {
+			return nil, fmt.Errorf("the %s search provider requires an api_key", SearchProviderBrave)
+		}
+		return braveProvider{client: client, url: cmp.Or(cfg.BaseURL, braveSearchURL), apiKey: cfg.APIKey}, nil
+	case SearchProviderTavily:
+		if cfg.APIKey == "" {
+			return nil, fmt.Errorf("the %s search provider requires an api_key", SearchProviderTavily)
+		}
+		return tavilyProvider{client: client, url: cmp.Or(cfg.BaseURL, tavilySearchURL), apiKey: cfg.APIKey}, nil
+	case SearchProviderKagi:
+		if cfg.APIKey == "" {
+			return nil, fmt.Errorf("the %s search provider requires an api_key", SearchProviderKagi)
+		}
+		return kagiProvider{client: client, url: cmp.Or(cfg.BaseURL, kagiSearchURL), apiKey: cfg.APIKey}, nil
+	default:
+		return nil, fmt.Errorf("unknown search provider: %s", cfg.Provider)
+	}
+}
+
+// duckDuckGoProvider scrapes the DuckDuckGo lite HTML page. It needs no API
+// key, but is rate limited, so searches are spaced out.
+type duckDuckGoProvider struct {
+	client *http.Client
+}
+
+func (duckDuckGoProvider) Name() string { return SearchProviderDuckDuckGo }
+
+func (p duckDuckGoProvider) Search(ctx context.Context, query string, maxResults int) ([]SearchResult, error) {
+	maybeDelaySearch()
+	return searchDuckDuckGo(ctx, p.client, query, maxResults)
+}
+
+// searxNGProvider uses the JSON API of a SearxNG instance, which must have
+// the json format enabled in its settings.
+type searxNGProvider struct {
+	client  *http.Client
+	baseURL string
+}
+
+func (searxNGProvider) Name() string { return SearchProviderSearxNG }
+
+func (p searxNGProvider) Search(ctx context.Context, query string, maxResults int) ([]SearchResult, error) {
+	params := url.Values{"q": {query}, "format": {"json"}}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.baseURL+"/search?"+params.Encode(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	var resp struct {
+		Results []struct {
+			Title   string `json:"title"`
+			URL     string `json:"url"`
+			Content string `json:"content"`
+		} `json:"results"`
+	}
+	if err := doSearchRequest(p.client, req, &resp); err != nil {
+		return nil, err
+	}
+
+	var results []SearchResult
+	for _, r := range resp.Results {
+		results = appendSearchResult(results, r.Title, r.URL, r.Content)
+		if len(results) >= maxResults {
+			break
+		}
+	}
+	return results, nil
+}
+
+// braveProvider uses the Brave Search API.
+type braveProvider struct {
+	client *http.Client
+	url    string
+	apiKey string
+}
+
+func (braveProvider) Name() string { return SearchProviderBrave }
+
+func (p braveProvider) Search(ctx context.Context, query string, maxResults int) ([]SearchResult, error) {
+	params := url.Values{"q": {query}, "count": {strconv.Itoa(maxResults)}}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.url+"?"+params.Encode(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("X-Subscription-Token", p.apiKey)
+
+	var resp struct {
+		Web struct {
+			Results []struct {
+				Title       string `json:"title"`
+				URL         string `json:"url"`
+				Description string `json:"description"`
+			} `json:"results"`
+		} `json:"web"`
+	}
+	if err := doSearchRequest(p.client, req, &resp); err != nil {
+		return nil, err
+	}
+
+	var results []SearchResult
+	for _, r := range resp.Web.Results {
+		results = appendSearchResult(results, r.Title, r.URL, r.Description)
+		if len(results) >= maxResults {
+			break
+		}
+	}
+	return results, nil
+}
+
+// tavilyProvider uses the Tavily Search API.
+type tavilyProvider struct {
+	client *http.Client
+	url    string
+	apiKey string
+}
+
+func (tavilyProvider) Name() string { return SearchProviderTavily }
+
+func (p tavilyProvider) Search(ctx context.Context, query string, maxResults int) ([]SearchResult, error) {
+	body, err := json.Marshal(map[string]any{
+		"query":       query,
+		"max_results": maxResults,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode request: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.url, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+p.apiKey)
+
+	var resp struct {
+		Results []struct {
+			Title   string `json:"title"`
+			URL     string `json:"url"`
+			Content string `json:"content"`
+		} `json:"results"`
+	}
+	if err := doSearchRequest(p.client, req, &resp); err != nil {
+		return nil, err
+	}
+
+	var results []SearchResult
+	for _, r := range resp.Results {
+		results = appendSearchResult(results, r.Title, r.URL, r.Content)
+		if len(results) >= maxResults {
+			break
+		}
+	}
+	return results, nil
+}
+
+// kagiProvider uses the Kagi Search API.
+type kagiProvider struct {
+	client *http.Client
+	url    string
+	apiKey string
+}
+
+func (kagiProvider) Name() string { return SearchProviderKagi }
+
+func (p kagiProvider) Search(ctx context.Context, query string, maxResults int) ([]SearchResult, error) {
+	params := url.Values{"q": {query}, "limit": {strconv.Itoa(maxResults)}}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.url+"?"+params.Encode(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bot "+p.apiKey)
+
+	var resp struct {
+		Data []struct {
+			// T is 0 for search results and 1 for related searches.
+			T       int    `json:"t"`
+			Title   string `json:"title"`
+			URL     string `json:"url"`
+			Snippet string `json:"snippet"`
+		} `json:"data"`
+	}
+	if err := doSearchRequest(p.client, req, &resp); err != nil {
+		return nil, err
+	}
+
+	var results []SearchResult
+	for _, r := range resp.Data {
+		if r.T != 0 {
+			continue
+		}
+		results = appendSearchResult(results, r.Title, r.URL, r.Snippet)
+		if len(results) >= maxResults {
+			break
+		}
+	}
+	return results, nil
+}
+
+// doSearchRequest sends a request to a search API and decodes its JSON
+// response into v.
+func doSearchRequest(client *http.Client, req *http.Request, v any) error {
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("User-Agent", "crush/1.0")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to execute search: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxSearchResponseSize))
+	if err != nil {
+		return fmt.Errorf("failed to read response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		msg := strings.TrimSpace(string(body))
+		if len(msg) > 200 {
+			msg = msg[:200] + "..."
+		}
+		return fmt.Errorf("search failed with status code %d: %s", resp.StatusCode, msg)
+	}
+	if err := json.Unmarshal(body, v); err != nil {
+		return fmt.Errorf("failed to parse response: %w", err)
+	}
+	return nil
+}
+
+// appendSearchResult appends a result to results, skipping results without
+// a link. Snippets may contain HTML highlighting, which is stripped.
+func appendSearchResult(results []SearchResult, title, link, snippet string) []SearchResult {
+	if link == "" {
+		return results
+	}
+	return append(results, SearchResult{
+		Title:    stripHTML(title),
+		Link:     link,
+		Snippet:  stripHTML(snippet),
+		Position: len(results) + 1,
+	})
+}
+
+// stripHTML returns the text content of an HTML fragment.
+func stripHTML(s string) string {
+	if !strings.ContainsAny(s, "<&") {
+		return strings.TrimSpace(s)
+	}
+	parent := &html.Node{Type: html.ElementNode, Data: "div", DataAtom: atom.Div}
+	nodes, err := html.ParseFragment(strings.NewReader(s), parent)
+	if err != nil {
+		return strings.TrimSpace(s)
+	}
+
+	var text strings.Builder
+	var traverse func(*html.Node)
+	traverse = func(n *html.Node) {
+		if n.Type == html.TextNode {
+			text.WriteString(n.Data)
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			traverse(c)
+		}
+	}
+	for _, n := range nodes {
+		traverse(n)
+	}
+	return strings.TrimSpace(text.String())
+}
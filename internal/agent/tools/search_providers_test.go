@@ -0,0 +1,157 @@
+package tools
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/charmbracelet/crush/internal/config"
+	"github.com/charmbracelet/crush/internal/permission"
+	"github.com/charmbracelet/crush/internal/pubsub"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSearchProviders(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		provider string
+		response string
+		check    func(t *testing.T, r *http.Request)
+	}{
+		{
+			provider: SearchProviderSearxNG,
+			response: `{"results": [
+				{"title": "Go", "url": "https://go.dev", "content": "The Go programming language"},
+				{"title": "No link"},
+				{"title": "Go docs", "url": "https://go.dev/doc", "content": "Documentation"},
+				{"title": "Go blog", "url": "https://go.dev/blog", "content": "Blog"}
+			]}`,
+			check: func(t *testing.T, r *http.Request) {
+				require.Equal(t, "/search", r.URL.Path)
+				require.Equal(t, "golang", r.URL.Query().Get("q"))
+				require.Equal(t, "json", r.URL.Query().Get("format"))
+			},
+		},
+		{
+			provider: SearchProviderBrave,
+			response: `{"web": {"results": [
+				{"title": "Go", "url": "https://go.dev", "description": "The <strong>Go</strong> programming language"},
+				{"title": "Go docs", "url": "https://go.dev/doc", "description": "Documentation"}
+			]}}`,
+			check: func(t *testing.T, r *http.Request) {
+				require.Equal(t, "golang", r.URL.Query().Get("q"))
+				require.Equal(t, "2", r.URL.Query().Get("count"))
+				require.Equal(t, "secret", r.Header.Get("X-Subscription-Token"))
+			},
+		},
+		{
+			provider: SearchProviderTavily,
+			response: `{"results": [
+				{"title": "Go", "url": "https://go.dev", "content": "The Go programming language"},
+				{"title": "Go docs", "url": "https://go.dev/doc", "content": "Documentation"}
+			]}`,
+			check: func(t *testing.T, r *http.Request) {
+				require.Equal(t, http.MethodPost, r.Method)
+				require.Equal(t, "Bearer secret", r.Header.Get("Authorization"))
+				body, err := io.ReadAll(r.Body)
+				require.NoError(t, err)
+				require.JSONEq(t, `{"query": "golang", "max_results": 2}`, string(body))
+			},
+		},
+		{
+			provider: SearchProviderKagi,
+			response: `{"data": [
+				{"t": 1, "list": ["golang tutorial"]},
+				{"t": 0, "title": "Go", "url": "https://go.dev", "snippet": "The Go programming language"},
+				{"t": 0, "title": "Go docs", "url": "https://go.dev/doc", "snippet": "Documentation"}
+			]}`,
+			check: func(t *testing.T, r *http.Request) {
+				require.Equal(t, "golang", r.URL.Query().Get("q"))
+				require.Equal(t, "Bot secret", r.Header.Get("Authorization"))
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.provider, func(t *testing.T) {
+			t.Parallel()
+
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				tt.check(t, r)
+				w.Header().Set("Content-Type", "application/json")
+				_, _ = io.WriteString(w, tt.response)
+			}))
+			defer server.Close()
+
+			baseURL := server.URL
+			if tt.provider != SearchProviderSearxNG {
+				baseURL += "/api/search"
+			}
+			provider, err := NewSearchProvider(config.ToolWebSearch{
+				Provider: tt.provider,
+				BaseURL:  baseURL,
+				APIKey:   "secret",
+			}, server.Client())
+			require.NoError(t, err)
+			require.Equal(t, tt.provider, provider.Name())
+
+			results, err := provider.Search(t.Context(), "golang", 2)
+			require.NoError(t, err)
+			require.Equal(t, []SearchResult{
+				{Title: "Go", Link: "https://go.dev", Snippet: "The Go programming language", Position: 1},
+				{Title: "Go docs", Link: "https://go.dev/doc", Snippet: "Documentation", Position: 2},
+			}, results)
+		})
+	}
+}
+
+func TestSearchProviderErrors(t *testing.T) {
+	t.Parallel()
+
+	provider, err := NewSearchProvider(config.ToolWebSearch{}, nil)
+	require.NoError(t, err)
+	require.Equal(t, SearchProviderDuckDuckGo, provider.Name())
+
+	_, err = NewSearchProvider(config.ToolWebSearch{Provider: SearchProviderSearxNG}, nil)
+	require.ErrorContains(t, err, "requires a base_url")
+	_, err = NewSearchProvider(config.ToolWebSearch{Provider: SearchProviderBrave}, nil)
+	require.ErrorContains(t, err, "requires an api_key")
+	_, err = NewSearchProvider(config.ToolWebSearch{Provider: "altavista"}, nil)
+	require.ErrorContains(t, err, "unknown search provider")
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "invalid token", http.StatusUnauthorized)
+	}))
+	defer server.Close()
+
+	provider, err = NewSearchProvider(config.ToolWebSearch{Provider: SearchProviderBrave, BaseURL: server.URL, APIKey: "bad"}, server.Client())
+	require.NoError(t, err)
+	_, err = provider.Search(t.Context(), "golang", 5)
+	require.ErrorContains(t, err, "status code 401: invalid token")
+}
+
+func TestWebSearchTool(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, `{"results": [{"title": "Crush", "url": "https://charm.land", "content": "Glamourous coding"}]}`)
+	}))
+	defer server.Close()
+
+	tool := NewWebSearchTool(
+		&mockPermissionService{Broker: pubsub.NewBroker[permission.PermissionRequest]()},
+		t.TempDir(),
+		server.Client(),
+		func(client *http.Client) SearchProvider {
+			require.Equal(t, server.Client(), client)
+			provider, err := NewSearchProvider(config.ToolWebSearch{Provider: SearchProviderSearxNG, BaseURL: server.URL}, client)
+			require.NoError(t, err)
+			return provider
+		},
+	)
+
+	resp := runTool(t, tool, WebSearchParams{Query: "crush"})
+	require.False(t, resp.IsError, resp.Content)
+	require.Equal(t, "Found 1 search results:\n\n1. Crush\n   URL: https://charm.land\n   Summary: Glamourous coding\n\n", resp.Content)
+}
@@ -0,0 +1,143 @@
+package tools
+
+import (
+	"context"
+	_ "embed"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"charm.land/fantasy"
+	"github.com/charmbracelet/crush/internal/codeindex"
+)
+
+type SymbolsParams struct {
+	Query      string `json:"query,omitempty" description:"Name or part of the name of the symbols to find, matched without case. Use Type.method to find the methods of a type"`
+	Kind       string `json:"kind,omitempty" enum:"function,method,type,struct,interface,class,enum,trait,module,const,var" description:"Only return symbols of this kind"`
+	Path       string `json:"path,omitempty" description:"Only return symbols in this directory or file. With a file and no query, returns the outline of the file with its imports"`
+	ImportedBy string `json:"imported_by,omitempty" description:"Instead of symbols, list the files importing this package or module (e.g., github.com/foo/bar, react, os.path)"`
+}
+
+const (
+	SymbolsToolName = "symbols"
+
+	// maxSymbolResults is the maximum number of symbols the tool returns.
+	maxSymbolResults = 100
+)
+
+//go:embed symbols.md
+var symbolsDescription []byte
+
+func NewSymbolsTool(index *codeindex.Index, workingDir string) fantasy.AgentTool {
+	return fantasy.NewAgentTool(
+		SymbolsToolName,
+		string(symbolsDescription),
+		func(ctx context.Context, params SymbolsParams, call fantasy.ToolCall) (fantasy.ToolResponse, error) {
+			if err := index.Refresh(ctx); err != nil {
+				return fantasy.NewTextErrorResponse(fmt.Sprintf("failed to update the code index: %s", err)), nil
+			}
+
+			if params.ImportedBy != "" {
+				files := index.Importers(params.ImportedBy)
+				if len(files) == 0 {
+					return fantasy.NewTextResponse(fmt.Sprintf("No files import '%s'", params.ImportedBy)), nil
+				}
+				return fantasy.NewTextResponse(fmt.Sprintf("Found %d file(s) importing '%s':\n\n%s\n", len(files), params.ImportedBy, strings.Join(files, "\n"))), nil
+			}
+
+			var absPath, path string
+			if params.Path != "" {
+				absPath = params.Path
+				if !filepath.IsAbs(absPath) {
+					absPath = filepath.Join(workingDir, absPath)
+				}
+				var ok bool
+				path, ok = index.RelPath(absPath)
+				if !ok {
+					return fantasy.NewTextErrorResponse(fmt.Sprintf("path %s is outside the workspace", params.Path)), nil
+				}
+			}
+
+			if params.Query == "" && params.Kind == "" {
+				if info, err := os.Stat(absPath); path == "" || err != nil || info.IsDir() {
+					return fantasy.NewTextErrorResponse("query or kind is required unless path is a file"), nil
+				}
+				file, ok := index.File(path)
+				if !ok {
+					return fantasy.NewTextErrorResponse(fmt.Sprintf("%s is not indexed; only source files of the supported languages are", params.Path)), nil
+				}
+				return fantasy.NewTextResponse(formatFileOutline(path, file)), nil
+			}
+
+			symbols, total := index.Search(params.Query, codeindex.SearchOptions{
+				Kind:  params.Kind,
+				Path:  path,
+				Limit: maxSymbolResults,
+			})
+			if len(symbols) == 0 {
+				return fantasy.NewTextResponse(fmt.Sprintf("No symbols found for '%s'", params.Query)), nil
+			}
+			output := formatIndexSymbols(symbols)
+			if total > len(symbols) {
+				output += fmt.Sprintf("(Showing the %d best matches of %d. Use a more specific query, kind or path.)\n", len(symbols), total)
+			}
+			return fantasy.NewTextResponse(output), nil
+		})
+}
+
+// formatIndexSymbols formats the symbols grouped by file, keeping the order
+// of the files of the best matches first.
+func formatIndexSymbols(symbols []codeindex.Symbol) string {
+	byFile := make(map[string][]codeindex.Symbol)
+	var files []string
+	for _, symbol := range symbols {
+		if _, ok := byFile[symbol.Path]; !ok {
+			files = append(files, symbol.Path)
+		}
+		byFile[symbol.Path] = append(byFile[symbol.Path], symbol)
+	}
+
+	var output strings.Builder
+	output.WriteString(fmt.Sprintf("Found %d symbol(s) in %d file(s):\n\n", len(symbols), len(files)))
+	for _, file := range files {
+		fileSymbols := byFile[file]
+		output.WriteString(fmt.Sprintf("%s (%d symbol(s)):\n", file, len(fileSymbols)))
+		writeIndexSymbols(&output, fileSymbols)
+		output.WriteString("\n")
+	}
+	return output.String()
+}
+
+// formatFileOutline formats the package, imports and symbols of a file.
+func formatFileOutline(path string, file codeindex.File) string {
+	var output strings.Builder
+	output.WriteString(path + "\n")
+	if file.Package != "" {
+		output.WriteString(fmt.Sprintf("Package: %s\n", file.Package))
+	}
+	if len(file.Imports) > 0 {
+		imports := slices.Compact(slices.Sorted(slices.Values(file.Imports)))
+		output.WriteString(fmt.Sprintf("\nImports (%d):\n", len(imports)))
+		for _, imp := range imports {
+			output.WriteString("  " + imp + "\n")
+		}
+	}
+	output.WriteString(fmt.Sprintf("\nSymbols (%d):\n", len(file.Symbols)))
+	writeIndexSymbols(&output, file.Symbols)
+	return output.String()
+}
+
+func writeIndexSymbols(output *strings.Builder, symbols []codeindex.Symbol) {
+	symbols = slices.SortedStableFunc(slices.Values(symbols), func(a, b codeindex.Symbol) int {
+		return a.Line - b.Line
+	})
+	for _, symbol := range symbols {
+		output.WriteString(fmt.Sprintf("  Line %d: %s %s", symbol.Line, symbol.Kind, symbol.QualifiedName()))
+		if symbol.Signature != "" {
+			output.WriteString(" - " + symbol.Signature)
+		}
+		output.WriteString("\n")
+	}
+}
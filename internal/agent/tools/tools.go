@@ -8,6 +8,7 @@ type (
 	sessionIDContextKey string
 	messageIDContextKey string
 	supportsImagesKey   string
+	supportsPDFKey      string
 	modelNameKey        string
 )
 
@@ -18,6 +19,9 @@ const (
 	MessageIDContextKey messageIDContextKey = "message_id"
 	// SupportsImagesContextKey is the key for the model's image support capability.
 	SupportsImagesContextKey supportsImagesKey = "supports_images"
+	// SupportsPDFContextKey is the key for whether PDFs can be attached to
+	// the model's context.
+	SupportsPDFContextKey supportsPDFKey = "supports_pdf"
 	// ModelNameContextKey is the key for the model name in the context.
 	ModelNameContextKey modelNameKey = "model_name"
 )
@@ -60,6 +64,13 @@ func GetSupportsImagesFromContext(ctx context.Context) bool {
 	return false
 }
 
+// GetSupportsPDFFromContext retrieves whether PDFs can be attached to the
+// model's context.
+func GetSupportsPDFFromContext(ctx context.Context) bool {
+	supports, _ := ctx.Value(SupportsPDFContextKey).(bool)
+	return supports
+}
+
 // GetModelNameFromContext retrieves the model name from the context.
 func GetModelNameFromContext(ctx context.Context) string {
 	modelName := ctx.Value(ModelNameContextKey)
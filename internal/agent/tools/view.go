@@ -13,6 +13,7 @@ import (
 	"unicode/utf8"
 
 	"charm.land/fantasy"
+	"github.com/charmbracelet/crush/internal/config"
 	"github.com/charmbracelet/crush/internal/csync"
 	"github.com/charmbracelet/crush/internal/filepathext"
 	"github.com/charmbracelet/crush/internal/filetracker"
@@ -27,12 +28,14 @@ type ViewParams struct {
 	FilePath string `json:"file_path" description:"The path to the file to read"`
 	Offset   int    `json:"offset,omitempty" description:"The line number to start reading from (0-based)"`
 	Limit    int    `json:"limit,omitempty" description:"The number of lines to read (defaults to 2000)"`
+	Pages    string `json:"pages,omitempty" description:"The pages of a PDF to read, such as 3 or 1-5 (defaults to the first 20 pages)"`
 }
 
 type ViewPermissionsParams struct {
 	FilePath string `json:"file_path"`
 	Offset   int    `json:"offset"`
 	Limit    int    `json:"limit"`
+	Pages    string `json:"pages"`
 }
 
 type ViewResponseMetadata struct {
@@ -47,7 +50,7 @@ const (
 	MaxLineLength    = 2000
 )
 
-func NewViewTool(lspClients *csync.Map[string, *lsp.Client], permissions permission.Service, workingDir string, skillsPaths ...string) fantasy.AgentTool {
+func NewViewTool(lspClients *csync.Map[string, *lsp.Client], permissions permission.Service, workingDir string, additionalDirs []string, skillsPaths ...string) fantasy.AgentTool {
 	return fantasy.NewAgentTool(
 		ViewToolName,
 		string(viewDescription),
@@ -59,19 +62,14 @@ func NewViewTool(lspClients *csync.Map[string, *lsp.Client], permissions permiss
 			// Handle relative paths
 			filePath := filepathext.SmartJoin(workingDir, params.FilePath)
 
-			// Check if file is outside working directory and request permission if needed
-			absWorkingDir, err := filepath.Abs(workingDir)
-			if err != nil {
-				return fantasy.ToolResponse{}, fmt.Errorf("error resolving working directory: %w", err)
-			}
-
+			// Check if file is outside the working and additional directories
+			// and request permission if needed
 			absFilePath, err := filepath.Abs(filePath)
 			if err != nil {
 				return fantasy.ToolResponse{}, fmt.Errorf("error resolving file path: %w", err)
 			}
 
-			relPath, err := filepath.Rel(absWorkingDir, absFilePath)
-			isOutsideWorkDir := err != nil || strings.HasPrefix(relPath, "..")
+			isOutsideWorkDir := !config.IsInRoots(absFilePath, append([]string{workingDir}, additionalDirs...)...)
 			isSkillFile := isInSkillsPath(absFilePath, skillsPaths)
 
 			// Request permission for files outside working directory, unless it's a skill file.
@@ -137,10 +135,16 @@ func NewViewTool(lspClients *csync.Map[string, *lsp.Client], permissions permiss
 				return fantasy.NewTextErrorResponse(fmt.Sprintf("Path is a directory, not a file: %s", filePath)), nil
 			}
 
+			documentKind := getDocumentKind(filePath)
+			maxReadSize := int64(MaxReadSize)
+			if documentKind != "" {
+				maxReadSize = MaxDocumentReadSize
+			}
+
 			// Based on the specifications we should not limit the skills read.
-			if !isSkillFile && fileInfo.Size() > MaxReadSize {
+			if !isSkillFile && fileInfo.Size() > maxReadSize {
 				return fantasy.NewTextErrorResponse(fmt.Sprintf("File is too large (%d bytes). Maximum size is %d bytes",
-					fileInfo.Size(), MaxReadSize)), nil
+					fileInfo.Size(), maxReadSize)), nil
 			}
 
 			// Set default limit if not provided (no limit for SKILL.md files)
@@ -168,6 +172,10 @@ func NewViewTool(lspClients *csync.Map[string, *lsp.Client], permissions permiss
 				return fantasy.NewImageResponse([]byte(encoded), mimeType), nil
 			}
 
+			if documentKind != "" {
+				return viewDocument(ctx, documentKind, filePath, params)
+			}
+
 			// Read the file content
 			content, lineCount, err := readTextFile(filePath, params.Offset, params.Limit)
 			isValidUt8 := utf8.ValidString(content)
@@ -189,7 +197,7 @@ func NewViewTool(lspClients *csync.Map[string, *lsp.Client], permissions permiss
 					params.Offset+len(strings.Split(content, "\n")))
 			}
 			output += "\n</file>\n"
-			output += getDiagnostics(filePath, lspClients)
+			output += getDiagnostics(ctx, filePath, lspClients)
 			filetracker.RecordRead(filePath)
 			return fantasy.WithResponseMetadata(
 				fantasy.NewTextResponse(output),
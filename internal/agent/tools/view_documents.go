@@ -0,0 +1,567 @@
+package tools
+
+import (
+	"archive/zip"
+	"cmp"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"charm.land/fantasy"
+	"github.com/charmbracelet/crush/internal/filetracker"
+	"github.com/ledongthuc/pdf"
+)
+
+const (
+	// MaxDocumentReadSize is the maximum size of the documents whose text is
+	// extracted, larger than MaxReadSize as most of their content is markup
+	// or compressed.
+	MaxDocumentReadSize = 50 * 1024 * 1024 // 50MB
+
+	// DefaultPDFPages is the number of pages read from a PDF when no pages
+	// are given.
+	DefaultPDFPages = 20
+)
+
+var errInvalidPages = errors.New("invalid pages")
+
+type documentKind string
+
+const (
+	documentPDF      documentKind = "pdf"
+	documentNotebook documentKind = "notebook"
+	documentDocx     documentKind = "docx"
+	documentXlsx     documentKind = "xlsx"
+)
+
+// getDocumentKind returns the kind of document the view tool extracts the
+// text of, or an empty string for other files.
+func getDocumentKind(filePath string) documentKind {
+	switch strings.ToLower(filepath.Ext(filePath)) {
+	case ".pdf":
+		return documentPDF
+	case ".ipynb":
+		return documentNotebook
+	case ".docx":
+		return documentDocx
+	case ".xlsx":
+		return documentXlsx
+	default:
+		return ""
+	}
+}
+
+// readDocument returns the text of a document. For PDFs, pages selects the
+// pages to read, such as "3" or "1-5", and note tells which pages were read
+// when it isn't the whole document.
+func readDocument(kind documentKind, filePath, pages string) (text, note string, err error) {
+	switch kind {
+	case documentPDF:
+		return readPDF(filePath, pages)
+	case documentNotebook:
+		data, err := os.ReadFile(filePath)
+		if err != nil {
+			return "", "", err
+		}
+		text, err = renderNotebook(data)
+		return text, "", err
+	case documentDocx:
+		text, err = readDocx(filePath)
+		return text, "", err
+	case documentXlsx:
+		text, err = readXlsx(filePath)
+		return text, "", err
+	default:
+		return "", "", fmt.Errorf("unsupported document: %s", filePath)
+	}
+}
+
+// paginateLines returns the lines of the text from offset, at most limit of
+// them, along with the total number of lines.
+func paginateLines(text string, offset, limit int) (string, int) {
+	lines := strings.Split(strings.TrimRight(text, "\n"), "\n")
+	if offset >= len(lines) {
+		return "", len(lines)
+	}
+	end := min(offset+limit, len(lines))
+	selected := make([]string, 0, end-offset)
+	for _, line := range lines[offset:end] {
+		if len(line) > MaxLineLength {
+			line = line[:MaxLineLength] + "..."
+		}
+		selected = append(selected, line)
+	}
+	return strings.Join(selected, "\n"), len(lines)
+}
+
+// parsePageRange parses a page range such as "3", "1-5" or "4-" of a
+// document with the given number of pages.
+func parsePageRange(pages string, total int) (first, last int, err error) {
+	pages = strings.TrimSpace(pages)
+	start, end, isRange := strings.Cut(pages, "-")
+	if first, err = strconv.Atoi(strings.TrimSpace(start)); err != nil || first < 1 {
+		return 0, 0, fmt.Errorf("%w %q: use a page number or a range such as 1-5", errInvalidPages, pages)
+	}
+	last = first
+	if isRange {
+		last = total
+		if end = strings.TrimSpace(end); end != "" {
+			if last, err = strconv.Atoi(end); err != nil || last < first {
+				return 0, 0, fmt.Errorf("%w %q: use a page number or a range such as 1-5", errInvalidPages, pages)
+			}
+		}
+	}
+	if first > total {
+		return 0, 0, fmt.Errorf("%w: page %d is out of range, the document has %d page(s)", errInvalidPages, first, total)
+	}
+	return first, min(last, total), nil
+}
+
+func readPDF(filePath, pages string) (text, note string, err error) {
+	// The PDF parser panics on some malformed documents.
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("malformed PDF: %v", r)
+		}
+	}()
+
+	f, reader, err := pdf.Open(filePath)
+	if err != nil {
+		return "", "", err
+	}
+	defer f.Close()
+
+	total := reader.NumPage()
+	if total == 0 {
+		return "", "", errors.New("the PDF has no pages")
+	}
+	first, last := 1, min(total, DefaultPDFPages)
+	if pages != "" {
+		if first, last, err = parsePageRange(pages, total); err != nil {
+			return "", "", err
+		}
+	}
+
+	var sb strings.Builder
+	hasText := false
+	for num := first; num <= last; num++ {
+		page := reader.Page(num)
+		if page.V.IsNull() {
+			continue
+		}
+		pageText, err := page.GetPlainText(nil)
+		if err != nil {
+			return "", "", fmt.Errorf("error extracting the text of page %d: %w", num, err)
+		}
+		pageText = strings.TrimSpace(pageText)
+		hasText = hasText || pageText != ""
+		fmt.Fprintf(&sb, "--- Page %d ---\n%s\n\n", num, pageText)
+	}
+	if !hasText {
+		return "", "", errors.New("the PDF has no extractable text; it may be scanned")
+	}
+
+	if first != 1 || last != total {
+		note = fmt.Sprintf("Showing pages %d-%d of %d. Use the 'pages' parameter to read other pages.", first, last, total)
+	}
+	return sb.String(), note, nil
+}
+
+// notebookText is text of a notebook, stored either as a string or as a list
+// of lines.
+type notebookText string
+
+func (t *notebookText) UnmarshalJSON(data []byte) error {
+	var lines []string
+	if err := json.Unmarshal(data, &lines); err == nil {
+		*t = notebookText(strings.Join(lines, ""))
+		return nil
+	}
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	*t = notebookText(s)
+	return nil
+}
+
+type notebookOutput struct {
+	OutputType string                     `json:"output_type"`
+	Name       string                     `json:"name"`
+	Text       notebookText               `json:"text"`
+	Data       map[string]json.RawMessage `json:"data"`
+	EName      string                     `json:"ename"`
+	EValue     string                     `json:"evalue"`
+	Traceback  []string                   `json:"traceback"`
+}
+
+type notebookCell struct {
+	ID             string           `json:"id"`
+	CellType       string           `json:"cell_type"`
+	Source         notebookText     `json:"source"`
+	ExecutionCount *int             `json:"execution_count"`
+	Outputs        []notebookOutput `json:"outputs"`
+}
+
+type notebookContent struct {
+	Cells    []notebookCell `json:"cells"`
+	Metadata struct {
+		KernelSpec struct {
+			Language string `json:"language"`
+		} `json:"kernelspec"`
+		LanguageInfo struct {
+			Name string `json:"name"`
+		} `json:"language_info"`
+	} `json:"metadata"`
+}
+
+var ansiEscape = regexp.MustCompile(`\x1b\[[0-9;]*[A-Za-z]`)
+
+// renderNotebook renders the cells of a Jupyter notebook with their outputs.
+func renderNotebook(data []byte) (string, error) {
+	var nb notebookContent
+	if err := json.Unmarshal(data, &nb); err != nil {
+		return "", fmt.Errorf("invalid notebook: %w", err)
+	}
+
+	var sb strings.Builder
+	language := cmp.Or(nb.Metadata.LanguageInfo.Name, nb.Metadata.KernelSpec.Language)
+	if language != "" {
+		fmt.Fprintf(&sb, "Notebook language: %s\n\n", language)
+	}
+	for i, cell := range nb.Cells {
+		sb.WriteString(renderNotebookCell(i, cell))
+		sb.WriteString("\n")
+	}
+	return sb.String(), nil
+}
+
+// renderNotebookCell renders the cell of a notebook at the given index with
+// its outputs.
+func renderNotebookCell(index int, cell notebookCell) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "<cell index=%d", index)
+	if cell.ID != "" {
+		fmt.Fprintf(&sb, " id=%q", cell.ID)
+	}
+	fmt.Fprintf(&sb, " type=%q", cell.CellType)
+	if cell.ExecutionCount != nil {
+		fmt.Fprintf(&sb, " execution_count=%d", *cell.ExecutionCount)
+	}
+	sb.WriteString(">\n")
+	sb.WriteString(strings.TrimRight(string(cell.Source), "\n"))
+	sb.WriteString("\n")
+	for _, output := range cell.Outputs {
+		if text := renderNotebookOutput(output); text != "" {
+			sb.WriteString("<output>\n")
+			sb.WriteString(strings.TrimRight(text, "\n"))
+			sb.WriteString("\n</output>\n")
+		}
+	}
+	sb.WriteString("</cell>\n")
+	return sb.String()
+}
+
+func renderNotebookOutput(output notebookOutput) string {
+	switch output.OutputType {
+	case "stream":
+		return string(output.Text)
+	case "error":
+		if len(output.Traceback) > 0 {
+			return ansiEscape.ReplaceAllString(strings.Join(output.Traceback, "\n"), "")
+		}
+		return fmt.Sprintf("%s: %s", output.EName, output.EValue)
+	case "execute_result", "display_data":
+		if raw, ok := output.Data["text/plain"]; ok {
+			var text notebookText
+			if err := json.Unmarshal(raw, &text); err == nil {
+				return string(text)
+			}
+		}
+		for mimeType := range output.Data {
+			return fmt.Sprintf("[%s output]", mimeType)
+		}
+	}
+	return ""
+}
+
+// readDocx returns the text of the paragraphs of a Word document.
+func readDocx(filePath string) (string, error) {
+	r, err := zip.OpenReader(filePath)
+	if err != nil {
+		return "", fmt.Errorf("invalid docx file: %w", err)
+	}
+	defer r.Close()
+
+	f, err := openZipFile(&r.Reader, "word/document.xml")
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	var sb strings.Builder
+	decoder := xml.NewDecoder(f)
+	inText := false
+	for {
+		token, err := decoder.Token()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return "", fmt.Errorf("invalid docx file: %w", err)
+		}
+		switch t := token.(type) {
+		case xml.StartElement:
+			switch t.Name.Local {
+			case "t":
+				inText = true
+			case "tab":
+				sb.WriteString("\t")
+			case "br", "cr":
+				sb.WriteString("\n")
+			}
+		case xml.EndElement:
+			switch t.Name.Local {
+			case "t":
+				inText = false
+			case "p":
+				sb.WriteString("\n")
+			case "tc":
+				sb.WriteString("\t")
+			}
+		case xml.CharData:
+			if inText {
+				sb.Write(t)
+			}
+		}
+	}
+	return sb.String(), nil
+}
+
+// readXlsx returns the cells of every sheet of an Excel workbook, one row per
+// line with tab-separated values.
+func readXlsx(filePath string) (string, error) {
+	r, err := zip.OpenReader(filePath)
+	if err != nil {
+		return "", fmt.Errorf("invalid xlsx file: %w", err)
+	}
+	defer r.Close()
+
+	sharedStrings, err := readSharedStrings(&r.Reader)
+	if err != nil {
+		return "", err
+	}
+	sheets, err := readWorkbookSheets(&r.Reader)
+	if err != nil {
+		return "", err
+	}
+
+	var sb strings.Builder
+	for _, sheet := range sheets {
+		rows, err := readWorksheet(&r.Reader, sheet.path, sharedStrings)
+		if err != nil {
+			return "", err
+		}
+		fmt.Fprintf(&sb, "--- Sheet %s ---\n", sheet.name)
+		for _, row := range rows {
+			sb.WriteString(strings.Join(row, "\t"))
+			sb.WriteString("\n")
+		}
+		sb.WriteString("\n")
+	}
+	return sb.String(), nil
+}
+
+type xlsxSheet struct {
+	name string
+	path string
+}
+
+func readWorkbookSheets(r *zip.Reader) ([]xlsxSheet, error) {
+	var workbook struct {
+		Sheets []struct {
+			Name string `xml:"name,attr"`
+			ID   string `xml:"http://schemas.openxmlformats.org/officeDocument/2006/relationships id,attr"`
+		} `xml:"sheets>sheet"`
+	}
+	if err := decodeZipXML(r, "xl/workbook.xml", &workbook); err != nil {
+		return nil, err
+	}
+	var rels struct {
+		Relationships []struct {
+			ID     string `xml:"Id,attr"`
+			Target string `xml:"Target,attr"`
+		} `xml:"Relationship"`
+	}
+	if err := decodeZipXML(r, "xl/_rels/workbook.xml.rels", &rels); err != nil {
+		return nil, err
+	}
+	targets := make(map[string]string, len(rels.Relationships))
+	for _, rel := range rels.Relationships {
+		target := strings.TrimPrefix(rel.Target, "/")
+		if !strings.HasPrefix(target, "xl/") {
+			target = path.Join("xl", target)
+		}
+		targets[rel.ID] = target
+	}
+
+	sheets := make([]xlsxSheet, 0, len(workbook.Sheets))
+	for _, sheet := range workbook.Sheets {
+		if target, ok := targets[sheet.ID]; ok {
+			sheets = append(sheets, xlsxSheet{name: sheet.Name, path: target})
+		}
+	}
+	return sheets, nil
+}
+
+func readSharedStrings(r *zip.Reader) ([]string, error) {
+	var sst struct {
+		Items []struct {
+			Text string `xml:"t"`
+			Runs []struct {
+				Text string `xml:"t"`
+			} `xml:"r"`
+		} `xml:"si"`
+	}
+	if err := decodeZipXML(r, "xl/sharedStrings.xml", &sst); errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	strs := make([]string, len(sst.Items))
+	for i, item := range sst.Items {
+		text := item.Text
+		for _, run := range item.Runs {
+			text += run.Text
+		}
+		strs[i] = text
+	}
+	return strs, nil
+}
+
+func readWorksheet(r *zip.Reader, name string, sharedStrings []string) ([][]string, error) {
+	var sheet struct {
+		Rows []struct {
+			Cells []struct {
+				Ref    string `xml:"r,attr"`
+				Type   string `xml:"t,attr"`
+				Value  string `xml:"v"`
+				Inline string `xml:"is>t"`
+			} `xml:"c"`
+		} `xml:"sheetData>row"`
+	}
+	if err := decodeZipXML(r, name, &sheet); err != nil {
+		return nil, err
+	}
+
+	rows := make([][]string, 0, len(sheet.Rows))
+	for _, row := range sheet.Rows {
+		var values []string
+		for _, cell := range row.Cells {
+			value := cell.Value
+			switch cell.Type {
+			case "s":
+				if idx, err := strconv.Atoi(value); err == nil && idx >= 0 && idx < len(sharedStrings) {
+					value = sharedStrings[idx]
+				}
+			case "inlineStr":
+				value = cell.Inline
+			case "b":
+				value = strconv.FormatBool(value == "1")
+			}
+			// Keep values in their column when previous cells are empty.
+			if col := columnIndex(cell.Ref); col > len(values) {
+				values = append(values, make([]string, col-len(values))...)
+			}
+			values = append(values, strings.ReplaceAll(value, "\n", " "))
+		}
+		rows = append(rows, values)
+	}
+	return rows, nil
+}
+
+// columnIndex returns the 0-based column of a cell reference such as "C12".
+func columnIndex(ref string) int {
+	col := 0
+	for _, c := range ref {
+		if c < 'A' || c > 'Z' {
+			break
+		}
+		col = col*26 + int(c-'A'+1)
+	}
+	return col - 1
+}
+
+func openZipFile(r *zip.Reader, name string) (io.ReadCloser, error) {
+	for _, f := range r.File {
+		if f.Name == name {
+			return f.Open()
+		}
+	}
+	return nil, fmt.Errorf("%s not found in the document: %w", name, os.ErrNotExist)
+}
+
+func decodeZipXML(r *zip.Reader, name string, v any) error {
+	f, err := openZipFile(r, name)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if err := xml.NewDecoder(f).Decode(v); err != nil {
+		return fmt.Errorf("invalid %s: %w", name, err)
+	}
+	return nil
+}
+
+// viewDocument returns the text of a document with line numbers, or the
+// document itself when its text can't be extracted and the model can read it.
+func viewDocument(ctx context.Context, kind documentKind, filePath string, params ViewParams) (fantasy.ToolResponse, error) {
+	text, note, err := readDocument(kind, filePath, params.Pages)
+	if err != nil {
+		if kind == documentPDF && !errors.Is(err, errInvalidPages) && GetSupportsPDFFromContext(ctx) {
+			// The PDF itself is sent to the model, so it's held to the same
+			// limit as images rather than MaxDocumentReadSize.
+			if info, statErr := os.Stat(filePath); statErr == nil && info.Size() > MaxReadSize {
+				return fantasy.NewTextErrorResponse(fmt.Sprintf("Could not read the text of %s (%s) and the PDF is too large to attach (%d bytes). Maximum size is %d bytes",
+					filePath, err, info.Size(), MaxReadSize)), nil
+			}
+			data, readErr := os.ReadFile(filePath)
+			if readErr != nil {
+				return fantasy.ToolResponse{}, fmt.Errorf("error reading file: %w", readErr)
+			}
+			filetracker.RecordRead(filePath)
+			encoded := base64.StdEncoding.EncodeToString(data)
+			return fantasy.NewMediaResponse([]byte(encoded), "application/pdf"), nil
+		}
+		return fantasy.NewTextErrorResponse(fmt.Sprintf("Could not read the text of %s: %s", filePath, err)), nil
+	}
+
+	content, lineCount := paginateLines(text, params.Offset, params.Limit)
+	output := "<file>\n"
+	output += addLineNumbers(content, params.Offset+1)
+	if shown := params.Offset + len(strings.Split(content, "\n")); lineCount > shown {
+		output += fmt.Sprintf("\n\n(Document has more lines. Use 'offset' parameter to read beyond line %d)", shown)
+	}
+	if note != "" {
+		output += "\n\n(" + note + ")"
+	}
+	output += "\n</file>\n"
+	filetracker.RecordRead(filePath)
+	return fantasy.WithResponseMetadata(
+		fantasy.NewTextResponse(output),
+		ViewResponseMetadata{
+			FilePath: filePath,
+			Content:  content,
+		},
+	), nil
+}
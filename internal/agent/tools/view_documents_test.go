@@ -0,0 +1,186 @@
+package tools
+
+import (
+	"archive/zip"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParsePageRange(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		pages       string
+		first, last int
+		err         string
+	}{
+		{pages: "3", first: 3, last: 3},
+		{pages: "2-4", first: 2, last: 4},
+		{pages: "8-", first: 8, last: 10},
+		{pages: "5-50", first: 5, last: 10},
+		{pages: "11", err: "out of range"},
+		{pages: "4-2", err: "invalid pages"},
+		{pages: "0", err: "invalid pages"},
+		{pages: "first", err: "invalid pages"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.pages, func(t *testing.T) {
+			t.Parallel()
+
+			first, last, err := parsePageRange(tt.pages, 10)
+			if tt.err != "" {
+				require.ErrorContains(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.first, first)
+			require.Equal(t, tt.last, last)
+		})
+	}
+}
+
+func TestRenderNotebook(t *testing.T) {
+	t.Parallel()
+
+	notebook := `{
+  "cells": [
+    {"cell_type": "markdown", "id": "intro", "metadata": {}, "source": ["# Title\n", "Some text"]},
+    {
+      "cell_type": "code", "id": "sum", "execution_count": 2, "metadata": {},
+      "source": "print(1 + 1)\n1 + 2",
+      "outputs": [
+        {"output_type": "stream", "name": "stdout", "text": ["2\n"]},
+        {"output_type": "execute_result", "execution_count": 2, "data": {"text/plain": ["3"]}, "metadata": {}},
+        {"output_type": "display_data", "data": {"image/png": "iVBORw0KGgo="}, "metadata": {}}
+      ]
+    },
+    {
+      "cell_type": "code", "execution_count": null, "metadata": {}, "source": "1 / 0",
+      "outputs": [{"output_type": "error", "ename": "ZeroDivisionError", "evalue": "division by zero", "traceback": ["\u001b[0;31mZeroDivisionError\u001b[0m: division by zero"]}]
+    }
+  ],
+  "metadata": {"kernelspec": {"language": "python"}},
+  "nbformat": 4,
+  "nbformat_minor": 5
+}`
+
+	text, err := renderNotebook([]byte(notebook))
+	require.NoError(t, err)
+	require.Equal(t, `Notebook language: python
+
+<cell index=0 id="intro" type="markdown">
+# Title
+Some text
+</cell>
+
+<cell index=1 id="sum" type="code" execution_count=2>
+print(1 + 1)
+1 + 2
+<output>
+2
+</output>
+<output>
+3
+</output>
+<output>
+[image/png output]
+</output>
+</cell>
+
+<cell index=2 type="code">
+1 / 0
+<output>
+ZeroDivisionError: division by zero
+</output>
+</cell>
+
+`, text)
+
+	_, err = renderNotebook([]byte("not json"))
+	require.ErrorContains(t, err, "invalid notebook")
+}
+
+func TestReadDocx(t *testing.T) {
+	t.Parallel()
+
+	path := writeZip(t, "design.docx", map[string]string{
+		"word/document.xml": `<?xml version="1.0" encoding="UTF-8"?>
+<w:document xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main">
+  <w:body>
+    <w:p><w:r><w:t>Design</w:t></w:r><w:r><w:t xml:space="preserve"> doc</w:t></w:r></w:p>
+    <w:p><w:r><w:t>Goals:</w:t><w:tab/><w:t>fast</w:t></w:r></w:p>
+  </w:body>
+</w:document>`,
+	})
+
+	text, err := readDocx(path)
+	require.NoError(t, err)
+	require.Equal(t, "Design doc\nGoals:\tfast\n", text)
+}
+
+func TestReadXlsx(t *testing.T) {
+	t.Parallel()
+
+	path := writeZip(t, "data.xlsx", map[string]string{
+		"xl/workbook.xml": `<?xml version="1.0" encoding="UTF-8"?>
+<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">
+  <sheets><sheet name="Budget" sheetId="1" r:id="rId1"/></sheets>
+</workbook>`,
+		"xl/_rels/workbook.xml.rels": `<?xml version="1.0" encoding="UTF-8"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+  <Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/>
+</Relationships>`,
+		"xl/sharedStrings.xml": `<?xml version="1.0" encoding="UTF-8"?>
+<sst xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">
+  <si><t>Item</t></si>
+  <si><t>Cost</t></si>
+  <si><r><t>Ser</t></r><r><t>vers</t></r></si>
+</sst>`,
+		"xl/worksheets/sheet1.xml": `<?xml version="1.0" encoding="UTF-8"?>
+<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">
+  <sheetData>
+    <row r="1"><c r="A1" t="s"><v>0</v></c><c r="B1" t="s"><v>1</v></c></row>
+    <row r="2"><c r="A2" t="s"><v>2</v></c><c r="C2"><v>1200</v></c></row>
+    <row r="3"><c r="A3" t="inlineStr"><is><t>Paid</t></is></c><c r="B3" t="b"><v>1</v></c></row>
+  </sheetData>
+</worksheet>`,
+	})
+
+	text, err := readXlsx(path)
+	require.NoError(t, err)
+	require.Equal(t, "--- Sheet Budget ---\nItem\tCost\nServers\t\t1200\nPaid\ttrue\n\n", text)
+}
+
+func TestPaginateLines(t *testing.T) {
+	t.Parallel()
+
+	content, total := paginateLines("a\nb\nc\nd\n", 1, 2)
+	require.Equal(t, "b\nc", content)
+	require.Equal(t, 4, total)
+
+	content, total = paginateLines("a\nb\n", 5, 2)
+	require.Empty(t, content)
+	require.Equal(t, 2, total)
+}
+
+func writeZip(t *testing.T, name string, files map[string]string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), name)
+	f, err := os.Create(path)
+	require.NoError(t, err)
+	defer f.Close()
+
+	w := zip.NewWriter(f)
+	for name, content := range files {
+		fw, err := w.Create(name)
+		require.NoError(t, err)
+		_, err = fw.Write([]byte(content))
+		require.NoError(t, err)
+	}
+	require.NoError(t, w.Close())
+	return path
+}
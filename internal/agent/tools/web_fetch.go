@@ -16,7 +16,7 @@ import (
 var webFetchToolDescription []byte
 
 // NewWebFetchTool creates a simple web fetch tool for sub-agents (no permissions needed).
-func NewWebFetchTool(workingDir string, client *http.Client) fantasy.AgentTool {
+func NewWebFetchTool(workingDir string, client *http.Client, cache *FetchCache) fantasy.AgentTool {
 	if client == nil {
 		client = &http.Client{
 			Timeout: 30 * time.Second,
@@ -36,11 +36,42 @@ func NewWebFetchTool(workingDir string, client *http.Client) fantasy.AgentTool {
 				return fantasy.NewTextErrorResponse("url is required"), nil
 			}
 
-			content, err := FetchURLAndConvert(ctx, client, params.URL)
+			page, err := fetchPage(ctx, client, cache, params.URL, params.Refresh)
 			if err != nil {
 				return fantasy.NewTextErrorResponse(fmt.Sprintf("Failed to fetch URL: %s", err)), nil
 			}
 
+			isHTML := strings.Contains(page.ContentType, "text/html")
+			narrowed := params.Section != "" || params.Selector != ""
+			var content string
+			if narrowed {
+				fragment, err := narrowPage(page, params.Section, params.Selector)
+				if err != nil {
+					return fantasy.NewTextErrorResponse(err.Error()), nil
+				}
+				content, err = convertToMarkdown(fragment, page.ContentType)
+				if err != nil {
+					return fantasy.NewTextErrorResponse(fmt.Sprintf("Failed to fetch URL: %s", err)), nil
+				}
+			} else {
+				content, err = pageMarkdown(cache, page)
+				if err != nil {
+					return fantasy.NewTextErrorResponse(fmt.Sprintf("Failed to fetch URL: %s", err)), nil
+				}
+			}
+
+			if params.Page > 0 {
+				content, note, err := paginateContent(content, params.Page, nil)
+				if err != nil {
+					return fantasy.NewTextErrorResponse(err.Error()), nil
+				}
+				result := fmt.Sprintf("Fetched content from %s:\n\n%s", params.URL, content)
+				if note != "" {
+					result += "\n\n" + note
+				}
+				return fantasy.NewTextResponse(result), nil
+			}
+
 			hasLargeContent := len(content) > LargeContentThreshold
 			var result strings.Builder
 
@@ -59,9 +90,18 @@ func NewWebFetchTool(workingDir string, client *http.Client) fantasy.AgentTool {
 					return fantasy.NewTextErrorResponse(fmt.Sprintf("Failed to close temporary file: %s", err)), nil
 				}
 
-				result.WriteString(fmt.Sprintf("Fetched content from %s (large page)\n\n", params.URL))
+				pages := splitPages(content, FetchPageSize)
+				result.WriteString(fmt.Sprintf("Fetched content from %s (large page, %d pages)\n\n", params.URL, len(pages)))
 				result.WriteString(fmt.Sprintf("Content saved to: %s\n\n", tempFilePath))
-				result.WriteString("Use the view and grep tools to analyze this file.")
+				if isHTML && !narrowed {
+					if doc, err := parseFetchedHTML(page.Body); err == nil {
+						if toc := tableOfContents(pageHeadings(doc), pages); toc != "" {
+							result.WriteString(toc)
+							result.WriteString("\n")
+						}
+					}
+				}
+				result.WriteString("Use the view and grep tools to analyze this file, or fetch a single section or page with the section and page parameters.")
 			} else {
 				result.WriteString(fmt.Sprintf("Fetched content from %s:\n\n", params.URL))
 				result.WriteString(content)
@@ -3,27 +3,35 @@ package tools
 import (
 	"context"
 	_ "embed"
+	"fmt"
 	"log/slog"
 	"net/http"
-	"time"
 
 	"charm.land/fantasy"
+	"github.com/charmbracelet/crush/internal/permission"
 )
 
 //go:embed web_search.md
 var webSearchToolDescription []byte
 
-// NewWebSearchTool creates a web search tool for sub-agents (no permissions needed).
-func NewWebSearchTool(client *http.Client) fantasy.AgentTool {
+// WebSearchPermissionsParams defines the permission parameters for the
+// web_search tool.
+type WebSearchPermissionsParams struct {
+	Query      string `json:"query"`
+	MaxResults int    `json:"max_results,omitempty"`
+	Provider   string `json:"provider"`
+}
+
+// NewWebSearchTool creates a web search tool using the search provider
+// returned by newProvider for the tool's client, or DuckDuckGo if
+// newProvider is nil.
+func NewWebSearchTool(permissions permission.Service, workingDir string, client *http.Client, newProvider func(*http.Client) SearchProvider) fantasy.AgentTool {
 	if client == nil {
-		client = &http.Client{
-			Timeout: 30 * time.Second,
-			Transport: &http.Transport{
-				MaxIdleConns:        100,
-				MaxIdleConnsPerHost: 10,
-				IdleConnTimeout:     90 * time.Second,
-			},
-		}
+		client = newSearchClient()
+	}
+	var provider SearchProvider = duckDuckGoProvider{client: client}
+	if newProvider != nil {
+		provider = newProvider(client)
 	}
 
 	return fantasy.NewParallelAgentTool(
@@ -42,9 +50,35 @@ func NewWebSearchTool(client *http.Client) fantasy.AgentTool {
 				maxResults = 20
 			}
 
-			maybeDelaySearch()
-			results, err := searchDuckDuckGo(ctx, client, params.Query, maxResults)
-			slog.Debug("Web search completed", "query", params.Query, "results", len(results), "err", err)
+			sessionID := GetSessionFromContext(ctx)
+			if sessionID == "" {
+				return fantasy.ToolResponse{}, fmt.Errorf("session ID is required for searching the web")
+			}
+
+			p, err := permissions.Request(ctx,
+				permission.CreatePermissionRequest{
+					SessionID:   sessionID,
+					Path:        workingDir,
+					ToolCallID:  call.ID,
+					ToolName:    WebSearchToolName,
+					Action:      "search",
+					Description: fmt.Sprintf("Search the web with %s: %s", provider.Name(), params.Query),
+					Params: WebSearchPermissionsParams{
+						Query:      params.Query,
+						MaxResults: params.MaxResults,
+						Provider:   provider.Name(),
+					},
+				},
+			)
+			if err != nil {
+				return fantasy.ToolResponse{}, err
+			}
+			if !p {
+				return fantasy.ToolResponse{}, permission.ErrorPermissionDenied
+			}
+
+			results, err := provider.Search(ctx, params.Query, maxResults)
+			slog.Debug("Web search completed", "provider", provider.Name(), "query", params.Query, "results", len(results), "err", err)
 			if err != nil {
 				return fantasy.NewTextErrorResponse("Failed to search: " + err.Error()), nil
 			}
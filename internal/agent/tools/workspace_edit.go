@@ -0,0 +1,258 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"charm.land/fantasy"
+	"github.com/charmbracelet/crush/internal/csync"
+	"github.com/charmbracelet/crush/internal/diff"
+	"github.com/charmbracelet/crush/internal/filetracker"
+	"github.com/charmbracelet/crush/internal/fsext"
+	"github.com/charmbracelet/crush/internal/history"
+	"github.com/charmbracelet/crush/internal/lsp"
+	"github.com/charmbracelet/crush/internal/lsp/util"
+	"github.com/charmbracelet/crush/internal/permission"
+	"github.com/charmbracelet/x/powernap/pkg/lsp/protocol"
+)
+
+// WorkspaceEditFile is the change a workspace edit makes to a single file.
+type WorkspaceEditFile struct {
+	FilePath   string `json:"file_path"`
+	OldContent string `json:"old_content,omitempty"`
+	NewContent string `json:"new_content,omitempty"`
+	Created    bool   `json:"created,omitempty"`
+	Deleted    bool   `json:"deleted,omitempty"`
+	Additions  int    `json:"additions"`
+	Removals   int    `json:"removals"`
+}
+
+// WorkspaceEditPermissionsParams are the params of a permission request to
+// apply a workspace edit computed by an LSP server or a patch, which may
+// change several files.
+type WorkspaceEditPermissionsParams struct {
+	Description string              `json:"description"`
+	Files       []WorkspaceEditFile `json:"files"`
+}
+
+// WorkspaceEditResponseMetadata is the metadata of the response of a tool
+// that applied a workspace edit.
+type WorkspaceEditResponseMetadata struct {
+	Files     []WorkspaceEditFile `json:"files"`
+	Additions int                 `json:"additions"`
+	Removals  int                 `json:"removals"`
+}
+
+// workspaceEditContext holds what is needed to apply a workspace edit.
+type workspaceEditContext struct {
+	ctx         context.Context
+	lspClients  *csync.Map[string, *lsp.Client]
+	permissions permission.Service
+	files       history.Service
+	workingDir  string
+	// additionalDirs are the workspace roots besides the working directory.
+	additionalDirs []string
+}
+
+// applyWorkspaceEdit previews the edit, asks for permission with a diff of
+// every changed file, then writes the files and records them in the file
+// history.
+func applyWorkspaceEdit(edit workspaceEditContext, toolName, description string, workspaceEdit protocol.WorkspaceEdit, call fantasy.ToolCall) (fantasy.ToolResponse, error) {
+	sessionID := GetSessionFromContext(edit.ctx)
+	if sessionID == "" {
+		return fantasy.ToolResponse{}, fmt.Errorf("session ID is required for applying edits")
+	}
+
+	changes, err := util.PreviewWorkspaceEdit(workspaceEdit)
+	if err != nil {
+		return fantasy.NewTextErrorResponse(fmt.Sprintf("failed to compute the changes: %s", err)), nil
+	}
+	if len(changes) == 0 {
+		return fantasy.NewTextResponse("No changes to apply"), nil
+	}
+	return applyFileChanges(edit, sessionID, toolName, description, changes, call)
+}
+
+// applyFileChanges asks for permission with a diff of every changed file,
+// then writes the files and records them in the file history.
+func applyFileChanges(edit workspaceEditContext, sessionID, toolName, description string, changes []util.FileChange, call fantasy.ToolCall) (fantasy.ToolResponse, error) {
+	var meta WorkspaceEditResponseMetadata
+	for _, change := range changes {
+		oldContent, _ := fsext.ToUnixLineEndings(change.OldContent)
+		newContent, _ := fsext.ToUnixLineEndings(change.NewContent)
+		_, additions, removals := diff.GenerateDiff(oldContent, newContent, strings.TrimPrefix(change.Path, edit.workingDir))
+		meta.Files = append(meta.Files, WorkspaceEditFile{
+			FilePath:   change.Path,
+			OldContent: oldContent,
+			NewContent: newContent,
+			Created:    change.Created,
+			Deleted:    change.Deleted,
+			Additions:  additions,
+			Removals:   removals,
+		})
+		meta.Additions += additions
+		meta.Removals += removals
+	}
+
+	// Files get the same permission path as with the edit and write tools:
+	// the workspace root they are in, the file itself otherwise. Permission
+	// is asked once per path.
+	var paths []string
+	filesByPath := make(map[string][]WorkspaceEditFile)
+	for _, file := range meta.Files {
+		path := permissionPath(file.FilePath, edit.workingDir, edit.additionalDirs)
+		if _, ok := filesByPath[path]; !ok {
+			paths = append(paths, path)
+		}
+		filesByPath[path] = append(filesByPath[path], file)
+	}
+	for _, path := range paths {
+		p, err := edit.permissions.Request(edit.ctx,
+			permission.CreatePermissionRequest{
+				SessionID:   sessionID,
+				Path:        path,
+				ToolCallID:  call.ID,
+				ToolName:    toolName,
+				Action:      "write",
+				Description: description,
+				Params: WorkspaceEditPermissionsParams{
+					Description: description,
+					Files:       filesByPath[path],
+				},
+			},
+		)
+		if err != nil {
+			return fantasy.ToolResponse{}, err
+		}
+		if !p {
+			return fantasy.ToolResponse{}, permission.ErrorPermissionDenied
+		}
+	}
+
+	if err := writeFileChanges(changes); err != nil {
+		return fantasy.NewTextErrorResponse(fmt.Sprintf("changes not applied, no file was changed: %s", err)), nil
+	}
+
+	var output strings.Builder
+	fmt.Fprintf(&output, "%s. Changed %d file(s):\n", description, len(changes))
+	for i, change := range changes {
+		recordFileChange(edit, sessionID, meta.Files[i])
+
+		switch {
+		case change.Created:
+			fmt.Fprintf(&output, "- %s (created)\n", change.Path)
+		case change.Deleted:
+			fmt.Fprintf(&output, "- %s (deleted)\n", change.Path)
+		default:
+			fmt.Fprintf(&output, "- %s (+%d -%d)\n", change.Path, meta.Files[i].Additions, meta.Files[i].Removals)
+		}
+	}
+
+	for _, change := range changes {
+		if !change.Deleted {
+			notifyLSPs(edit.ctx, edit.lspClients, change.Path)
+		}
+	}
+
+	text := fmt.Sprintf("<result>\n%s</result>\n", output.String())
+	for _, change := range changes {
+		if !change.Deleted {
+			text += getDiagnostics(edit.ctx, change.Path, edit.lspClients)
+		}
+	}
+	return fantasy.WithResponseMetadata(fantasy.NewTextResponse(text), meta), nil
+}
+
+// writeFileChanges writes every change, or none of them: the files must be
+// unchanged since the changes were computed, which may have been a while ago
+// when waiting for permission, and the files already written are restored
+// when writing one fails.
+func writeFileChanges(changes []util.FileChange) error {
+	for _, change := range changes {
+		if err := checkFileUnchanged(change); err != nil {
+			return err
+		}
+	}
+	for i, change := range changes {
+		if err := writeFileChange(change); err != nil {
+			for _, written := range slices.Backward(changes[:i]) {
+				if restoreErr := restoreFileChange(written); restoreErr != nil {
+					slog.Error("Failed to restore file", "path", written.Path, "error", restoreErr)
+				}
+			}
+			return fmt.Errorf("%s: %w", change.Path, err)
+		}
+	}
+	return nil
+}
+
+// checkFileUnchanged makes sure the file still has the content the change
+// was computed from.
+func checkFileUnchanged(change util.FileChange) error {
+	content, err := os.ReadFile(change.Path)
+	if change.Created {
+		if err == nil {
+			return fmt.Errorf("%s was created in the meantime", change.Path)
+		}
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", change.Path, err)
+	}
+	if string(content) != change.OldContent {
+		return fmt.Errorf("%s was modified in the meantime, read it again before changing it", change.Path)
+	}
+	return nil
+}
+
+// restoreFileChange undoes a change that was written.
+func restoreFileChange(change util.FileChange) error {
+	if change.Created {
+		return os.Remove(change.Path)
+	}
+	return os.WriteFile(change.Path, []byte(change.OldContent), 0o644)
+}
+
+func writeFileChange(change util.FileChange) error {
+	if change.Deleted {
+		if err := os.Remove(change.Path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return fmt.Errorf("failed to delete file: %w", err)
+		}
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(change.Path), 0o755); err != nil {
+		return fmt.Errorf("failed to create parent directories: %w", err)
+	}
+	if err := os.WriteFile(change.Path, []byte(change.NewContent), 0o644); err != nil {
+		return fmt.Errorf("failed to write file: %w", err)
+	}
+	filetracker.RecordWrite(change.Path)
+	filetracker.RecordRead(change.Path)
+	return nil
+}
+
+// recordFileChange records a changed file in the file history.
+func recordFileChange(edit workspaceEditContext, sessionID string, file WorkspaceEditFile) {
+	existing, err := edit.files.GetByPathAndSession(edit.ctx, file.FilePath, sessionID)
+	if err != nil {
+		if _, err := edit.files.Create(edit.ctx, sessionID, file.FilePath, file.OldContent); err != nil {
+			slog.Error("Error creating file history", "error", err)
+			return
+		}
+	} else if existing.Content != file.OldContent {
+		// User manually changed the content; store an intermediate version
+		if _, err := edit.files.CreateVersion(edit.ctx, sessionID, file.FilePath, file.OldContent); err != nil {
+			slog.Error("Error creating file history version", "error", err)
+		}
+	}
+	if _, err := edit.files.CreateVersion(edit.ctx, sessionID, file.FilePath, file.NewContent); err != nil {
+		slog.Error("Error creating file history version", "error", err)
+	}
+}
@@ -0,0 +1,158 @@
+package tools
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"charm.land/fantasy"
+	"github.com/charmbracelet/crush/internal/csync"
+	"github.com/charmbracelet/crush/internal/history"
+	"github.com/charmbracelet/crush/internal/lsp"
+	"github.com/charmbracelet/crush/internal/lsp/util"
+	"github.com/charmbracelet/crush/internal/permission"
+	"github.com/charmbracelet/crush/internal/pubsub"
+	"github.com/charmbracelet/x/powernap/pkg/lsp/protocol"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApplyWorkspaceEdit(t *testing.T) {
+	t.Parallel()
+
+	tmpDir := t.TempDir()
+	a := filepath.Join(tmpDir, "a.go")
+	b := filepath.Join(tmpDir, "b.go")
+	require.NoError(t, os.WriteFile(a, []byte("func Foo() {}\n"), 0o644))
+	require.NoError(t, os.WriteFile(b, []byte("var x = Foo()\n"), 0o644))
+
+	edit := workspaceEditContext{
+		ctx:         context.WithValue(t.Context(), SessionIDContextKey, "session"),
+		lspClients:  csync.NewMap[string, *lsp.Client](),
+		permissions: &mockPermissionService{Broker: pubsub.NewBroker[permission.PermissionRequest]()},
+		files:       &mockHistoryService{Broker: pubsub.NewBroker[history.File]()},
+		workingDir:  tmpDir,
+	}
+	rename := func(line, char uint32) []protocol.TextEdit {
+		return []protocol.TextEdit{{
+			Range: protocol.Range{
+				Start: protocol.Position{Line: line, Character: char},
+				End:   protocol.Position{Line: line, Character: char + 3},
+			},
+			NewText: "Bar",
+		}}
+	}
+	workspaceEdit := protocol.WorkspaceEdit{
+		Changes: map[protocol.DocumentURI][]protocol.TextEdit{
+			protocol.URIFromPath(a): rename(0, 5),
+			protocol.URIFromPath(b): rename(0, 8),
+		},
+	}
+
+	resp, err := applyWorkspaceEdit(edit, RenameToolName, "Rename Foo to Bar", workspaceEdit, fantasy.ToolCall{ID: "call"})
+	require.NoError(t, err)
+	require.False(t, resp.IsError)
+	require.Contains(t, resp.Content, "Changed 2 file(s)")
+
+	content, err := os.ReadFile(a)
+	require.NoError(t, err)
+	require.Equal(t, "func Bar() {}\n", string(content))
+	content, err = os.ReadFile(b)
+	require.NoError(t, err)
+	require.Equal(t, "var x = Bar()\n", string(content))
+
+	resp, err = applyWorkspaceEdit(edit, RenameToolName, "Rename", protocol.WorkspaceEdit{}, fantasy.ToolCall{ID: "call"})
+	require.NoError(t, err)
+	require.Equal(t, "No changes to apply", resp.Content)
+}
+
+// recordingPermissionService grants every request and records their paths.
+type recordingPermissionService struct {
+	mockPermissionService
+	paths []string
+}
+
+func (m *recordingPermissionService) Request(ctx context.Context, req permission.CreatePermissionRequest) (bool, error) {
+	m.paths = append(m.paths, req.Path)
+	return true, nil
+}
+
+func TestApplyWorkspaceEditPermissions(t *testing.T) {
+	t.Parallel()
+
+	tmpDir := t.TempDir()
+	outsideDir := t.TempDir()
+	a := filepath.Join(tmpDir, "a.go")
+	outside := filepath.Join(outsideDir, "outside.go")
+	c := filepath.Join(tmpDir, "c.go")
+	for _, path := range []string{a, outside, c} {
+		require.NoError(t, os.WriteFile(path, []byte("func Foo() {}\n"), 0o644))
+	}
+
+	permissions := &recordingPermissionService{mockPermissionService: mockPermissionService{Broker: pubsub.NewBroker[permission.PermissionRequest]()}}
+	edit := workspaceEditContext{
+		ctx:         context.WithValue(t.Context(), SessionIDContextKey, "session"),
+		lspClients:  csync.NewMap[string, *lsp.Client](),
+		permissions: permissions,
+		files:       &mockHistoryService{Broker: pubsub.NewBroker[history.File]()},
+		workingDir:  tmpDir,
+	}
+	rename := []protocol.TextEdit{{
+		Range: protocol.Range{
+			Start: protocol.Position{Line: 0, Character: 5},
+			End:   protocol.Position{Line: 0, Character: 8},
+		},
+		NewText: "Bar",
+	}}
+	workspaceEdit := protocol.WorkspaceEdit{
+		Changes: map[protocol.DocumentURI][]protocol.TextEdit{
+			protocol.URIFromPath(a):       rename,
+			protocol.URIFromPath(outside): rename,
+			protocol.URIFromPath(c):       rename,
+		},
+	}
+
+	resp, err := applyWorkspaceEdit(edit, RenameToolName, "Rename Foo to Bar", workspaceEdit, fantasy.ToolCall{ID: "call"})
+	require.NoError(t, err)
+	require.False(t, resp.IsError, resp.Content)
+	require.ElementsMatch(t, []string{tmpDir, outside}, permissions.paths)
+}
+
+func TestWriteFileChanges(t *testing.T) {
+	t.Parallel()
+
+	t.Run("rollback", func(t *testing.T) {
+		t.Parallel()
+
+		dir := t.TempDir()
+		a := filepath.Join(dir, "a.go")
+		b := filepath.Join(dir, "b.go")
+		require.NoError(t, os.WriteFile(a, []byte("old"), 0o644))
+		// The parent of c.go is a file, so writing it fails.
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "dir"), nil, 0o644))
+		changes := []util.FileChange{
+			{Path: a, OldContent: "old", NewContent: "new"},
+			{Path: b, NewContent: "new", Created: true},
+			{Path: filepath.Join(dir, "dir", "c.go"), NewContent: "new", Created: true},
+		}
+		require.Error(t, writeFileChanges(changes))
+
+		content, err := os.ReadFile(a)
+		require.NoError(t, err)
+		require.Equal(t, "old", string(content))
+		require.NoFileExists(t, b)
+	})
+
+	t.Run("modified in the meantime", func(t *testing.T) {
+		t.Parallel()
+
+		a := filepath.Join(t.TempDir(), "a.go")
+		require.NoError(t, os.WriteFile(a, []byte("changed"), 0o644))
+		changes := []util.FileChange{{Path: a, OldContent: "old", NewContent: "new"}}
+		require.ErrorContains(t, writeFileChanges(changes), "was modified in the meantime")
+
+		content, err := os.ReadFile(a)
+		require.NoError(t, err)
+		require.Equal(t, "changed", string(content))
+	})
+}
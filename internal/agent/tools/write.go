@@ -11,11 +11,11 @@ import (
 	"time"
 
 	"charm.land/fantasy"
+	"github.com/charmbracelet/crush/internal/config"
 	"github.com/charmbracelet/crush/internal/csync"
 	"github.com/charmbracelet/crush/internal/diff"
 	"github.com/charmbracelet/crush/internal/filepathext"
 	"github.com/charmbracelet/crush/internal/filetracker"
-	"github.com/charmbracelet/crush/internal/fsext"
 	"github.com/charmbracelet/crush/internal/history"
 
 	"github.com/charmbracelet/crush/internal/lsp"
@@ -44,7 +44,7 @@ type WriteResponseMetadata struct {
 
 const WriteToolName = "write"
 
-func NewWriteTool(lspClients *csync.Map[string, *lsp.Client], permissions permission.Service, files history.Service, workingDir string) fantasy.AgentTool {
+func NewWriteTool(lspClients *csync.Map[string, *lsp.Client], permissions permission.Service, files history.Service, workingDir string, additionalDirs []string, formatCfg config.ToolFormat) fantasy.AgentTool {
 	return fantasy.NewAgentTool(
 		WriteToolName,
 		string(writeDescription),
@@ -98,7 +98,7 @@ func NewWriteTool(lspClients *csync.Map[string, *lsp.Client], permissions permis
 				return fantasy.ToolResponse{}, fmt.Errorf("session_id is required")
 			}
 
-			diff, additions, removals := diff.GenerateDiff(
+			writeDiff, additions, removals := diff.GenerateDiff(
 				oldContent,
 				params.Content,
 				strings.TrimPrefix(filePath, workingDir),
@@ -107,7 +107,7 @@ func NewWriteTool(lspClients *csync.Map[string, *lsp.Client], permissions permis
 			p, err := permissions.Request(ctx,
 				permission.CreatePermissionRequest{
 					SessionID:   sessionID,
-					Path:        fsext.PathOrPrefix(filePath, workingDir),
+					Path:        permissionPath(filePath, workingDir, additionalDirs),
 					ToolCallID:  call.ID,
 					ToolName:    WriteToolName,
 					Action:      "write",
@@ -156,14 +156,19 @@ func NewWriteTool(lspClients *csync.Map[string, *lsp.Client], permissions permis
 			filetracker.RecordWrite(filePath)
 			filetracker.RecordRead(filePath)
 
+			formatted, formatNote := formatAfterWrite(ctx, files, formatCfg, workingDir, filePath)
+			if formatted != "" {
+				writeDiff, additions, removals = diff.GenerateDiff(oldContent, formatted, strings.TrimPrefix(filePath, workingDir))
+			}
 			notifyLSPs(ctx, lspClients, params.FilePath)
 
 			result := fmt.Sprintf("File successfully written: %s", filePath)
 			result = fmt.Sprintf("<result>\n%s\n</result>", result)
-			result += getDiagnostics(filePath, lspClients)
+			result += formatNote
+			result += getDiagnostics(ctx, filePath, lspClients)
 			return fantasy.WithResponseMetadata(fantasy.NewTextResponse(result),
 				WriteResponseMetadata{
-					Diff:      diff,
+					Diff:      writeDiff,
 					Additions: additions,
 					Removals:  removals,
 				},
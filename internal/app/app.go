@@ -10,6 +10,7 @@ import (
 	"io"
 	"log/slog"
 	"os"
+	"path/filepath"
 	"strings"
 	"sync"
 	"time"
@@ -20,6 +21,7 @@ import (
 	"github.com/charmbracelet/catwalk/pkg/catwalk"
 	"github.com/charmbracelet/crush/internal/agent"
 	"github.com/charmbracelet/crush/internal/agent/tools/mcp"
+	"github.com/charmbracelet/crush/internal/codeindex"
 	"github.com/charmbracelet/crush/internal/config"
 	"github.com/charmbracelet/crush/internal/csync"
 	"github.com/charmbracelet/crush/internal/db"
@@ -57,6 +59,11 @@ type App struct {
 	AgentCoordinator agent.Coordinator
 
 	LSPClients *csync.Map[string, *lsp.Client]
+	lspWatcher *lsp.Watcher
+
+	// CodeIndex is the index of the symbols of the project, used when no
+	// language server is available.
+	CodeIndex *codeindex.Index
 
 	config *config.Config
 
@@ -88,6 +95,7 @@ func New(ctx context.Context, conn *sql.DB, cfg *config.Config) (*App, error) {
 		History:     files,
 		Permissions: permission.NewPermissionService(cfg.WorkingDir(), skipPermissionsRequests, allowedTools),
 		LSPClients:  csync.NewMap[string, *lsp.Client](),
+		CodeIndex:   codeindex.New(cfg.WorkspaceRoots, filepath.Join(cfg.Options.DataDirectory, "codeindex.json")),
 
 		globalCtx: ctx,
 
@@ -98,11 +106,17 @@ func New(ctx context.Context, conn *sql.DB, cfg *config.Config) (*App, error) {
 		tuiWG:           &sync.WaitGroup{},
 	}
 
+	app.lspWatcher = lsp.NewWatcher(cfg.WorkspaceRoots(), app.LSPClients)
+	app.lspWatcher.OnChange(func([]string) { app.CodeIndex.Invalidate() })
+
 	app.setupEvents()
 
 	// Initialize LSP clients in the background.
 	go app.initLSPClients(ctx)
 
+	// Build the code index in the background.
+	go app.updateCodeIndex(ctx)
+
 	// Check for updates in the background.
 	go app.checkForUpdates(ctx)
 
@@ -112,7 +126,7 @@ func New(ctx context.Context, conn *sql.DB, cfg *config.Config) (*App, error) {
 	}()
 
 	// cleanup database upon app shutdown
-	app.cleanupFuncs = append(app.cleanupFuncs, conn.Close, mcp.Close)
+	app.cleanupFuncs = append(app.cleanupFuncs, conn.Close, mcp.Close, app.lspWatcher.Close)
 
 	// TODO: remove the concept of agent config, most likely.
 	if !cfg.IsConfigured() {
@@ -306,6 +320,34 @@ func (app *App) UpdateAgentModel(ctx context.Context) error {
 	return app.AgentCoordinator.UpdateModels(ctx)
 }
 
+// AddDirectory adds a project directory to the workspace for the rest of the
+// session: the tools can work in it, the running LSP servers are restarted
+// with it as a workspace folder, and the servers matching it are started. It returns the
+// absolute path of the directory.
+func (app *App) AddDirectory(ctx context.Context, dir string) (string, error) {
+	path, added, err := app.config.AddDirectory(dir)
+	if err != nil {
+		return "", err
+	}
+	if !added {
+		return path, nil
+	}
+
+	for name, client := range app.LSPClients.Seq2() {
+		if err := client.AddWorkspaceFolder(path); err != nil {
+			slog.Warn("Failed to add workspace folder to LSP server", "name", name, "error", err)
+		}
+	}
+	app.lspWatcher.AddRoot(app.globalCtx, path)
+	app.CodeIndex.Invalidate()
+	app.startMatchingLSPClients()
+
+	if app.AgentCoordinator == nil {
+		return path, nil
+	}
+	return path, app.UpdateAgentModel(ctx)
+}
+
 // overrideModelsForNonInteractive parses the model strings and temporarily
 // overrides the model configurations, then rebuilds the agent.
 // Format: "model-name" (searches all providers) or "provider/model-name".
@@ -405,6 +447,8 @@ func (app *App) setupEvents() {
 	setupSubscriber(ctx, app.serviceEventsWG, "permissions-notifications", app.Permissions.SubscribeNotifications, app.events)
 	setupSubscriber(ctx, app.serviceEventsWG, "history", app.History.Subscribe, app.events)
 	setupSubscriber(ctx, app.serviceEventsWG, "mcp", mcp.SubscribeEvents, app.events)
+	setupSubscriber(ctx, app.serviceEventsWG, "mcp-logs", mcp.SubscribeLogs, app.events)
+	setupSubscriber(ctx, app.serviceEventsWG, "mcp-progress", mcp.SubscribeProgress, app.events)
 	setupSubscriber(ctx, app.serviceEventsWG, "lsp", SubscribeLSPEvents, app.events)
 	cleanupFunc := func() error {
 		cancel()
@@ -461,6 +505,7 @@ func (app *App) InitCoderAgent(ctx context.Context) error {
 		app.Permissions,
 		app.History,
 		app.LSPClients,
+		app.CodeIndex,
 	)
 	if err != nil {
 		slog.Error("Failed to create coder agent", "err", err)
@@ -547,6 +592,25 @@ func (app *App) Shutdown() {
 	wg.Wait()
 }
 
+// updateCodeIndex brings the code index up to date with the files of the
+// project, so the first lookups don't have to wait for it, and watches the
+// workspace so the index is updated again when files change.
+func (app *App) updateCodeIndex(ctx context.Context) {
+	start := time.Now()
+	parsed, removed, err := app.CodeIndex.Update(ctx)
+	if err != nil {
+		if !errors.Is(err, context.Canceled) {
+			slog.Warn("Failed to update code index", "error", err)
+		}
+		return
+	}
+	slog.Debug("Code index ready", "parsed", parsed, "removed", removed, "took", time.Since(start))
+
+	if err := app.lspWatcher.Start(ctx); err != nil {
+		slog.Error("Failed to start file watcher for the code index", "error", err)
+	}
+}
+
 // checkForUpdates checks for available updates.
 func (app *App) checkForUpdates(ctx context.Context) {
 	checkCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
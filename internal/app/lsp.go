@@ -2,9 +2,12 @@ package app
 
 import (
 	"context"
+	"fmt"
 	"log/slog"
+	"maps"
 	"os/exec"
 	"slices"
+	"strings"
 	"time"
 
 	"github.com/charmbracelet/crush/internal/config"
@@ -12,17 +15,35 @@ import (
 	powernapconfig "github.com/charmbracelet/x/powernap/pkg/config"
 )
 
-// initLSPClients initializes LSP clients.
-func (app *App) initLSPClients(ctx context.Context) {
-	slog.Info("LSP clients initialization started")
+// LSPServer is a language server known to Crush, either configured by the
+// user or one of the defaults, and whether it's started for the working
+// directory.
+type LSPServer struct {
+	Name           string
+	Config         config.LSPConfig
+	UserConfigured bool
+	// Matched is set when the root markers were found in the working
+	// directory.
+	Matched bool
+	Enabled bool
+	// Reason explains why the server is enabled or skipped.
+	Reason string
+}
 
+// DetectLSPServers resolves the user configured and default language servers
+// and decides which ones to start for the workspace roots, sorted by name.
+func DetectLSPServers(cfg *config.Config) []LSPServer {
+	return detectLSPServers(cfg.WorkspaceRoots(), cfg.LSP, cfg.Options.AutoLSP)
+}
+
+func detectLSPServers(roots []string, lspConfigs config.LSPs, autoLSPOption *bool) []LSPServer {
 	manager := powernapconfig.NewManager()
 	manager.LoadDefaults()
 
-	var userConfiguredLSPs []string
-	for name, clientConfig := range app.config.LSP {
+	var disabled, userConfiguredLSPs []string
+	for name, clientConfig := range lspConfigs {
 		if clientConfig.Disabled {
-			slog.Info("Skipping disabled LSP client", "name", name)
+			disabled = append(disabled, name)
 			manager.RemoveServer(name)
 			continue
 		}
@@ -51,23 +72,101 @@ func (app *App) initLSPClients(ctx context.Context) {
 	}
 
 	servers := manager.GetServers()
-	filtered := lsp.FilterMatching(app.config.WorkingDir(), servers)
+	filtered := make(map[string]*powernapconfig.ServerConfig)
+	for _, root := range roots {
+		maps.Copy(filtered, lsp.FilterMatching(root, servers))
+	}
+	autoLSP := autoLSPOption == nil || *autoLSPOption
+
+	result := make([]LSPServer, 0, len(servers)+len(disabled))
+	for _, name := range disabled {
+		result = append(result, LSPServer{
+			Name:           name,
+			Config:         lspConfigs[name],
+			UserConfigured: true,
+			Reason:         "disabled in configuration",
+		})
+	}
+	for name, server := range servers {
+		s := LSPServer{
+			Name:           name,
+			Config:         toOurConfig(server),
+			UserConfigured: slices.Contains(userConfiguredLSPs, name),
+		}
+		_, s.Matched = filtered[name]
+		switch {
+		case len(server.RootMarkers) == 0:
+			s.Reason = "no root markers configured"
+		case !s.Matched:
+			s.Reason = "no root markers found"
+		case !autoLSP && !s.UserConfigured:
+			s.Reason = "not configured and auto_lsp is disabled"
+		case !s.UserConfigured && !commandInstalled(server.Command):
+			s.Reason = fmt.Sprintf("%s not installed", server.Command)
+		case s.UserConfigured:
+			s.Enabled = true
+			s.Reason = "configured and root markers found"
+		default:
+			s.Enabled = true
+			s.Reason = "root markers found"
+		}
+		result = append(result, s)
+	}
 
-	for _, name := range userConfiguredLSPs {
-		if _, ok := filtered[name]; !ok {
-			updateLSPState(name, lsp.StateDisabled, nil, nil, 0)
+	slices.SortFunc(result, func(a, b LSPServer) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+	return result
+}
+
+func commandInstalled(command string) bool {
+	_, err := exec.LookPath(command)
+	return err == nil
+}
+
+// initLSPClients initializes LSP clients.
+func (app *App) initLSPClients(ctx context.Context) {
+	slog.Info("LSP clients initialization started")
+
+	var started bool
+	for _, server := range DetectLSPServers(app.config) {
+		if !server.Enabled {
+			slog.Debug("Skipping LSP client", "name", server.Name, "reason", server.Reason)
+			if server.UserConfigured && !server.Config.Disabled {
+				updateLSPState(server.Name, lsp.StateDisabled, nil, nil, 0)
+			}
+			continue
 		}
+		started = true
+		go app.createAndStartLSPClient(ctx, server.Name, server.Config, server.UserConfigured)
 	}
-	for name, server := range filtered {
-		if app.config.Options.AutoLSP != nil && !*app.config.Options.AutoLSP && !slices.Contains(userConfiguredLSPs, name) {
-			slog.Debug("Ignoring non user-define LSP client due to AutoLSP being disabled", "name", name)
+
+	if started {
+		// Tell the servers about files changed outside of Crush.
+		if err := app.lspWatcher.Start(ctx); err != nil {
+			slog.Error("Failed to start file watcher for LSP servers", "error", err)
+		}
+	}
+}
+
+// startMatchingLSPClients starts the enabled LSP servers that are not running
+// yet, e.g. after a project directory was added.
+func (app *App) startMatchingLSPClients() {
+	var started bool
+	for _, server := range DetectLSPServers(app.config) {
+		if !server.Enabled {
+			continue
+		}
+		if info, ok := lspStates.Get(server.Name); ok && info.State != lsp.StateDisabled {
 			continue
 		}
-		go app.createAndStartLSPClient(
-			ctx, name,
-			toOurConfig(server),
-			slices.Contains(userConfiguredLSPs, name),
-		)
+		started = true
+		go app.createAndStartLSPClient(app.globalCtx, server.Name, server.Config, server.UserConfigured)
+	}
+	if started {
+		if err := app.lspWatcher.Start(app.globalCtx); err != nil {
+			slog.Error("Failed to start file watcher for LSP servers", "error", err)
+		}
 	}
 }
 
@@ -85,13 +184,6 @@ func toOurConfig(in *powernapconfig.ServerConfig) config.LSPConfig {
 
 // createAndStartLSPClient creates a new LSP client, initializes it, and starts its workspace watcher.
 func (app *App) createAndStartLSPClient(ctx context.Context, name string, config config.LSPConfig, userConfigured bool) {
-	if !userConfigured {
-		if _, err := exec.LookPath(config.Command); err != nil {
-			slog.Warn("Default LSP config skipped: server not installed", "name", name, "error", err)
-			return
-		}
-	}
-
 	slog.Debug("Creating LSP client", "name", name, "command", config.Command, "fileTypes", config.FileTypes, "args", config.Args)
 
 	// Update state to starting.
@@ -110,8 +202,10 @@ func (app *App) createAndStartLSPClient(ctx context.Context, name string, config
 		return
 	}
 
-	// Set diagnostics callback
+	// Set diagnostics, progress and message callbacks
 	lspClient.SetDiagnosticsCallback(updateLSPDiagnostics)
+	lspClient.SetProgressCallback(updateLSPProgress)
+	lspClient.SetMessageCallback(publishLSPMessage)
 
 	// Increase initialization timeout as some servers take more time to start.
 	initCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
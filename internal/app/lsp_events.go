@@ -2,11 +2,15 @@ package app
 
 import (
 	"context"
+	"fmt"
+	"slices"
 	"time"
 
 	"github.com/charmbracelet/crush/internal/csync"
 	"github.com/charmbracelet/crush/internal/lsp"
 	"github.com/charmbracelet/crush/internal/pubsub"
+	"github.com/charmbracelet/crush/internal/uiutil"
+	"github.com/charmbracelet/x/powernap/pkg/lsp/protocol"
 )
 
 // LSPEventType represents the type of LSP event
@@ -15,6 +19,8 @@ type LSPEventType string
 const (
 	LSPEventStateChanged       LSPEventType = "state_changed"
 	LSPEventDiagnosticsChanged LSPEventType = "diagnostics_changed"
+	LSPEventProgressChanged    LSPEventType = "progress_changed"
+	LSPEventMessage            LSPEventType = "message"
 )
 
 // LSPEvent represents an event in the LSP system
@@ -24,6 +30,32 @@ type LSPEvent struct {
 	State           lsp.ServerState
 	Error           error
 	DiagnosticCount int
+	// Message is the server message for message events, or the title of the
+	// operation started for progress events.
+	Message     string
+	MessageType protocol.MessageType
+}
+
+// StatusMsg returns the status bar message for the event, if any.
+func (e LSPEvent) StatusMsg() (uiutil.InfoMsg, bool) {
+	if e.Message == "" {
+		return uiutil.InfoMsg{}, false
+	}
+	msg := fmt.Sprintf("%s: %s", e.Name, e.Message)
+	switch e.Type {
+	case LSPEventProgressChanged:
+		return uiutil.NewInfoMsg(msg + "..."), true
+	case LSPEventMessage:
+		switch e.MessageType {
+		case protocol.Error:
+			return uiutil.InfoMsg{Type: uiutil.InfoTypeError, Msg: msg}, true
+		case protocol.Warning:
+			return uiutil.NewWarnMsg(msg), true
+		case protocol.Info:
+			return uiutil.NewInfoMsg(msg), true
+		}
+	}
+	return uiutil.InfoMsg{}, false
 }
 
 // LSPClientInfo holds information about an LSP client's state
@@ -34,6 +66,8 @@ type LSPClientInfo struct {
 	Client          *lsp.Client
 	DiagnosticCount int
 	ConnectedAt     time.Time
+	// Progress lists the operations the server is running, such as indexing.
+	Progress []lsp.WorkDoneProgress
 }
 
 var (
@@ -68,6 +102,9 @@ func updateLSPState(name string, state lsp.ServerState, err error, client *lsp.C
 	if state == lsp.StateReady {
 		info.ConnectedAt = time.Now()
 	}
+	if prev, ok := lspStates.Get(name); ok && (state == lsp.StateStarting || state == lsp.StateReady) {
+		info.Progress = prev.Progress
+	}
 	lspStates.Set(name, info)
 
 	// Publish state change event
@@ -96,3 +133,50 @@ func updateLSPDiagnostics(name string, diagnosticCount int) {
 		})
 	}
 }
+
+// updateLSPProgress updates the running operations of an LSP client and
+// publishes an event
+func updateLSPProgress(name string, progress []lsp.WorkDoneProgress) {
+	info, exists := lspStates.Get(name)
+	if !exists {
+		return
+	}
+
+	// Announce operations that just started.
+	var started string
+	for _, p := range progress {
+		if !slices.ContainsFunc(info.Progress, func(prev lsp.WorkDoneProgress) bool {
+			return prev.Title == p.Title
+		}) {
+			started = p.Title
+			break
+		}
+	}
+
+	info.Progress = progress
+	lspStates.Set(name, info)
+
+	lspBroker.Publish(pubsub.UpdatedEvent, LSPEvent{
+		Type:            LSPEventProgressChanged,
+		Name:            name,
+		State:           info.State,
+		Error:           info.Error,
+		DiagnosticCount: info.DiagnosticCount,
+		Message:         started,
+	})
+}
+
+// publishLSPMessage publishes a message the LSP server wants shown to the
+// user.
+func publishLSPMessage(name string, msgType protocol.MessageType, message string) {
+	info, _ := lspStates.Get(name)
+	lspBroker.Publish(pubsub.UpdatedEvent, LSPEvent{
+		Type:            LSPEventMessage,
+		Name:            name,
+		State:           info.State,
+		Error:           info.Error,
+		DiagnosticCount: info.DiagnosticCount,
+		Message:         message,
+		MessageType:     msgType,
+	})
+}
@@ -0,0 +1,52 @@
+package app
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/charmbracelet/crush/internal/config"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDetectLSPServers(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module example\n"), 0o644))
+
+	lsps := config.LSPs{
+		"mine": {
+			Command:     "my-lsp",
+			RootMarkers: []string{"go.mod"},
+		},
+		"other": {
+			Command:     "other-lsp",
+			RootMarkers: []string{"Cargo.toml"},
+		},
+		"off": {
+			Command:  "off-lsp",
+			Disabled: true,
+		},
+	}
+	autoLSP := false
+	servers := map[string]LSPServer{}
+	for _, s := range detectLSPServers([]string{dir}, lsps, &autoLSP) {
+		servers[s.Name] = s
+	}
+
+	require.True(t, servers["mine"].Enabled)
+	require.True(t, servers["mine"].UserConfigured)
+
+	require.False(t, servers["other"].Enabled)
+	require.False(t, servers["other"].Matched)
+	require.Equal(t, "no root markers found", servers["other"].Reason)
+
+	require.False(t, servers["off"].Enabled)
+	require.Equal(t, "disabled in configuration", servers["off"].Reason)
+
+	// gopls is a default matching go.mod, skipped as auto LSP is off.
+	require.True(t, servers["gopls"].Matched)
+	require.False(t, servers["gopls"].Enabled)
+	require.Equal(t, "not configured and auto_lsp is disabled", servers["gopls"].Reason)
+}
@@ -0,0 +1,469 @@
+package app
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"charm.land/fantasy"
+	"github.com/charmbracelet/crush/internal/agent"
+	"github.com/charmbracelet/crush/internal/agent/tools"
+	"github.com/charmbracelet/crush/internal/agent/tools/mcp"
+	"github.com/charmbracelet/crush/internal/csync"
+	"github.com/charmbracelet/crush/internal/message"
+	"github.com/charmbracelet/crush/internal/permission"
+	"github.com/charmbracelet/crush/internal/pubsub"
+	"github.com/charmbracelet/crush/internal/session"
+	"github.com/charmbracelet/crush/internal/version"
+	"github.com/google/uuid"
+	mcpsdk "github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+const (
+	// MCPCoderToolName is the name of the tool that runs the coder agent when
+	// Crush is served over MCP.
+	MCPCoderToolName = "coder"
+
+	mcpSessionURIPrefix   = "crush://sessions/"
+	mcpSessionURITemplate = mcpSessionURIPrefix + "{id}"
+)
+
+// MCPServeOptions configures how Crush is served over MCP.
+type MCPServeOptions struct {
+	// HTTPAddr is the address to serve streamable HTTP on. If empty, the
+	// server speaks MCP over stdin and stdout.
+	HTTPAddr string
+}
+
+// mcpServer exposes Crush's built-in tools, the coder agent and the sessions
+// over the Model Context Protocol.
+type mcpServer struct {
+	app    *App
+	server *mcpsdk.Server
+
+	// sessions maps each connected MCP client to the Crush session its tool
+	// calls run in.
+	sessions *csync.Map[*mcpsdk.ServerSession, string]
+	// clients maps Crush sessions back to the MCP client that owns them, so
+	// permission requests can be forwarded to it.
+	clients *csync.Map[string, *mcpsdk.ServerSession]
+}
+
+type mcpCoderParams struct {
+	Prompt    string `json:"prompt" jsonschema:"The task for the coder agent to perform"`
+	SessionID string `json:"session_id,omitempty" jsonschema:"The ID of an existing Crush session to continue, a new session is created if empty"`
+}
+
+type mcpCoderResult struct {
+	SessionID string `json:"session_id" jsonschema:"The ID of the Crush session the agent ran in"`
+	Response  string `json:"response" jsonschema:"The final response of the agent"`
+}
+
+// ServeMCP serves Crush as an MCP server until the context is done. Tool
+// calls go through the same permission service as the TUI; requests that
+// are not allowed by the configuration are forwarded to the MCP client as
+// elicitations.
+func (app *App) ServeMCP(ctx context.Context, opts MCPServeOptions) error {
+	if app.AgentCoordinator == nil {
+		return errors.New("coder agent is not configured")
+	}
+
+	s := &mcpServer{
+		app:      app,
+		sessions: csync.NewMap[*mcpsdk.ServerSession, string](),
+		clients:  csync.NewMap[string, *mcpsdk.ServerSession](),
+	}
+	s.server = mcpsdk.NewServer(&mcpsdk.Implementation{
+		Name:    "crush",
+		Title:   "Crush",
+		Version: version.Version,
+	}, &mcpsdk.ServerOptions{
+		Instructions: "Crush is a coding agent working in " + app.config.WorkingDir() + ". Use its tools to read, search and edit the code, or hand off whole tasks to the coder tool.",
+		InitializedHandler: func(_ context.Context, req *mcpsdk.InitializedRequest) {
+			go s.forgetOnClose(req.Session)
+		},
+	})
+
+	if err := s.addTools(ctx); err != nil {
+		return err
+	}
+	if err := s.addSessions(ctx); err != nil {
+		return err
+	}
+
+	go s.handlePermissions(ctx)
+	go s.watchSessions(ctx)
+
+	if opts.HTTPAddr == "" {
+		slog.Info("Serving MCP over stdio")
+		return s.server.Run(ctx, &mcpsdk.StdioTransport{})
+	}
+
+	srv := &http.Server{
+		Addr: opts.HTTPAddr,
+		Handler: mcpsdk.NewStreamableHTTPHandler(func(*http.Request) *mcpsdk.Server {
+			return s.server
+		}, nil),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = srv.Shutdown(shutdownCtx)
+	}()
+	slog.Info("Serving MCP over streamable HTTP", "addr", opts.HTTPAddr)
+	if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// addTools registers the built-in tools of the coder agent and the coder
+// agent itself.
+func (s *mcpServer) addTools(ctx context.Context) error {
+	agentTools, err := s.app.AgentCoordinator.Tools(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to build tools: %w", err)
+	}
+	for _, tool := range agentTools {
+		// Tools of the MCP servers Crush is connected to are not re-exposed.
+		if _, ok := tool.(*tools.Tool); ok {
+			continue
+		}
+		info := tool.Info()
+		required := info.Required
+		if required == nil {
+			required = []string{}
+		}
+		s.server.AddTool(&mcpsdk.Tool{
+			Name:        info.Name,
+			Description: info.Description,
+			InputSchema: map[string]any{
+				"type":       "object",
+				"properties": info.Parameters,
+				"required":   required,
+			},
+		}, s.toolHandler(tool))
+	}
+
+	mcpsdk.AddTool(s.server, &mcpsdk.Tool{
+		Name:        MCPCoderToolName,
+		Title:       "Crush coder agent",
+		Description: "Run Crush's coder agent on a task. The agent can read, search and edit files and run commands on its own, and returns its final response. Pass the returned session_id to continue the same conversation.",
+	}, s.runCoder)
+	return nil
+}
+
+func (s *mcpServer) toolHandler(tool fantasy.AgentTool) mcpsdk.ToolHandler {
+	return func(ctx context.Context, req *mcpsdk.CallToolRequest) (*mcpsdk.CallToolResult, error) {
+		sessionID, err := s.sessionFor(ctx, req.Session)
+		if err != nil {
+			return nil, err
+		}
+
+		input := string(req.Params.Arguments)
+		if input == "" {
+			input = "{}"
+		}
+
+		ctx = context.WithValue(ctx, tools.SessionIDContextKey, sessionID)
+		ctx = context.WithValue(ctx, tools.MessageIDContextKey, uuid.NewString())
+		ctx = context.WithValue(ctx, tools.SupportsImagesContextKey, true)
+		resp, err := tool.Run(ctx, fantasy.ToolCall{
+			ID:    uuid.NewString(),
+			Name:  tool.Info().Name,
+			Input: input,
+		})
+		if err != nil {
+			if errors.Is(err, permission.ErrorPermissionDenied) {
+				resp = fantasy.NewTextErrorResponse("permission denied")
+			} else {
+				return nil, err
+			}
+		}
+		return toolResponseToMCP(resp), nil
+	}
+}
+
+func (s *mcpServer) runCoder(ctx context.Context, req *mcpsdk.CallToolRequest, params mcpCoderParams) (*mcpsdk.CallToolResult, mcpCoderResult, error) {
+	if strings.TrimSpace(params.Prompt) == "" {
+		return nil, mcpCoderResult{}, errors.New("prompt is required")
+	}
+
+	// Wait for MCP initialization so the agent gets the tools of the
+	// configured MCP servers too.
+	if err := mcp.WaitForInit(ctx); err != nil {
+		return nil, mcpCoderResult{}, err
+	}
+
+	sessionID := params.SessionID
+	if sessionID == "" {
+		sess, err := s.app.Sessions.Create(ctx, "MCP: "+stringPrefix(params.Prompt, 100))
+		if err != nil {
+			return nil, mcpCoderResult{}, fmt.Errorf("failed to create session: %w", err)
+		}
+		sessionID = sess.ID
+	} else if _, err := s.app.Sessions.Get(ctx, sessionID); err != nil {
+		return nil, mcpCoderResult{}, fmt.Errorf("session %s not found", sessionID)
+	}
+	s.clients.Set(sessionID, req.Session)
+
+	result, err := s.app.AgentCoordinator.Run(ctx, sessionID, params.Prompt)
+	if err != nil {
+		if errors.Is(err, context.Canceled) || errors.Is(err, agent.ErrRequestCancelled) {
+			return nil, mcpCoderResult{}, errors.New("agent run cancelled")
+		}
+		return nil, mcpCoderResult{}, fmt.Errorf("agent run failed: %w", err)
+	}
+
+	out := mcpCoderResult{SessionID: sessionID}
+	if result != nil {
+		out.Response = result.Response.Content.Text()
+	}
+	return &mcpsdk.CallToolResult{
+		Content: []mcpsdk.Content{&mcpsdk.TextContent{Text: out.Response}},
+	}, out, nil
+}
+
+// sessionFor returns the Crush session the tool calls of the given MCP
+// client run in, creating it on the first call.
+func (s *mcpServer) sessionFor(ctx context.Context, ss *mcpsdk.ServerSession) (string, error) {
+	if id, ok := s.sessions.Get(ss); ok {
+		return id, nil
+	}
+	title := "MCP client"
+	if params := ss.InitializeParams(); params != nil && params.ClientInfo != nil {
+		title = "MCP: " + params.ClientInfo.Name
+	}
+	sess, err := s.app.Sessions.Create(ctx, title)
+	if err != nil {
+		return "", fmt.Errorf("failed to create session: %w", err)
+	}
+	s.sessions.Set(ss, sess.ID)
+	s.clients.Set(sess.ID, ss)
+	return sess.ID, nil
+}
+
+// forgetOnClose waits for an MCP client to disconnect, then forgets its
+// session and the Crush sessions it owns.
+func (s *mcpServer) forgetOnClose(ss *mcpsdk.ServerSession) {
+	_ = ss.Wait()
+	s.sessions.Del(ss)
+	for sessionID, client := range s.clients.Seq2() {
+		if client == ss {
+			s.clients.Del(sessionID)
+		}
+	}
+}
+
+// clientFor returns the MCP client that owns the given Crush session,
+// following parent sessions for the sessions of sub-agents.
+func (s *mcpServer) clientFor(ctx context.Context, sessionID string) *mcpsdk.ServerSession {
+	for sessionID != "" {
+		if ss, ok := s.clients.Get(sessionID); ok {
+			return ss
+		}
+		sess, err := s.app.Sessions.Get(ctx, sessionID)
+		if err != nil {
+			return nil
+		}
+		sessionID = sess.ParentSessionID
+	}
+	return nil
+}
+
+// handlePermissions forwards the permission requests that need a decision
+// to the MCP client that triggered them.
+func (s *mcpServer) handlePermissions(ctx context.Context) {
+	for event := range s.app.Permissions.Subscribe(ctx) {
+		go s.requestPermission(ctx, event.Payload)
+	}
+}
+
+func (s *mcpServer) requestPermission(ctx context.Context, req permission.PermissionRequest) {
+	ss := s.clientFor(ctx, req.SessionID)
+	if ss == nil {
+		slog.Warn("Denying permission request without MCP client", "tool", req.ToolName, "session_id", req.SessionID)
+		s.app.Permissions.Deny(req)
+		return
+	}
+
+	message := fmt.Sprintf("Crush wants to run %s (%s) in %s.", req.ToolName, req.Action, req.Path)
+	if req.Description != "" {
+		message += "\n\n" + req.Description
+	}
+	res, err := ss.Elicit(ctx, &mcpsdk.ElicitParams{
+		Message: message,
+		RequestedSchema: map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"decision": map[string]any{
+					"type":        "string",
+					"title":       "Decision",
+					"enum":        []string{"allow", "allow_session", "deny"},
+					"enumNames":   []string{"Allow", "Allow for session", "Deny"},
+					"description": "Whether to allow this tool call",
+				},
+			},
+			"required": []string{"decision"},
+		},
+	})
+	if err != nil {
+		slog.Warn("Denying permission request, elicitation failed", "tool", req.ToolName, "error", err)
+		s.app.Permissions.Deny(req)
+		return
+	}
+	if res.Action != "accept" {
+		s.app.Permissions.Deny(req)
+		return
+	}
+	switch res.Content["decision"] {
+	case "allow":
+		s.app.Permissions.Grant(req)
+	case "allow_session":
+		s.app.Permissions.GrantPersistent(req)
+	default:
+		s.app.Permissions.Deny(req)
+	}
+}
+
+// addSessions registers the existing top level sessions as resources, along
+// with a template to read any session by ID.
+func (s *mcpServer) addSessions(ctx context.Context) error {
+	s.server.AddResourceTemplate(&mcpsdk.ResourceTemplate{
+		Name:        "session",
+		Title:       "Crush session",
+		Description: "The transcript of a Crush session",
+		MIMEType:    "text/markdown",
+		URITemplate: mcpSessionURITemplate,
+	}, s.readSession)
+
+	sessions, err := s.app.Sessions.List(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list sessions: %w", err)
+	}
+	for _, sess := range sessions {
+		s.addSession(sess)
+	}
+	return nil
+}
+
+func (s *mcpServer) addSession(sess session.Session) {
+	s.server.AddResource(&mcpsdk.Resource{
+		Name:        sess.ID,
+		Title:       sess.Title,
+		Description: fmt.Sprintf("Crush session with %d messages", sess.MessageCount),
+		MIMEType:    "text/markdown",
+		URI:         mcpSessionURIPrefix + sess.ID,
+	}, s.readSession)
+}
+
+// watchSessions keeps the session resources in sync with the database.
+func (s *mcpServer) watchSessions(ctx context.Context) {
+	for event := range s.app.Sessions.Subscribe(ctx) {
+		sess := event.Payload
+		if sess.ParentSessionID != "" {
+			continue
+		}
+		switch event.Type {
+		case pubsub.DeletedEvent:
+			s.server.RemoveResources(mcpSessionURIPrefix + sess.ID)
+		default:
+			s.addSession(sess)
+		}
+	}
+}
+
+func (s *mcpServer) readSession(ctx context.Context, req *mcpsdk.ReadResourceRequest) (*mcpsdk.ReadResourceResult, error) {
+	uri := req.Params.URI
+	id, ok := strings.CutPrefix(uri, mcpSessionURIPrefix)
+	if !ok || id == "" {
+		return nil, mcpsdk.ResourceNotFoundError(uri)
+	}
+	sess, err := s.app.Sessions.Get(ctx, id)
+	if err != nil {
+		return nil, mcpsdk.ResourceNotFoundError(uri)
+	}
+	msgs, err := s.app.Messages.List(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list messages: %w", err)
+	}
+	return &mcpsdk.ReadResourceResult{
+		Contents: []*mcpsdk.ResourceContents{{
+			URI:      uri,
+			MIMEType: "text/markdown",
+			Text:     sessionTranscript(sess, msgs),
+		}},
+	}, nil
+}
+
+// sessionTranscript renders the messages of a session as markdown.
+func sessionTranscript(sess session.Session, msgs []message.Message) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "# %s\n", sess.Title)
+	for _, msg := range msgs {
+		switch msg.Role {
+		case message.User:
+			sb.WriteString("\n## User\n")
+		case message.Assistant:
+			sb.WriteString("\n## Assistant\n")
+		case message.Tool:
+			sb.WriteString("\n## Tool results\n")
+		default:
+			continue
+		}
+		if text := strings.TrimSpace(msg.Content().Text); text != "" {
+			sb.WriteString("\n" + text + "\n")
+		}
+		for _, call := range msg.ToolCalls() {
+			fmt.Fprintf(&sb, "\n**%s** `%s`\n", call.Name, call.Input)
+		}
+		for _, result := range msg.ToolResults() {
+			fmt.Fprintf(&sb, "\n**%s**\n\n```\n%s\n```\n", result.Name, strings.TrimSpace(result.Content))
+		}
+	}
+	return sb.String()
+}
+
+// toolResponseToMCP converts the response of a built-in tool to an MCP tool
+// result.
+func toolResponseToMCP(resp fantasy.ToolResponse) *mcpsdk.CallToolResult {
+	result := &mcpsdk.CallToolResult{IsError: resp.IsError}
+	if resp.Content != "" {
+		result.Content = append(result.Content, &mcpsdk.TextContent{Text: resp.Content})
+	}
+	if len(resp.Data) > 0 {
+		// The view tool returns images already base64 encoded.
+		data := resp.Data
+		if decoded, err := base64.StdEncoding.DecodeString(string(data)); err == nil {
+			data = decoded
+		}
+		switch {
+		case strings.HasPrefix(resp.MediaType, "image/"):
+			result.Content = append(result.Content, &mcpsdk.ImageContent{Data: data, MIMEType: resp.MediaType})
+		case strings.HasPrefix(resp.MediaType, "audio/"):
+			result.Content = append(result.Content, &mcpsdk.AudioContent{Data: data, MIMEType: resp.MediaType})
+		}
+	}
+	if len(result.Content) == 0 {
+		result.Content = []mcpsdk.Content{&mcpsdk.TextContent{Text: ""}}
+	}
+	return result
+}
+
+func stringPrefix(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	return s[:n] + "..."
+}
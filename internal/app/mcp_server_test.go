@@ -0,0 +1,103 @@
+package app
+
+import (
+	"context"
+	"encoding/base64"
+	"testing"
+	"time"
+
+	"charm.land/fantasy"
+	"github.com/charmbracelet/crush/internal/csync"
+	"github.com/charmbracelet/crush/internal/message"
+	"github.com/charmbracelet/crush/internal/session"
+	mcpsdk "github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/require"
+)
+
+func TestToolResponseToMCP(t *testing.T) {
+	t.Parallel()
+
+	t.Run("text", func(t *testing.T) {
+		t.Parallel()
+		result := toolResponseToMCP(fantasy.NewTextErrorResponse("boom"))
+		require.True(t, result.IsError)
+		require.Len(t, result.Content, 1)
+		require.Equal(t, "boom", result.Content[0].(*mcpsdk.TextContent).Text)
+	})
+
+	t.Run("base64 image", func(t *testing.T) {
+		t.Parallel()
+		data := []byte{0x89, 'P', 'N', 'G'}
+		encoded := base64.StdEncoding.EncodeToString(data)
+		result := toolResponseToMCP(fantasy.NewImageResponse([]byte(encoded), "image/png"))
+		require.False(t, result.IsError)
+		require.Len(t, result.Content, 1)
+		image := result.Content[0].(*mcpsdk.ImageContent)
+		require.Equal(t, data, image.Data)
+		require.Equal(t, "image/png", image.MIMEType)
+	})
+
+	t.Run("empty", func(t *testing.T) {
+		t.Parallel()
+		result := toolResponseToMCP(fantasy.ToolResponse{})
+		require.Len(t, result.Content, 1)
+	})
+}
+
+func TestSessionTranscript(t *testing.T) {
+	t.Parallel()
+
+	user := message.Message{Role: message.User, Parts: []message.ContentPart{message.TextContent{Text: "list files"}}}
+	assistant := message.Message{Role: message.Assistant, Parts: []message.ContentPart{
+		message.TextContent{Text: "Sure."},
+		message.ToolCall{ID: "1", Name: "ls", Input: `{"path":"."}`},
+	}}
+	tool := message.Message{Role: message.Tool, Parts: []message.ContentPart{
+		message.ToolResult{ToolCallID: "1", Name: "ls", Content: "- main.go\n"},
+	}}
+
+	transcript := sessionTranscript(session.Session{Title: "Files"}, []message.Message{user, assistant, tool})
+	require.Equal(t, "# Files\n\n## User\n\nlist files\n\n## Assistant\n\nSure.\n\n**ls** `{\"path\":\".\"}`\n\n## Tool results\n\n**ls**\n\n```\n- main.go\n```\n", transcript)
+}
+
+func TestStringPrefix(t *testing.T) {
+	t.Parallel()
+
+	require.Equal(t, "short", stringPrefix("short", 10))
+	require.Equal(t, "abc...", stringPrefix("abcdef", 3))
+	// The prefix never ends in the middle of a rune.
+	require.Equal(t, "ab...", stringPrefix("abéd", 3))
+}
+
+func TestMCPServerForgetsClosedClients(t *testing.T) {
+	t.Parallel()
+
+	s := &mcpServer{
+		sessions: csync.NewMap[*mcpsdk.ServerSession, string](),
+		clients:  csync.NewMap[string, *mcpsdk.ServerSession](),
+	}
+	initialized := make(chan *mcpsdk.ServerSession, 1)
+	s.server = mcpsdk.NewServer(&mcpsdk.Implementation{Name: "crush"}, &mcpsdk.ServerOptions{
+		InitializedHandler: func(_ context.Context, req *mcpsdk.InitializedRequest) {
+			initialized <- req.Session
+			go s.forgetOnClose(req.Session)
+		},
+	})
+
+	serverTransport, clientTransport := mcpsdk.NewInMemoryTransports()
+	_, err := s.server.Connect(t.Context(), serverTransport, nil)
+	require.NoError(t, err)
+	client := mcpsdk.NewClient(&mcpsdk.Implementation{Name: "test"}, nil)
+	cs, err := client.Connect(t.Context(), clientTransport, nil)
+	require.NoError(t, err)
+
+	ss := <-initialized
+	s.sessions.Set(ss, "session")
+	s.clients.Set("session", ss)
+	s.clients.Set("other", nil)
+
+	require.NoError(t, cs.Close())
+	require.Eventually(t, func() bool {
+		return s.sessions.Len() == 0 && s.clients.Len() == 1
+	}, 5*time.Second, 10*time.Millisecond)
+}
@@ -0,0 +1,298 @@
+package cmd
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"charm.land/lipgloss/v2"
+	"charm.land/lipgloss/v2/table"
+	"github.com/charmbracelet/crush/internal/app"
+	"github.com/charmbracelet/crush/internal/config"
+	"github.com/charmbracelet/crush/internal/lsp"
+	"github.com/charmbracelet/x/powernap/pkg/lsp/protocol"
+	"github.com/charmbracelet/x/term"
+	"github.com/spf13/cobra"
+)
+
+var lspCmd = &cobra.Command{
+	Use:   "lsp",
+	Short: "Inspect language servers",
+	Long:  "Inspect and test the language servers Crush uses in the working directory",
+}
+
+var lspListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List detected language servers",
+	Long:  "List the configured and default language servers matching the working directory, whether Crush starts them and why",
+	Example: `
+# List the language servers for the current directory
+crush lsp list
+
+# Include every known default language server
+crush lsp list --all
+
+# Output as JSON
+crush lsp list --json
+  `,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		jsonOutput, _ := cmd.Flags().GetBool("json")
+		all, _ := cmd.Flags().GetBool("all")
+
+		cfg, err := initConfig(cmd)
+		if err != nil {
+			return err
+		}
+
+		type server struct {
+			Name        string   `json:"name"`
+			Source      string   `json:"source"`
+			Enabled     bool     `json:"enabled"`
+			Reason      string   `json:"reason"`
+			Command     string   `json:"command"`
+			RootMarkers []string `json:"root_markers,omitempty"`
+		}
+
+		var servers []server
+		for _, s := range app.DetectLSPServers(cfg) {
+			// Unless asked for, hide the defaults unrelated to the project.
+			if !all && !s.UserConfigured && !s.Matched {
+				continue
+			}
+			source := "default"
+			if s.UserConfigured {
+				source = "config"
+			}
+			servers = append(servers, server{
+				Name:        s.Name,
+				Source:      source,
+				Enabled:     s.Enabled,
+				Reason:      s.Reason,
+				Command:     s.Config.Command,
+				RootMarkers: s.Config.RootMarkers,
+			})
+		}
+
+		if jsonOutput {
+			return printJSON(cmd, struct {
+				Servers []server `json:"servers"`
+			}{Servers: servers})
+		}
+
+		if len(servers) == 0 {
+			cmd.Println("No language servers detected.")
+			return nil
+		}
+
+		status := func(s server) string {
+			if s.Enabled {
+				return "enabled"
+			}
+			return "skipped"
+		}
+
+		if term.IsTerminal(os.Stdout.Fd()) {
+			// We're in a TTY: make it fancy.
+			t := table.New().
+				Border(lipgloss.RoundedBorder()).
+				StyleFunc(func(row, col int) lipgloss.Style {
+					return lipgloss.NewStyle().Padding(0, 2)
+				}).
+				Headers("Name", "Source", "Status", "Reason", "Root markers")
+			for _, s := range servers {
+				t.Row(s.Name, s.Source, status(s), s.Reason, strings.Join(s.RootMarkers, ", "))
+			}
+			lipgloss.Println(t)
+			return nil
+		}
+
+		// Not a TTY: plain output
+		for _, s := range servers {
+			cmd.Printf("%s\t%s\t%s\t%s\t%s\n", s.Name, s.Source, status(s), s.Reason, strings.Join(s.RootMarkers, ","))
+		}
+		return nil
+	},
+}
+
+var lspCheckCmd = &cobra.Command{
+	Use:   "check <file>",
+	Short: "Check a file with the matching language servers",
+	Long:  "Start the language servers enabled for the working directory that handle the file, open it and print its diagnostics. Exits with an error if any server fails or reports errors",
+	Example: `
+# Check a Go file
+crush lsp check internal/app/app.go
+
+# Wait longer for slow servers
+crush lsp check src/main.rs --timeout 2m
+
+# Output the diagnostics as JSON
+crush lsp check main.go --json
+  `,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		jsonOutput, _ := cmd.Flags().GetBool("json")
+		timeout, _ := cmd.Flags().GetDuration("timeout")
+
+		cfg, err := initConfig(cmd)
+		if err != nil {
+			return err
+		}
+
+		path, err := filepath.Abs(args[0])
+		if err != nil {
+			return err
+		}
+		if _, err := os.Stat(path); err != nil {
+			return err
+		}
+
+		type diagnostic struct {
+			Line     int    `json:"line"`
+			Column   int    `json:"column"`
+			Severity string `json:"severity"`
+			Source   string `json:"source,omitempty"`
+			Message  string `json:"message"`
+		}
+		type result struct {
+			Server      string       `json:"server"`
+			Error       string       `json:"error,omitempty"`
+			Diagnostics []diagnostic `json:"diagnostics"`
+		}
+
+		var results []result
+		var failed, errs int
+		for _, s := range app.DetectLSPServers(cfg) {
+			if !s.Enabled || !lsp.HandlesFileType(s.Config.FileTypes, path) {
+				continue
+			}
+			r := result{Server: s.Name, Diagnostics: []diagnostic{}}
+			diags, err := checkFile(cmd.Context(), cfg, s, path, timeout)
+			if err != nil {
+				failed++
+				r.Error = err.Error()
+			}
+			for _, d := range diags {
+				if d.Severity == protocol.SeverityError {
+					errs++
+				}
+				r.Diagnostics = append(r.Diagnostics, diagnostic{
+					Line:     int(d.Range.Start.Line) + 1,
+					Column:   int(d.Range.Start.Character) + 1,
+					Severity: severityName(d.Severity),
+					Source:   d.Source,
+					Message:  d.Message,
+				})
+			}
+			results = append(results, r)
+		}
+
+		if len(results) == 0 {
+			return fmt.Errorf("no enabled language server handles %s, see crush lsp list", args[0])
+		}
+
+		if jsonOutput {
+			if err := printJSON(cmd, struct {
+				File    string   `json:"file"`
+				Results []result `json:"results"`
+			}{File: args[0], Results: results}); err != nil {
+				return err
+			}
+		} else {
+			for _, r := range results {
+				if r.Error != "" {
+					cmd.Printf("%s: %s\n", r.Server, r.Error)
+					continue
+				}
+				cmd.Printf("%s: %d diagnostic(s)\n", r.Server, len(r.Diagnostics))
+				for _, d := range r.Diagnostics {
+					source := ""
+					if d.Source != "" {
+						source = fmt.Sprintf(" (%s)", d.Source)
+					}
+					cmd.Printf("  %s:%d:%d: %s: %s%s\n", args[0], d.Line, d.Column, d.Severity, d.Message, source)
+				}
+			}
+		}
+
+		switch {
+		case failed > 0:
+			return fmt.Errorf("%d language server(s) failed", failed)
+		case errs > 0:
+			return fmt.Errorf("%d error(s) found", errs)
+		}
+		return nil
+	},
+}
+
+func init() {
+	lspListCmd.Flags().Bool("json", false, "Output as JSON")
+	lspListCmd.Flags().Bool("all", false, "Include default servers unrelated to the working directory")
+
+	lspCheckCmd.Flags().Bool("json", false, "Output as JSON")
+	lspCheckCmd.Flags().Duration("timeout", 30*time.Second, "How long to wait for each server to start and report diagnostics")
+
+	lspCmd.AddCommand(
+		lspListCmd,
+		lspCheckCmd,
+	)
+}
+
+// checkFile starts the language server, opens the file and returns the
+// diagnostics the server published for it.
+func checkFile(ctx context.Context, cfg *config.Config, server app.LSPServer, path string, timeout time.Duration) ([]protocol.Diagnostic, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	client, err := lsp.New(ctx, server.Name, server.Config, cfg.Resolver())
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		closeCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = client.Close(closeCtx)
+	}()
+
+	if _, err := client.Initialize(ctx, cfg.WorkingDir()); err != nil {
+		return nil, err
+	}
+	if err := client.WaitForServerReady(ctx); err != nil {
+		return nil, err
+	}
+	if err := client.OpenFile(ctx, path); err != nil {
+		return nil, err
+	}
+	if !client.WaitForFileDiagnostics(ctx, path, timeout) {
+		return nil, fmt.Errorf("no diagnostics received within %s", timeout)
+	}
+	// Servers may publish fast diagnostics first, and complete ones later.
+	client.WaitForDiagnostics(ctx, time.Second)
+
+	diags := client.GetFileDiagnostics(protocol.URIFromPath(path))
+	slices.SortStableFunc(diags, func(a, b protocol.Diagnostic) int {
+		return cmp.Or(
+			cmp.Compare(a.Range.Start.Line, b.Range.Start.Line),
+			cmp.Compare(a.Range.Start.Character, b.Range.Start.Character),
+		)
+	})
+	return diags, nil
+}
+
+func severityName(severity protocol.DiagnosticSeverity) string {
+	switch severity {
+	case protocol.SeverityError:
+		return "error"
+	case protocol.SeverityWarning:
+		return "warning"
+	case protocol.SeverityHint:
+		return "hint"
+	default:
+		return "info"
+	}
+}
@@ -0,0 +1,502 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+
+	"charm.land/lipgloss/v2"
+	"charm.land/lipgloss/v2/table"
+	"charm.land/log/v2"
+	"github.com/charmbracelet/colorprofile"
+	"github.com/charmbracelet/crush/internal/agent/tools/mcp"
+	"github.com/charmbracelet/crush/internal/app"
+	"github.com/charmbracelet/crush/internal/config"
+	"github.com/charmbracelet/crush/internal/event"
+	"github.com/charmbracelet/x/term"
+	"github.com/spf13/cobra"
+)
+
+var mcpCmd = &cobra.Command{
+	Use:   "mcp",
+	Short: "Manage MCP servers",
+	Long:  "Inspect, test and manage the Model Context Protocol servers configured for Crush",
+}
+
+var mcpListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List configured MCP servers",
+	Long:  "Connect to all configured MCP servers and show their state and how many tools and prompts they provide",
+	Example: `
+# List all MCP servers in a table
+crush mcp list
+
+# Output MCP servers as JSON
+crush mcp list --json
+  `,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		jsonOutput, _ := cmd.Flags().GetBool("json")
+
+		cfg, err := initConfig(cmd)
+		if err != nil {
+			return err
+		}
+
+		mcp.Initialize(cmd.Context(), nil, cfg)
+		defer mcp.Close()
+
+		type server struct {
+			Name    string `json:"name"`
+			Type    string `json:"type"`
+			State   string `json:"state"`
+			Tools   int    `json:"tools"`
+			Prompts int    `json:"prompts"`
+			Error   string `json:"error,omitempty"`
+		}
+
+		states := mcp.GetStates()
+		servers := make([]server, 0, len(cfg.MCP))
+		for _, m := range cfg.MCP.Sorted() {
+			state := states[m.Name]
+			s := server{
+				Name:    m.Name,
+				Type:    string(cmpMCPType(m.MCP.Type)),
+				State:   state.State.String(),
+				Tools:   state.Counts.Tools,
+				Prompts: state.Counts.Prompts,
+			}
+			if state.Error != nil {
+				s.Error = state.Error.Error()
+			}
+			servers = append(servers, s)
+		}
+
+		if jsonOutput {
+			return printJSON(cmd, struct {
+				Servers []server `json:"servers"`
+			}{Servers: servers})
+		}
+
+		if len(servers) == 0 {
+			cmd.Println("No MCP servers configured.")
+			return nil
+		}
+
+		if term.IsTerminal(os.Stdout.Fd()) {
+			// We're in a TTY: make it fancy.
+			t := table.New().
+				Border(lipgloss.RoundedBorder()).
+				StyleFunc(func(row, col int) lipgloss.Style {
+					return lipgloss.NewStyle().Padding(0, 2)
+				}).
+				Headers("Name", "Type", "State", "Tools", "Prompts", "Error")
+			for _, s := range servers {
+				t.Row(s.Name, s.Type, s.State, strconv.Itoa(s.Tools), strconv.Itoa(s.Prompts), s.Error)
+			}
+			lipgloss.Println(t)
+			return nil
+		}
+
+		// Not a TTY: plain output
+		for _, s := range servers {
+			cmd.Printf("%s\t%s\t%s\t%d\t%d\t%s\n", s.Name, s.Type, s.State, s.Tools, s.Prompts, s.Error)
+		}
+		return nil
+	},
+}
+
+var mcpAddCmd = &cobra.Command{
+	Use:   "add <name> [-- <command> [args...]]",
+	Short: "Add an MCP server to the configuration",
+	Long:  "Add an MCP server to the project configuration, or to the global configuration with --global",
+	Example: `
+# Add a stdio MCP server to the project configuration
+crush mcp add filesystem -- npx -y @modelcontextprotocol/server-filesystem .
+
+# Add an HTTP MCP server to the global configuration
+crush mcp add github --url https://api.githubcopilot.com/mcp/ --header "Authorization=Bearer $GH_PAT" --global
+  `,
+	Args: cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		name := args[0]
+		var command []string
+		if dash := cmd.ArgsLenAtDash(); dash >= 0 {
+			if dash != 1 {
+				return errors.New("expected a single server name before --")
+			}
+			command = args[dash:]
+		} else if len(args) > 1 {
+			return errors.New("the server command must be passed after --")
+		}
+
+		mcpType, _ := cmd.Flags().GetString("type")
+		url, _ := cmd.Flags().GetString("url")
+		headers, _ := cmd.Flags().GetStringArray("header")
+		env, _ := cmd.Flags().GetStringArray("env")
+		timeout, _ := cmd.Flags().GetInt("timeout")
+
+		m := config.MCPConfig{
+			Type:    config.MCPType(mcpType),
+			URL:     url,
+			Timeout: timeout,
+		}
+		if m.Type == "" {
+			m.Type = config.MCPStdio
+			if url != "" {
+				m.Type = config.MCPHttp
+			}
+		}
+
+		var err error
+		if m.Headers, err = parseKeyValues(headers); err != nil {
+			return fmt.Errorf("invalid header: %w", err)
+		}
+		if m.Env, err = parseKeyValues(env); err != nil {
+			return fmt.Errorf("invalid env: %w", err)
+		}
+
+		switch m.Type {
+		case config.MCPStdio:
+			if len(command) == 0 {
+				return errors.New("stdio MCP servers require a command after --")
+			}
+			m.Command = command[0]
+			m.Args = command[1:]
+		case config.MCPHttp, config.MCPSSE:
+			if url == "" {
+				return fmt.Errorf("%s MCP servers require --url", m.Type)
+			}
+			if len(command) > 0 {
+				return fmt.Errorf("%s MCP servers do not take a command", m.Type)
+			}
+		default:
+			return fmt.Errorf("unsupported mcp type: %s", m.Type)
+		}
+
+		path, err := mcpConfigPath(cmd)
+		if err != nil {
+			return err
+		}
+		key := config.ConfigKey("mcp", name)
+		if config.HasFileConfigField(path, key) {
+			return fmt.Errorf("mcp %q already exists in %s", name, path)
+		}
+		if err := config.SetFileConfigField(path, key, m); err != nil {
+			return err
+		}
+		cmd.Printf("Added MCP server %q to %s\n", name, path)
+		return nil
+	},
+}
+
+var mcpRemoveCmd = &cobra.Command{
+	Use:     "remove <name>",
+	Aliases: []string{"rm"},
+	Short:   "Remove an MCP server from the configuration",
+	Long:    "Remove an MCP server from the project configuration, or from the global configuration with --global",
+	Args:    cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		name := args[0]
+		path, err := mcpConfigPath(cmd)
+		if err != nil {
+			return err
+		}
+		key := config.ConfigKey("mcp", name)
+		if !config.HasFileConfigField(path, key) {
+			return fmt.Errorf("mcp %q not found in %s", name, path)
+		}
+		if err := config.RemoveFileConfigField(path, key); err != nil {
+			return err
+		}
+		cmd.Printf("Removed MCP server %q from %s\n", name, path)
+		return nil
+	},
+}
+
+var mcpTestCmd = &cobra.Command{
+	Use:   "test <name>",
+	Short: "Connect to an MCP server and show what it provides",
+	Long:  "Connect to an MCP server and print its instructions, tools and prompts",
+	Example: `
+# Test the "github" MCP server
+crush mcp test github
+
+# Output the tools and prompts as JSON
+crush mcp test github --json
+  `,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		jsonOutput, _ := cmd.Flags().GetBool("json")
+
+		cfg, m, err := loadMCPConfig(cmd, args[0])
+		if err != nil {
+			return err
+		}
+
+		info, err := mcp.Inspect(cmd.Context(), args[0], m, cfg.Resolver())
+		if err != nil {
+			return fmt.Errorf("failed to connect to mcp %q: %w", args[0], err)
+		}
+
+		if jsonOutput {
+			return printJSON(cmd, info)
+		}
+
+		if info.Server != nil {
+			cmd.Printf("Connected to %s %s\n", info.Server.Name, info.Server.Version)
+		} else {
+			cmd.Printf("Connected to %s\n", info.Name)
+		}
+		if info.Instructions != "" {
+			cmd.Printf("\nInstructions:\n%s\n", strings.TrimSpace(info.Instructions))
+		}
+		cmd.Printf("\nTools (%d):\n", len(info.Tools))
+		for _, tool := range info.Tools {
+			cmd.Printf("  %s\t%s\n", tool.Name, firstLine(tool.Description))
+		}
+		cmd.Printf("\nPrompts (%d):\n", len(info.Prompts))
+		for _, prompt := range info.Prompts {
+			cmd.Printf("  %s\t%s\n", prompt.Name, firstLine(prompt.Description))
+		}
+		return nil
+	},
+}
+
+var mcpCallCmd = &cobra.Command{
+	Use:   "call <name> <tool> [json-arguments]",
+	Short: "Call a tool of an MCP server",
+	Long:  "Connect to an MCP server, call one of its tools with the given JSON arguments and print the result",
+	Example: `
+# Call a tool without arguments
+crush mcp call github get_me
+
+# Call a tool with arguments and print the raw result as JSON
+crush mcp call github search_repositories '{"query": "crush"}' --json
+  `,
+	Args: cobra.RangeArgs(2, 3),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		jsonOutput, _ := cmd.Flags().GetBool("json")
+
+		input := "{}"
+		if len(args) == 3 {
+			input = args[2]
+		}
+
+		cfg, m, err := loadMCPConfig(cmd, args[0])
+		if err != nil {
+			return err
+		}
+
+		result, err := mcp.Call(cmd.Context(), args[0], m, cfg.Resolver(), args[1], input)
+		if err != nil {
+			return fmt.Errorf("failed to call %s on mcp %q: %w", args[1], args[0], err)
+		}
+
+		if jsonOutput {
+			return printJSON(cmd, result)
+		}
+		if result.Content != "" {
+			cmd.Println(result.Content)
+		}
+		if result.IsError {
+			return fmt.Errorf("tool %s of mcp %q returned an error", args[1], args[0])
+		}
+		return nil
+	},
+}
+
+var mcpServeCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "Serve Crush as an MCP server",
+	Long: `Serve Crush's built-in tools, its coder agent and its sessions over the
+Model Context Protocol, on stdio by default or over streamable HTTP with --http.
+Permission requests that are not allowed by the configuration are sent to the
+MCP client as elicitations.`,
+	Example: `
+# Serve over stdio, e.g. from another tool's MCP configuration
+crush mcp serve
+
+# Serve over streamable HTTP
+crush mcp serve --http localhost:8080
+  `,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		httpAddr, _ := cmd.Flags().GetString("http")
+
+		ctx, cancel := signal.NotifyContext(cmd.Context(), os.Interrupt)
+		defer cancel()
+
+		appInstance, err := setupApp(cmd)
+		if err != nil {
+			return err
+		}
+		defer appInstance.Shutdown()
+
+		if !appInstance.Config().IsConfigured() {
+			return fmt.Errorf("no providers configured - please run 'crush' to set up a provider interactively")
+		}
+
+		event.SetNonInteractive(true)
+		event.AppInitialized()
+		defer event.AppExited()
+
+		return appInstance.ServeMCP(ctx, app.MCPServeOptions{HTTPAddr: httpAddr})
+	},
+}
+
+var mcpLogsCmd = &cobra.Command{
+	Use:   "logs <name>",
+	Short: "View the logs of an MCP server",
+	Long:  "View the logs of an MCP server, including its stderr output and the log notifications it sent.",
+	Example: `
+# Show the logs of the "github" MCP server
+crush mcp logs github
+
+# Follow the logs of the "github" MCP server
+crush mcp logs github -f
+  `,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		follow, _ := cmd.Flags().GetBool("follow")
+		tailLines, _ := cmd.Flags().GetInt("tail")
+
+		log.SetLevel(log.DebugLevel)
+		log.SetOutput(os.Stdout)
+		if !term.IsTerminal(os.Stdout.Fd()) {
+			log.SetColorProfile(colorprofile.NoTTY)
+		}
+
+		cfg, _, err := loadMCPConfig(cmd, args[0])
+		if err != nil {
+			return err
+		}
+
+		name := args[0]
+		logsFile := mcp.LogFile(cfg.Options.DataDirectory, name)
+		if _, err := os.Stat(logsFile); os.IsNotExist(err) {
+			log.Warn("No logs found for this MCP server yet.", "name", name)
+			return nil
+		}
+
+		if follow {
+			return followLogs(cmd.Context(), logsFile, tailLines)
+		}
+		return showLogs(logsFile, tailLines)
+	},
+}
+
+func init() {
+	mcpListCmd.Flags().Bool("json", false, "Output as JSON")
+
+	mcpAddCmd.Flags().String("type", "", "Type of the MCP server: stdio, http or sse (default: stdio, or http when --url is set)")
+	mcpAddCmd.Flags().String("url", "", "URL of an HTTP or SSE MCP server")
+	mcpAddCmd.Flags().StringArray("header", nil, "HTTP header in KEY=VALUE form, can be repeated")
+	mcpAddCmd.Flags().StringArray("env", nil, "Environment variable in KEY=VALUE form, can be repeated")
+	mcpAddCmd.Flags().Int("timeout", 0, "Timeout in seconds for connecting to the MCP server")
+	mcpAddCmd.Flags().BoolP("global", "g", false, "Write to the global configuration instead of the project one")
+
+	mcpRemoveCmd.Flags().BoolP("global", "g", false, "Remove from the global configuration instead of the project one")
+
+	mcpTestCmd.Flags().Bool("json", false, "Output as JSON")
+
+	mcpCallCmd.Flags().Bool("json", false, "Output the raw result as JSON")
+
+	mcpServeCmd.Flags().String("http", "", "Serve streamable HTTP on the given address instead of stdio")
+	mcpServeCmd.Flags().BoolP("yolo", "y", false, "Automatically accept all permissions (dangerous mode)")
+
+	mcpLogsCmd.Flags().BoolP("follow", "f", false, "Follow log output")
+	mcpLogsCmd.Flags().IntP("tail", "t", defaultTailLines, "Show only the last N lines")
+
+	mcpCmd.AddCommand(
+		mcpListCmd,
+		mcpAddCmd,
+		mcpRemoveCmd,
+		mcpTestCmd,
+		mcpCallCmd,
+		mcpServeCmd,
+		mcpLogsCmd,
+	)
+}
+
+// initConfig loads the configuration and makes it globally available, as
+// the MCP and LSP packages rely on it.
+func initConfig(cmd *cobra.Command) (*config.Config, error) {
+	dataDir, _ := cmd.Flags().GetString("data-dir")
+	cwd, err := ResolveCwd(cmd)
+	if err != nil {
+		return nil, err
+	}
+	cfg, err := config.Init(cwd, dataDir, false)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load configuration: %v", err)
+	}
+	return cfg, nil
+}
+
+// loadMCPConfig loads the configuration and returns the config of the MCP
+// server with the given name.
+func loadMCPConfig(cmd *cobra.Command, name string) (*config.Config, config.MCPConfig, error) {
+	cfg, err := initConfig(cmd)
+	if err != nil {
+		return nil, config.MCPConfig{}, err
+	}
+	m, ok := cfg.MCP[name]
+	if !ok {
+		return nil, config.MCPConfig{}, fmt.Errorf("mcp %q is not configured", name)
+	}
+	return cfg, m, nil
+}
+
+// mcpConfigPath returns the config file MCP servers are added to or removed
+// from.
+func mcpConfigPath(cmd *cobra.Command) (string, error) {
+	if global, _ := cmd.Flags().GetBool("global"); global {
+		return config.GlobalConfig(), nil
+	}
+	cwd, err := ResolveCwd(cmd)
+	if err != nil {
+		return "", err
+	}
+	return config.ProjectConfigPath(cwd), nil
+}
+
+func cmpMCPType(t config.MCPType) config.MCPType {
+	if t == "" {
+		return config.MCPStdio
+	}
+	return t
+}
+
+func parseKeyValues(values []string) (map[string]string, error) {
+	if len(values) == 0 {
+		return nil, nil
+	}
+	result := make(map[string]string, len(values))
+	for _, v := range values {
+		key, value, ok := strings.Cut(v, "=")
+		if !ok || strings.TrimSpace(key) == "" {
+			return nil, fmt.Errorf("expected KEY=VALUE, got %q", v)
+		}
+		result[strings.TrimSpace(key)] = value
+	}
+	return result, nil
+}
+
+func firstLine(s string) string {
+	line, _, _ := strings.Cut(strings.TrimSpace(s), "\n")
+	return line
+}
+
+func printJSON(cmd *cobra.Command, v any) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	cmd.Println(string(data))
+	return nil
+}
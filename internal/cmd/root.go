@@ -52,6 +52,8 @@ func init() {
 		schemaCmd,
 		loginCmd,
 		statsCmd,
+		mcpCmd,
+		lspCmd,
 	)
 }
 
@@ -0,0 +1,424 @@
+// Package codeindex maintains a local index of the symbols and imports of the
+// source files in a project, for structured lookups without a language
+// server.
+package codeindex
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/charmbracelet/crush/internal/fsext"
+)
+
+// indexVersion is bumped whenever the parsers change, so stale indexes are
+// rebuilt.
+const indexVersion = 1
+
+const (
+	// maxFileSize is the size above which files are not indexed, as they are
+	// most likely generated.
+	maxFileSize = 1024 * 1024
+
+	// minUpdateInterval is how long lookups use the index as is before
+	// checking the files again.
+	minUpdateInterval = 5 * time.Second
+)
+
+// Kinds of symbols.
+const (
+	KindFunction  = "function"
+	KindMethod    = "method"
+	KindType      = "type"
+	KindStruct    = "struct"
+	KindInterface = "interface"
+	KindClass     = "class"
+	KindEnum      = "enum"
+	KindTrait     = "trait"
+	KindModule    = "module"
+	KindConst     = "const"
+	KindVar       = "var"
+)
+
+// Symbol is a declaration found in a source file.
+type Symbol struct {
+	Name string `json:"name"`
+	Kind string `json:"kind"`
+	// Container is the type or class the symbol belongs to, if any.
+	Container string `json:"container,omitempty"`
+	// Path is the path of the file, relative to the first root of the index,
+	// or absolute for the files of the other roots.
+	Path string `json:"path"`
+	// Line is the 1-based line of the declaration.
+	Line int `json:"line"`
+	// Signature is the declaration line, or the signature of functions.
+	Signature string `json:"signature,omitempty"`
+}
+
+// QualifiedName returns the name of the symbol prefixed by its container,
+// e.g. "Client.Run".
+func (s Symbol) QualifiedName() string {
+	if s.Container == "" {
+		return s.Name
+	}
+	return s.Container + "." + s.Name
+}
+
+// File holds what the index knows about a source file.
+type File struct {
+	ModTime time.Time `json:"mod_time"`
+	Size    int64     `json:"size"`
+	Package string    `json:"package,omitempty"`
+	Imports []string  `json:"imports,omitempty"`
+	Symbols []Symbol  `json:"symbols,omitempty"`
+}
+
+// Index is the index of the source files under the roots of a workspace,
+// stored in a file and updated incrementally from the modification times of
+// the files.
+type Index struct {
+	roots func() []string
+	path  string
+
+	updateMu sync.Mutex
+
+	mu         sync.RWMutex
+	files      map[string]File
+	loaded     bool
+	lastUpdate time.Time
+}
+
+type indexFile struct {
+	Version int             `json:"version"`
+	Files   map[string]File `json:"files"`
+}
+
+// New returns the index of the source files under the roots, stored at path.
+// The roots are read on every update, so roots added later are indexed too;
+// the first one is the working directory. The index is loaded and built on
+// first use.
+func New(roots func() []string, path string) *Index {
+	return &Index{
+		roots: roots,
+		path:  path,
+		files: make(map[string]File),
+	}
+}
+
+// Update parses the source files added or changed since the last update and
+// drops the removed ones, saving the index if anything changed. It returns
+// the number of files parsed and removed.
+func (i *Index) Update(ctx context.Context) (parsed, removed int, err error) {
+	i.updateMu.Lock()
+	defer i.updateMu.Unlock()
+
+	i.mu.Lock()
+	if !i.loaded {
+		if err := i.load(); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			slog.Warn("Failed to load code index, rebuilding it", "path", i.path, "error", err)
+		}
+		i.loaded = true
+	}
+	files := i.files
+	i.mu.Unlock()
+
+	roots := i.roots()
+	var paths []string
+	for _, root := range roots {
+		rootPaths, _, err := fsext.ListDirectory(root, nil, 0, 0)
+		if err != nil {
+			return 0, 0, fmt.Errorf("failed to list files: %w", err)
+		}
+		paths = append(paths, rootPaths...)
+	}
+
+	updated := make(map[string]File, len(files))
+	for _, path := range paths {
+		if err := ctx.Err(); err != nil {
+			return 0, 0, err
+		}
+		if strings.HasSuffix(path, string(filepath.Separator)) || !Supported(path) {
+			continue
+		}
+		rel, ok := relPath(roots, path)
+		if !ok {
+			continue
+		}
+		if _, ok := updated[rel]; ok {
+			// Listed by a root nested in another one.
+			continue
+		}
+		info, err := os.Stat(path)
+		if err != nil || info.Size() > maxFileSize {
+			continue
+		}
+
+		if file, ok := files[rel]; ok && file.ModTime.Equal(info.ModTime()) && file.Size == info.Size() {
+			updated[rel] = file
+			continue
+		}
+
+		content, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+		file := Parse(rel, content)
+		file.ModTime = info.ModTime()
+		file.Size = info.Size()
+		updated[rel] = file
+		parsed++
+	}
+	for rel := range files {
+		if _, ok := updated[rel]; !ok {
+			removed++
+		}
+	}
+
+	i.mu.Lock()
+	i.files = updated
+	i.lastUpdate = time.Now()
+	i.mu.Unlock()
+
+	if parsed > 0 || removed > 0 {
+		slog.Debug("Updated code index", "parsed", parsed, "removed", removed, "files", len(updated))
+		if err := i.save(updated); err != nil {
+			return parsed, removed, fmt.Errorf("failed to save code index: %w", err)
+		}
+	}
+	return parsed, removed, nil
+}
+
+// Invalidate makes the next refresh update the index, e.g. when files of the
+// workspace changed.
+func (i *Index) Invalidate() {
+	if i == nil {
+		return
+	}
+	i.mu.Lock()
+	i.lastUpdate = time.Time{}
+	i.mu.Unlock()
+}
+
+// Refresh updates the index unless it was updated very recently and not
+// invalidated since.
+func (i *Index) Refresh(ctx context.Context) error {
+	i.mu.RLock()
+	fresh := time.Since(i.lastUpdate) < minUpdateInterval
+	i.mu.RUnlock()
+	if fresh {
+		return nil
+	}
+	_, _, err := i.Update(ctx)
+	return err
+}
+
+// SearchOptions narrows down a search.
+type SearchOptions struct {
+	// Kind only returns symbols of the given kind.
+	Kind string
+	// Path only returns symbols in the file or directory, as returned by
+	// RelPath.
+	Path string
+	// Limit is the maximum number of symbols returned; no limit if zero.
+	Limit int
+}
+
+// Search returns the symbols whose name matches the query, best matches
+// first. The query is matched without case against the names, and against
+// the container too when qualified, e.g. "Client.Run". It also returns the
+// number of symbols matching before the limit.
+func (i *Index) Search(query string, opts SearchOptions) ([]Symbol, int) {
+	if i == nil {
+		return nil, 0
+	}
+
+	container, name, _ := cutLast(query, ".")
+	container, lowerName := strings.ToLower(container), strings.ToLower(name)
+	prefix := strings.TrimRight(filepath.ToSlash(opts.Path), "/")
+
+	type match struct {
+		symbol Symbol
+		rank   int
+	}
+	var matches []match
+
+	i.mu.RLock()
+	for path, file := range i.files {
+		if prefix != "" && prefix != "." && path != prefix && !strings.HasPrefix(path, prefix+"/") {
+			continue
+		}
+		for _, symbol := range file.Symbols {
+			if opts.Kind != "" && symbol.Kind != opts.Kind {
+				continue
+			}
+			if container != "" && strings.ToLower(symbol.Container) != container {
+				continue
+			}
+			if rank, ok := matchRank(symbol.Name, name, lowerName); ok {
+				matches = append(matches, match{symbol, rank})
+			}
+		}
+	}
+	i.mu.RUnlock()
+
+	slices.SortFunc(matches, func(a, b match) int {
+		return cmp.Or(
+			cmp.Compare(a.rank, b.rank),
+			cmp.Compare(len(a.symbol.Name), len(b.symbol.Name)),
+			strings.Compare(a.symbol.Path, b.symbol.Path),
+			cmp.Compare(a.symbol.Line, b.symbol.Line),
+		)
+	})
+
+	total := len(matches)
+	if opts.Limit > 0 && len(matches) > opts.Limit {
+		matches = matches[:opts.Limit]
+	}
+	symbols := make([]Symbol, len(matches))
+	for j, m := range matches {
+		symbols[j] = m.symbol
+	}
+	return symbols, total
+}
+
+// RelPath returns the path of a file or directory as used by the index:
+// relative to the first root, or absolute in the other roots. It returns
+// false if the path is outside of the roots.
+func (i *Index) RelPath(path string) (string, bool) {
+	if i == nil {
+		return "", false
+	}
+	return relPath(i.roots(), path)
+}
+
+func relPath(roots []string, path string) (string, bool) {
+	for j, root := range roots {
+		rel, err := filepath.Rel(root, path)
+		if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			continue
+		}
+		if j > 0 {
+			return filepath.ToSlash(filepath.Clean(path)), true
+		}
+		return filepath.ToSlash(rel), true
+	}
+	return "", false
+}
+
+// File returns what the index knows about the file, as returned by RelPath.
+func (i *Index) File(path string) (File, bool) {
+	if i == nil {
+		return File{}, false
+	}
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+	file, ok := i.files[filepath.ToSlash(filepath.Clean(path))]
+	return file, ok
+}
+
+// Importers returns the files importing the given package or module,
+// sorted.
+func (i *Index) Importers(imp string) []string {
+	if i == nil {
+		return nil
+	}
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+	var paths []string
+	for path, file := range i.files {
+		if slices.Contains(file.Imports, imp) {
+			paths = append(paths, path)
+		}
+	}
+	slices.Sort(paths)
+	return paths
+}
+
+// Symbols returns the symbols of the given kinds, sorted by path and line;
+// all of them if no kind is given.
+func (i *Index) Symbols(kinds ...string) []Symbol {
+	if i == nil {
+		return nil
+	}
+	i.mu.RLock()
+	var symbols []Symbol
+	for _, file := range i.files {
+		for _, symbol := range file.Symbols {
+			if len(kinds) == 0 || slices.Contains(kinds, symbol.Kind) {
+				symbols = append(symbols, symbol)
+			}
+		}
+	}
+	i.mu.RUnlock()
+	slices.SortFunc(symbols, func(a, b Symbol) int {
+		return cmp.Or(strings.Compare(a.Path, b.Path), cmp.Compare(a.Line, b.Line))
+	})
+	return symbols
+}
+
+// matchRank returns how well the name matches the query, lower being better.
+func matchRank(name, query, lowerQuery string) (int, bool) {
+	lowerName := strings.ToLower(name)
+	switch {
+	case name == query || lowerQuery == "":
+		return 0, true
+	case lowerName == lowerQuery:
+		return 1, true
+	case strings.HasPrefix(lowerName, lowerQuery):
+		return 2, true
+	case strings.Contains(lowerName, lowerQuery):
+		return 3, true
+	}
+	return 0, false
+}
+
+func cutLast(s, sep string) (before, after string, found bool) {
+	if idx := strings.LastIndex(s, sep); idx != -1 {
+		return s[:idx], s[idx+len(sep):], true
+	}
+	return "", s, false
+}
+
+func (i *Index) load() error {
+	data, err := os.ReadFile(i.path)
+	if err != nil {
+		return err
+	}
+	var stored indexFile
+	if err := json.Unmarshal(data, &stored); err != nil {
+		return err
+	}
+	if stored.Version != indexVersion {
+		return nil
+	}
+	if stored.Files != nil {
+		i.files = stored.Files
+	}
+	return nil
+}
+
+func (i *Index) save(files map[string]File) error {
+	data, err := json.Marshal(indexFile{Version: indexVersion, Files: files})
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(i.path), 0o700); err != nil {
+		return err
+	}
+	// Write to a temporary file first so readers never see a partial index.
+	tmp := i.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, i.path)
+}
@@ -0,0 +1,126 @@
+package codeindex
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestIndexUpdate(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	indexPath := filepath.Join(t.TempDir(), "code_index.json")
+	write := func(name, content string) {
+		path := filepath.Join(root, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+	}
+	write("main.go", "package main\n\nimport \"example.com/app/server\"\n\nfunc main() { server.Run() }\n")
+	write("server/server.go", "package server\n\ntype Server struct{}\n\nfunc Run() {}\n\nfunc (s *Server) Run() {}\n")
+	write("README.md", "# App\n")
+
+	roots := func() []string { return []string{root} }
+	idx := New(roots, indexPath)
+	parsed, removed, err := idx.Update(t.Context())
+	require.NoError(t, err)
+	require.Equal(t, 2, parsed)
+	require.Equal(t, 0, removed)
+	require.FileExists(t, indexPath)
+
+	symbols, total := idx.Search("run", SearchOptions{})
+	require.Equal(t, 2, total)
+	require.Equal(t, []string{"function Run", "method Server.Run"}, names(symbols))
+
+	symbols, _ = idx.Search("Server.Run", SearchOptions{})
+	require.Equal(t, []string{"method Server.Run"}, names(symbols))
+
+	symbols, _ = idx.Search("", SearchOptions{Kind: KindStruct})
+	require.Equal(t, []string{"struct Server"}, names(symbols))
+
+	symbols, _ = idx.Search("", SearchOptions{Path: "server"})
+	require.Len(t, symbols, 3)
+
+	symbols, total = idx.Search("r", SearchOptions{Limit: 1})
+	require.Len(t, symbols, 1)
+	require.Equal(t, 3, total)
+
+	require.Equal(t, []string{"main.go"}, idx.Importers("example.com/app/server"))
+
+	file, ok := idx.File("server/server.go")
+	require.True(t, ok)
+	require.Equal(t, "server", file.Package)
+
+	// Nothing changed.
+	parsed, removed, err = idx.Update(t.Context())
+	require.NoError(t, err)
+	require.Equal(t, 0, parsed)
+	require.Equal(t, 0, removed)
+
+	// A new index loads the stored one and only parses what changed.
+	write("server/server.go", "package server\n\nfunc Start() {}\n")
+	later := time.Now().Add(time.Minute)
+	require.NoError(t, os.Chtimes(filepath.Join(root, "server/server.go"), later, later))
+	require.NoError(t, os.Remove(filepath.Join(root, "main.go")))
+
+	idx = New(roots, indexPath)
+	parsed, removed, err = idx.Update(t.Context())
+	require.NoError(t, err)
+	require.Equal(t, 1, parsed)
+	require.Equal(t, 1, removed)
+
+	symbols, _ = idx.Search("", SearchOptions{})
+	require.Equal(t, []string{"function Start"}, names(symbols))
+}
+
+func TestIndexRoots(t *testing.T) {
+	t.Parallel()
+
+	root, other := t.TempDir(), t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(root, "main.go"), []byte("package main\n\nfunc main() {}\n"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(other, "lib.go"), []byte("package lib\n\nfunc Helper() {}\n"), 0o644))
+
+	roots := []string{root}
+	idx := New(func() []string { return roots }, filepath.Join(t.TempDir(), "code_index.json"))
+	_, _, err := idx.Update(t.Context())
+	require.NoError(t, err)
+	symbols, _ := idx.Search("", SearchOptions{})
+	require.Equal(t, []string{"function main"}, names(symbols))
+
+	// Roots added later are indexed on the next refresh once invalidated.
+	roots = append(roots, other)
+	require.NoError(t, idx.Refresh(t.Context()))
+	symbols, _ = idx.Search("helper", SearchOptions{})
+	require.Empty(t, symbols)
+	idx.Invalidate()
+	require.NoError(t, idx.Refresh(t.Context()))
+	symbols, _ = idx.Search("helper", SearchOptions{})
+	require.Len(t, symbols, 1)
+
+	otherPath, ok := idx.RelPath(other)
+	require.True(t, ok)
+	require.Equal(t, filepath.ToSlash(filepath.Join(other, "lib.go")), symbols[0].Path)
+	symbols, _ = idx.Search("", SearchOptions{Path: otherPath})
+	require.Equal(t, []string{"function Helper"}, names(symbols))
+
+	rel, ok := idx.RelPath(filepath.Join(root, "main.go"))
+	require.True(t, ok)
+	require.Equal(t, "main.go", rel)
+	_, ok = idx.RelPath(filepath.Dir(root))
+	require.False(t, ok)
+}
+
+func TestIndexNil(t *testing.T) {
+	t.Parallel()
+
+	var idx *Index
+	symbols, total := idx.Search("x", SearchOptions{})
+	require.Empty(t, symbols)
+	require.Zero(t, total)
+	require.Empty(t, idx.Symbols())
+	_, ok := idx.File("x.go")
+	require.False(t, ok)
+}
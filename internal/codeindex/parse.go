@@ -0,0 +1,399 @@
+package codeindex
+
+import (
+	"bytes"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// maxSignatureLength is the length above which signatures are truncated.
+const maxSignatureLength = 200
+
+// Supported returns whether the index can parse the file.
+func Supported(path string) bool {
+	ext := strings.ToLower(filepath.Ext(path))
+	if ext == ".go" {
+		return true
+	}
+	_, ok := languages[ext]
+	return ok
+}
+
+// Parse returns the package, imports and symbols declared in the file. The
+// path is only used to pick the parser and is stored in the symbols.
+func Parse(path string, content []byte) File {
+	ext := strings.ToLower(filepath.Ext(path))
+	if ext == ".go" {
+		return parseGo(path, content)
+	}
+	if lang, ok := languages[ext]; ok {
+		return lang.parse(path, content)
+	}
+	return File{}
+}
+
+// parseGo parses Go files with the standard library parser. Files with syntax
+// errors are indexed as far as they can be parsed.
+func parseGo(path string, content []byte) File {
+	fset := token.NewFileSet()
+	f, _ := parser.ParseFile(fset, path, content, parser.SkipObjectResolution)
+	if f == nil {
+		return File{}
+	}
+
+	file := File{Package: f.Name.Name}
+	for _, imp := range f.Imports {
+		if p, err := strconv.Unquote(imp.Path.Value); err == nil {
+			file.Imports = append(file.Imports, p)
+		}
+	}
+
+	line := func(pos token.Pos) int {
+		return fset.Position(pos).Line
+	}
+	// source returns the source between the positions, on a single line.
+	source := func(from, to token.Pos) string {
+		start, end := fset.Position(from).Offset, fset.Position(to).Offset
+		if start < 0 || end > len(content) || start >= end {
+			return ""
+		}
+		return signature(string(content[start:end]))
+	}
+
+	for _, decl := range f.Decls {
+		switch decl := decl.(type) {
+		case *ast.FuncDecl:
+			symbol := Symbol{
+				Name:      decl.Name.Name,
+				Kind:      KindFunction,
+				Path:      path,
+				Line:      line(decl.Name.Pos()),
+				Signature: source(decl.Pos(), decl.Type.End()),
+			}
+			if decl.Recv != nil && len(decl.Recv.List) > 0 {
+				symbol.Kind = KindMethod
+				symbol.Container = receiverType(decl.Recv.List[0].Type)
+			}
+			file.Symbols = append(file.Symbols, symbol)
+		case *ast.GenDecl:
+			for _, spec := range decl.Specs {
+				switch spec := spec.(type) {
+				case *ast.TypeSpec:
+					kind := KindType
+					switch t := spec.Type.(type) {
+					case *ast.StructType:
+						kind = KindStruct
+					case *ast.InterfaceType:
+						kind = KindInterface
+						for _, method := range t.Methods.List {
+							if _, ok := method.Type.(*ast.FuncType); !ok || len(method.Names) == 0 {
+								continue
+							}
+							file.Symbols = append(file.Symbols, Symbol{
+								Name:      method.Names[0].Name,
+								Kind:      KindMethod,
+								Container: spec.Name.Name,
+								Path:      path,
+								Line:      line(method.Pos()),
+								Signature: source(method.Pos(), method.End()),
+							})
+						}
+					}
+					file.Symbols = append(file.Symbols, Symbol{
+						Name:      spec.Name.Name,
+						Kind:      kind,
+						Path:      path,
+						Line:      line(spec.Name.Pos()),
+						Signature: "type " + source(spec.Name.Pos(), spec.Type.Pos()) + " " + typeKeyword(spec.Type, source),
+					})
+				case *ast.ValueSpec:
+					kind := KindVar
+					if decl.Tok == token.CONST {
+						kind = KindConst
+					}
+					for _, name := range spec.Names {
+						if name.Name == "_" {
+							continue
+						}
+						file.Symbols = append(file.Symbols, Symbol{
+							Name: name.Name,
+							Kind: kind,
+							Path: path,
+							Line: line(name.Pos()),
+						})
+					}
+				}
+			}
+		}
+	}
+	return file
+}
+
+// receiverType returns the name of the type of a method receiver, without
+// pointer and type parameters.
+func receiverType(expr ast.Expr) string {
+	switch t := expr.(type) {
+	case *ast.StarExpr:
+		return receiverType(t.X)
+	case *ast.IndexExpr:
+		return receiverType(t.X)
+	case *ast.IndexListExpr:
+		return receiverType(t.X)
+	case *ast.Ident:
+		return t.Name
+	}
+	return ""
+}
+
+// typeKeyword returns how the type of a type declaration starts, e.g.
+// "struct" or "func(string) error", without the fields or methods.
+func typeKeyword(expr ast.Expr, source func(from, to token.Pos) string) string {
+	switch expr.(type) {
+	case *ast.StructType:
+		return "struct"
+	case *ast.InterfaceType:
+		return "interface"
+	}
+	return source(expr.Pos(), expr.End())
+}
+
+// signature collapses the whitespace of a declaration and truncates it.
+func signature(s string) string {
+	s = strings.Join(strings.Fields(s), " ")
+	if len(s) > maxSignatureLength {
+		s = s[:maxSignatureLength] + "..."
+	}
+	return s
+}
+
+// symbolPattern matches the declaration of a symbol in a line. The name of the
+// symbol is captured by the "name" group.
+type symbolPattern struct {
+	re   *regexp.Regexp
+	kind string
+	// container marks declarations whose indented lines belong to them, such
+	// as classes. Container patterns without kind are not symbols themselves,
+	// like Rust impl blocks.
+	container bool
+	// member marks declarations only recognized inside a container, as they
+	// would match plain statements elsewhere.
+	member bool
+}
+
+// keywords are the control keywords looking like a call, which the patterns
+// of member functions would otherwise match.
+var keywords = map[string]bool{
+	"if": true, "for": true, "foreach": true, "while": true, "switch": true,
+	"catch": true, "return": true, "else": true, "do": true, "try": true,
+	"with": true, "new": true, "typeof": true, "await": true, "yield": true,
+	"super": true, "sizeof": true, "using": true, "lock": true, "synchronized": true,
+}
+
+// language extracts symbols and imports line by line with patterns, for the
+// languages without a parser in the standard library. Declarations more
+// indented than a container declaration belong to it.
+type language struct {
+	symbols []symbolPattern
+	// imports capture the imported module in their first group.
+	imports []*regexp.Regexp
+	// methods is the kind of the functions declared in a container.
+	methods string
+}
+
+func (l *language) parse(path string, content []byte) File {
+	type container struct {
+		name   string
+		indent int
+	}
+	var (
+		file       File
+		containers []container
+	)
+	for n, line := range bytes.Split(content, []byte("\n")) {
+		text := strings.TrimRight(string(line), "\r")
+		trimmed := strings.TrimLeft(text, " \t")
+		if trimmed == "" {
+			continue
+		}
+		indent := len(text) - len(trimmed)
+
+		// Leave the containers this line is not indented into.
+		for len(containers) > 0 && indent <= containers[len(containers)-1].indent {
+			containers = containers[:len(containers)-1]
+		}
+
+		for _, re := range l.imports {
+			if m := re.FindStringSubmatch(text); m != nil {
+				file.Imports = append(file.Imports, strings.TrimSpace(m[1]))
+			}
+		}
+
+		for _, p := range l.symbols {
+			m := p.re.FindStringSubmatch(text)
+			if m == nil {
+				continue
+			}
+			name := m[p.re.SubexpIndex("name")]
+			if p.member && (len(containers) == 0 || keywords[name]) {
+				continue
+			}
+			if p.kind != "" {
+				symbol := Symbol{
+					Name:      name,
+					Kind:      p.kind,
+					Path:      path,
+					Line:      n + 1,
+					Signature: signature(strings.TrimRight(trimmed, "{:")),
+				}
+				if len(containers) > 0 {
+					symbol.Container = containers[len(containers)-1].name
+					if p.kind == KindFunction && l.methods != "" {
+						symbol.Kind = l.methods
+					}
+				}
+				file.Symbols = append(file.Symbols, symbol)
+			}
+			if p.container {
+				containers = append(containers, container{name: name, indent: indent})
+			}
+			break
+		}
+	}
+	return file
+}
+
+var (
+	pythonLanguage = &language{
+		symbols: []symbolPattern{
+			{re: regexp.MustCompile(`^\s*class\s+(?P<name>\w+)`), kind: KindClass, container: true},
+			{re: regexp.MustCompile(`^\s*(?:async\s+)?def\s+(?P<name>\w+)`), kind: KindFunction},
+			{re: regexp.MustCompile(`^(?P<name>[A-Z][A-Z0-9_]*)\s*(?::[^=]+)?=`), kind: KindConst},
+		},
+		imports: []*regexp.Regexp{
+			regexp.MustCompile(`^\s*import\s+([\w.]+)`),
+			regexp.MustCompile(`^\s*from\s+([\w.]+)\s+import\b`),
+		},
+		methods: KindMethod,
+	}
+
+	javaScriptLanguage = &language{
+		symbols: []symbolPattern{
+			{re: regexp.MustCompile(`^\s*(?:export\s+)?(?:default\s+)?(?:abstract\s+)?class\s+(?P<name>\w+)`), kind: KindClass, container: true},
+			{re: regexp.MustCompile(`^\s*(?:export\s+)?interface\s+(?P<name>\w+)`), kind: KindInterface, container: true},
+			{re: regexp.MustCompile(`^\s*(?:export\s+)?(?:const\s+)?enum\s+(?P<name>\w+)`), kind: KindEnum},
+			{re: regexp.MustCompile(`^\s*(?:export\s+)?type\s+(?P<name>\w+)(?:<[^=]*>)?\s*=`), kind: KindType},
+			{re: regexp.MustCompile(`^\s*(?:export\s+)?(?:default\s+)?(?:async\s+)?function\s*\*?\s*(?P<name>\w+)`), kind: KindFunction},
+			{re: regexp.MustCompile(`^\s*(?:export\s+)?(?:const|let|var)\s+(?P<name>\w+)\s*(?::[^=]+)?=\s*(?:async\s+)?(?:function\b|\([^)]*\)\s*(?::[^=]+)?=>|\w+\s*=>)`), kind: KindFunction},
+			{re: regexp.MustCompile(`^\s+(?:(?:public|private|protected|static|async|readonly|override|get|set)\s+)*(?P<name>[A-Za-z_$][\w$]*)\s*(?:<[^>]*>)?\([^)]*\)?\s*(?::[^{]+)?\{?\s*$`), kind: KindFunction, member: true},
+			{re: regexp.MustCompile(`^\s*export\s+(?:const|let|var)\s+(?P<name>\w+)`), kind: KindVar},
+		},
+		imports: []*regexp.Regexp{
+			regexp.MustCompile(`^\s*import\s.*?\bfrom\s+['"]([^'"]+)['"]`),
+			regexp.MustCompile(`^\s*import\s+['"]([^'"]+)['"]`),
+			regexp.MustCompile(`\brequire\(\s*['"]([^'"]+)['"]\s*\)`),
+		},
+		methods: KindMethod,
+	}
+
+	rustLanguage = &language{
+		symbols: []symbolPattern{
+			{re: regexp.MustCompile(`^\s*impl(?:<[^>]*>)?\s+(?:[\w:<>, ]+\s+for\s+)?(?P<name>\w+)`), container: true},
+			{re: regexp.MustCompile(`^\s*(?:pub(?:\([^)]*\))?\s+)?trait\s+(?P<name>\w+)`), kind: KindTrait, container: true},
+			{re: regexp.MustCompile(`^\s*(?:pub(?:\([^)]*\))?\s+)?(?:const\s+)?(?:async\s+)?(?:unsafe\s+)?(?:extern\s+"[^"]*"\s+)?fn\s+(?P<name>\w+)`), kind: KindFunction},
+			{re: regexp.MustCompile(`^\s*(?:pub(?:\([^)]*\))?\s+)?struct\s+(?P<name>\w+)`), kind: KindStruct},
+			{re: regexp.MustCompile(`^\s*(?:pub(?:\([^)]*\))?\s+)?enum\s+(?P<name>\w+)`), kind: KindEnum},
+			{re: regexp.MustCompile(`^\s*(?:pub(?:\([^)]*\))?\s+)?type\s+(?P<name>\w+)`), kind: KindType},
+			{re: regexp.MustCompile(`^\s*(?:pub(?:\([^)]*\))?\s+)?mod\s+(?P<name>\w+)`), kind: KindModule},
+			{re: regexp.MustCompile(`^\s*(?:pub(?:\([^)]*\))?\s+)?(?:const|static)\s+(?P<name>[A-Z][A-Z0-9_]*)\s*:`), kind: KindConst},
+		},
+		imports: []*regexp.Regexp{
+			regexp.MustCompile(`^\s*(?:pub\s+)?use\s+([\w:]+)`),
+		},
+		methods: KindMethod,
+	}
+
+	rubyLanguage = &language{
+		symbols: []symbolPattern{
+			{re: regexp.MustCompile(`^\s*class\s+(?P<name>[\w:]+)`), kind: KindClass, container: true},
+			{re: regexp.MustCompile(`^\s*module\s+(?P<name>[\w:]+)`), kind: KindModule, container: true},
+			{re: regexp.MustCompile(`^\s*def\s+(?:self\.)?(?P<name>\w+[?!=]?)`), kind: KindFunction},
+		},
+		imports: []*regexp.Regexp{
+			regexp.MustCompile(`^\s*require(?:_relative)?\s+['"]([^'"]+)['"]`),
+		},
+		methods: KindMethod,
+	}
+
+	javaLanguage = &language{
+		symbols: []symbolPattern{
+			{re: regexp.MustCompile(`^\s*(?:(?:public|private|protected|static|final|abstract|sealed|data|open|internal|partial)\s+)*class\s+(?P<name>\w+)`), kind: KindClass, container: true},
+			{re: regexp.MustCompile(`^\s*(?:(?:public|private|protected|static|sealed|internal|partial)\s+)*interface\s+(?P<name>\w+)`), kind: KindInterface, container: true},
+			{re: regexp.MustCompile(`^\s*(?:(?:public|private|protected|static|internal)\s+)*enum\s+(?:class\s+)?(?P<name>\w+)`), kind: KindEnum, container: true},
+			{re: regexp.MustCompile(`^\s*(?:(?:public|private|protected|static|final|internal)\s+)*record\s+(?P<name>\w+)`), kind: KindClass, container: true},
+			{re: regexp.MustCompile(`^\s*(?:(?:public|private|protected|internal|override|suspend|inline|open)\s+)*fun\s+(?:<[^>]*>\s*)?(?:\w+\.)?(?P<name>\w+)\s*\(`), kind: KindFunction},
+			{re: regexp.MustCompile(`^\s+(?:(?:public|private|protected|static|final|abstract|synchronized|native|virtual|override|async|internal)\s+)+[\w<>\[\],.? ]+\s+(?P<name>\w+)\s*\([^;]*$`), kind: KindFunction, member: true},
+		},
+		imports: []*regexp.Regexp{
+			regexp.MustCompile(`^\s*import\s+(?:static\s+)?([\w.*]+)`),
+			regexp.MustCompile(`^\s*using\s+([\w.]+)\s*;`),
+		},
+		methods: KindMethod,
+	}
+
+	cLanguage = &language{
+		symbols: []symbolPattern{
+			{re: regexp.MustCompile(`^(?:typedef\s+)?struct\s+(?P<name>\w+)\s*\{?\s*$`), kind: KindStruct},
+			{re: regexp.MustCompile(`^(?:typedef\s+)?enum\s+(?:class\s+)?(?P<name>\w+)`), kind: KindEnum},
+			{re: regexp.MustCompile(`^class\s+(?P<name>\w+)`), kind: KindClass, container: true},
+			{re: regexp.MustCompile(`^namespace\s+(?P<name>\w+)`), kind: KindModule},
+			{re: regexp.MustCompile(`^#define\s+(?P<name>\w+)`), kind: KindConst},
+			{re: regexp.MustCompile(`^(?:(?:static|inline|extern|const|unsigned|signed|virtual)\s+)*[\w:<>*&]+[\s*&]+\**(?:\w+::)?(?P<name>\w+)\s*\([^;]*$`), kind: KindFunction},
+		},
+		imports: []*regexp.Regexp{
+			regexp.MustCompile(`^\s*#include\s+[<"]([^>"]+)[>"]`),
+		},
+		methods: KindMethod,
+	}
+
+	phpLanguage = &language{
+		symbols: []symbolPattern{
+			{re: regexp.MustCompile(`^\s*(?:(?:abstract|final)\s+)?class\s+(?P<name>\w+)`), kind: KindClass, container: true},
+			{re: regexp.MustCompile(`^\s*interface\s+(?P<name>\w+)`), kind: KindInterface, container: true},
+			{re: regexp.MustCompile(`^\s*trait\s+(?P<name>\w+)`), kind: KindTrait, container: true},
+			{re: regexp.MustCompile(`^\s*(?:(?:public|private|protected|static|abstract|final)\s+)*function\s+&?(?P<name>\w+)`), kind: KindFunction},
+		},
+		imports: []*regexp.Regexp{
+			regexp.MustCompile(`^\s*use\s+([\w\\]+)`),
+		},
+		methods: KindMethod,
+	}
+)
+
+// languages maps file extensions to their pattern based parsers.
+var languages = map[string]*language{
+	".py":   pythonLanguage,
+	".js":   javaScriptLanguage,
+	".jsx":  javaScriptLanguage,
+	".mjs":  javaScriptLanguage,
+	".cjs":  javaScriptLanguage,
+	".ts":   javaScriptLanguage,
+	".tsx":  javaScriptLanguage,
+	".mts":  javaScriptLanguage,
+	".cts":  javaScriptLanguage,
+	".rs":   rustLanguage,
+	".rb":   rubyLanguage,
+	".java": javaLanguage,
+	".kt":   javaLanguage,
+	".cs":   javaLanguage,
+	".c":    cLanguage,
+	".h":    cLanguage,
+	".cc":   cLanguage,
+	".cpp":  cLanguage,
+	".hpp":  cLanguage,
+	".php":  phpLanguage,
+}
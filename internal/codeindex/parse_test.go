@@ -0,0 +1,202 @@
+package codeindex
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// names returns the qualified names and kinds of the symbols, e.g.
+// "method Client.Run".
+func names(symbols []Symbol) []string {
+	result := make([]string, len(symbols))
+	for i, s := range symbols {
+		result[i] = s.Kind + " " + s.QualifiedName()
+	}
+	return result
+}
+
+func TestParseGo(t *testing.T) {
+	t.Parallel()
+
+	file := Parse("client/client.go", []byte(`package client
+
+import (
+	"context"
+	str "strings"
+)
+
+const Version = "1.0"
+
+var (
+	ErrClosed = errors.New("closed")
+	_         = str.ToLower
+)
+
+type Runner interface {
+	Run(ctx context.Context) error
+	fmt.Stringer
+}
+
+type Client[T any] struct {
+	name string
+}
+
+type Handler func(string) error
+
+func New(name string) *Client[string] {
+	return &Client[string]{name: name}
+}
+
+func (c *Client[T]) Run(
+	ctx context.Context,
+) error {
+	return nil
+}
+`))
+
+	require.Equal(t, "client", file.Package)
+	require.Equal(t, []string{"context", "strings"}, file.Imports)
+	require.Equal(t, []string{
+		"const Version",
+		"var ErrClosed",
+		"method Runner.Run",
+		"interface Runner",
+		"struct Client",
+		"type Handler",
+		"function New",
+		"method Client.Run",
+	}, names(file.Symbols))
+
+	require.Equal(t, 16, file.Symbols[2].Line)
+	require.Equal(t, "Run(ctx context.Context) error", file.Symbols[2].Signature)
+	require.Equal(t, "type Client[T any] struct", file.Symbols[4].Signature)
+	require.Equal(t, "type Handler func(string) error", file.Symbols[5].Signature)
+	require.Equal(t, "func (c *Client[T]) Run( ctx context.Context, ) error", file.Symbols[7].Signature)
+	require.Equal(t, 30, file.Symbols[7].Line)
+}
+
+func TestParsePatterns(t *testing.T) {
+	t.Parallel()
+
+	t.Run("python", func(t *testing.T) {
+		file := Parse("app/models.py", []byte(`import os
+from typing import Optional
+
+MAX_SIZE = 10
+
+class User(Base):
+    """A user."""
+
+    def __init__(self, name):
+        self.name = name
+
+    async def save(self):
+        if self.name:
+            return True
+
+def load(path: str) -> Optional[User]:
+    pass
+`))
+		require.Equal(t, []string{"os", "typing"}, file.Imports)
+		require.Equal(t, []string{
+			"const MAX_SIZE",
+			"class User",
+			"method User.__init__",
+			"method User.save",
+			"function load",
+		}, names(file.Symbols))
+		require.Equal(t, 16, file.Symbols[4].Line)
+		require.Equal(t, "def load(path: str) -> Optional[User]", file.Symbols[4].Signature)
+	})
+
+	t.Run("typescript", func(t *testing.T) {
+		file := Parse("src/store.ts", []byte(`import { useState } from "react";
+import "./styles.css";
+const fs = require('fs');
+
+export interface Store {
+  get(key: string): string;
+}
+
+export type Key = string;
+
+export class MemoryStore implements Store {
+  private items = new Map();
+
+  constructor() {
+    if (debug) {
+      log();
+    }
+  }
+
+  async get(key: string): Promise<string> {
+    return this.items.get(key);
+  }
+}
+
+export const createStore = (name: string) => {
+  return new MemoryStore();
+};
+
+export default function App() {
+  return null;
+}
+`))
+		require.Equal(t, []string{"react", "./styles.css", "fs"}, file.Imports)
+		require.Equal(t, []string{
+			"interface Store",
+			"method Store.get",
+			"type Key",
+			"class MemoryStore",
+			"method MemoryStore.constructor",
+			"method MemoryStore.get",
+			"function createStore",
+			"function App",
+		}, names(file.Symbols))
+	})
+
+	t.Run("rust", func(t *testing.T) {
+		file := Parse("src/lib.rs", []byte(`use std::collections::HashMap;
+
+pub const MAX: usize = 10;
+
+pub struct Cache {
+    items: HashMap<String, String>,
+}
+
+impl Cache {
+    pub fn new() -> Self {
+        Self { items: HashMap::new() }
+    }
+}
+
+pub trait Store {
+    fn get(&self, key: &str) -> Option<String>;
+}
+
+impl Store for Cache {
+    fn get(&self, key: &str) -> Option<String> {
+        self.items.get(key).cloned()
+    }
+}
+
+fn helper() {}
+`))
+		require.Equal(t, []string{"std::collections::HashMap"}, file.Imports)
+		require.Equal(t, []string{
+			"const MAX",
+			"struct Cache",
+			"method Cache.new",
+			"trait Store",
+			"method Store.get",
+			"method Cache.get",
+			"function helper",
+		}, names(file.Symbols))
+	})
+
+	t.Run("unsupported", func(t *testing.T) {
+		require.False(t, Supported("README.md"))
+		require.Empty(t, Parse("README.md", []byte("# Title")).Symbols)
+	})
+}
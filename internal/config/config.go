@@ -246,6 +246,7 @@ func (Attribution) JSONSchemaExtend(schema *jsonschema.Schema) {
 type Options struct {
 	ContextPaths              []string     `json:"context_paths,omitempty" jsonschema:"description=Paths to files containing context information for the AI,example=.cursorrules,example=CRUSH.md"`
 	SkillsPaths               []string     `json:"skills_paths,omitempty" jsonschema:"description=Paths to directories containing Agent Skills (folders with SKILL.md files),example=~/.config/crush/skills,example=./skills"`
+	AdditionalDirectories     []string     `json:"additional_directories,omitempty" jsonschema:"description=Project directories the tools and LSPs can work in besides the working directory (relative to working directory),example=../shared"`
 	TUI                       *TUIOptions  `json:"tui,omitempty" jsonschema:"description=Terminal user interface options"`
 	Debug                     bool         `json:"debug,omitempty" jsonschema:"description=Enable debug logging,default=false"`
 	DebugLSP                  bool         `json:"debug_lsp,omitempty" jsonschema:"description=Enable debug logging for LSP servers,default=false"`
@@ -258,6 +259,7 @@ type Options struct {
 	DisableMetrics            bool         `json:"disable_metrics,omitempty" jsonschema:"description=Disable sending metrics,default=false"`
 	InitializeAs              string       `json:"initialize_as,omitempty" jsonschema:"description=Name of the context file to create/update during project initialization,default=AGENTS.md,example=AGENTS.md,example=CRUSH.md,example=CLAUDE.md,example=docs/LLMs.md"`
 	AutoLSP                   *bool        `json:"auto_lsp,omitempty" jsonschema:"description=Automatically setup LSPs based on root markers"`
+	DiagnosticsFeedback       bool         `json:"diagnostics_feedback,omitempty" jsonschema:"description=Send the LSP errors introduced during a turn back to the model before it ends the turn,default=false"`
 }
 
 type MCPs map[string]MCPConfig
@@ -347,7 +349,10 @@ type Agent struct {
 }
 
 type Tools struct {
-	Ls ToolLs `json:"ls,omitempty"`
+	Ls        ToolLs        `json:"ls,omitempty"`
+	Format    ToolFormat    `json:"format,omitempty"`
+	WebSearch ToolWebSearch `json:"web_search,omitempty"`
+	Fetch     ToolFetch     `json:"fetch,omitempty"`
 }
 
 type ToolLs struct {
@@ -359,6 +364,54 @@ func (t ToolLs) Limits() (depth, items int) {
 	return ptrValOr(t.MaxDepth, 0), ptrValOr(t.MaxItems, 0)
 }
 
+// ToolFormat configures formatting files after the edit, multiedit and write
+// tools change them.
+type ToolFormat struct {
+	OnWrite    bool                       `json:"on_write,omitempty" jsonschema:"description=Format files after the edit tools change them,default=false"`
+	Formatters map[string]FormatterConfig `json:"formatters,omitempty" jsonschema:"description=External formatters by name"`
+}
+
+// FormatterFor returns the external formatter handling the given file, if
+// any.
+func (t ToolFormat) FormatterFor(path string) (string, FormatterConfig, bool) {
+	name := strings.ToLower(filepath.Base(path))
+	for _, key := range slices.Sorted(maps.Keys(t.Formatters)) {
+		formatter := t.Formatters[key]
+		for _, filetype := range formatter.FileTypes {
+			suffix := strings.ToLower(filetype)
+			if !strings.HasPrefix(suffix, ".") {
+				suffix = "." + suffix
+			}
+			if strings.HasSuffix(name, suffix) {
+				return key, formatter, true
+			}
+		}
+	}
+	return "", FormatterConfig{}, false
+}
+
+// FormatterConfig is an external command formatting files in place.
+type FormatterConfig struct {
+	Command   string   `json:"command" jsonschema:"required,description=Command formatting a file in place. The file path is appended to the arguments,example=gofmt,example=prettier,example=ruff"`
+	Args      []string `json:"args,omitempty" jsonschema:"description=Arguments to pass to the formatter before the file path,example=-w,example=--write,example=format"`
+	FileTypes []string `json:"filetypes" jsonschema:"required,description=File types this formatter handles,example=go,example=ts,example=py"`
+}
+
+// ToolWebSearch configures the search backend of the web_search tool.
+type ToolWebSearch struct {
+	Provider string `json:"provider,omitempty" jsonschema:"description=Search backend used by the web_search tool,enum=duckduckgo,enum=searxng,enum=brave,enum=tavily,enum=kagi,default=duckduckgo"`
+	BaseURL  string `json:"base_url,omitempty" jsonschema:"description=Base URL of the search backend. Required for SearxNG and optional for the others,example=http://localhost:8888"`
+	APIKey   string `json:"api_key,omitempty" jsonschema:"description=API key for Brave, Tavily or Kagi. Supports environment variables,example=$BRAVE_API_KEY"`
+}
+
+// ToolFetch configures the on-disk cache of the fetch, web_fetch and
+// agentic_fetch tools.
+type ToolFetch struct {
+	DisableCache bool `json:"disable_cache,omitempty" jsonschema:"description=Disable the cache of fetched pages,default=false"`
+	CacheTTL     int  `json:"cache_ttl,omitempty" jsonschema:"description=Seconds a fetched page stays fresh when the server doesn't say how long to cache it,default=3600,example=86400"`
+	Offline      bool `json:"offline,omitempty" jsonschema:"description=Serve fetched pages only from the cache without network access,default=false"`
+}
+
 // Config holds the configuration for crush.
 type Config struct {
 	Schema string `json:"$schema,omitempty"`
@@ -385,7 +438,8 @@ type Config struct {
 	Agents map[string]Agent `json:"-"`
 
 	// Internal
-	workingDir string `json:"-"`
+	workingDir     string               `json:"-"`
+	additionalDirs *csync.Slice[string] `json:"-"`
 	// TODO: find a better way to do this this should probably not be part of the config
 	resolver       VariableResolver
 	dataConfigDir  string             `json:"-"`
@@ -484,15 +538,31 @@ func (c *Config) UpdatePreferredModel(modelType SelectedModelType, model Selecte
 }
 
 func (c *Config) HasConfigField(key string) bool {
-	data, err := os.ReadFile(c.dataConfigDir)
+	return HasFileConfigField(c.dataConfigDir, key)
+}
+
+func (c *Config) SetConfigField(key string, value any) error {
+	return SetFileConfigField(c.dataConfigDir, key, value)
+}
+
+func (c *Config) RemoveConfigField(key string) error {
+	return RemoveFileConfigField(c.dataConfigDir, key)
+}
+
+// HasFileConfigField checks whether the JSON config file at path has the given
+// key.
+func HasFileConfigField(path, key string) bool {
+	data, err := os.ReadFile(path)
 	if err != nil {
 		return false
 	}
 	return gjson.Get(string(data), key).Exists()
 }
 
-func (c *Config) SetConfigField(key string, value any) error {
-	data, err := os.ReadFile(c.dataConfigDir)
+// SetFileConfigField sets the given key in the JSON config file at path,
+// creating the file if it does not exist yet.
+func SetFileConfigField(path, key string, value any) error {
+	data, err := os.ReadFile(path)
 	if err != nil {
 		if os.IsNotExist(err) {
 			data = []byte("{}")
@@ -505,17 +575,19 @@ func (c *Config) SetConfigField(key string, value any) error {
 	if err != nil {
 		return fmt.Errorf("failed to set config field %s: %w", key, err)
 	}
-	if err := os.MkdirAll(filepath.Dir(c.dataConfigDir), 0o755); err != nil {
-		return fmt.Errorf("failed to create config directory %q: %w", c.dataConfigDir, err)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("failed to create config directory %q: %w", path, err)
 	}
-	if err := os.WriteFile(c.dataConfigDir, []byte(newValue), 0o600); err != nil {
+	if err := os.WriteFile(path, []byte(newValue), 0o600); err != nil {
 		return fmt.Errorf("failed to write config file: %w", err)
 	}
 	return nil
 }
 
-func (c *Config) RemoveConfigField(key string) error {
-	data, err := os.ReadFile(c.dataConfigDir)
+// RemoveFileConfigField removes the given key from the JSON config file at
+// path.
+func RemoveFileConfigField(path, key string) error {
+	data, err := os.ReadFile(path)
 	if err != nil {
 		return fmt.Errorf("failed to read config file: %w", err)
 	}
@@ -524,15 +596,45 @@ func (c *Config) RemoveConfigField(key string) error {
 	if err != nil {
 		return fmt.Errorf("failed to delete config field %s: %w", key, err)
 	}
-	if err := os.MkdirAll(filepath.Dir(c.dataConfigDir), 0o755); err != nil {
-		return fmt.Errorf("failed to create config directory %q: %w", c.dataConfigDir, err)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("failed to create config directory %q: %w", path, err)
 	}
-	if err := os.WriteFile(c.dataConfigDir, []byte(newValue), 0o600); err != nil {
+	if err := os.WriteFile(path, []byte(newValue), 0o600); err != nil {
 		return fmt.Errorf("failed to write config file: %w", err)
 	}
 	return nil
 }
 
+// ConfigKey joins the given parts into a config field key, escaping
+// characters with a special meaning in keys, so names containing dots can be
+// used as map keys.
+func ConfigKey(parts ...string) string {
+	escaped := make([]string, len(parts))
+	for i, part := range parts {
+		escaped[i] = configKeyEscaper.Replace(part)
+	}
+	return strings.Join(escaped, ".")
+}
+
+var configKeyEscaper = strings.NewReplacer(
+	".", `\.`,
+	"*", `\*`,
+	"?", `\?`,
+)
+
+// ProjectConfigPath returns the path of the project config file in the given
+// directory. An existing crush.json or .crush.json is preferred, otherwise
+// crush.json is used.
+func ProjectConfigPath(dir string) string {
+	for _, name := range []string{appName + ".json", "." + appName + ".json"} {
+		path := filepath.Join(dir, name)
+		if _, err := os.Stat(path); err == nil {
+			return path
+		}
+	}
+	return filepath.Join(dir, appName+".json")
+}
+
 // RefreshOAuthToken refreshes the OAuth token for the given provider.
 func (c *Config) RefreshOAuthToken(ctx context.Context, providerID string) error {
 	providerConfig, exists := c.Providers.Get(providerID)
@@ -692,18 +794,31 @@ func allToolNames() []string {
 		"bash",
 		"job_output",
 		"job_kill",
+		"job_wait",
+		"job_list",
+		"git_status",
+		"git_diff",
+		"git_log",
+		"git_blame",
+		"git_commit",
 		"download",
 		"edit",
 		"multiedit",
+		"apply_patch",
+		"notebook_edit",
 		"lsp_diagnostics",
 		"lsp_references",
+		"lsp_hover",
+		"lsp_rename",
 		"lsp_restart",
 		"fetch",
 		"agentic_fetch",
+		"web_search",
 		"glob",
 		"grep",
 		"ls",
 		"sourcegraph",
+		"symbols",
 		"todos",
 		"view",
 		"write",
@@ -719,7 +834,7 @@ func resolveAllowedTools(allTools []string, disabledTools []string) []string {
 }
 
 func resolveReadOnlyTools(tools []string) []string {
-	readOnlyTools := []string{"glob", "grep", "ls", "sourcegraph", "view"}
+	readOnlyTools := []string{"git_blame", "git_diff", "git_log", "git_status", "glob", "grep", "ls", "sourcegraph", "symbols", "view"}
 	// filter to only include tools that are in allowedtools (include mode)
 	return filterSlice(tools, readOnlyTools, true)
 }
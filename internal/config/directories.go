@@ -0,0 +1,110 @@
+package config
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/charmbracelet/crush/internal/csync"
+	"github.com/charmbracelet/crush/internal/home"
+)
+
+// AdditionalDirectories returns the project directories the tools and LSPs
+// work in besides the working directory, as absolute paths.
+func (c *Config) AdditionalDirectories() []string {
+	if c.additionalDirs == nil {
+		return nil
+	}
+	return c.additionalDirs.Copy()
+}
+
+// WorkspaceRoots returns the working directory followed by the additional
+// directories.
+func (c *Config) WorkspaceRoots() []string {
+	return append([]string{c.workingDir}, c.AdditionalDirectories()...)
+}
+
+// AddDirectory adds a project directory for the rest of the session. Relative
+// paths are resolved against the working directory. It returns the absolute
+// path of the directory, and false if it was already part of the workspace.
+func (c *Config) AddDirectory(dir string) (string, bool, error) {
+	path, err := c.resolveDirectory(dir)
+	if err != nil {
+		return "", false, err
+	}
+	if IsInRoots(path, c.WorkspaceRoots()...) {
+		return path, false, nil
+	}
+	if c.additionalDirs == nil {
+		c.additionalDirs = csync.NewSlice[string]()
+	}
+	c.additionalDirs.Append(path)
+	c.Options.AdditionalDirectories = append(c.Options.AdditionalDirectories, path)
+	return path, true, nil
+}
+
+// resolveDirectory expands and makes the directory absolute, making sure it
+// exists.
+func (c *Config) resolveDirectory(dir string) (string, error) {
+	path := home.Long(strings.TrimSpace(dir))
+	if path == "" {
+		return "", fmt.Errorf("directory is required")
+	}
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(c.workingDir, path)
+	}
+	path = filepath.Clean(path)
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", err
+	}
+	if !info.IsDir() {
+		return "", fmt.Errorf("%s is not a directory", path)
+	}
+	return path, nil
+}
+
+// setAdditionalDirectories resolves the configured additional directories,
+// skipping the ones that don't exist.
+func (c *Config) setAdditionalDirectories() {
+	c.additionalDirs = csync.NewSlice[string]()
+	var roots []string
+	for _, dir := range c.Options.AdditionalDirectories {
+		path, err := c.resolveDirectory(dir)
+		if err != nil {
+			slog.Warn("Skipping additional directory", "directory", dir, "error", err)
+			continue
+		}
+		if IsInRoots(path, c.workingDir) || slices.Contains(roots, path) {
+			continue
+		}
+		roots = append(roots, path)
+	}
+	c.additionalDirs.SetSlice(roots)
+}
+
+// IsInRoots returns whether the path is one of the roots or inside one of
+// them.
+func IsInRoots(path string, roots ...string) bool {
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return false
+	}
+	for _, root := range roots {
+		if root == "" {
+			continue
+		}
+		absRoot, err := filepath.Abs(root)
+		if err != nil {
+			continue
+		}
+		rel, err := filepath.Rel(absRoot, absPath)
+		if err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,85 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSetAdditionalDirectories(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	workingDir := filepath.Join(root, "project")
+	shared := filepath.Join(root, "shared")
+	require.NoError(t, os.MkdirAll(filepath.Join(workingDir, "sub"), 0o755))
+	require.NoError(t, os.MkdirAll(shared, 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(root, "file.txt"), nil, 0o644))
+
+	cfg := &Config{
+		workingDir: workingDir,
+		Options: &Options{
+			AdditionalDirectories: []string{
+				"../shared",
+				shared,
+				"sub",
+				"../missing",
+				"../file.txt",
+			},
+		},
+	}
+	cfg.setAdditionalDirectories()
+
+	require.Equal(t, []string{shared}, cfg.AdditionalDirectories())
+	require.Equal(t, []string{workingDir, shared}, cfg.WorkspaceRoots())
+}
+
+func TestAddDirectory(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	workingDir := filepath.Join(root, "project")
+	shared := filepath.Join(root, "shared")
+	require.NoError(t, os.MkdirAll(filepath.Join(shared, "pkg"), 0o755))
+	require.NoError(t, os.MkdirAll(workingDir, 0o755))
+
+	cfg := &Config{workingDir: workingDir, Options: &Options{}}
+	require.Empty(t, cfg.AdditionalDirectories())
+
+	path, added, err := cfg.AddDirectory("../shared")
+	require.NoError(t, err)
+	require.True(t, added)
+	require.Equal(t, shared, path)
+	require.Equal(t, []string{shared}, cfg.AdditionalDirectories())
+	require.Equal(t, []string{shared}, cfg.Options.AdditionalDirectories)
+
+	path, added, err = cfg.AddDirectory(filepath.Join(shared, "pkg"))
+	require.NoError(t, err)
+	require.False(t, added)
+	require.Equal(t, filepath.Join(shared, "pkg"), path)
+
+	_, added, err = cfg.AddDirectory(".")
+	require.NoError(t, err)
+	require.False(t, added)
+
+	_, _, err = cfg.AddDirectory("../missing")
+	require.Error(t, err)
+
+	_, _, err = cfg.AddDirectory(" ")
+	require.Error(t, err)
+}
+
+func TestIsInRoots(t *testing.T) {
+	t.Parallel()
+
+	roots := []string{"/work/project", "/work/shared"}
+	require.True(t, IsInRoots("/work/project", roots...))
+	require.True(t, IsInRoots("/work/project/main.go", roots...))
+	require.True(t, IsInRoots("/work/shared/pkg/lib.go", roots...))
+	require.False(t, IsInRoots("/work/projectile/main.go", roots...))
+	require.False(t, IsInRoots("/work/other/main.go", roots...))
+	require.False(t, IsInRoots("/work", roots...))
+	require.False(t, IsInRoots("/work/project/main.go", ""))
+}
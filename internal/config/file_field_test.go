@@ -0,0 +1,41 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileConfigField(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "crush.json")
+	key := ConfigKey("mcp", "context7.com")
+
+	require.False(t, HasFileConfigField(path, key))
+	require.NoError(t, SetFileConfigField(path, key, MCPConfig{
+		Type: MCPHttp,
+		URL:  "https://mcp.context7.com/mcp",
+	}))
+	require.True(t, HasFileConfigField(path, key))
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.JSONEq(t, `{"mcp":{"context7.com":{"type":"http","url":"https://mcp.context7.com/mcp"}}}`, string(data))
+
+	require.NoError(t, RemoveFileConfigField(path, key))
+	require.False(t, HasFileConfigField(path, key))
+}
+
+func TestProjectConfigPath(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	require.Equal(t, filepath.Join(dir, "crush.json"), ProjectConfigPath(dir))
+
+	hidden := filepath.Join(dir, ".crush.json")
+	require.NoError(t, os.WriteFile(hidden, []byte("{}"), 0o600))
+	require.Equal(t, hidden, ProjectConfigPath(dir))
+}
@@ -468,7 +468,7 @@ func TestConfig_setupAgentsWithNoDisabledTools(t *testing.T) {
 
 	taskAgent, ok := cfg.Agents[AgentTask]
 	require.True(t, ok)
-	assert.Equal(t, []string{"glob", "grep", "ls", "sourcegraph", "view"}, taskAgent.AllowedTools)
+	assert.Equal(t, []string{"git_status", "git_diff", "git_log", "git_blame", "glob", "grep", "ls", "sourcegraph", "symbols", "view"}, taskAgent.AllowedTools)
 }
 
 func TestConfig_setupAgentsWithDisabledTools(t *testing.T) {
@@ -486,21 +486,26 @@ func TestConfig_setupAgentsWithDisabledTools(t *testing.T) {
 	coderAgent, ok := cfg.Agents[AgentCoder]
 	require.True(t, ok)
 
-	assert.Equal(t, []string{"agent", "bash", "job_output", "job_kill", "multiedit", "lsp_diagnostics", "lsp_references", "lsp_restart", "fetch", "agentic_fetch", "glob", "ls", "sourcegraph", "todos", "view", "write"}, coderAgent.AllowedTools)
+	assert.Equal(t, []string{"agent", "bash", "job_output", "job_kill", "job_wait", "job_list", "git_status", "git_diff", "git_log", "git_blame", "git_commit", "multiedit", "apply_patch", "notebook_edit", "lsp_diagnostics", "lsp_references", "lsp_hover", "lsp_rename", "lsp_restart", "fetch", "agentic_fetch", "web_search", "glob", "ls", "sourcegraph", "symbols", "todos", "view", "write"}, coderAgent.AllowedTools)
 
 	taskAgent, ok := cfg.Agents[AgentTask]
 	require.True(t, ok)
-	assert.Equal(t, []string{"glob", "ls", "sourcegraph", "view"}, taskAgent.AllowedTools)
+	assert.Equal(t, []string{"git_status", "git_diff", "git_log", "git_blame", "glob", "ls", "sourcegraph", "symbols", "view"}, taskAgent.AllowedTools)
 }
 
 func TestConfig_setupAgentsWithEveryReadOnlyToolDisabled(t *testing.T) {
 	cfg := &Config{
 		Options: &Options{
 			DisabledTools: []string{
+				"git_status",
+				"git_diff",
+				"git_log",
+				"git_blame",
 				"glob",
 				"grep",
 				"ls",
 				"sourcegraph",
+				"symbols",
 				"view",
 			},
 		},
@@ -509,7 +514,7 @@ func TestConfig_setupAgentsWithEveryReadOnlyToolDisabled(t *testing.T) {
 	cfg.SetupAgents()
 	coderAgent, ok := cfg.Agents[AgentCoder]
 	require.True(t, ok)
-	assert.Equal(t, []string{"agent", "bash", "job_output", "job_kill", "download", "edit", "multiedit", "lsp_diagnostics", "lsp_references", "lsp_restart", "fetch", "agentic_fetch", "todos", "write"}, coderAgent.AllowedTools)
+	assert.Equal(t, []string{"agent", "bash", "job_output", "job_kill", "job_wait", "job_list", "git_commit", "download", "edit", "multiedit", "apply_patch", "notebook_edit", "lsp_diagnostics", "lsp_references", "lsp_hover", "lsp_rename", "lsp_restart", "fetch", "agentic_fetch", "web_search", "todos", "write"}, coderAgent.AllowedTools)
 
 	taskAgent, ok := cfg.Agents[AgentTask]
 	require.True(t, ok)
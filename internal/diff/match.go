@@ -0,0 +1,136 @@
+package diff
+
+import "strings"
+
+const (
+	// FuzzyMatchThreshold is the similarity above which a block of lines
+	// matches lines that are not the same, from 0 to 1.
+	FuzzyMatchThreshold = 0.9
+
+	// maxFuzzyMatchComparisons is the number of line comparisons above which
+	// fuzzy matching is skipped, as it would take too long.
+	maxFuzzyMatchComparisons = 500_000
+)
+
+// LineMatcher compares the lines of a file with the lines looked for in it,
+// one line at a time.
+type LineMatcher struct {
+	// Description tells how the lines matched, e.g. "ignoring indentation".
+	Description string
+	Match       func(fileLine, line string) bool
+}
+
+// LineMatchers are the ways lines are matched when they aren't found
+// exactly, from the strictest to the most tolerant. When none matches, the
+// block of lines is looked for with [ClosestLines].
+var LineMatchers = []LineMatcher{
+	{
+		Description: "ignoring trailing whitespace",
+		Match: func(fileLine, line string) bool {
+			return strings.TrimRight(fileLine, " \t") == strings.TrimRight(line, " \t")
+		},
+	},
+	{
+		Description: "ignoring indentation",
+		Match: func(fileLine, line string) bool {
+			return strings.TrimSpace(fileLine) == strings.TrimSpace(line)
+		},
+	},
+	{
+		Description: "ignoring whitespace",
+		Match: func(fileLine, line string) bool {
+			return normalizeSpace(fileLine) == normalizeSpace(line)
+		},
+	},
+}
+
+// LinesMatch returns whether each of the lines of the file matches the line
+// looked for at the same index.
+func LinesMatch(fileLines, lines []string, match func(fileLine, line string) bool) bool {
+	for i := range lines {
+		if !match(fileLines[i], lines[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// ClosestLines returns the index of the block of lines of the file most
+// similar to the lines looked for, its similarity from 0 to 1, and whether
+// it's the only block similar enough to match, according to
+// [FuzzyMatchThreshold]. Whitespace is collapsed before comparing lines. It
+// returns -1 if there are too many lines to compare.
+func ClosestLines(fileLines, lines []string) (best int, score float64, unique bool) {
+	windows := len(fileLines) - len(lines) + 1
+	if windows <= 0 || windows*len(lines) > maxFuzzyMatchComparisons {
+		return -1, 0, false
+	}
+
+	normalized := make([]string, len(lines))
+	for i, line := range lines {
+		normalized[i] = normalizeSpace(line)
+	}
+
+	best, matches := -1, 0
+	for i := range windows {
+		total := 0.0
+		for j, line := range normalized {
+			// Stop early when this block can't be the best nor match.
+			bound := min(score, FuzzyMatchThreshold)
+			if (total+float64(len(lines)-j))/float64(len(lines)) < bound {
+				total = -1
+				break
+			}
+			total += similarity(normalizeSpace(fileLines[i+j]), line)
+		}
+		if total < 0 {
+			continue
+		}
+		s := total / float64(len(lines))
+		if s >= FuzzyMatchThreshold {
+			matches++
+		}
+		if best == -1 || s > score {
+			best, score = i, s
+		}
+	}
+	return best, score, matches == 1
+}
+
+func normalizeSpace(line string) string {
+	return strings.Join(strings.Fields(line), " ")
+}
+
+// similarity returns how similar two strings are, from 0 to 1, based on
+// their Levenshtein distance.
+func similarity(a, b string) float64 {
+	if a == b {
+		return 1
+	}
+	ra, rb := []rune(a), []rune(b)
+	longest := max(len(ra), len(rb))
+	if longest == 0 {
+		return 1
+	}
+	return 1 - float64(levenshtein(ra, rb))/float64(longest)
+}
+
+func levenshtein(a, b []rune) int {
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(b)]
+}
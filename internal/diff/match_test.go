@@ -0,0 +1,34 @@
+package diff
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestClosestLines(t *testing.T) {
+	t.Parallel()
+
+	lines := []string{"func a() {", "\treturn 1", "}", "func b() {", "\treturn 2", "}"}
+
+	best, score, unique := ClosestLines(lines, []string{"func b()  {", "  return 2;"})
+	require.Equal(t, 3, best)
+	require.InDelta(t, 0.95, score, 0.01)
+	require.True(t, unique)
+
+	// Both functions are similar enough to the lines.
+	_, _, unique = ClosestLines(lines, []string{"func c() {", "\treturn 3"})
+	require.False(t, unique)
+
+	best, _, _ = ClosestLines(lines[:1], []string{"a", "b"})
+	require.Equal(t, -1, best)
+}
+
+func TestSimilarity(t *testing.T) {
+	t.Parallel()
+
+	require.Equal(t, 1.0, similarity("", ""))
+	require.Equal(t, 1.0, similarity("abc", "abc"))
+	require.Equal(t, 0.0, similarity("abc", "xyz"))
+	require.InDelta(t, 0.75, similarity("abcd", "abed"), 0.001)
+}
@@ -0,0 +1,464 @@
+package diff
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// FilePatch is the change a patch makes to a single file.
+type FilePatch struct {
+	// OldPath is the path of the file before the change, empty if the file
+	// is created.
+	OldPath string
+	// NewPath is the path of the file after the change, empty if the file is
+	// deleted.
+	NewPath string
+	Hunks   []Hunk
+}
+
+// IsCreate returns whether the patch creates the file.
+func (p FilePatch) IsCreate() bool {
+	return p.OldPath == ""
+}
+
+// IsDelete returns whether the patch deletes the file.
+func (p FilePatch) IsDelete() bool {
+	return p.NewPath == ""
+}
+
+// Hunk is a change to a contiguous range of lines.
+type Hunk struct {
+	// OldStart is the 1-based line where the hunk starts in the original
+	// file, used as a hint to find it; zero if unknown.
+	OldStart int
+	// Anchor is a line preceding the hunk, used to find it when the context
+	// lines are ambiguous, e.g. the declaration of the function it changes.
+	Anchor string
+	Lines  []HunkLine
+	// NoNewlineAtEnd is true if the new file has no newline at the end.
+	NoNewlineAtEnd bool
+}
+
+// HunkLine is a line of a hunk.
+type HunkLine struct {
+	// Op is ' ' for context lines, '-' for removed lines and '+' for added
+	// lines.
+	Op   byte
+	Text string
+}
+
+// oldLines returns the lines the hunk expects in the original file.
+func (h Hunk) oldLines() []string {
+	var lines []string
+	for _, line := range h.Lines {
+		if line.Op != '+' {
+			lines = append(lines, line.Text)
+		}
+	}
+	return lines
+}
+
+// newLines returns the lines replacing the lines of the file the hunk
+// matched. Context lines are taken from the file rather than from the hunk,
+// as they may only match it ignoring whitespace.
+func (h Hunk) newLines(matched []string) []string {
+	var lines []string
+	i := 0
+	for _, line := range h.Lines {
+		switch line.Op {
+		case ' ':
+			lines = append(lines, matched[i])
+			i++
+		case '-':
+			i++
+		default:
+			lines = append(lines, line.Text)
+		}
+	}
+	return lines
+}
+
+// ParsePatch parses a patch changing one or more files. It accepts unified
+// diffs, as output by diff -u or git diff, and the structured format
+// delimited by "*** Begin Patch" and "*** End Patch".
+func ParsePatch(patch string) ([]FilePatch, error) {
+	patch = strings.ReplaceAll(patch, "\r\n", "\n")
+	lines := strings.Split(strings.TrimRight(patch, "\n"), "\n")
+
+	var (
+		patches []FilePatch
+		err     error
+	)
+	if isStructuredPatch(lines) {
+		patches, err = parseStructuredPatch(lines)
+	} else {
+		patches, err = parseUnifiedDiff(lines)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(patches) == 0 {
+		return nil, fmt.Errorf("no file changes found in the patch")
+	}
+	return patches, nil
+}
+
+func isStructuredPatch(lines []string) bool {
+	for _, line := range lines {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		return strings.TrimSpace(line) == "*** Begin Patch"
+	}
+	return false
+}
+
+// parseStructuredPatch parses the structured patch format:
+//
+//	*** Begin Patch
+//	*** Update File: path/to/file.go
+//	*** Move to: path/to/renamed.go
+//	@@ func Example() {
+//	 context
+//	-removed
+//	+added
+//	*** Add File: path/to/new.go
+//	+content
+//	*** Delete File: path/to/old.go
+//	*** End Patch
+func parseStructuredPatch(lines []string) ([]FilePatch, error) {
+	var (
+		patches []FilePatch
+		current *FilePatch
+		hunk    *Hunk
+		ended   bool
+	)
+	flush := func() {
+		if current == nil {
+			return
+		}
+		if hunk != nil && len(hunk.Lines) > 0 {
+			current.Hunks = append(current.Hunks, *hunk)
+		}
+		patches = append(patches, *current)
+		current, hunk = nil, nil
+	}
+
+	for i, line := range lines {
+		switch {
+		case strings.TrimSpace(line) == "*** Begin Patch":
+			continue
+		case strings.TrimSpace(line) == "*** End Patch":
+			flush()
+			ended = true
+		case strings.HasPrefix(line, "*** Update File: "):
+			flush()
+			path := strings.TrimSpace(strings.TrimPrefix(line, "*** Update File: "))
+			current = &FilePatch{OldPath: path, NewPath: path}
+			hunk = &Hunk{}
+		case strings.HasPrefix(line, "*** Add File: "):
+			flush()
+			current = &FilePatch{NewPath: strings.TrimSpace(strings.TrimPrefix(line, "*** Add File: "))}
+			hunk = &Hunk{}
+		case strings.HasPrefix(line, "*** Delete File: "):
+			flush()
+			current = &FilePatch{OldPath: strings.TrimSpace(strings.TrimPrefix(line, "*** Delete File: "))}
+		case strings.HasPrefix(line, "*** Move to: "):
+			if current == nil || current.IsCreate() || current.IsDelete() {
+				return nil, fmt.Errorf("line %d: move without an updated file", i+1)
+			}
+			current.NewPath = strings.TrimSpace(strings.TrimPrefix(line, "*** Move to: "))
+		case line == "*** End of File":
+			continue
+		case current == nil:
+			if strings.TrimSpace(line) == "" {
+				continue
+			}
+			return nil, fmt.Errorf("line %d: expected a file header, got %q", i+1, line)
+		case current.IsDelete():
+			return nil, fmt.Errorf("line %d: unexpected content for deleted file %s", i+1, current.OldPath)
+		case strings.HasPrefix(line, "@@"):
+			if current.IsCreate() {
+				return nil, fmt.Errorf("line %d: unexpected hunk header for added file %s", i+1, current.NewPath)
+			}
+			if len(hunk.Lines) > 0 {
+				current.Hunks = append(current.Hunks, *hunk)
+			}
+			hunk = &Hunk{Anchor: strings.TrimSpace(strings.TrimPrefix(line, "@@"))}
+		default:
+			hunkLine, err := parseHunkLine(line)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", i+1, err)
+			}
+			if current.IsCreate() && hunkLine.Op != '+' {
+				return nil, fmt.Errorf("line %d: lines of added file %s must start with +", i+1, current.NewPath)
+			}
+			hunk.Lines = append(hunk.Lines, hunkLine)
+		}
+	}
+	if !ended {
+		return nil, fmt.Errorf("missing *** End Patch")
+	}
+	return patches, nil
+}
+
+// parseUnifiedDiff parses a unified diff of one or more files, ignoring the
+// lines that are not part of a file diff, such as git headers.
+func parseUnifiedDiff(lines []string) ([]FilePatch, error) {
+	var patches []FilePatch
+	for i := 0; i < len(lines); i++ {
+		if !strings.HasPrefix(lines[i], "--- ") || i+1 >= len(lines) || !strings.HasPrefix(lines[i+1], "+++ ") {
+			continue
+		}
+		patch := FilePatch{
+			OldPath: diffPath(lines[i], "--- ", "a/"),
+			NewPath: diffPath(lines[i+1], "+++ ", "b/"),
+		}
+		i += 2
+
+		for i < len(lines) && strings.HasPrefix(lines[i], "@@") {
+			hunk, oldCount, newCount, err := parseHunkHeader(lines[i])
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", i+1, err)
+			}
+			i++
+			for i < len(lines) && (oldCount > 0 || newCount > 0) {
+				line := lines[i]
+				if strings.HasPrefix(line, `\`) {
+					i++
+					continue
+				}
+				hunkLine, err := parseHunkLine(line)
+				if err != nil {
+					return nil, fmt.Errorf("line %d: %w", i+1, err)
+				}
+				if hunkLine.Op != '+' {
+					oldCount--
+				}
+				if hunkLine.Op != '-' {
+					newCount--
+				}
+				hunk.Lines = append(hunk.Lines, hunkLine)
+				i++
+			}
+			if oldCount > 0 || newCount > 0 {
+				return nil, fmt.Errorf("hunk %d of %s is shorter than its header says", len(patch.Hunks)+1, patch.path())
+			}
+			// The marker follows the last line of the file it applies to; only
+			// the one of the new file matters.
+			for i < len(lines) && strings.HasPrefix(lines[i], `\`) {
+				if len(hunk.Lines) > 0 && hunk.Lines[len(hunk.Lines)-1].Op != '-' {
+					hunk.NoNewlineAtEnd = true
+				}
+				i++
+			}
+			patch.Hunks = append(patch.Hunks, hunk)
+		}
+		i--
+
+		if len(patch.Hunks) == 0 && !patch.IsDelete() {
+			return nil, fmt.Errorf("no hunks found for %s", patch.path())
+		}
+		patches = append(patches, patch)
+	}
+	return patches, nil
+}
+
+// diffPath returns the path of a --- or +++ line, empty for /dev/null.
+func diffPath(line, prefix, gitPrefix string) string {
+	path := strings.TrimPrefix(line, prefix)
+	// Drop the timestamp diff -u adds after a tab.
+	path, _, _ = strings.Cut(path, "\t")
+	path = strings.TrimSpace(path)
+	if path == "/dev/null" {
+		return ""
+	}
+	return strings.TrimPrefix(path, gitPrefix)
+}
+
+// parseHunkHeader parses a "@@ -l,s +l,s @@" line, returning the hunk and
+// the number of lines it has in the old and new file.
+func parseHunkHeader(line string) (Hunk, int, int, error) {
+	fields := strings.Fields(line)
+	if len(fields) < 4 || fields[0] != "@@" || !strings.HasPrefix(fields[1], "-") || !strings.HasPrefix(fields[2], "+") {
+		return Hunk{}, 0, 0, fmt.Errorf("invalid hunk header %q", line)
+	}
+	oldStart, oldCount, err := parseRange(fields[1][1:])
+	if err != nil {
+		return Hunk{}, 0, 0, fmt.Errorf("invalid hunk header %q: %w", line, err)
+	}
+	_, newCount, err := parseRange(fields[2][1:])
+	if err != nil {
+		return Hunk{}, 0, 0, fmt.Errorf("invalid hunk header %q: %w", line, err)
+	}
+	hunk := Hunk{OldStart: oldStart}
+	if oldCount == 0 && oldStart > 0 {
+		// Hunks only adding lines give the line after which they are added.
+		hunk.OldStart++
+	}
+	// git diff puts the enclosing function after the header.
+	if _, anchor, ok := strings.Cut(strings.TrimPrefix(line, "@@"), "@@"); ok {
+		hunk.Anchor = strings.TrimSpace(anchor)
+	}
+	return hunk, oldCount, newCount, nil
+}
+
+func parseRange(s string) (start, count int, err error) {
+	startStr, countStr, ok := strings.Cut(s, ",")
+	if start, err = strconv.Atoi(startStr); err != nil {
+		return 0, 0, err
+	}
+	count = 1
+	if ok {
+		if count, err = strconv.Atoi(countStr); err != nil {
+			return 0, 0, err
+		}
+	}
+	return start, count, nil
+}
+
+func parseHunkLine(line string) (HunkLine, error) {
+	if line == "" {
+		// Editors and models often strip the space of empty context lines.
+		return HunkLine{Op: ' '}, nil
+	}
+	switch line[0] {
+	case ' ', '-', '+':
+		return HunkLine{Op: line[0], Text: line[1:]}, nil
+	}
+	return HunkLine{}, fmt.Errorf("invalid hunk line %q: lines must start with ' ', '-' or '+'", line)
+}
+
+func (p FilePatch) path() string {
+	if p.NewPath != "" {
+		return p.NewPath
+	}
+	return p.OldPath
+}
+
+// Apply applies the hunks of the patch to the content of the file, which
+// must use Unix line endings. Hunks are found by their context and removed
+// lines, near the line given in their header if any, tolerating differences
+// in whitespace, then small differences, when there is no exact match.
+// Either all hunks apply or an error describing the first one that doesn't
+// is returned.
+func (p FilePatch) Apply(content string) (string, error) {
+	if p.IsCreate() {
+		content = ""
+	}
+	lines := strings.Split(content, "\n")
+	trailingNewline := content == "" || strings.HasSuffix(content, "\n")
+	if strings.HasSuffix(content, "\n") || content == "" {
+		lines = lines[:len(lines)-1]
+	}
+
+	// cursor is the line where the search for the next hunk starts, as hunks
+	// are in order; offset is how much the previous hunks moved the lines.
+	cursor, offset := 0, 0
+	for i, hunk := range p.Hunks {
+		old := hunk.oldLines()
+
+		from, anchored := cursor, false
+		if hunk.Anchor != "" && hunk.OldStart == 0 {
+			if idx := findAnchor(lines, hunk.Anchor, cursor); idx != -1 {
+				from, anchored = idx+1, true
+			}
+		}
+		hint := -1
+		if hunk.OldStart > 0 {
+			hint = max(hunk.OldStart-1+offset, from)
+		}
+
+		var pos int
+		if len(old) == 0 {
+			// A hunk without context adds lines at the given line, after its
+			// anchor, or at the end of the file.
+			switch {
+			case hint != -1:
+				pos = min(hint, len(lines))
+			case anchored:
+				pos = from
+			default:
+				pos = len(lines)
+			}
+		} else {
+			pos = findLines(lines, old, from, hint)
+			if pos == -1 {
+				return "", fmt.Errorf("hunk %d of %s does not match the file; expected:\n%s", i+1, p.path(), strings.Join(old, "\n"))
+			}
+		}
+
+		replacement := hunk.newLines(lines[pos : pos+len(old)])
+		lines = append(lines[:pos], append(replacement, lines[pos+len(old):]...)...)
+		cursor = pos + len(replacement)
+		if hunk.OldStart > 0 {
+			offset = pos - (hunk.OldStart - 1) + len(replacement) - len(old)
+		}
+		if i == len(p.Hunks)-1 && pos+len(replacement) == len(lines) {
+			trailingNewline = !hunk.NoNewlineAtEnd
+		}
+	}
+
+	result := strings.Join(lines, "\n")
+	if trailingNewline && len(lines) > 0 {
+		result += "\n"
+	}
+	return result, nil
+}
+
+// patchMatchers are the ways the lines of hunks and their anchors are
+// matched, from the strictest to the most tolerant.
+var patchMatchers = append([]LineMatcher{{
+	Description: "exactly",
+	Match:       func(fileLine, line string) bool { return fileLine == line },
+}}, LineMatchers...)
+
+// findLines returns the index of the lines in the file at or after from,
+// the closest to hint if several places match, or the first one if there is
+// no hint. When no lines match, even ignoring whitespace, it falls back to
+// the only block of lines similar enough to them. It returns -1 if the
+// lines are not found.
+func findLines(lines, want []string, from, hint int) int {
+	for _, matcher := range patchMatchers {
+		best := -1
+		for pos := from; pos+len(want) <= len(lines); pos++ {
+			if !LinesMatch(lines[pos:pos+len(want)], want, matcher.Match) {
+				continue
+			}
+			if hint == -1 {
+				return pos
+			}
+			if best == -1 || abs(pos-hint) < abs(best-hint) {
+				best = pos
+			}
+		}
+		if best != -1 {
+			return best
+		}
+	}
+	if len(want) > 1 && from < len(lines) {
+		if best, score, unique := ClosestLines(lines[from:], want); best != -1 && score >= FuzzyMatchThreshold && unique {
+			return from + best
+		}
+	}
+	return -1
+}
+
+func findAnchor(lines []string, anchor string, from int) int {
+	for _, matcher := range patchMatchers {
+		for pos := from; pos < len(lines); pos++ {
+			if matcher.Match(strings.TrimSpace(lines[pos]), anchor) {
+				return pos
+			}
+		}
+	}
+	return -1
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
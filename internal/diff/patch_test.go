@@ -0,0 +1,187 @@
+package diff
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParsePatchUnifiedDiff(t *testing.T) {
+	t.Parallel()
+
+	patch := `diff --git a/main.go b/main.go
+index 1111111..2222222 100644
+--- a/main.go
++++ b/main.go
+@@ -1,3 +1,3 @@ package main
+ package main
+-var x = 1
++var x = 2
+
+@@ -10,2 +10,3 @@ func main() {
+ 	run()
++	stop()
+ }
+--- /dev/null
++++ b/new.go
+@@ -0,0 +1,2 @@
++package main
++var y = 1
+--- a/old.go
++++ /dev/null
+@@ -1 +0,0 @@
+-package main
+`
+	patches, err := ParsePatch(patch)
+	require.NoError(t, err)
+	require.Len(t, patches, 3)
+
+	require.Equal(t, "main.go", patches[0].OldPath)
+	require.Equal(t, "main.go", patches[0].NewPath)
+	require.Len(t, patches[0].Hunks, 2)
+	require.Equal(t, 1, patches[0].Hunks[0].OldStart)
+	require.Equal(t, "package main", patches[0].Hunks[0].Anchor)
+	require.Equal(t, []string{"package main", "var x = 1", ""}, patches[0].Hunks[0].oldLines())
+	require.Equal(t, []string{"package main", "var x = 2", ""}, patches[0].Hunks[0].newLines(patches[0].Hunks[0].oldLines()))
+
+	require.True(t, patches[1].IsCreate())
+	require.Equal(t, "new.go", patches[1].NewPath)
+	require.True(t, patches[2].IsDelete())
+	require.Equal(t, "old.go", patches[2].OldPath)
+}
+
+func TestParsePatchStructured(t *testing.T) {
+	t.Parallel()
+
+	patch := `*** Begin Patch
+*** Update File: a.go
+*** Move to: b.go
+@@ func Foo() {
+ 	x := 1
+-	return x
++	return x + 1
+*** Add File: c.go
++package c
+*** Delete File: d.go
+*** End Patch`
+	patches, err := ParsePatch(patch)
+	require.NoError(t, err)
+	require.Len(t, patches, 3)
+
+	require.Equal(t, "a.go", patches[0].OldPath)
+	require.Equal(t, "b.go", patches[0].NewPath)
+	require.Len(t, patches[0].Hunks, 1)
+	require.Equal(t, "func Foo() {", patches[0].Hunks[0].Anchor)
+	require.True(t, patches[1].IsCreate())
+	require.True(t, patches[2].IsDelete())
+
+	_, err = ParsePatch("*** Begin Patch\n*** Update File: a.go\n+x\n")
+	require.ErrorContains(t, err, "End Patch")
+
+	_, err = ParsePatch("*** Begin Patch\n*** Add File: a.go\n x\n*** End Patch")
+	require.ErrorContains(t, err, "must start with +")
+}
+
+func TestParsePatchErrors(t *testing.T) {
+	t.Parallel()
+
+	_, err := ParsePatch("just some text")
+	require.ErrorContains(t, err, "no file changes")
+
+	_, err = ParsePatch("--- a/a.go\n+++ b/a.go\n@@ -1,3 +1,3 @@\n-x\n+y\n")
+	require.ErrorContains(t, err, "shorter than its header")
+
+	_, err = ParsePatch("--- a/a.go\n+++ b/a.go\n@@ -1 +1 @@\n*x\n")
+	require.ErrorContains(t, err, "invalid hunk line")
+}
+
+func TestFilePatchApply(t *testing.T) {
+	t.Parallel()
+
+	content := "package main\n\nfunc a() {\n\treturn\n}\n\nfunc b() {\n\treturn\n}\n"
+
+	tests := []struct {
+		name  string
+		patch string
+		want  string
+		err   string
+	}{
+		{
+			name:  "exact",
+			patch: "--- a/f.go\n+++ b/f.go\n@@ -7,3 +7,4 @@\n func b() {\n+\tb()\n \treturn\n }\n",
+			want:  "package main\n\nfunc a() {\n\treturn\n}\n\nfunc b() {\n\tb()\n\treturn\n}\n",
+		},
+		{
+			name:  "wrong line numbers",
+			patch: "--- a/f.go\n+++ b/f.go\n@@ -1,3 +1,4 @@\n func b() {\n+\tb()\n \treturn\n }\n",
+			want:  "package main\n\nfunc a() {\n\treturn\n}\n\nfunc b() {\n\tb()\n\treturn\n}\n",
+		},
+		{
+			name:  "ambiguous context uses the closest match",
+			patch: "--- a/f.go\n+++ b/f.go\n@@ -8,2 +8,2 @@\n-\treturn\n+\treturn nil\n }\n",
+			want:  "package main\n\nfunc a() {\n\treturn\n}\n\nfunc b() {\n\treturn nil\n}\n",
+		},
+		{
+			name:  "different indentation",
+			patch: "--- a/f.go\n+++ b/f.go\n@@ -3,3 +3,3 @@\n func a() {\n-    return\n+\treturn 1\n }\n",
+			want:  "package main\n\nfunc a() {\n\treturn 1\n}\n\nfunc b() {\n\treturn\n}\n",
+		},
+		{
+			name:  "context indented differently keeps the file's lines",
+			patch: "--- a/f.go\n+++ b/f.go\n@@ -7,3 +7,4 @@\n func b() {\n+\tb()\n     return\n }\n",
+			want:  "package main\n\nfunc a() {\n\treturn\n}\n\nfunc b() {\n\tb()\n\treturn\n}\n",
+		},
+		{
+			name:  "approximate context",
+			patch: "--- a/f.go\n+++ b/f.go\n@@ -7,2 +7,2 @@\n func b() {\n-\treturm\n+\treturn 3\n",
+			want:  "package main\n\nfunc a() {\n\treturn\n}\n\nfunc b() {\n\treturn 3\n}\n",
+		},
+		{
+			name:  "anchor",
+			patch: "*** Begin Patch\n*** Update File: f.go\n@@ func b() {\n-\treturn\n+\treturn 2\n*** End Patch",
+			want:  "package main\n\nfunc a() {\n\treturn\n}\n\nfunc b() {\n\treturn 2\n}\n",
+		},
+		{
+			name:  "added lines only",
+			patch: "--- a/f.go\n+++ b/f.go\n@@ -1,0 +2,1 @@\n+// Package main.\n",
+			want:  "package main\n// Package main.\n\nfunc a() {\n\treturn\n}\n\nfunc b() {\n\treturn\n}\n",
+		},
+		{
+			name:  "no newline at end",
+			patch: "--- a/f.go\n+++ b/f.go\n@@ -9 +9 @@\n-}\n+} // b\n\\ No newline at end of file\n",
+			want:  "package main\n\nfunc a() {\n\treturn\n}\n\nfunc b() {\n\treturn\n} // b",
+		},
+		{
+			name:  "no match",
+			patch: "--- a/f.go\n+++ b/f.go\n@@ -1,1 +1,1 @@\n-func c() {\n+func d() {\n",
+			err:   "hunk 1 of f.go does not match the file",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			patches, err := ParsePatch(tt.patch)
+			require.NoError(t, err)
+			require.Len(t, patches, 1)
+
+			got, err := patches[0].Apply(content)
+			if tt.err != "" {
+				require.ErrorContains(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestFilePatchApplyCreate(t *testing.T) {
+	t.Parallel()
+
+	patches, err := ParsePatch("*** Begin Patch\n*** Add File: new.go\n+package main\n+\n+var x = 1\n*** End Patch")
+	require.NoError(t, err)
+	got, err := patches[0].Apply("")
+	require.NoError(t, err)
+	require.Equal(t, "package main\n\nvar x = 1\n", got)
+}
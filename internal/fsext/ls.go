@@ -173,6 +173,12 @@ func (dl *directoryLister) shouldIgnore(path string, ignorePatterns []string) bo
 	return false
 }
 
+// ShouldIgnore returns whether the path is ignored by the common patterns or
+// the ignore files, as listed in [directoryLister.shouldIgnore].
+func (dl *directoryLister) ShouldIgnore(path string) bool {
+	return dl.shouldIgnore(path, nil)
+}
+
 func (dl *directoryLister) checkParentIgnores(path string) bool {
 	parent := filepath.Dir(filepath.Dir(path))
 	for parent != "." && path != "." {
@@ -251,3 +257,17 @@ func ListDirectory(initialPath string, ignorePatterns []string, depth, limit int
 	matches, truncated := truncate(slices.Collect(found.Seq()), limit)
 	return matches, truncated || errors.Is(err, filepath.SkipAll), nil
 }
+
+// ListWorkspace lists the files of the current directory, followed by the
+// files of the additional project directories as absolute paths, sorted.
+// Each directory is listed with the given depth and limit.
+func ListWorkspace(additionalDirs []string, depth, limit int) []string {
+	files, _, _ := ListDirectory(".", nil, depth, limit)
+	slices.Sort(files)
+	for _, dir := range additionalDirs {
+		dirFiles, _, _ := ListDirectory(dir, nil, depth, limit)
+		slices.Sort(dirFiles)
+		files = append(files, dirFiles...)
+	}
+	return files
+}
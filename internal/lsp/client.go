@@ -39,6 +39,9 @@ type Client struct {
 	// Working directory this LSP is scoped to.
 	workDir string
 
+	// Additional project directories sent as workspace folders.
+	additionalDirs *csync.Slice[string]
+
 	// File types this LSP server handles (e.g., .go, .rs, .py)
 	fileTypes []string
 
@@ -52,6 +55,10 @@ type Client struct {
 	// Diagnostic change callback
 	onDiagnosticsChanged func(name string, count int)
 
+	// Progress change and server message callbacks
+	onProgressChanged func(name string, progress []WorkDoneProgress)
+	onMessage         func(name string, msgType protocol.MessageType, message string)
+
 	// Diagnostic cache
 	diagnostics *csync.VersionedMap[protocol.DocumentURI, []protocol.Diagnostic]
 
@@ -63,6 +70,12 @@ type Client struct {
 	// Files are currently opened by the LSP
 	openFiles *csync.Map[string, *OpenFileInfo]
 
+	// File watchers registered by the server, by registration ID
+	fileWatchers *csync.Map[string, []protocol.FileSystemWatcher]
+
+	// Work done progress reported by the server, by token
+	progress *csync.Map[string, WorkDoneProgress]
+
 	// Server state
 	serverState atomic.Value
 }
@@ -70,13 +83,16 @@ type Client struct {
 // New creates a new LSP client using the powernap implementation.
 func New(ctx context.Context, name string, cfg config.LSPConfig, resolver config.VariableResolver) (*Client, error) {
 	client := &Client{
-		name:        name,
-		fileTypes:   cfg.FileTypes,
-		diagnostics: csync.NewVersionedMap[protocol.DocumentURI, []protocol.Diagnostic](),
-		openFiles:   csync.NewMap[string, *OpenFileInfo](),
-		config:      cfg,
-		ctx:         ctx,
-		resolver:    resolver,
+		name:           name,
+		fileTypes:      cfg.FileTypes,
+		diagnostics:    csync.NewVersionedMap[protocol.DocumentURI, []protocol.Diagnostic](),
+		openFiles:      csync.NewMap[string, *OpenFileInfo](),
+		fileWatchers:   csync.NewMap[string, []protocol.FileSystemWatcher](),
+		progress:       csync.NewMap[string, WorkDoneProgress](),
+		additionalDirs: csync.NewSlice[string](),
+		config:         cfg,
+		ctx:            ctx,
+		resolver:       resolver,
 	}
 	client.serverState.Store(StateStarting)
 
@@ -140,24 +156,32 @@ func (c *Client) createPowernapClient() error {
 	rootURI := string(protocol.URIFromPath(workDir))
 	c.workDir = workDir
 
+	workspaceFolders := []protocol.WorkspaceFolder{
+		{
+			URI:  rootURI,
+			Name: filepath.Base(workDir),
+		},
+	}
+	if cfg := config.Get(); cfg != nil {
+		c.additionalDirs.SetSlice(cfg.AdditionalDirectories())
+	}
+	for dir := range c.additionalDirs.Seq() {
+		workspaceFolders = append(workspaceFolders, workspaceFolder(dir))
+	}
+
 	command, err := c.resolver.ResolveValue(c.config.Command)
 	if err != nil {
 		return fmt.Errorf("invalid lsp command: %w", err)
 	}
 
 	clientConfig := powernap.ClientConfig{
-		Command:     home.Long(command),
-		Args:        c.config.Args,
-		RootURI:     rootURI,
-		Environment: maps.Clone(c.config.Env),
-		Settings:    c.config.Options,
-		InitOptions: c.config.InitOptions,
-		WorkspaceFolders: []protocol.WorkspaceFolder{
-			{
-				URI:  rootURI,
-				Name: filepath.Base(workDir),
-			},
-		},
+		Command:          home.Long(command),
+		Args:             c.config.Args,
+		RootURI:          rootURI,
+		Environment:      maps.Clone(c.config.Env),
+		Settings:         c.config.Options,
+		InitOptions:      c.config.InitOptions,
+		WorkspaceFolders: workspaceFolders,
 	}
 
 	powernapClient, err := powernap.NewClient(clientConfig)
@@ -173,8 +197,19 @@ func (c *Client) createPowernapClient() error {
 func (c *Client) registerHandlers() {
 	c.RegisterServerRequestHandler("workspace/applyEdit", HandleApplyEdit)
 	c.RegisterServerRequestHandler("workspace/configuration", HandleWorkspaceConfiguration)
-	c.RegisterServerRequestHandler("client/registerCapability", HandleRegisterCapability)
-	c.RegisterNotificationHandler("window/showMessage", HandleServerMessage)
+	c.RegisterServerRequestHandler("client/registerCapability", func(_ context.Context, _ string, params json.RawMessage) (any, error) {
+		return HandleRegisterCapability(c, params)
+	})
+	c.RegisterServerRequestHandler("client/unregisterCapability", func(_ context.Context, _ string, params json.RawMessage) (any, error) {
+		return HandleUnregisterCapability(c, params)
+	})
+	c.RegisterServerRequestHandler("window/workDoneProgress/create", HandleWorkDoneProgressCreate)
+	c.RegisterNotificationHandler("window/showMessage", func(_ context.Context, _ string, params json.RawMessage) {
+		HandleServerMessage(c, params)
+	})
+	c.RegisterNotificationHandler("$/progress", func(_ context.Context, _ string, params json.RawMessage) {
+		HandleProgress(c, params)
+	})
 	c.RegisterNotificationHandler("textDocument/publishDiagnostics", func(_ context.Context, _ string, params json.RawMessage) {
 		HandleDiagnostics(c, params)
 	})
@@ -196,6 +231,11 @@ func (c *Client) Restart() error {
 
 	c.diagCountsCache = DiagnosticCounts{}
 	c.diagCountsVersion = 0
+	c.fileWatchers.Reset(map[string][]protocol.FileSystemWatcher{})
+	c.progress.Reset(map[string]WorkDoneProgress{})
+	if c.onProgressChanged != nil {
+		c.onProgressChanged(c.name, nil)
+	}
 
 	if err := c.createPowernapClient(); err != nil {
 		return err
@@ -260,7 +300,9 @@ func (c *Client) SetDiagnosticsCallback(callback func(name string, count int)) {
 	c.onDiagnosticsChanged = callback
 }
 
-// WaitForServerReady waits for the server to be ready
+// WaitForServerReady waits for the server to be ready. Operations the
+// server keeps running after that, such as indexing, are reported through
+// [Client.GetProgress].
 func (c *Client) WaitForServerReady(ctx context.Context) error {
 	cfg := config.Get()
 
@@ -320,30 +362,65 @@ func (c *Client) HandlesFile(path string) bool {
 		slog.Debug("cannot resolve path", "name", c.name, "file", path, "error", err)
 		return false
 	}
-	relPath, err := filepath.Rel(c.workDir, absPath)
-	if err != nil || strings.HasPrefix(relPath, "..") {
+	if !config.IsInRoots(absPath, c.WorkspaceRoots()...) {
 		slog.Debug("file outside workspace", "name", c.name, "file", path, "workDir", c.workDir)
 		return false
 	}
 
+	if HandlesFileType(c.fileTypes, path) {
+		slog.Debug("handles file", "name", c.name, "file", path)
+		return true
+	}
+	slog.Debug("doesn't handle file", "name", c.name, "file", path)
+	return false
+}
+
+// WorkspaceRoots returns the working directory followed by the additional
+// directories of the workspace.
+func (c *Client) WorkspaceRoots() []string {
+	if c.additionalDirs == nil {
+		return []string{c.workDir}
+	}
+	return append([]string{c.workDir}, c.additionalDirs.Copy()...)
+}
+
+// AddWorkspaceFolder adds a project directory to the workspace of the
+// server. powernap can't send workspace/didChangeWorkspaceFolders, so the
+// server is restarted to get the directory as a workspace folder.
+func (c *Client) AddWorkspaceFolder(dir string) error {
+	if config.IsInRoots(dir, c.WorkspaceRoots()...) {
+		return nil
+	}
+	c.additionalDirs.Append(dir)
+	return c.Restart()
+}
+
+func workspaceFolder(dir string) protocol.WorkspaceFolder {
+	return protocol.WorkspaceFolder{
+		URI:  string(protocol.URIFromPath(dir)),
+		Name: filepath.Base(dir),
+	}
+}
+
+// HandlesFileType checks if a server for the given file types handles the
+// file, based on its extension or detected language.
+func HandlesFileType(fileTypes []string, path string) bool {
 	// If no file types are specified, handle all files (backward compatibility).
-	if len(c.fileTypes) == 0 {
+	if len(fileTypes) == 0 {
 		return true
 	}
 
 	kind := powernap.DetectLanguage(path)
 	name := strings.ToLower(filepath.Base(path))
-	for _, filetype := range c.fileTypes {
+	for _, filetype := range fileTypes {
 		suffix := strings.ToLower(filetype)
 		if !strings.HasPrefix(suffix, ".") {
 			suffix = "." + suffix
 		}
 		if strings.HasSuffix(name, suffix) || filetype == string(kind) {
-			slog.Debug("handles file", "name", c.name, "file", name, "filetype", filetype, "kind", kind)
 			return true
 		}
 	}
-	slog.Debug("doesn't handle file", "name", c.name, "file", name)
 	return false
 }
 
@@ -567,6 +644,27 @@ func (c *Client) WaitForDiagnostics(ctx context.Context, d time.Duration) {
 	}
 }
 
+// WaitForFileDiagnostics waits until the server published diagnostics for
+// the file, returning false if the timeout is reached first.
+func (c *Client) WaitForFileDiagnostics(ctx context.Context, path string, d time.Duration) bool {
+	uri := protocol.URIFromPath(path)
+	ticker := time.NewTicker(200 * time.Millisecond)
+	defer ticker.Stop()
+	timeout := time.After(d)
+	for {
+		if _, ok := c.diagnostics.Get(uri); ok {
+			return true
+		}
+		select {
+		case <-ctx.Done():
+			return false
+		case <-timeout:
+			return false
+		case <-ticker.C:
+		}
+	}
+}
+
 // FindReferences finds all references to the symbol at the given position.
 func (c *Client) FindReferences(ctx context.Context, filepath string, line, character int, includeDeclaration bool) ([]protocol.Location, error) {
 	if err := c.OpenFileOnDemand(ctx, filepath); err != nil {
@@ -0,0 +1,107 @@
+package lsp
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/crush/internal/config"
+	"github.com/charmbracelet/crush/internal/env"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeServerEnv makes the test binary run as a fake language server.
+const fakeServerEnv = "CRUSH_FAKE_LSP"
+
+func TestMain(m *testing.M) {
+	if os.Getenv(fakeServerEnv) == "1" {
+		runFakeServer(os.Stdin, os.Stdout)
+		os.Exit(0)
+	}
+	os.Exit(m.Run())
+}
+
+// fakeResponses holds the result the fake server returns for each method.
+var fakeResponses = map[string]any{
+	"initialize": map[string]any{"capabilities": map[string]any{}},
+	"textDocument/hover": map[string]any{
+		"contents": map[string]any{"kind": "markdown", "value": "```go\nfunc Foo()\n```\n\nFoo does things."},
+	},
+}
+
+// runFakeServer answers LSP requests with [fakeResponses] until it receives
+// an exit notification.
+func runFakeServer(in io.Reader, out io.Writer) {
+	r := bufio.NewReader(in)
+	for {
+		var length int
+		for {
+			line, err := r.ReadString('\n')
+			if err != nil {
+				return
+			}
+			line = strings.TrimSpace(line)
+			if line == "" {
+				break
+			}
+			if v, ok := strings.CutPrefix(line, "Content-Length:"); ok {
+				length, _ = strconv.Atoi(strings.TrimSpace(v))
+			}
+		}
+		body := make([]byte, length)
+		if _, err := io.ReadFull(r, body); err != nil {
+			return
+		}
+
+		var msg struct {
+			ID     json.RawMessage `json:"id"`
+			Method string          `json:"method"`
+		}
+		if err := json.Unmarshal(body, &msg); err != nil {
+			return
+		}
+		if msg.Method == "exit" {
+			return
+		}
+		if msg.ID == nil {
+			continue
+		}
+		resp, _ := json.Marshal(map[string]any{
+			"jsonrpc": "2.0",
+			"id":      msg.ID,
+			"result":  fakeResponses[msg.Method],
+		})
+		fmt.Fprintf(out, "Content-Length: %d\r\n\r\n%s", len(resp), resp)
+	}
+}
+
+// newFakeClient starts an initialized client talking to the fake server.
+func newFakeClient(t *testing.T) *Client {
+	t.Helper()
+
+	exe, err := os.Executable()
+	require.NoError(t, err)
+
+	cfg := config.LSPConfig{
+		Command: exe,
+		Env:     map[string]string{fakeServerEnv: "1"},
+	}
+	client, err := New(t.Context(), "fake", cfg, config.NewEnvironmentVariableResolver(env.NewFromMap(nil)))
+	require.NoError(t, err)
+
+	wd, err := os.Getwd()
+	require.NoError(t, err)
+	_, err = client.Initialize(t.Context(), wd)
+	require.NoError(t, err)
+
+	t.Cleanup(func() {
+		_ = client.Close(context.Background())
+	})
+	return client
+}
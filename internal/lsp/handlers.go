@@ -16,7 +16,7 @@ func HandleWorkspaceConfiguration(_ context.Context, _ string, params json.RawMe
 }
 
 // HandleRegisterCapability handles capability registration requests
-func HandleRegisterCapability(_ context.Context, _ string, params json.RawMessage) (any, error) {
+func HandleRegisterCapability(client *Client, params json.RawMessage) (any, error) {
 	var registerParams protocol.RegistrationParams
 	if err := json.Unmarshal(params, &registerParams); err != nil {
 		slog.Error("Error unmarshaling registration params", "error", err)
@@ -38,7 +38,23 @@ func HandleRegisterCapability(_ context.Context, _ string, params json.RawMessag
 				continue
 			}
 			// Store the file watchers registrations
-			notifyFileWatchRegistration(reg.ID, options.Watchers)
+			client.fileWatchers.Set(reg.ID, options.Watchers)
+		}
+	}
+	return nil, nil
+}
+
+// HandleUnregisterCapability handles capability unregistration requests
+func HandleUnregisterCapability(client *Client, params json.RawMessage) (any, error) {
+	var unregisterParams protocol.UnregistrationParams
+	if err := json.Unmarshal(params, &unregisterParams); err != nil {
+		slog.Error("Error unmarshaling unregistration params", "error", err)
+		return nil, err
+	}
+
+	for _, unreg := range unregisterParams.Unregisterations {
+		if unreg.Method == "workspace/didChangeWatchedFiles" {
+			client.fileWatchers.Del(unreg.ID)
 		}
 	}
 	return nil, nil
@@ -60,34 +76,20 @@ func HandleApplyEdit(_ context.Context, _ string, params json.RawMessage) (any,
 	return protocol.ApplyWorkspaceEditResult{Applied: true}, nil
 }
 
-// FileWatchRegistrationHandler is a function that will be called when file watch registrations are received
-type FileWatchRegistrationHandler func(id string, watchers []protocol.FileSystemWatcher)
-
-// fileWatchHandler holds the current handler for file watch registrations
-var fileWatchHandler FileWatchRegistrationHandler
-
-// RegisterFileWatchHandler sets the handler for file watch registrations
-func RegisterFileWatchHandler(handler FileWatchRegistrationHandler) {
-	fileWatchHandler = handler
-}
-
-// notifyFileWatchRegistration notifies the handler about new file watch registrations
-func notifyFileWatchRegistration(id string, watchers []protocol.FileSystemWatcher) {
-	if fileWatchHandler != nil {
-		fileWatchHandler(id, watchers)
+// HandleServerMessage handles messages the server wants shown to the user
+func HandleServerMessage(client *Client, params json.RawMessage) {
+	var msg protocol.ShowMessageParams
+	if err := json.Unmarshal(params, &msg); err != nil {
+		slog.Error("Error unmarshaling server message", "error", err)
+		return
 	}
-}
 
-// HandleServerMessage handles server messages
-func HandleServerMessage(_ context.Context, method string, params json.RawMessage) {
-	cfg := config.Get()
-	if !cfg.Options.DebugLSP {
-		return
+	if client.onMessage != nil {
+		client.onMessage(client.name, msg.Type, msg.Message)
 	}
 
-	var msg protocol.ShowMessageParams
-	if err := json.Unmarshal(params, &msg); err != nil {
-		slog.Debug("Server message", "type", msg.Type, "message", msg.Message)
+	cfg := config.Get()
+	if cfg == nil || !cfg.Options.DebugLSP {
 		return
 	}
 
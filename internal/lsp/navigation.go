@@ -0,0 +1,25 @@
+package lsp
+
+import (
+	"context"
+
+	"github.com/charmbracelet/x/powernap/pkg/lsp/protocol"
+)
+
+// Hover returns the hover documentation of the symbol at the given position,
+// as markdown or plain text.
+func (c *Client) Hover(ctx context.Context, filepath string, line, character int) (string, error) {
+	if err := c.OpenFileOnDemand(ctx, filepath); err != nil {
+		return "", err
+	}
+	// NOTE: line and character should be 0-based.
+	// See: https://microsoft.github.io/language-server-protocol/specifications/lsp/3.17/specification/#position
+	hover, err := c.client.RequestHover(ctx, string(protocol.URIFromPath(filepath)), protocol.Position{
+		Line:      uint32(max(line-1, 0)),      //nolint:gosec
+		Character: uint32(max(character-1, 0)), //nolint:gosec
+	})
+	if err != nil {
+		return "", err
+	}
+	return hover.Contents.Value, nil
+}
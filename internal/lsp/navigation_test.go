@@ -0,0 +1,20 @@
+package lsp
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestHover(t *testing.T) {
+	t.Parallel()
+
+	client := newFakeClient(t)
+	file, err := filepath.Abs("navigation.go")
+	require.NoError(t, err)
+
+	hover, err := client.Hover(t.Context(), file, 1, 1)
+	require.NoError(t, err)
+	require.Contains(t, hover, "Foo does things.")
+}
@@ -0,0 +1,134 @@
+package lsp
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/crush/internal/config"
+	"github.com/charmbracelet/x/powernap/pkg/lsp/protocol"
+)
+
+// WorkDoneProgress is a long running operation reported by the server, such
+// as loading or indexing the workspace.
+type WorkDoneProgress struct {
+	Title      string
+	Message    string
+	Percentage *uint32
+
+	started time.Time
+}
+
+// String returns a short description of the progress, e.g.
+// "Loading packages: 3/10 (30%)".
+func (p WorkDoneProgress) String() string {
+	var sb strings.Builder
+	sb.WriteString(p.Title)
+	if p.Message != "" {
+		if sb.Len() > 0 {
+			sb.WriteString(": ")
+		}
+		sb.WriteString(p.Message)
+	}
+	if p.Percentage != nil {
+		fmt.Fprintf(&sb, " (%d%%)", *p.Percentage)
+	}
+	return sb.String()
+}
+
+// GetProgress returns the operations the server is currently running, oldest
+// first.
+func (c *Client) GetProgress() []WorkDoneProgress {
+	progress := slices.Collect(c.progress.Seq())
+	slices.SortFunc(progress, func(a, b WorkDoneProgress) int {
+		return cmp.Or(a.started.Compare(b.started), strings.Compare(a.Title, b.Title))
+	})
+	return progress
+}
+
+// IsBusy returns whether the server reported operations still running, such
+// as indexing the workspace.
+func (c *Client) IsBusy() bool {
+	return c.progress.Len() > 0
+}
+
+// SetProgressCallback sets the callback function for progress changes.
+func (c *Client) SetProgressCallback(callback func(name string, progress []WorkDoneProgress)) {
+	c.onProgressChanged = callback
+}
+
+// SetMessageCallback sets the callback function for messages the server
+// wants shown to the user.
+func (c *Client) SetMessageCallback(callback func(name string, msgType protocol.MessageType, message string)) {
+	c.onMessage = callback
+}
+
+// HandleWorkDoneProgressCreate accepts the progress tokens created by the
+// server.
+func HandleWorkDoneProgressCreate(_ context.Context, _ string, _ json.RawMessage) (any, error) {
+	return nil, nil
+}
+
+// HandleProgress handles work done progress notifications from the server.
+func HandleProgress(client *Client, params json.RawMessage) {
+	var progressParams struct {
+		Token json.RawMessage `json:"token"`
+		Value json.RawMessage `json:"value"`
+	}
+	if err := json.Unmarshal(params, &progressParams); err != nil {
+		slog.Error("Error unmarshaling progress params", "error", err)
+		return
+	}
+
+	var value struct {
+		Kind       string  `json:"kind"`
+		Title      string  `json:"title"`
+		Message    string  `json:"message"`
+		Percentage *uint32 `json:"percentage"`
+	}
+	if err := json.Unmarshal(progressParams.Value, &value); err != nil {
+		// Not a work done progress, e.g. partial results.
+		return
+	}
+
+	token := string(progressParams.Token)
+	switch value.Kind {
+	case "begin":
+		client.progress.Set(token, WorkDoneProgress{
+			Title:      value.Title,
+			Message:    value.Message,
+			Percentage: value.Percentage,
+			started:    time.Now(),
+		})
+	case "report":
+		progress, ok := client.progress.Get(token)
+		if !ok {
+			return
+		}
+		if value.Message != "" {
+			progress.Message = value.Message
+		}
+		if value.Percentage != nil {
+			progress.Percentage = value.Percentage
+		}
+		client.progress.Set(token, progress)
+	case "end":
+		client.progress.Del(token)
+	default:
+		// Not a work done progress, e.g. partial results.
+		return
+	}
+
+	if cfg := config.Get(); cfg != nil && cfg.Options.DebugLSP {
+		slog.Debug("LSP server progress", "name", client.name, "kind", value.Kind, "title", value.Title, "message", value.Message)
+	}
+
+	if client.onProgressChanged != nil {
+		client.onProgressChanged(client.name, client.GetProgress())
+	}
+}
@@ -0,0 +1,60 @@
+package lsp
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/charmbracelet/crush/internal/csync"
+	"github.com/charmbracelet/x/powernap/pkg/lsp/protocol"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandleProgress(t *testing.T) {
+	t.Parallel()
+
+	var notified [][]WorkDoneProgress
+	client := &Client{
+		name:     "gopls",
+		progress: csync.NewMap[string, WorkDoneProgress](),
+	}
+	client.SetProgressCallback(func(name string, progress []WorkDoneProgress) {
+		require.Equal(t, "gopls", name)
+		notified = append(notified, progress)
+	})
+
+	notify := func(params string) {
+		HandleProgress(client, json.RawMessage(params))
+	}
+
+	notify(`{"token":"1","value":{"kind":"begin","title":"Loading packages"}}`)
+	require.True(t, client.IsBusy())
+	require.Equal(t, "Loading packages", client.GetProgress()[0].String())
+
+	notify(`{"token":"1","value":{"kind":"report","message":"3/10","percentage":30}}`)
+	require.Equal(t, "Loading packages: 3/10 (30%)", client.GetProgress()[0].String())
+
+	// Reports for unknown tokens and partial results are ignored.
+	notify(`{"token":2,"value":{"kind":"report","message":"ignored"}}`)
+	notify(`{"token":"3","value":[{"name":"partial"}]}`)
+	require.Len(t, client.GetProgress(), 1)
+
+	notify(`{"token":"1","value":{"kind":"end","message":"done"}}`)
+	require.False(t, client.IsBusy())
+	require.Len(t, notified, 3)
+	require.Empty(t, notified[2])
+}
+
+func TestHandleServerMessage(t *testing.T) {
+	t.Parallel()
+
+	var got string
+	var gotType protocol.MessageType
+	client := &Client{name: "gopls"}
+	client.SetMessageCallback(func(name string, msgType protocol.MessageType, message string) {
+		got, gotType = name+": "+message, msgType
+	})
+
+	HandleServerMessage(client, json.RawMessage(`{"type":2,"message":"go.mod not found"}`))
+	require.Equal(t, "gopls: go.mod not found", got)
+	require.Equal(t, protocol.Warning, gotType)
+}
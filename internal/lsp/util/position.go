@@ -0,0 +1,33 @@
+package util
+
+import "unicode/utf8"
+
+// UTF16Column returns the number of UTF-16 code units in line[:offset]. LSP
+// positions count characters in UTF-16 code units, while Go indexes strings
+// by byte.
+func UTF16Column(line string, offset int) int {
+	column := 0
+	for _, r := range line[:min(offset, len(line))] {
+		column += utf16Len(r)
+	}
+	return column
+}
+
+// ByteOffset returns the byte offset in the line of the given UTF-16 column,
+// or the length of the line if the column is past its end.
+func ByteOffset(line string, column int) int {
+	for i, r := range line {
+		if column <= 0 {
+			return i
+		}
+		column -= utf16Len(r)
+	}
+	return len(line)
+}
+
+func utf16Len(r rune) int {
+	if r >= 0x10000 && r <= utf8.MaxRune {
+		return 2
+	}
+	return 1
+}
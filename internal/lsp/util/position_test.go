@@ -0,0 +1,22 @@
+package util
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestUTF16Column(t *testing.T) {
+	t.Parallel()
+
+	// é is 2 bytes and 1 UTF-16 code unit, 🙂 is 4 bytes and 2 code units.
+	line := "é🙂 Foo"
+	offset := len("é🙂 ")
+	require.Equal(t, 4, UTF16Column(line, offset))
+	require.Equal(t, offset, ByteOffset(line, 4))
+
+	require.Equal(t, 0, UTF16Column(line, 0))
+	require.Equal(t, 0, ByteOffset(line, 0))
+	require.Equal(t, 7, UTF16Column(line, 100))
+	require.Equal(t, len(line), ByteOffset(line, 100))
+}
@@ -0,0 +1,185 @@
+package util
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"maps"
+	"os"
+	"slices"
+
+	"github.com/charmbracelet/x/powernap/pkg/lsp/protocol"
+)
+
+// FileChange is the change a workspace edit makes to a single file.
+type FileChange struct {
+	Path       string
+	OldContent string
+	NewContent string
+	// Created is true if the file does not exist before the edit.
+	Created bool
+	// Deleted is true if the file does not exist after the edit, including
+	// when it's renamed.
+	Deleted bool
+}
+
+// previewFile is the state of a file while previewing a workspace edit.
+type previewFile struct {
+	original []byte
+	existed  bool
+	content  []byte
+	exists   bool
+}
+
+// workspacePreview tracks the files changed by a workspace edit, in memory.
+type workspacePreview struct {
+	files map[string]*previewFile
+	order []string
+}
+
+func (w *workspacePreview) file(path string) (*previewFile, error) {
+	if f, ok := w.files[path]; ok {
+		return f, nil
+	}
+	content, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("failed to read file: %w", err)
+	}
+	f := &previewFile{
+		original: content,
+		existed:  err == nil,
+		content:  content,
+		exists:   err == nil,
+	}
+	w.files[path] = f
+	w.order = append(w.order, path)
+	return f, nil
+}
+
+func (w *workspacePreview) edit(uri protocol.DocumentURI, edits []protocol.TextEdit) error {
+	path, err := uri.Path()
+	if err != nil {
+		return fmt.Errorf("invalid URI: %w", err)
+	}
+	f, err := w.file(path)
+	if err != nil {
+		return err
+	}
+	if !f.exists {
+		return fmt.Errorf("failed to read file: %s does not exist", path)
+	}
+	content, err := editContent(f.content, edits)
+	if err != nil {
+		return err
+	}
+	f.content = content
+	return nil
+}
+
+func (w *workspacePreview) change(change protocol.DocumentChange) error {
+	if change.CreateFile != nil {
+		path, err := change.CreateFile.URI.Path()
+		if err != nil {
+			return fmt.Errorf("invalid URI: %w", err)
+		}
+		f, err := w.file(path)
+		if err != nil {
+			return err
+		}
+		options := change.CreateFile.Options
+		if !f.exists || options == nil || options.Overwrite || !options.IgnoreIfExists {
+			f.content = nil
+			f.exists = true
+		}
+	}
+
+	if change.DeleteFile != nil {
+		path, err := change.DeleteFile.URI.Path()
+		if err != nil {
+			return fmt.Errorf("invalid URI: %w", err)
+		}
+		f, err := w.file(path)
+		if err != nil {
+			return err
+		}
+		f.content = nil
+		f.exists = false
+	}
+
+	if change.RenameFile != nil {
+		oldPath, err := change.RenameFile.OldURI.Path()
+		if err != nil {
+			return err
+		}
+		newPath, err := change.RenameFile.NewURI.Path()
+		if err != nil {
+			return err
+		}
+		oldFile, err := w.file(oldPath)
+		if err != nil {
+			return err
+		}
+		newFile, err := w.file(newPath)
+		if err != nil {
+			return err
+		}
+		if newFile.exists && (change.RenameFile.Options == nil || !change.RenameFile.Options.Overwrite) {
+			return fmt.Errorf("target file already exists and overwrite is not allowed: %s", newPath)
+		}
+		newFile.content = oldFile.content
+		newFile.exists = true
+		oldFile.content = nil
+		oldFile.exists = false
+	}
+
+	if change.TextDocumentEdit != nil {
+		textEdits := make([]protocol.TextEdit, len(change.TextDocumentEdit.Edits))
+		for i, edit := range change.TextDocumentEdit.Edits {
+			var err error
+			textEdits[i], err = edit.AsTextEdit()
+			if err != nil {
+				return fmt.Errorf("invalid edit type: %w", err)
+			}
+		}
+		return w.edit(change.TextDocumentEdit.TextDocument.URI, textEdits)
+	}
+
+	return nil
+}
+
+// PreviewWorkspaceEdit returns the changes the given WorkspaceEdit would make
+// to each file, in the order they are first changed, without touching the
+// filesystem. Files left unchanged are omitted.
+func PreviewWorkspaceEdit(edit protocol.WorkspaceEdit) ([]FileChange, error) {
+	w := &workspacePreview{files: make(map[string]*previewFile)}
+
+	// Sort the URIs so the order is stable, as ApplyWorkspaceEdit applies
+	// them in map order.
+	for _, uri := range slices.Sorted(maps.Keys(edit.Changes)) {
+		if err := w.edit(uri, edit.Changes[uri]); err != nil {
+			return nil, fmt.Errorf("failed to apply text edits: %w", err)
+		}
+	}
+
+	for _, change := range edit.DocumentChanges {
+		if err := w.change(change); err != nil {
+			return nil, fmt.Errorf("failed to apply document change: %w", err)
+		}
+	}
+
+	var changes []FileChange
+	for _, path := range w.order {
+		f := w.files[path]
+		if f.existed == f.exists && string(f.original) == string(f.content) {
+			continue
+		}
+		changes = append(changes, FileChange{
+			Path:       path,
+			OldContent: string(f.original),
+			NewContent: string(f.content),
+			Created:    !f.existed && f.exists,
+			Deleted:    f.existed && !f.exists,
+		})
+	}
+	return changes, nil
+}
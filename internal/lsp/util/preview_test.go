@@ -0,0 +1,88 @@
+package util
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/charmbracelet/x/powernap/pkg/lsp/protocol"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPreviewWorkspaceEdit(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	a := filepath.Join(dir, "a.go")
+	b := filepath.Join(dir, "b.go")
+	require.NoError(t, os.WriteFile(a, []byte("package a\n\nfunc Foo() {}\n"), 0o644))
+	require.NoError(t, os.WriteFile(b, []byte("package a\n\nvar _ = Foo\n"), 0o644))
+
+	rename := func(line, start, end uint32) protocol.TextEdit {
+		return protocol.TextEdit{
+			Range: protocol.Range{
+				Start: protocol.Position{Line: line, Character: start},
+				End:   protocol.Position{Line: line, Character: end},
+			},
+			NewText: "Bar",
+		}
+	}
+
+	t.Run("changes", func(t *testing.T) {
+		t.Parallel()
+
+		changes, err := PreviewWorkspaceEdit(protocol.WorkspaceEdit{
+			Changes: map[protocol.DocumentURI][]protocol.TextEdit{
+				protocol.URIFromPath(b): {rename(2, 8, 11)},
+				protocol.URIFromPath(a): {rename(2, 5, 8)},
+			},
+		})
+		require.NoError(t, err)
+		require.Len(t, changes, 2)
+		require.Equal(t, a, changes[0].Path)
+		require.Equal(t, "package a\n\nfunc Foo() {}\n", changes[0].OldContent)
+		require.Equal(t, "package a\n\nfunc Bar() {}\n", changes[0].NewContent)
+		require.Equal(t, "package a\n\nvar _ = Bar\n", changes[1].NewContent)
+
+		// Nothing is written.
+		content, err := os.ReadFile(a)
+		require.NoError(t, err)
+		require.Equal(t, "package a\n\nfunc Foo() {}\n", string(content))
+	})
+
+	t.Run("rename file then edit it", func(t *testing.T) {
+		t.Parallel()
+
+		c := filepath.Join(dir, "c.go")
+		edit := protocol.TextDocumentEdit{
+			TextDocument: protocol.OptionalVersionedTextDocumentIdentifier{
+				TextDocumentIdentifier: protocol.TextDocumentIdentifier{URI: protocol.URIFromPath(c)},
+			},
+			Edits: []protocol.Or_TextDocumentEdit_edits_Elem{{Value: rename(2, 5, 8)}},
+		}
+		changes, err := PreviewWorkspaceEdit(protocol.WorkspaceEdit{
+			DocumentChanges: []protocol.DocumentChange{
+				{RenameFile: &protocol.RenameFile{OldURI: protocol.URIFromPath(a), NewURI: protocol.URIFromPath(c)}},
+				{TextDocumentEdit: &edit},
+			},
+		})
+		require.NoError(t, err)
+		require.Len(t, changes, 2)
+		require.Equal(t, a, changes[0].Path)
+		require.True(t, changes[0].Deleted)
+		require.Equal(t, c, changes[1].Path)
+		require.True(t, changes[1].Created)
+		require.Equal(t, "package a\n\nfunc Bar() {}\n", changes[1].NewContent)
+	})
+
+	t.Run("overlapping edits", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := PreviewWorkspaceEdit(protocol.WorkspaceEdit{
+			Changes: map[protocol.DocumentURI][]protocol.TextEdit{
+				protocol.URIFromPath(a): {rename(2, 5, 8), rename(2, 6, 7)},
+			},
+		})
+		require.Error(t, err)
+	})
+}
@@ -0,0 +1,360 @@
+package lsp
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"log/slog"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bmatcuk/doublestar/v4"
+	"github.com/charmbracelet/crush/internal/config"
+	"github.com/charmbracelet/crush/internal/csync"
+	"github.com/charmbracelet/crush/internal/fsext"
+	"github.com/charmbracelet/x/powernap/pkg/lsp/protocol"
+	"github.com/fsnotify/fsnotify"
+)
+
+// watchDebounce is how long the watcher waits for more events before
+// notifying the servers.
+const watchDebounce = 300 * time.Millisecond
+
+type ignorer interface{ ShouldIgnore(string) bool }
+
+// Watcher watches the workspace and notifies each LSP client of the changes
+// matching the file watchers its server registered.
+type Watcher struct {
+	clients  *csync.Map[string, *Client]
+	onChange func(paths []string)
+
+	mu       sync.Mutex
+	fsw      *fsnotify.Watcher
+	roots    []string
+	ignorers map[string]ignorer
+	pending  map[string]protocol.FileChangeType
+	timer    *time.Timer
+	closed   bool
+	debounce time.Duration
+}
+
+// NewWatcher creates a watcher for the given workspace roots. It does nothing
+// until started.
+func NewWatcher(roots []string, clients *csync.Map[string, *Client]) *Watcher {
+	return &Watcher{
+		clients:  clients,
+		roots:    roots,
+		ignorers: make(map[string]ignorer),
+		pending:  make(map[string]protocol.FileChangeType),
+		debounce: watchDebounce,
+	}
+}
+
+// Start watches every directory of the workspace that is not ignored by the
+// common patterns, .gitignore or .crushignore, until the context is done or
+// the watcher is closed.
+func (w *Watcher) Start(ctx context.Context) error {
+	fsw, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+
+	w.mu.Lock()
+	if w.closed || w.fsw != nil {
+		w.mu.Unlock()
+		return fsw.Close()
+	}
+	w.fsw = fsw
+	roots := slices.Clone(w.roots)
+	for _, root := range roots {
+		w.ignorers[root] = fsext.NewDirectoryLister(root)
+	}
+	w.mu.Unlock()
+
+	for _, root := range roots {
+		w.addTree(ctx, root, false)
+	}
+	slog.Debug("Watching workspace", "roots", roots, "directories", len(fsw.WatchList()))
+
+	go w.loop(ctx, fsw)
+	return nil
+}
+
+// OnChange sets a function called with the paths of the files changed in the
+// workspace, after each debounced batch of changes. It must be set before the
+// watcher is started.
+func (w *Watcher) OnChange(fn func(paths []string)) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.onChange = fn
+}
+
+// AddRoot starts watching another root of the workspace. Roots added before
+// the watcher is started are watched when it starts.
+func (w *Watcher) AddRoot(ctx context.Context, root string) {
+	w.mu.Lock()
+	if slices.Contains(w.roots, root) {
+		w.mu.Unlock()
+		return
+	}
+	w.roots = append(w.roots, root)
+	started := w.fsw != nil && !w.closed
+	if started {
+		w.ignorers[root] = fsext.NewDirectoryLister(root)
+	}
+	w.mu.Unlock()
+
+	if started {
+		w.addTree(ctx, root, false)
+	}
+}
+
+// Close stops watching the workspace.
+func (w *Watcher) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.closed = true
+	if w.timer != nil {
+		w.timer.Stop()
+	}
+	if w.fsw == nil {
+		return nil
+	}
+	return w.fsw.Close()
+}
+
+func (w *Watcher) loop(ctx context.Context, fsw *fsnotify.Watcher) {
+	for {
+		select {
+		case <-ctx.Done():
+			_ = w.Close()
+			return
+		case event, ok := <-fsw.Events:
+			if !ok {
+				return
+			}
+			w.handle(ctx, event)
+		case err, ok := <-fsw.Errors:
+			if !ok {
+				return
+			}
+			slog.Warn("File watcher error", "error", err)
+		}
+	}
+}
+
+func (w *Watcher) handle(ctx context.Context, event fsnotify.Event) {
+	path := event.Name
+	if w.shouldIgnore(path) {
+		return
+	}
+
+	if base := filepath.Base(path); base == ".gitignore" || base == ".crushignore" {
+		// Ignore rules changed; read them again.
+		if root := w.rootOf(path); root != "" {
+			w.mu.Lock()
+			w.ignorers[root] = fsext.NewDirectoryLister(root)
+			w.mu.Unlock()
+		}
+	}
+
+	switch {
+	case event.Has(fsnotify.Create):
+		if info, err := os.Stat(path); err == nil && info.IsDir() {
+			// Files may be created in the directory before it's watched.
+			w.addTree(ctx, path, true)
+		}
+		w.queue(ctx, path, protocol.Created)
+	case event.Has(fsnotify.Write):
+		w.queue(ctx, path, protocol.Changed)
+	case event.Has(fsnotify.Remove), event.Has(fsnotify.Rename):
+		w.queue(ctx, path, protocol.Deleted)
+	}
+}
+
+// addTree watches the directory and its subdirectories, queuing creation
+// events for their files if created is set.
+func (w *Watcher) addTree(ctx context.Context, dir string, created bool) {
+	_ = filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil //nolint:nilerr
+		}
+		if path != dir && w.shouldIgnore(path) {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !d.IsDir() {
+			if created {
+				w.queue(ctx, path, protocol.Created)
+			}
+			return nil
+		}
+		w.mu.Lock()
+		fsw := w.fsw
+		w.mu.Unlock()
+		if fsw == nil {
+			return filepath.SkipAll
+		}
+		if err := fsw.Add(path); err != nil {
+			slog.Warn("Failed to watch directory", "path", path, "error", err)
+			if errors.Is(err, fsnotify.ErrClosed) {
+				return filepath.SkipAll
+			}
+		}
+		return nil
+	})
+}
+
+func (w *Watcher) shouldIgnore(path string) bool {
+	root := w.rootOf(path)
+	w.mu.Lock()
+	ignorer := w.ignorers[root]
+	w.mu.Unlock()
+	return ignorer != nil && path != root && ignorer.ShouldIgnore(path)
+}
+
+// rootOf returns the innermost root containing the path, or an empty string.
+func (w *Watcher) rootOf(path string) string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	var found string
+	for _, root := range w.roots {
+		if len(root) > len(found) && config.IsInRoots(path, root) {
+			found = root
+		}
+	}
+	return found
+}
+
+// queue records a change, merging it with the pending change of the same
+// file, and schedules a notification.
+func (w *Watcher) queue(ctx context.Context, path string, change protocol.FileChangeType) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.closed {
+		return
+	}
+
+	switch previous, ok := w.pending[path]; {
+	case !ok:
+		w.pending[path] = change
+	case previous == protocol.Created && change == protocol.Deleted:
+		// The file never existed as far as the servers know.
+		delete(w.pending, path)
+	case previous == protocol.Created:
+		// Still a new file.
+	case previous == protocol.Deleted && change == protocol.Created:
+		w.pending[path] = protocol.Changed
+	default:
+		w.pending[path] = change
+	}
+
+	if w.timer != nil {
+		w.timer.Stop()
+	}
+	w.timer = time.AfterFunc(w.debounce, func() { w.flush(ctx) })
+}
+
+// flush sends the pending changes to the clients watching them.
+func (w *Watcher) flush(ctx context.Context) {
+	w.mu.Lock()
+	pending := w.pending
+	w.pending = make(map[string]protocol.FileChangeType)
+	onChange := w.onChange
+	w.mu.Unlock()
+	if len(pending) == 0 {
+		return
+	}
+
+	if onChange != nil {
+		onChange(slices.Sorted(maps.Keys(pending)))
+	}
+
+	for name, client := range w.clients.Seq2() {
+		var changes []protocol.FileEvent
+		for path, change := range pending {
+			if client.WatchesFile(path, change) {
+				changes = append(changes, protocol.FileEvent{
+					URI:  protocol.URIFromPath(path),
+					Type: change,
+				})
+			}
+		}
+		if len(changes) == 0 {
+			continue
+		}
+		slog.Debug("Notifying LSP server of file changes", "name", name, "changes", len(changes))
+		if err := client.DidChangeWatchedFiles(ctx, protocol.DidChangeWatchedFilesParams{Changes: changes}); err != nil {
+			slog.Warn("Failed to notify LSP server of file changes", "name", name, "error", err)
+		}
+	}
+}
+
+// WatchesFile returns whether the server registered a file watcher for the
+// given kind of change of the file.
+func (c *Client) WatchesFile(path string, change protocol.FileChangeType) bool {
+	kind := map[protocol.FileChangeType]protocol.WatchKind{
+		protocol.Created: protocol.WatchCreate,
+		protocol.Changed: protocol.WatchChange,
+		protocol.Deleted: protocol.WatchDelete,
+	}[change]
+
+	for watchers := range c.fileWatchers.Seq() {
+		for _, watcher := range watchers {
+			if watcher.Kind != nil && *watcher.Kind&kind == 0 {
+				continue
+			}
+			if matchesGlobPattern(watcher.GlobPattern, c.WorkspaceRoots(), path) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// matchesGlobPattern returns whether the path matches the pattern of a file
+// watcher. Plain patterns are matched against the absolute path and the path
+// relative to the workspace roots; relative patterns against the path
+// relative to their base.
+func matchesGlobPattern(pattern protocol.GlobPattern, roots []string, path string) bool {
+	switch p := pattern.Value.(type) {
+	case string:
+		if matchGlob(p, path) {
+			return true
+		}
+		for _, root := range roots {
+			rel, err := filepath.Rel(root, path)
+			if err == nil && !strings.HasPrefix(rel, "..") && matchGlob(p, rel) {
+				return true
+			}
+		}
+		return false
+	case protocol.RelativePattern:
+		var base string
+		switch b := p.BaseURI.Value.(type) {
+		case protocol.URI:
+			base = string(b)
+		case protocol.WorkspaceFolder:
+			base = string(b.URI)
+		}
+		basePath, err := protocol.DocumentURI(base).Path()
+		if err != nil {
+			return false
+		}
+		rel, err := filepath.Rel(basePath, path)
+		return err == nil && !strings.HasPrefix(rel, "..") && matchGlob(p.Pattern, rel)
+	}
+	return false
+}
+
+func matchGlob(pattern, path string) bool {
+	matched, err := doublestar.Match(pattern, filepath.ToSlash(path))
+	return err == nil && matched
+}
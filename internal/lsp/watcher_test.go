@@ -0,0 +1,92 @@
+package lsp
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/charmbracelet/crush/internal/csync"
+	"github.com/charmbracelet/x/powernap/pkg/lsp/protocol"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWatchesFile(t *testing.T) {
+	t.Parallel()
+
+	workDir := t.TempDir()
+	deleteOnly := protocol.WatchDelete
+	client := &Client{
+		workDir:      workDir,
+		fileWatchers: csync.NewMap[string, []protocol.FileSystemWatcher](),
+	}
+	client.fileWatchers.Set("go", []protocol.FileSystemWatcher{
+		{GlobPattern: protocol.GlobPattern{Value: "**/*.go"}},
+		{
+			GlobPattern: protocol.GlobPattern{Value: "go.mod"},
+			Kind:        &deleteOnly,
+		},
+	})
+	client.fileWatchers.Set("config", []protocol.FileSystemWatcher{
+		{GlobPattern: protocol.GlobPattern{Value: protocol.RelativePattern{
+			BaseURI: protocol.Or_RelativePattern_baseUri{Value: protocol.URI(protocol.URIFromPath(filepath.Join(workDir, "config")))},
+			Pattern: "*.json",
+		}}},
+	})
+
+	require.True(t, client.WatchesFile(filepath.Join(workDir, "main.go"), protocol.Created))
+	require.True(t, client.WatchesFile(filepath.Join(workDir, "pkg", "a.go"), protocol.Changed))
+	require.False(t, client.WatchesFile(filepath.Join(workDir, "README.md"), protocol.Changed))
+
+	require.True(t, client.WatchesFile(filepath.Join(workDir, "go.mod"), protocol.Deleted))
+	require.False(t, client.WatchesFile(filepath.Join(workDir, "go.mod"), protocol.Changed))
+
+	require.True(t, client.WatchesFile(filepath.Join(workDir, "config", "app.json"), protocol.Changed))
+	require.False(t, client.WatchesFile(filepath.Join(workDir, "app.json"), protocol.Changed))
+
+	client.fileWatchers.Del("go")
+	require.False(t, client.WatchesFile(filepath.Join(workDir, "main.go"), protocol.Created))
+}
+
+func TestWatcherQueue(t *testing.T) {
+	t.Parallel()
+
+	w := NewWatcher([]string{t.TempDir()}, csync.NewMap[string, *Client]())
+	t.Cleanup(func() { _ = w.Close() })
+	w.debounce = time.Hour
+
+	w.queue(t.Context(), "created", protocol.Created)
+	w.queue(t.Context(), "created", protocol.Changed)
+	w.queue(t.Context(), "temporary", protocol.Created)
+	w.queue(t.Context(), "temporary", protocol.Deleted)
+	w.queue(t.Context(), "replaced", protocol.Deleted)
+	w.queue(t.Context(), "replaced", protocol.Created)
+	w.queue(t.Context(), "removed", protocol.Changed)
+	w.queue(t.Context(), "removed", protocol.Deleted)
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	require.Equal(t, map[string]protocol.FileChangeType{
+		"created":  protocol.Created,
+		"replaced": protocol.Changed,
+		"removed":  protocol.Deleted,
+	}, w.pending)
+}
+
+func TestWatcherOnChange(t *testing.T) {
+	t.Parallel()
+
+	w := NewWatcher([]string{t.TempDir()}, csync.NewMap[string, *Client]())
+	t.Cleanup(func() { _ = w.Close() })
+	w.debounce = time.Hour
+	var changed []string
+	w.OnChange(func(paths []string) { changed = paths })
+
+	w.queue(t.Context(), "b.go", protocol.Changed)
+	w.queue(t.Context(), "a.go", protocol.Created)
+	w.flush(t.Context())
+	require.Equal(t, []string{"a.go", "b.go"}, changed)
+
+	changed = nil
+	w.flush(t.Context())
+	require.Nil(t, changed)
+}
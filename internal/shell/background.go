@@ -2,9 +2,13 @@ package shell
 
 import (
 	"bytes"
+	"cmp"
 	"context"
 	"fmt"
+	"io"
+	"regexp"
 	"slices"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -17,32 +21,76 @@ const (
 	MaxBackgroundJobs = 50
 	// CompletedJobRetentionMinutes is how long to keep completed jobs before auto-cleanup (8 hours)
 	CompletedJobRetentionMinutes = 8 * 60
+	// maxOutputSize is how much of the interleaved output of a job is kept
+	// for reading from a cursor; older output is dropped.
+	maxOutputSize = 1024 * 1024
 )
 
 // syncBuffer is a thread-safe wrapper around bytes.Buffer.
 type syncBuffer struct {
 	buf bytes.Buffer
 	mu  sync.RWMutex
+	// limit is the number of bytes kept, the oldest ones being dropped past
+	// it; no limit if zero.
+	limit int
+	// base is the number of bytes dropped, i.e. the offset of the start of
+	// the buffer in everything written.
+	base int
+	// changed is closed, and replaced, on every write to wake up the
+	// readers waiting for more output.
+	changed chan struct{}
+}
+
+func newSyncBuffer() *syncBuffer {
+	return &syncBuffer{changed: make(chan struct{})}
+}
+
+// newRingBuffer returns a buffer keeping only the last limit bytes written.
+func newRingBuffer(limit int) *syncBuffer {
+	return &syncBuffer{limit: limit, changed: make(chan struct{})}
 }
 
 func (sb *syncBuffer) Write(p []byte) (n int, err error) {
 	sb.mu.Lock()
 	defer sb.mu.Unlock()
+	defer sb.notify()
 	return sb.buf.Write(p)
 }
 
 func (sb *syncBuffer) WriteString(s string) (n int, err error) {
 	sb.mu.Lock()
 	defer sb.mu.Unlock()
+	defer sb.notify()
 	return sb.buf.WriteString(s)
 }
 
+// notify drops the bytes past the limit and wakes up the waiting readers.
+// It must be called with the lock held.
+func (sb *syncBuffer) notify() {
+	if excess := sb.buf.Len() - sb.limit; sb.limit > 0 && excess > 0 {
+		sb.buf.Next(excess)
+		sb.base += excess
+	}
+	close(sb.changed)
+	sb.changed = make(chan struct{})
+}
+
 func (sb *syncBuffer) String() string {
 	sb.mu.RLock()
 	defer sb.mu.RUnlock()
 	return sb.buf.String()
 }
 
+// readFrom returns the content written from the given offset on, along with
+// the offset it starts at, later than the given one if the bytes in between
+// were dropped, and a channel closed on the next write.
+func (sb *syncBuffer) readFrom(offset int) (content string, start int, changed <-chan struct{}) {
+	sb.mu.RLock()
+	defer sb.mu.RUnlock()
+	start = min(max(offset, sb.base), sb.base+sb.buf.Len())
+	return string(sb.buf.Bytes()[start-sb.base:]), start, sb.changed
+}
+
 // BackgroundShell represents a shell running in the background.
 type BackgroundShell struct {
 	ID          string
@@ -52,8 +100,10 @@ type BackgroundShell struct {
 	WorkingDir  string
 	ctx         context.Context
 	cancel      context.CancelFunc
+	StartedAt   time.Time
 	stdout      *syncBuffer
 	stderr      *syncBuffer
+	output      *syncBuffer // stdout and stderr, interleaved as written
 	done        chan struct{}
 	exitErr     error
 	completedAt int64 // Unix timestamp when job completed (0 if still running)
@@ -107,10 +157,12 @@ func (m *BackgroundShellManager) Start(ctx context.Context, workingDir string, b
 		Description: description,
 		WorkingDir:  workingDir,
 		Shell:       shell,
+		StartedAt:   time.Now(),
 		ctx:         shellCtx,
 		cancel:      cancel,
-		stdout:      &syncBuffer{},
-		stderr:      &syncBuffer{},
+		stdout:      newSyncBuffer(),
+		stderr:      newSyncBuffer(),
+		output:      newRingBuffer(maxOutputSize),
 		done:        make(chan struct{}),
 	}
 
@@ -119,7 +171,12 @@ func (m *BackgroundShellManager) Start(ctx context.Context, workingDir string, b
 	go func() {
 		defer close(bgShell.done)
 
-		err := shell.ExecStream(shellCtx, command, bgShell.stdout, bgShell.stderr)
+		err := shell.ExecStream(
+			shellCtx,
+			command,
+			io.MultiWriter(bgShell.stdout, bgShell.output),
+			io.MultiWriter(bgShell.stderr, bgShell.output),
+		)
 
 		bgShell.exitErr = err
 		atomic.StoreInt64(&bgShell.completedAt, time.Now().Unix())
@@ -171,6 +228,15 @@ func (m *BackgroundShellManager) List() []string {
 	return ids
 }
 
+// Shells returns all background shells, oldest first.
+func (m *BackgroundShellManager) Shells() []*BackgroundShell {
+	shells := slices.Collect(m.shells.Seq())
+	slices.SortFunc(shells, func(a, b *BackgroundShell) int {
+		return cmp.Or(a.StartedAt.Compare(b.StartedAt), cmp.Compare(a.ID, b.ID))
+	})
+	return shells
+}
+
 // Cleanup removes completed jobs that have been finished for more than the retention period
 func (m *BackgroundShellManager) Cleanup() int {
 	now := time.Now().Unix()
@@ -226,6 +292,67 @@ func (bs *BackgroundShell) GetOutput() (stdout string, stderr string, done bool,
 	}
 }
 
+// ReadOutput returns the output, stdout and stderr interleaved as written,
+// from the given offset on, along with the offset it starts at and the offset
+// to read from next. Only the last megabyte of output is kept, so the output
+// starts later than the given offset if the output in between was dropped.
+func (bs *BackgroundShell) ReadOutput(offset int) (output string, start, next int) {
+	output, start, _ = bs.output.readFrom(offset)
+	return output, start, start + len(output)
+}
+
+// WaitForOutput blocks until the output from the given offset on matches re,
+// the shell exits, or ctx is done. On a match, it returns the offset of the
+// end of the match; otherwise it returns the offset of the end of the output,
+// and ok is false. A nil re waits for the shell to exit.
+//
+// The output is matched as it is written, each line once it is complete, so
+// patterns spanning several lines only match lines written together.
+func (bs *BackgroundShell) WaitForOutput(ctx context.Context, re *regexp.Regexp, offset int) (match string, next int, ok bool, err error) {
+	for {
+		// Check whether the shell is done before reading the output, so
+		// that no output is missed when it is.
+		done := bs.IsDone()
+		content, start, changed := bs.output.readFrom(offset)
+		if re != nil {
+			if loc := re.FindStringIndex(content); loc != nil {
+				return content[loc[0]:loc[1]], start + loc[1], true, nil
+			}
+		}
+		end := start + len(content)
+		if done {
+			return "", end, false, nil
+		}
+		// Only check the last, possibly incomplete, line again.
+		offset = start + strings.LastIndexByte(content, '\n') + 1
+
+		select {
+		case <-changed:
+		case <-bs.done:
+		case <-ctx.Done():
+			return "", end, false, ctx.Err()
+		}
+	}
+}
+
+// ExitErr returns the error the shell exited with, if it is done.
+func (bs *BackgroundShell) ExitErr() error {
+	if !bs.IsDone() {
+		return nil
+	}
+	return bs.exitErr
+}
+
+// CompletedAt returns when the shell exited, or the zero time if it is still
+// running.
+func (bs *BackgroundShell) CompletedAt() time.Time {
+	completedAt := atomic.LoadInt64(&bs.completedAt)
+	if completedAt == 0 {
+		return time.Time{}
+	}
+	return time.Unix(completedAt, 0)
+}
+
 // IsDone checks if the background shell has finished execution.
 func (bs *BackgroundShell) IsDone() bool {
 	select {
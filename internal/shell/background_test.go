@@ -2,6 +2,8 @@ package shell
 
 import (
 	"context"
+	"errors"
+	"regexp"
 	"runtime"
 	"strings"
 	"testing"
@@ -280,3 +282,139 @@ func TestBackgroundShellManager_KillAll(t *testing.T) {
 		}
 	}
 }
+
+func TestBackgroundShell_ReadOutput(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	workingDir := t.TempDir()
+	manager := newBackgroundShellManager()
+
+	bgShell, err := manager.Start(ctx, workingDir, nil, "echo 'out' && echo 'err' >&2 && echo 'more'", "")
+	if err != nil {
+		t.Fatalf("failed to start background shell: %v", err)
+	}
+	defer manager.Kill(bgShell.ID)
+	bgShell.Wait()
+
+	output, start, next := bgShell.ReadOutput(0)
+	if output != "out\nerr\nmore\n" {
+		t.Errorf("expected interleaved output, got: %q", output)
+	}
+	if start != 0 || next != len(output) {
+		t.Errorf("expected offsets 0 and %d, got %d and %d", len(output), start, next)
+	}
+
+	output, _, _ = bgShell.ReadOutput(4)
+	if output != "err\nmore\n" {
+		t.Errorf("expected output after offset, got: %q", output)
+	}
+
+	output, _, next = bgShell.ReadOutput(next + 10)
+	if output != "" || next != len("out\nerr\nmore\n") {
+		t.Errorf("expected no output past the end, got: %q, %d", output, next)
+	}
+}
+
+func TestRingBuffer(t *testing.T) {
+	t.Parallel()
+
+	buf := newRingBuffer(8)
+	buf.WriteString("one\ntwo\n")
+	buf.WriteString("three\n")
+
+	content, start, _ := buf.readFrom(0)
+	if content != "o\nthree\n" || start != 6 {
+		t.Errorf("expected the last 8 bytes at offset 6, got: %q at %d", content, start)
+	}
+	content, start, _ = buf.readFrom(9)
+	if content != "hree\n" || start != 9 {
+		t.Errorf("expected the output from offset 9, got: %q at %d", content, start)
+	}
+	content, start, _ = buf.readFrom(100)
+	if content != "" || start != 14 {
+		t.Errorf("expected no output at the end, got: %q at %d", content, start)
+	}
+}
+
+func TestBackgroundShell_WaitForOutput(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	workingDir := t.TempDir()
+	manager := newBackgroundShellManager()
+
+	bgShell, err := manager.Start(ctx, workingDir, nil, "echo 'starting' && sleep 0.2 && echo 'listening on :8080' && sleep 0.2 && echo 'ready'", "")
+	if err != nil {
+		t.Fatalf("failed to start background shell: %v", err)
+	}
+	defer manager.Kill(bgShell.ID)
+
+	waitCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	match, next, ok, err := bgShell.WaitForOutput(waitCtx, regexp.MustCompile(`listening on (\S+)`), 0)
+	if err != nil || !ok {
+		t.Fatalf("expected a match, got: %v, %v", ok, err)
+	}
+	if match != "listening on :8080" {
+		t.Errorf("unexpected match: %q", match)
+	}
+	if bgShell.IsDone() {
+		t.Error("shell should still be running after the match")
+	}
+
+	// Waiting again from the end of the match doesn't match the same line.
+	_, _, ok, err = bgShell.WaitForOutput(waitCtx, regexp.MustCompile(`listening`), next)
+	if err != nil || ok {
+		t.Errorf("expected no match after the cursor, got: %v, %v", ok, err)
+	}
+	if !bgShell.IsDone() {
+		t.Error("shell should be done when the pattern can't match anymore")
+	}
+}
+
+func TestBackgroundShell_WaitForOutputTimeout(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	workingDir := t.TempDir()
+	manager := newBackgroundShellManager()
+
+	bgShell, err := manager.Start(ctx, workingDir, nil, "sleep 10", "")
+	if err != nil {
+		t.Fatalf("failed to start background shell: %v", err)
+	}
+	defer manager.Kill(bgShell.ID)
+
+	waitCtx, cancel := context.WithTimeout(ctx, 100*time.Millisecond)
+	defer cancel()
+
+	_, _, ok, err := bgShell.WaitForOutput(waitCtx, nil, 0)
+	if ok || !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected a timeout, got: %v, %v", ok, err)
+	}
+}
+
+func TestBackgroundShellManager_Shells(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	workingDir := t.TempDir()
+	manager := newBackgroundShellManager()
+
+	first, err := manager.Start(ctx, workingDir, nil, "sleep 1", "")
+	if err != nil {
+		t.Fatalf("failed to start background shell: %v", err)
+	}
+	second, err := manager.Start(ctx, workingDir, nil, "sleep 1", "")
+	if err != nil {
+		t.Fatalf("failed to start background shell: %v", err)
+	}
+	defer manager.KillAll()
+
+	shells := manager.Shells()
+	if len(shells) != 2 || shells[0] != first || shells[1] != second {
+		t.Errorf("expected the shells in start order, got: %v", shells)
+	}
+}
@@ -613,10 +613,9 @@ func (m *editorCmp) SetPosition(x, y int) tea.Cmd {
 }
 
 func (m *editorCmp) startCompletions() tea.Msg {
-	ls := m.app.Config().Options.TUI.Completions
-	depth, limit := ls.Limits()
-	files, _, _ := fsext.ListDirectory(".", nil, depth, limit)
-	slices.Sort(files)
+	cfg := m.app.Config()
+	depth, limit := cfg.Options.TUI.Completions.Limits()
+	files := fsext.ListWorkspace(cfg.AdditionalDirectories(), depth, limit)
 	completionItems := make([]completions.Completion, 0, len(files))
 	for _, file := range files {
 		file = strings.TrimPrefix(file, "./")
@@ -184,6 +184,7 @@ func init() {
 	registry.register(tools.BashToolName, func() renderer { return bashRenderer{} })
 	registry.register(tools.JobOutputToolName, func() renderer { return bashOutputRenderer{} })
 	registry.register(tools.JobKillToolName, func() renderer { return bashKillRenderer{} })
+	registry.register(tools.JobWaitToolName, func() renderer { return bashWaitRenderer{} })
 	registry.register(tools.DownloadToolName, func() renderer { return downloadRenderer{} })
 	registry.register(tools.ViewToolName, func() renderer { return viewRenderer{} })
 	registry.register(tools.EditToolName, func() renderer { return editRenderer{} })
@@ -414,6 +415,42 @@ func (bkr bashKillRenderer) Render(v *toolCallCmp) string {
 	return joinHeaderBody(header, body)
 }
 
+// -----------------------------------------------------------------------------
+//  Bash Wait renderer
+// -----------------------------------------------------------------------------
+
+// bashWaitRenderer handles waiting on a background shell display
+type bashWaitRenderer struct {
+	baseRenderer
+}
+
+// Render displays the shell ID, the pattern waited for and the output read
+func (bwr bashWaitRenderer) Render(v *toolCallCmp) string {
+	var params tools.JobWaitParams
+	if err := bwr.unmarshalParams(v.call.Input, &params); err != nil {
+		return bwr.renderError(v, "Invalid job_wait parameters")
+	}
+
+	description := params.Pattern
+	if description == "" {
+		description = "exit"
+	}
+
+	width := v.textWidth()
+	if v.isNested {
+		width -= 4 // Adjust for nested tool call indentation
+	}
+	header := makeJobHeader(v, "Wait", fmt.Sprintf("PID %s", params.ShellID), description, width)
+	if v.isNested {
+		return v.style().Render(header)
+	}
+	if res, done := earlyState(header, v); done {
+		return res
+	}
+	body := renderPlainContent(v, v.result.Content)
+	return joinHeaderBody(header, body)
+}
+
 // -----------------------------------------------------------------------------
 //  View renderer
 // -----------------------------------------------------------------------------
@@ -435,6 +472,7 @@ func (vr viewRenderer) Render(v *toolCallCmp) string {
 		addMain(file).
 		addKeyValue("limit", formatNonZero(params.Limit)).
 		addKeyValue("offset", formatNonZero(params.Offset)).
+		addKeyValue("pages", params.Pages).
 		build()
 
 	return vr.renderWithParams(v, "View", args, func() string {
@@ -609,6 +647,10 @@ func (fr simpleFetchRenderer) Render(v *toolCallCmp) string {
 			addMain(params.URL).
 			addKeyValue("format", params.Format).
 			addKeyValue("timeout", formatTimeout(params.Timeout)).
+			addFlag("refresh", params.Refresh).
+			addKeyValue("section", params.Section).
+			addKeyValue("selector", params.Selector).
+			addKeyValue("page", formatNonZero(params.Page)).
 			build()
 	}
 
@@ -728,6 +770,9 @@ func (wfr webFetchRenderer) Render(v *toolCallCmp) string {
 	if err := wfr.unmarshalParams(v.call.Input, &params); err == nil {
 		args = newParamBuilder().
 			addMain(params.URL).
+			addKeyValue("section", params.Section).
+			addKeyValue("selector", params.Selector).
+			addKeyValue("page", formatNonZero(params.Page)).
 			build()
 	}
 
@@ -820,7 +865,7 @@ type grepRenderer struct {
 	baseRenderer
 }
 
-// Render displays the search pattern with path, include, and literal text options
+// Render displays the search pattern with its path, filter and output options
 func (gr grepRenderer) Render(v *toolCallCmp) string {
 	var params tools.GrepParams
 	var args []string
@@ -829,7 +874,14 @@ func (gr grepRenderer) Render(v *toolCallCmp) string {
 			addMain(params.Pattern).
 			addKeyValue("path", params.Path).
 			addKeyValue("include", params.Include).
+			addKeyValue("type", params.Type).
+			addKeyValue("mode", params.OutputMode).
+			addKeyValue("before", formatNonZero(params.ContextBefore)).
+			addKeyValue("after", formatNonZero(params.ContextAfter)).
+			addKeyValue("offset", formatNonZero(params.Offset)).
 			addFlag("literal", params.LiteralText).
+			addFlag("ignore case", params.CaseInsensitive).
+			addFlag("multiline", params.Multiline).
 			build()
 	}
 
@@ -1278,6 +1330,20 @@ func prettifyToolName(name string) string {
 		return "Job: Output"
 	case tools.JobKillToolName:
 		return "Job: Kill"
+	case tools.JobWaitToolName:
+		return "Job: Wait"
+	case tools.JobListToolName:
+		return "Job: List"
+	case tools.GitStatusToolName:
+		return "Git: Status"
+	case tools.GitDiffToolName:
+		return "Git: Diff"
+	case tools.GitLogToolName:
+		return "Git: Log"
+	case tools.GitBlameToolName:
+		return "Git: Blame"
+	case tools.GitCommitToolName:
+		return "Git: Commit"
 	case tools.DownloadToolName:
 		return "Download"
 	case tools.EditToolName:
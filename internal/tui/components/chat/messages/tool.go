@@ -325,9 +325,21 @@ func (m *toolCallCmp) formatParametersForCopy() string {
 			if params.Include != "" {
 				parts = append(parts, fmt.Sprintf("**Include:** %s", params.Include))
 			}
+			if params.Type != "" {
+				parts = append(parts, fmt.Sprintf("**Type:** %s", params.Type))
+			}
+			if params.OutputMode != "" {
+				parts = append(parts, fmt.Sprintf("**Output Mode:** %s", params.OutputMode))
+			}
 			if params.LiteralText {
 				parts = append(parts, "**Literal:** true")
 			}
+			if params.CaseInsensitive {
+				parts = append(parts, "**Case Insensitive:** true")
+			}
+			if params.Multiline {
+				parts = append(parts, "**Multiline:** true")
+			}
 			return strings.Join(parts, "\n")
 		}
 	case tools.GlobToolName:
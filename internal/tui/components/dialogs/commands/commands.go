@@ -84,6 +84,10 @@ type (
 	CompactMsg             struct {
 		SessionID string
 	}
+	// AddDirectoryMsg adds a project directory to the workspace.
+	AddDirectoryMsg struct {
+		Path string
+	}
 )
 
 func NewCommandDialog(sessionID string) CommandsDialog {
@@ -464,6 +468,30 @@ func (c *commandDialogCmp) defaultCommands() []Command {
 				})
 			},
 		},
+		{
+			ID:          "add_dir",
+			Title:       "Add Directory",
+			Description: "Let the tools and LSPs work in another project directory",
+			Handler: func(cmd Command) tea.Cmd {
+				return util.CmdHandler(dialogs.OpenDialogMsg{
+					Model: NewCommandArgumentsDialog(
+						"add_dir",
+						"Add Directory",
+						"add_dir",
+						"Let the tools and LSPs work in another project directory for this session.",
+						[]Argument{{
+							Name:        "path",
+							Title:       "Path",
+							Description: "Directory to add, relative to the working directory",
+							Required:    true,
+						}},
+						func(args map[string]string) tea.Cmd {
+							return util.CmdHandler(AddDirectoryMsg{Path: args["path"]})
+						},
+					),
+				})
+			},
+		},
 		{
 			ID:          "quit",
 			Title:       "Quit",
@@ -92,7 +92,11 @@ func (p *permissionDialogCmp) Init() tea.Cmd {
 }
 
 func (p *permissionDialogCmp) supportsDiffView() bool {
-	return p.permission.ToolName == tools.EditToolName || p.permission.ToolName == tools.WriteToolName || p.permission.ToolName == tools.MultiEditToolName
+	switch p.permission.ToolName {
+	case tools.EditToolName, tools.WriteToolName, tools.MultiEditToolName, tools.RenameToolName, tools.ApplyPatchToolName, tools.NotebookEditToolName:
+		return true
+	}
+	return false
 }
 
 func (p *permissionDialogCmp) Update(msg tea.Msg) (util.Model, tea.Cmd) {
@@ -381,6 +385,43 @@ func (p *permissionDialogCmp) renderHeader() string {
 			),
 			baseStyle.Render(strings.Repeat(" ", p.width)),
 		)
+	case tools.NotebookEditToolName:
+		params := p.permission.Params.(tools.NotebookEditPermissionsParams)
+		fileKey := t.S().Muted.Render("File")
+		filePath := t.S().Text.
+			Width(p.width - lipgloss.Width(fileKey)).
+			Render(fmt.Sprintf(" %s", fsext.PrettyPath(params.FilePath)))
+		descKey := t.S().Muted.Render("Desc")
+		desc := t.S().Text.
+			Width(p.width - lipgloss.Width(descKey)).
+			Render(fmt.Sprintf(" %s", params.Description))
+		headerParts = append(headerParts,
+			lipgloss.JoinHorizontal(
+				lipgloss.Left,
+				fileKey,
+				filePath,
+			),
+			lipgloss.JoinHorizontal(
+				lipgloss.Left,
+				descKey,
+				desc,
+			),
+			baseStyle.Render(strings.Repeat(" ", p.width)),
+		)
+	case tools.RenameToolName, tools.ApplyPatchToolName:
+		params := p.permission.Params.(tools.WorkspaceEditPermissionsParams)
+		descKey := t.S().Muted.Render("Desc")
+		desc := t.S().Text.
+			Width(p.width - lipgloss.Width(descKey)).
+			Render(fmt.Sprintf(" %s (%d files)", params.Description, len(params.Files)))
+		headerParts = append(headerParts,
+			lipgloss.JoinHorizontal(
+				lipgloss.Left,
+				descKey,
+				desc,
+			),
+			baseStyle.Render(strings.Repeat(" ", p.width)),
+		)
 	case tools.FetchToolName:
 		headerParts = append(headerParts,
 			baseStyle.Render(strings.Repeat(" ", p.width)),
@@ -391,6 +432,36 @@ func (p *permissionDialogCmp) renderHeader() string {
 			baseStyle.Render(strings.Repeat(" ", p.width)),
 			t.S().Muted.Width(p.width).Bold(true).Render("Web"),
 		)
+	case tools.GitCommitToolName:
+		params := p.permission.Params.(tools.GitCommitPermissionsParams)
+		stageKey := t.S().Muted.Render("Stage")
+		stageValue := t.S().Text.
+			Width(p.width - lipgloss.Width(stageKey)).
+			Render(fmt.Sprintf(" %s", gitCommitStaging(params)))
+		headerParts = append(headerParts,
+			lipgloss.JoinHorizontal(
+				lipgloss.Left,
+				stageKey,
+				stageValue,
+			),
+			baseStyle.Render(strings.Repeat(" ", p.width)),
+			t.S().Muted.Width(p.width).Bold(true).Render("Message"),
+		)
+	case tools.WebSearchToolName:
+		params := p.permission.Params.(tools.WebSearchPermissionsParams)
+		providerKey := t.S().Muted.Render("Provider")
+		provider := t.S().Text.
+			Width(p.width - lipgloss.Width(providerKey)).
+			Render(fmt.Sprintf(" %s", params.Provider))
+		headerParts = append(headerParts,
+			lipgloss.JoinHorizontal(
+				lipgloss.Left,
+				providerKey,
+				provider,
+			),
+			baseStyle.Render(strings.Repeat(" ", p.width)),
+			t.S().Muted.Width(p.width).Bold(true).Render("Query"),
+		)
 	case tools.ViewToolName:
 		params := p.permission.Params.(tools.ViewPermissionsParams)
 		fileKey := t.S().Muted.Render("File")
@@ -443,10 +514,18 @@ func (p *permissionDialogCmp) getOrGenerateContent() string {
 		content = p.generateWriteContent()
 	case tools.MultiEditToolName:
 		content = p.generateMultiEditContent()
+	case tools.RenameToolName, tools.ApplyPatchToolName:
+		content = p.generateWorkspaceEditContent()
+	case tools.NotebookEditToolName:
+		content = p.generateNotebookEditContent()
 	case tools.FetchToolName:
 		content = p.generateFetchContent()
 	case tools.AgenticFetchToolName:
 		content = p.generateAgenticFetchContent()
+	case tools.WebSearchToolName:
+		content = p.generateWebSearchContent()
+	case tools.GitCommitToolName:
+		content = p.generateGitCommitContent()
 	case tools.ViewToolName:
 		content = p.generateViewContent()
 	case tools.LSToolName:
@@ -588,6 +667,56 @@ func (p *permissionDialogCmp) generateMultiEditContent() string {
 	return ""
 }
 
+// generateNotebookEditContent renders the diff of the notebook cells changed
+// by the edit.
+func (p *permissionDialogCmp) generateNotebookEditContent() string {
+	if pr, ok := p.permission.Params.(tools.NotebookEditPermissionsParams); ok {
+		formatter := core.DiffFormatter().
+			Before(fsext.PrettyPath(pr.FilePath), pr.OldContent).
+			After(fsext.PrettyPath(pr.FilePath), pr.NewContent).
+			Height(p.contentViewPort.Height()).
+			Width(p.contentViewPort.Width()).
+			XOffset(p.diffXOffset).
+			YOffset(p.diffYOffset)
+		if p.useDiffSplitMode() {
+			formatter = formatter.Split()
+		} else {
+			formatter = formatter.Unified()
+		}
+		return formatter.String()
+	}
+	return ""
+}
+
+// generateWorkspaceEditContent renders the diffs of all the files changed by
+// a workspace edit one after the other, scrolled vertically as a whole.
+func (p *permissionDialogCmp) generateWorkspaceEditContent() string {
+	if pr, ok := p.permission.Params.(tools.WorkspaceEditPermissionsParams); ok {
+		var lines []string
+		for _, file := range pr.Files {
+			formatter := core.DiffFormatter().
+				Before(fsext.PrettyPath(file.FilePath), file.OldContent).
+				After(fsext.PrettyPath(file.FilePath), file.NewContent).
+				Width(p.contentViewPort.Width()).
+				XOffset(p.diffXOffset)
+			if p.useDiffSplitMode() {
+				formatter = formatter.Split()
+			} else {
+				formatter = formatter.Unified()
+			}
+			lines = append(lines, strings.Split(formatter.String(), "\n")...)
+		}
+
+		p.diffYOffset = min(p.diffYOffset, max(0, len(lines)-p.contentViewPort.Height()))
+		lines = lines[p.diffYOffset:]
+		if len(lines) > p.contentViewPort.Height() {
+			lines = lines[:p.contentViewPort.Height()]
+		}
+		return strings.Join(lines, "\n")
+	}
+	return ""
+}
+
 func (p *permissionDialogCmp) generateFetchContent() string {
 	t := styles.CurrentTheme()
 	baseStyle := t.S().Base.Background(t.BgSubtle)
@@ -620,6 +749,47 @@ func (p *permissionDialogCmp) generateAgenticFetchContent() string {
 	return ""
 }
 
+func (p *permissionDialogCmp) generateWebSearchContent() string {
+	t := styles.CurrentTheme()
+	baseStyle := t.S().Base.Background(t.BgSubtle)
+	if pr, ok := p.permission.Params.(tools.WebSearchPermissionsParams); ok {
+		finalContent := baseStyle.
+			Padding(1, 2).
+			Width(p.contentViewPort.Width()).
+			Render(pr.Query)
+		return finalContent
+	}
+	return ""
+}
+
+func (p *permissionDialogCmp) generateGitCommitContent() string {
+	t := styles.CurrentTheme()
+	baseStyle := t.S().Base.Background(t.BgSubtle)
+	if pr, ok := p.permission.Params.(tools.GitCommitPermissionsParams); ok {
+		finalContent := baseStyle.
+			Padding(1, 2).
+			Width(p.contentViewPort.Width()).
+			Render(pr.Message)
+		return finalContent
+	}
+	return ""
+}
+
+// gitCommitStaging describes the files a commit stages before committing.
+func gitCommitStaging(params tools.GitCommitPermissionsParams) string {
+	var parts []string
+	if params.All {
+		parts = append(parts, "all tracked files")
+	}
+	for _, file := range params.Files {
+		parts = append(parts, fsext.PrettyPath(file))
+	}
+	if len(parts) == 0 {
+		return "already staged changes only"
+	}
+	return strings.Join(parts, ", ")
+}
+
 func (p *permissionDialogCmp) generateViewContent() string {
 	t := styles.CurrentTheme()
 	baseStyle := t.S().Base.Background(t.BgSubtle)
@@ -631,6 +801,9 @@ func (p *permissionDialogCmp) generateViewContent() string {
 		if pr.Limit > 0 && pr.Limit != 2000 { // 2000 is the default limit
 			content += fmt.Sprintf("\nLines to read: %d", pr.Limit)
 		}
+		if pr.Pages != "" {
+			content += fmt.Sprintf("\nPages: %s", pr.Pages)
+		}
 
 		finalContent := baseStyle.
 			Padding(1, 2).
@@ -820,13 +993,13 @@ func (p *permissionDialogCmp) SetSize() tea.Cmd {
 	case tools.WriteToolName:
 		p.width = int(float64(p.wWidth) * 0.8)
 		p.height = int(float64(p.wHeight) * 0.8)
-	case tools.MultiEditToolName:
+	case tools.MultiEditToolName, tools.RenameToolName, tools.ApplyPatchToolName, tools.NotebookEditToolName:
 		p.width = int(float64(p.wWidth) * 0.8)
 		p.height = int(float64(p.wHeight) * 0.8)
-	case tools.FetchToolName:
+	case tools.FetchToolName, tools.WebSearchToolName:
 		p.width = int(float64(p.wWidth) * 0.8)
 		p.height = int(float64(p.wHeight) * 0.3)
-	case tools.AgenticFetchToolName:
+	case tools.AgenticFetchToolName, tools.GitCommitToolName:
 		p.width = int(float64(p.wWidth) * 0.8)
 		p.height = int(float64(p.wHeight) * 0.4)
 	case tools.ViewToolName:
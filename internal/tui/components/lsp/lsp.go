@@ -100,8 +100,14 @@ func RenderLSPList(lspClients *csync.Map[string, *lsp.Client], opts RenderOption
 func iconAndDescription(t *styles.Theme, info app.LSPClientInfo) (lipgloss.Style, string) {
 	switch info.State {
 	case lsp.StateStarting:
+		if len(info.Progress) > 0 {
+			return t.ItemBusyIcon, t.S().Subtle.Render(info.Progress[0].String())
+		}
 		return t.ItemBusyIcon, t.S().Subtle.Render("starting...")
 	case lsp.StateReady:
+		if len(info.Progress) > 0 {
+			return t.ItemBusyIcon, t.S().Subtle.Render(info.Progress[0].String())
+		}
 		return t.ItemOnlineIcon, ""
 	case lsp.StateError:
 		description := t.S().Subtle.Render("error")
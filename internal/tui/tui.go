@@ -158,6 +158,11 @@ func (a *appModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		a.completions.Update(msg)
 		return a, a.handleWindowResize(msg.Width, msg.Height)
 
+	case pubsub.Event[app.LSPEvent]:
+		if info, ok := msg.Payload.StatusMsg(); ok {
+			cmds = append(cmds, util.CmdHandler(info))
+		}
+
 	case pubsub.Event[mcp.Event]:
 		switch msg.Payload.Type {
 		case mcp.EventStateChanged:
@@ -268,6 +273,14 @@ func (a *appModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return a, util.CmdHandler(dialogs.OpenDialogMsg{
 			Model: quit.NewQuitDialog(),
 		})
+	case commands.AddDirectoryMsg:
+		return a, func() tea.Msg {
+			path, err := a.app.AddDirectory(context.Background(), msg.Path)
+			if err != nil {
+				return util.ReportError(err)()
+			}
+			return util.ReportInfo("Added directory " + path)()
+		}
 	case commands.ToggleYoloModeMsg:
 		a.app.Permissions.SetSkipRequests(!a.app.Permissions.SkipRequests())
 	case commands.ToggleHelpMsg:
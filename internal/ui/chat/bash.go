@@ -197,6 +197,50 @@ func (j *JobKillToolRenderContext) RenderTool(sty *styles.Styles, width int, opt
 	return renderJobTool(sty, opts, cappedWidth, "Kill", params.ShellID, description, content)
 }
 
+// -----------------------------------------------------------------------------
+// Job Wait Tool
+// -----------------------------------------------------------------------------
+
+// JobWaitToolMessageItem is a message item for job_wait tool calls.
+type JobWaitToolMessageItem struct {
+	*baseToolMessageItem
+}
+
+var _ ToolMessageItem = (*JobWaitToolMessageItem)(nil)
+
+// NewJobWaitToolMessageItem creates a new [JobWaitToolMessageItem].
+func NewJobWaitToolMessageItem(
+	sty *styles.Styles,
+	toolCall message.ToolCall,
+	result *message.ToolResult,
+	canceled bool,
+) ToolMessageItem {
+	return newBaseToolMessageItem(sty, toolCall, result, &JobWaitToolRenderContext{}, canceled)
+}
+
+// JobWaitToolRenderContext renders job_wait tool messages.
+type JobWaitToolRenderContext struct{}
+
+// RenderTool implements the [ToolRenderer] interface.
+func (j *JobWaitToolRenderContext) RenderTool(sty *styles.Styles, width int, opts *ToolRenderOpts) string {
+	cappedWidth := cappedMessageWidth(width)
+	if opts.IsPending() {
+		return pendingTool(sty, "Job", opts.Anim)
+	}
+
+	var params tools.JobWaitParams
+	if err := json.Unmarshal([]byte(opts.ToolCall.Input), &params); err != nil {
+		return toolErrorContent(sty, &message.ToolResult{Content: "Invalid parameters"}, cappedWidth)
+	}
+
+	description := cmp.Or(params.Pattern, "exit")
+	content := ""
+	if opts.HasResult() {
+		content = opts.Result.Content
+	}
+	return renderJobTool(sty, opts, cappedWidth, "Wait", params.ShellID, description, content)
+}
+
 // renderJobTool renders a job-related tool with the common pattern:
 // header → nested check → early state → body.
 func renderJobTool(sty *styles.Styles, opts *ToolRenderOpts, width int, action, shellID, description, content string) string {
@@ -2,6 +2,7 @@ package chat
 
 import (
 	"encoding/json"
+	"strconv"
 
 	"github.com/charmbracelet/crush/internal/agent/tools"
 	"github.com/charmbracelet/crush/internal/message"
@@ -51,6 +52,10 @@ func (f *FetchToolRenderContext) RenderTool(sty *styles.Styles, width int, opts
 	if params.Timeout != 0 {
 		toolParams = append(toolParams, "timeout", formatTimeout(params.Timeout))
 	}
+	if params.Refresh {
+		toolParams = append(toolParams, "refresh", "true")
+	}
+	toolParams = appendFetchSectionParams(toolParams, params.Section, params.Selector, params.Page)
 
 	header := toolHeader(sty, opts.Status, "Fetch", cappedWidth, opts.Compact, toolParams...)
 	if opts.Compact {
@@ -71,6 +76,21 @@ func (f *FetchToolRenderContext) RenderTool(sty *styles.Styles, width int, opts
 	return joinToolParts(header, body)
 }
 
+// appendFetchSectionParams appends the parameters narrowing a fetch down to
+// part of a page to toolParams.
+func appendFetchSectionParams(toolParams []string, section, selector string, page int) []string {
+	if section != "" {
+		toolParams = append(toolParams, "section", section)
+	}
+	if selector != "" {
+		toolParams = append(toolParams, "selector", selector)
+	}
+	if page != 0 {
+		toolParams = append(toolParams, "page", strconv.Itoa(page))
+	}
+	return toolParams
+}
+
 // getFileExtensionForFormat returns a filename with appropriate extension for syntax highlighting.
 func getFileExtensionForFormat(format string) string {
 	switch format {
@@ -119,7 +139,7 @@ func (w *WebFetchToolRenderContext) RenderTool(sty *styles.Styles, width int, op
 		return toolErrorContent(sty, &message.ToolResult{Content: "Invalid parameters"}, cappedWidth)
 	}
 
-	toolParams := []string{params.URL}
+	toolParams := appendFetchSectionParams([]string{params.URL}, params.Section, params.Selector, params.Page)
 	header := toolHeader(sty, opts.Status, "Fetch", cappedWidth, opts.Compact, toolParams...)
 	if opts.Compact {
 		return header
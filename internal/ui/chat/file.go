@@ -6,6 +6,7 @@ import (
 	"strings"
 
 	"github.com/charmbracelet/crush/internal/agent/tools"
+	"github.com/charmbracelet/crush/internal/diff"
 	"github.com/charmbracelet/crush/internal/fsext"
 	"github.com/charmbracelet/crush/internal/message"
 	"github.com/charmbracelet/crush/internal/ui/styles"
@@ -55,6 +56,9 @@ func (v *ViewToolRenderContext) RenderTool(sty *styles.Styles, width int, opts *
 	if params.Offset != 0 {
 		toolParams = append(toolParams, "offset", fmt.Sprintf("%d", params.Offset))
 	}
+	if params.Pages != "" {
+		toolParams = append(toolParams, "pages", params.Pages)
+	}
 
 	header := toolHeader(sty, opts.Status, "View", cappedWidth, opts.Compact, toolParams...)
 	if opts.Compact {
@@ -277,6 +281,157 @@ func (m *MultiEditToolRenderContext) RenderTool(sty *styles.Styles, width int, o
 	return joinToolParts(header, body)
 }
 
+// -----------------------------------------------------------------------------
+// Apply Patch Tool
+// -----------------------------------------------------------------------------
+
+// ApplyPatchToolMessageItem is a message item that represents an apply patch
+// tool call.
+type ApplyPatchToolMessageItem struct {
+	*baseToolMessageItem
+}
+
+var _ ToolMessageItem = (*ApplyPatchToolMessageItem)(nil)
+
+// NewApplyPatchToolMessageItem creates a new [ApplyPatchToolMessageItem].
+func NewApplyPatchToolMessageItem(
+	sty *styles.Styles,
+	toolCall message.ToolCall,
+	result *message.ToolResult,
+	canceled bool,
+) ToolMessageItem {
+	return newBaseToolMessageItem(sty, toolCall, result, &ApplyPatchToolRenderContext{}, canceled)
+}
+
+// ApplyPatchToolRenderContext renders apply patch tool messages.
+type ApplyPatchToolRenderContext struct{}
+
+// RenderTool implements the [ToolRenderer] interface.
+func (a *ApplyPatchToolRenderContext) RenderTool(sty *styles.Styles, width int, opts *ToolRenderOpts) string {
+	// Apply patch tool uses full width for diffs.
+	if opts.IsPending() {
+		return pendingTool(sty, "Apply Patch", opts.Anim)
+	}
+
+	var params tools.ApplyPatchParams
+	if err := json.Unmarshal([]byte(opts.ToolCall.Input), &params); err != nil {
+		return toolErrorContent(sty, &message.ToolResult{Content: "Invalid parameters"}, width)
+	}
+
+	var toolParams []string
+	if patches, err := diff.ParsePatch(params.Patch); err == nil {
+		if len(patches) == 1 {
+			path := patches[0].NewPath
+			if path == "" {
+				path = patches[0].OldPath
+			}
+			toolParams = []string{fsext.PrettyPath(path)}
+		} else {
+			toolParams = []string{fmt.Sprintf("%d files", len(patches))}
+		}
+	}
+
+	header := toolHeader(sty, opts.Status, "Apply Patch", width, opts.Compact, toolParams...)
+	if opts.Compact {
+		return header
+	}
+
+	if earlyState, ok := toolEarlyStateContent(sty, opts, width); ok {
+		return joinToolParts(header, earlyState)
+	}
+
+	if !opts.HasResult() {
+		return header
+	}
+
+	// Get the diffs of the files from metadata.
+	var meta tools.WorkspaceEditResponseMetadata
+	if err := json.Unmarshal([]byte(opts.Result.Metadata), &meta); err != nil || len(meta.Files) == 0 {
+		bodyWidth := width - toolBodyLeftPaddingTotal
+		body := sty.Tool.Body.Render(toolOutputPlainContent(sty, opts.Result.Content, bodyWidth, opts.ExpandedContent))
+		return joinToolParts(header, body)
+	}
+
+	bodies := make([]string, 0, len(meta.Files))
+	for _, file := range meta.Files {
+		bodies = append(bodies, toolOutputDiffContent(sty, fsext.PrettyPath(file.FilePath), file.OldContent, file.NewContent, width, opts.ExpandedContent))
+	}
+	return joinToolParts(header, strings.Join(bodies, "\n\n"))
+}
+
+// -----------------------------------------------------------------------------
+// Notebook Edit Tool
+// -----------------------------------------------------------------------------
+
+// NotebookEditToolMessageItem is a message item that represents a notebook
+// edit tool call.
+type NotebookEditToolMessageItem struct {
+	*baseToolMessageItem
+}
+
+var _ ToolMessageItem = (*NotebookEditToolMessageItem)(nil)
+
+// NewNotebookEditToolMessageItem creates a new [NotebookEditToolMessageItem].
+func NewNotebookEditToolMessageItem(
+	sty *styles.Styles,
+	toolCall message.ToolCall,
+	result *message.ToolResult,
+	canceled bool,
+) ToolMessageItem {
+	return newBaseToolMessageItem(sty, toolCall, result, &NotebookEditToolRenderContext{}, canceled)
+}
+
+// NotebookEditToolRenderContext renders notebook edit tool messages.
+type NotebookEditToolRenderContext struct{}
+
+// RenderTool implements the [ToolRenderer] interface.
+func (n *NotebookEditToolRenderContext) RenderTool(sty *styles.Styles, width int, opts *ToolRenderOpts) string {
+	// Notebook edit tool uses full width for diffs.
+	if opts.IsPending() {
+		return pendingTool(sty, "Notebook Edit", opts.Anim)
+	}
+
+	var params tools.NotebookEditParams
+	if err := json.Unmarshal([]byte(opts.ToolCall.Input), &params); err != nil {
+		return toolErrorContent(sty, &message.ToolResult{Content: "Invalid parameters"}, width)
+	}
+
+	file := fsext.PrettyPath(params.FilePath)
+	toolParams := []string{file}
+	if params.EditMode != "" {
+		toolParams = append(toolParams, "mode", params.EditMode)
+	}
+	if params.CellID != "" {
+		toolParams = append(toolParams, "cell", params.CellID)
+	} else {
+		toolParams = append(toolParams, "cell", fmt.Sprintf("%d", params.CellIndex))
+	}
+
+	header := toolHeader(sty, opts.Status, "Notebook Edit", width, opts.Compact, toolParams...)
+	if opts.Compact {
+		return header
+	}
+
+	if earlyState, ok := toolEarlyStateContent(sty, opts, width); ok {
+		return joinToolParts(header, earlyState)
+	}
+
+	if !opts.HasResult() {
+		return header
+	}
+
+	// Get the diff of the cells from metadata.
+	var meta tools.NotebookEditResponseMetadata
+	if err := json.Unmarshal([]byte(opts.Result.Metadata), &meta); err != nil {
+		bodyWidth := width - toolBodyLeftPaddingTotal
+		body := sty.Tool.Body.Render(toolOutputPlainContent(sty, opts.Result.Content, bodyWidth, opts.ExpandedContent))
+		return joinToolParts(header, body)
+	}
+
+	body := toolOutputDiffContent(sty, file, meta.OldContent, meta.NewContent, width, opts.ExpandedContent)
+	return joinToolParts(header, body)
+}
+
 // -----------------------------------------------------------------------------
 // Download Tool
 // -----------------------------------------------------------------------------
@@ -0,0 +1,133 @@
+package chat
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/crush/internal/agent/tools"
+	"github.com/charmbracelet/crush/internal/fsext"
+	"github.com/charmbracelet/crush/internal/message"
+	"github.com/charmbracelet/crush/internal/ui/styles"
+)
+
+// GitToolMessageItem is a message item that represents a call to one of the
+// git tools: status, diff, log, blame and commit.
+type GitToolMessageItem struct {
+	*baseToolMessageItem
+}
+
+var _ ToolMessageItem = (*GitToolMessageItem)(nil)
+
+// NewGitToolMessageItem creates a new [GitToolMessageItem].
+func NewGitToolMessageItem(
+	sty *styles.Styles,
+	toolCall message.ToolCall,
+	result *message.ToolResult,
+	canceled bool,
+) ToolMessageItem {
+	return newBaseToolMessageItem(sty, toolCall, result, &GitToolRenderContext{}, canceled)
+}
+
+// GitToolRenderContext renders git tool messages.
+type GitToolRenderContext struct{}
+
+// RenderTool implements the [ToolRenderer] interface.
+func (r *GitToolRenderContext) RenderTool(sty *styles.Styles, width int, opts *ToolRenderOpts) string {
+	cappedWidth := cappedMessageWidth(width)
+	name := prettifyToolName(opts.ToolCall.Name)
+	if opts.IsPending() {
+		return pendingTool(sty, name, opts.Anim)
+	}
+
+	var toolParams []string
+	switch opts.ToolCall.Name {
+	case tools.GitStatusToolName:
+		var params tools.GitStatusParams
+		_ = json.Unmarshal([]byte(opts.ToolCall.Input), &params)
+		if params.Path != "" {
+			toolParams = []string{fsext.PrettyPath(params.Path)}
+		}
+	case tools.GitDiffToolName:
+		var params tools.GitDiffParams
+		_ = json.Unmarshal([]byte(opts.ToolCall.Input), &params)
+		toolParams = gitRefParams(params.Ref, params.Path)
+		if params.Staged {
+			toolParams = append(toolParams, "staged", "true")
+		}
+		if params.Hunk > 0 {
+			toolParams = append(toolParams, "hunk", fmt.Sprintf("%d", params.Hunk))
+		}
+		if params.StatOnly {
+			toolParams = append(toolParams, "stat_only", "true")
+		}
+	case tools.GitLogToolName:
+		var params tools.GitLogParams
+		_ = json.Unmarshal([]byte(opts.ToolCall.Input), &params)
+		toolParams = gitRefParams(params.Ref, params.Path)
+		if params.Limit > 0 {
+			toolParams = append(toolParams, "limit", fmt.Sprintf("%d", params.Limit))
+		}
+		if params.Author != "" {
+			toolParams = append(toolParams, "author", params.Author)
+		}
+		if params.Grep != "" {
+			toolParams = append(toolParams, "grep", params.Grep)
+		}
+	case tools.GitBlameToolName:
+		var params tools.GitBlameParams
+		_ = json.Unmarshal([]byte(opts.ToolCall.Input), &params)
+		toolParams = []string{fsext.PrettyPath(params.FilePath)}
+		if params.StartLine > 0 || params.EndLine > 0 {
+			toolParams = append(toolParams, "lines", fmt.Sprintf("%d-%d", max(params.StartLine, 1), params.EndLine))
+		}
+		if params.Ref != "" {
+			toolParams = append(toolParams, "ref", params.Ref)
+		}
+	case tools.GitCommitToolName:
+		var params tools.GitCommitParams
+		_ = json.Unmarshal([]byte(opts.ToolCall.Input), &params)
+		subject, _, _ := strings.Cut(params.Message, "\n")
+		toolParams = []string{subject}
+		if len(params.Files) > 0 {
+			toolParams = append(toolParams, "files", fmt.Sprintf("%d", len(params.Files)))
+		}
+		if params.All {
+			toolParams = append(toolParams, "all", "true")
+		}
+	}
+
+	header := toolHeader(sty, opts.Status, name, cappedWidth, opts.Compact, toolParams...)
+	if opts.Compact {
+		return header
+	}
+
+	if earlyState, ok := toolEarlyStateContent(sty, opts, cappedWidth); ok {
+		return joinToolParts(header, earlyState)
+	}
+
+	if opts.HasEmptyResult() {
+		return header
+	}
+
+	bodyWidth := cappedWidth - toolBodyLeftPaddingTotal
+	body := sty.Tool.Body.Render(toolOutputPlainContent(sty, opts.Result.Content, bodyWidth, opts.ExpandedContent))
+	return joinToolParts(header, body)
+}
+
+// gitRefParams returns the header params of a git tool looking at a ref
+// and a path.
+func gitRefParams(ref, path string) []string {
+	var params []string
+	if ref != "" {
+		params = append(params, ref)
+	}
+	if path != "" {
+		if len(params) == 0 {
+			params = append(params, fsext.PrettyPath(path))
+		} else {
+			params = append(params, "path", fsext.PrettyPath(path))
+		}
+	}
+	return params
+}
@@ -0,0 +1,101 @@
+package chat
+
+import (
+	"encoding/json"
+
+	"github.com/charmbracelet/crush/internal/agent/tools"
+	"github.com/charmbracelet/crush/internal/fsext"
+	"github.com/charmbracelet/crush/internal/message"
+	"github.com/charmbracelet/crush/internal/ui/styles"
+)
+
+// LSPNavigationToolMessageItem is a message item that represents a call to
+// one of the code navigation tools: the LSP hover tool and the code index
+// symbols tool.
+type LSPNavigationToolMessageItem struct {
+	*baseToolMessageItem
+}
+
+var _ ToolMessageItem = (*LSPNavigationToolMessageItem)(nil)
+
+// NewLSPNavigationToolMessageItem creates a new [LSPNavigationToolMessageItem].
+func NewLSPNavigationToolMessageItem(
+	sty *styles.Styles,
+	toolCall message.ToolCall,
+	result *message.ToolResult,
+	canceled bool,
+) ToolMessageItem {
+	return newBaseToolMessageItem(sty, toolCall, result, &LSPNavigationToolRenderContext{}, canceled)
+}
+
+// LSPNavigationToolRenderContext renders LSP navigation tool messages.
+type LSPNavigationToolRenderContext struct{}
+
+// RenderTool implements the [ToolRenderer] interface.
+func (r *LSPNavigationToolRenderContext) RenderTool(sty *styles.Styles, width int, opts *ToolRenderOpts) string {
+	cappedWidth := cappedMessageWidth(width)
+
+	var name string
+	var toolParams []string
+	switch opts.ToolCall.Name {
+	case tools.HoverToolName:
+		var params tools.HoverParams
+		_ = json.Unmarshal([]byte(opts.ToolCall.Input), &params)
+		name = "Hover"
+		toolParams = symbolToolParams(params.Symbol, params.Path)
+	default:
+		var params tools.SymbolsParams
+		_ = json.Unmarshal([]byte(opts.ToolCall.Input), &params)
+		name = "Symbols"
+		switch {
+		case params.ImportedBy != "":
+			name = "Find Importers"
+			toolParams = []string{params.ImportedBy}
+		case params.Query == "" && params.Kind == "":
+			toolParams = []string{fsext.PrettyPath(params.Path)}
+		default:
+			toolParams = []string{params.Query}
+			if params.Kind != "" {
+				toolParams = append(toolParams, "kind", params.Kind)
+			}
+			if params.Path != "" {
+				toolParams = append(toolParams, "path", fsext.PrettyPath(params.Path))
+			}
+		}
+	}
+
+	if opts.IsPending() {
+		return pendingTool(sty, name, opts.Anim)
+	}
+
+	header := toolHeader(sty, opts.Status, name, cappedWidth, opts.Compact, toolParams...)
+	if opts.Compact {
+		return header
+	}
+
+	if earlyState, ok := toolEarlyStateContent(sty, opts, cappedWidth); ok {
+		return joinToolParts(header, earlyState)
+	}
+
+	if opts.HasEmptyResult() {
+		return header
+	}
+
+	bodyWidth := cappedWidth - toolBodyLeftPaddingTotal
+	var body string
+	if opts.ToolCall.Name == tools.HoverToolName {
+		body = toolOutputMarkdownContent(sty, opts.Result.Content, bodyWidth, opts.ExpandedContent)
+	} else {
+		body = sty.Tool.Body.Render(toolOutputPlainContent(sty, opts.Result.Content, bodyWidth, opts.ExpandedContent))
+	}
+	return joinToolParts(header, body)
+}
+
+// symbolToolParams returns the header params of a tool looking up a symbol.
+func symbolToolParams(symbol, path string) []string {
+	params := []string{symbol}
+	if path != "" {
+		params = append(params, "path", fsext.PrettyPath(path))
+	}
+	return params
+}
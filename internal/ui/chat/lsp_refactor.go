@@ -0,0 +1,63 @@
+package chat
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/charmbracelet/crush/internal/agent/tools"
+	"github.com/charmbracelet/crush/internal/fsext"
+	"github.com/charmbracelet/crush/internal/message"
+	"github.com/charmbracelet/crush/internal/ui/styles"
+)
+
+// LSPRefactorToolMessageItem is a message item that represents a call to the
+// LSP rename tool.
+type LSPRefactorToolMessageItem struct {
+	*baseToolMessageItem
+}
+
+var _ ToolMessageItem = (*LSPRefactorToolMessageItem)(nil)
+
+// NewLSPRefactorToolMessageItem creates a new [LSPRefactorToolMessageItem].
+func NewLSPRefactorToolMessageItem(
+	sty *styles.Styles,
+	toolCall message.ToolCall,
+	result *message.ToolResult,
+	canceled bool,
+) ToolMessageItem {
+	return newBaseToolMessageItem(sty, toolCall, result, &LSPRefactorToolRenderContext{}, canceled)
+}
+
+// LSPRefactorToolRenderContext renders LSP refactoring tool messages.
+type LSPRefactorToolRenderContext struct{}
+
+// RenderTool implements the [ToolRenderer] interface.
+func (r *LSPRefactorToolRenderContext) RenderTool(sty *styles.Styles, width int, opts *ToolRenderOpts) string {
+	cappedWidth := cappedMessageWidth(width)
+
+	var params tools.RenameParams
+	_ = json.Unmarshal([]byte(opts.ToolCall.Input), &params)
+	name := "Rename"
+	toolParams := []string{fmt.Sprintf("%s → %s", params.Symbol, params.NewName), "file", fsext.PrettyPath(params.FilePath)}
+
+	if opts.IsPending() {
+		return pendingTool(sty, name, opts.Anim)
+	}
+
+	header := toolHeader(sty, opts.Status, name, cappedWidth, opts.Compact, toolParams...)
+	if opts.Compact {
+		return header
+	}
+
+	if earlyState, ok := toolEarlyStateContent(sty, opts, cappedWidth); ok {
+		return joinToolParts(header, earlyState)
+	}
+
+	if opts.HasEmptyResult() {
+		return header
+	}
+
+	bodyWidth := cappedWidth - toolBodyLeftPaddingTotal
+	body := sty.Tool.Body.Render(toolOutputPlainContent(sty, opts.Result.Content, bodyWidth, opts.ExpandedContent))
+	return joinToolParts(header, body)
+}
@@ -5,14 +5,21 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/charmbracelet/crush/internal/agent/tools"
+	"github.com/charmbracelet/crush/internal/agent/tools/mcp"
 	"github.com/charmbracelet/crush/internal/message"
 	"github.com/charmbracelet/crush/internal/stringext"
 	"github.com/charmbracelet/crush/internal/ui/styles"
+	"github.com/charmbracelet/x/ansi"
 )
 
-// MCPToolMessageItem is a message item that represents a bash tool call.
+// MCPToolMessageItem is a message item that represents an MCP tool call.
 type MCPToolMessageItem struct {
 	*baseToolMessageItem
+
+	// progress is the latest progress reported by the MCP server while the
+	// tool is running.
+	progress *mcp.ToolProgress
 }
 
 var _ ToolMessageItem = (*MCPToolMessageItem)(nil)
@@ -23,12 +30,22 @@ func NewMCPToolMessageItem(
 	toolCall message.ToolCall,
 	result *message.ToolResult,
 	canceled bool,
-) ToolMessageItem {
-	return newBaseToolMessageItem(sty, toolCall, result, &MCPToolRenderContext{}, canceled)
+) *MCPToolMessageItem {
+	t := &MCPToolMessageItem{}
+	t.baseToolMessageItem = newBaseToolMessageItem(sty, toolCall, result, &MCPToolRenderContext{mcp: t}, canceled)
+	return t
+}
+
+// SetProgress sets the latest progress reported for the tool call.
+func (t *MCPToolMessageItem) SetProgress(progress mcp.ToolProgress) {
+	t.progress = &progress
+	t.clearCache()
 }
 
-// MCPToolRenderContext renders bash tool messages.
-type MCPToolRenderContext struct{}
+// MCPToolRenderContext renders MCP tool messages.
+type MCPToolRenderContext struct {
+	mcp *MCPToolMessageItem
+}
 
 // RenderTool implements the [ToolRenderer] interface.
 func (b *MCPToolRenderContext) RenderTool(sty *styles.Styles, width int, opts *ToolRenderOpts) string {
@@ -65,6 +82,10 @@ func (b *MCPToolRenderContext) RenderTool(sty *styles.Styles, width int, opts *T
 		return header
 	}
 
+	if opts.Status == ToolStatusRunning && !opts.HasResult() && b.mcp != nil && b.mcp.progress != nil {
+		return joinToolParts(header, mcpProgressContent(sty, *b.mcp.progress, cappedWidth-toolBodyLeftPaddingTotal))
+	}
+
 	if earlyState, ok := toolEarlyStateContent(sty, opts, cappedWidth); ok {
 		return joinToolParts(header, earlyState)
 	}
@@ -74,24 +95,54 @@ func (b *MCPToolRenderContext) RenderTool(sty *styles.Styles, width int, opts *T
 	}
 
 	bodyWidth := cappedWidth - toolBodyLeftPaddingTotal
+	content := opts.Result.Content
+	// prefer the structured content, it is what the tool's output schema
+	// describes.
+	var meta tools.MCPResponseMetadata
+	if err := json.Unmarshal([]byte(opts.Result.Metadata), &meta); err == nil && meta.Structured != nil {
+		if structured, err := json.Marshal(meta.Structured); err == nil {
+			content = string(structured)
+		}
+	}
+
 	// see if the result is json
 	var result json.RawMessage
 	var body string
-	if err := json.Unmarshal([]byte(opts.Result.Content), &result); err == nil {
+	if err := json.Unmarshal([]byte(content), &result); err == nil {
 		prettyResult, err := json.MarshalIndent(result, "", "  ")
 		if err == nil {
 			body = sty.Tool.Body.Render(toolOutputCodeContent(sty, "result.json", string(prettyResult), 0, bodyWidth, opts.ExpandedContent))
 		} else {
-			body = sty.Tool.Body.Render(toolOutputPlainContent(sty, opts.Result.Content, bodyWidth, opts.ExpandedContent))
+			body = sty.Tool.Body.Render(toolOutputPlainContent(sty, content, bodyWidth, opts.ExpandedContent))
 		}
-	} else if looksLikeMarkdown(opts.Result.Content) {
-		body = sty.Tool.Body.Render(toolOutputCodeContent(sty, "result.md", opts.Result.Content, 0, bodyWidth, opts.ExpandedContent))
+	} else if looksLikeMarkdown(content) {
+		body = sty.Tool.Body.Render(toolOutputCodeContent(sty, "result.md", content, 0, bodyWidth, opts.ExpandedContent))
 	} else {
-		body = sty.Tool.Body.Render(toolOutputPlainContent(sty, opts.Result.Content, bodyWidth, opts.ExpandedContent))
+		body = sty.Tool.Body.Render(toolOutputPlainContent(sty, content, bodyWidth, opts.ExpandedContent))
 	}
 	return joinToolParts(header, body)
 }
 
+// mcpProgressBarWidth is the width of the progress bar of running MCP tools.
+const mcpProgressBarWidth = 20
+
+// mcpProgressContent renders the progress reported by an MCP server.
+func mcpProgressContent(sty *styles.Styles, progress mcp.ToolProgress, width int) string {
+	var parts []string
+	if percent := progress.Percent(); percent >= 0 {
+		filled := int(percent / 100 * mcpProgressBarWidth)
+		bar := sty.Tool.ProgressFilled.Render(strings.Repeat("█", filled)) +
+			sty.Tool.ProgressEmpty.Render(strings.Repeat("░", mcpProgressBarWidth-filled))
+		parts = append(parts, bar, sty.Tool.StateWaiting.Render(fmt.Sprintf("%3.0f%%", percent)))
+	} else {
+		parts = append(parts, sty.Tool.StateWaiting.Render(fmt.Sprintf("%g", progress.Progress)))
+	}
+	if progress.Message != "" {
+		parts = append(parts, sty.Tool.StateWaiting.Render(progress.Message))
+	}
+	return ansi.Truncate(strings.Join(parts, " "), width, "…")
+}
+
 func prettyName(name string) string {
 	name = strings.ReplaceAll(name, "_", " ")
 	name = strings.ReplaceAll(name, "-", " ")
@@ -2,6 +2,7 @@ package chat
 
 import (
 	"encoding/json"
+	"fmt"
 
 	"github.com/charmbracelet/crush/internal/agent/tools"
 	"github.com/charmbracelet/crush/internal/fsext"
@@ -111,9 +112,30 @@ func (g *GrepToolRenderContext) RenderTool(sty *styles.Styles, width int, opts *
 	if params.Include != "" {
 		toolParams = append(toolParams, "include", params.Include)
 	}
+	if params.Type != "" {
+		toolParams = append(toolParams, "type", params.Type)
+	}
+	if params.OutputMode != "" {
+		toolParams = append(toolParams, "mode", params.OutputMode)
+	}
+	if params.ContextBefore > 0 {
+		toolParams = append(toolParams, "before", fmt.Sprintf("%d", params.ContextBefore))
+	}
+	if params.ContextAfter > 0 {
+		toolParams = append(toolParams, "after", fmt.Sprintf("%d", params.ContextAfter))
+	}
+	if params.Offset > 0 {
+		toolParams = append(toolParams, "offset", fmt.Sprintf("%d", params.Offset))
+	}
 	if params.LiteralText {
 		toolParams = append(toolParams, "literal", "true")
 	}
+	if params.CaseInsensitive {
+		toolParams = append(toolParams, "ignore case", "true")
+	}
+	if params.Multiline {
+		toolParams = append(toolParams, "multiline", "true")
+	}
 
 	header := toolHeader(sty, opts.Status, "Grep", cappedWidth, opts.Compact, toolParams...)
 	if opts.Compact {
@@ -215,6 +215,10 @@ func NewToolMessageItem(
 		item = NewJobOutputToolMessageItem(sty, toolCall, result, canceled)
 	case tools.JobKillToolName:
 		item = NewJobKillToolMessageItem(sty, toolCall, result, canceled)
+	case tools.JobWaitToolName:
+		item = NewJobWaitToolMessageItem(sty, toolCall, result, canceled)
+	case tools.GitStatusToolName, tools.GitDiffToolName, tools.GitLogToolName, tools.GitBlameToolName, tools.GitCommitToolName:
+		item = NewGitToolMessageItem(sty, toolCall, result, canceled)
 	case tools.ViewToolName:
 		item = NewViewToolMessageItem(sty, toolCall, result, canceled)
 	case tools.WriteToolName:
@@ -223,6 +227,10 @@ func NewToolMessageItem(
 		item = NewEditToolMessageItem(sty, toolCall, result, canceled)
 	case tools.MultiEditToolName:
 		item = NewMultiEditToolMessageItem(sty, toolCall, result, canceled)
+	case tools.ApplyPatchToolName:
+		item = NewApplyPatchToolMessageItem(sty, toolCall, result, canceled)
+	case tools.NotebookEditToolName:
+		item = NewNotebookEditToolMessageItem(sty, toolCall, result, canceled)
 	case tools.GlobToolName:
 		item = NewGlobToolMessageItem(sty, toolCall, result, canceled)
 	case tools.GrepToolName:
@@ -249,6 +257,10 @@ func NewToolMessageItem(
 		item = NewTodosToolMessageItem(sty, toolCall, result, canceled)
 	case tools.ReferencesToolName:
 		item = NewReferencesToolMessageItem(sty, toolCall, result, canceled)
+	case tools.HoverToolName, tools.SymbolsToolName:
+		item = NewLSPNavigationToolMessageItem(sty, toolCall, result, canceled)
+	case tools.RenameToolName:
+		item = NewLSPRefactorToolMessageItem(sty, toolCall, result, canceled)
 	case tools.LSPRestartToolName:
 		item = NewLSPRestartToolMessageItem(sty, toolCall, result, canceled)
 	default:
@@ -873,6 +885,9 @@ func (t *baseToolMessageItem) formatParametersForCopy() string {
 			if params.Offset > 0 {
 				parts = append(parts, fmt.Sprintf("**Offset:** %d", params.Offset))
 			}
+			if params.Pages != "" {
+				parts = append(parts, fmt.Sprintf("**Pages:** %s", params.Pages))
+			}
 			return strings.Join(parts, "\n")
 		}
 	case tools.EditToolName:
@@ -888,6 +903,11 @@ func (t *baseToolMessageItem) formatParametersForCopy() string {
 			parts = append(parts, fmt.Sprintf("**Edits:** %d", len(params.Edits)))
 			return strings.Join(parts, "\n")
 		}
+	case tools.NotebookEditToolName:
+		var params tools.NotebookEditParams
+		if json.Unmarshal([]byte(t.toolCall.Input), &params) == nil {
+			return fmt.Sprintf("**File:** %s", fsext.PrettyPath(params.FilePath))
+		}
 	case tools.WriteToolName:
 		var params tools.WriteParams
 		if json.Unmarshal([]byte(t.toolCall.Input), &params) == nil {
@@ -904,6 +924,15 @@ func (t *baseToolMessageItem) formatParametersForCopy() string {
 			if params.Timeout > 0 {
 				parts = append(parts, fmt.Sprintf("**Timeout:** %ds", params.Timeout))
 			}
+			if params.Section != "" {
+				parts = append(parts, fmt.Sprintf("**Section:** %s", params.Section))
+			}
+			if params.Selector != "" {
+				parts = append(parts, fmt.Sprintf("**Selector:** %s", params.Selector))
+			}
+			if params.Page > 0 {
+				parts = append(parts, fmt.Sprintf("**Page:** %d", params.Page))
+			}
 			return strings.Join(parts, "\n")
 		}
 	case tools.AgenticFetchToolName:
@@ -934,9 +963,21 @@ func (t *baseToolMessageItem) formatParametersForCopy() string {
 			if params.Include != "" {
 				parts = append(parts, fmt.Sprintf("**Include:** %s", params.Include))
 			}
+			if params.Type != "" {
+				parts = append(parts, fmt.Sprintf("**Type:** %s", params.Type))
+			}
+			if params.OutputMode != "" {
+				parts = append(parts, fmt.Sprintf("**Output Mode:** %s", params.OutputMode))
+			}
 			if params.LiteralText {
 				parts = append(parts, "**Literal:** true")
 			}
+			if params.CaseInsensitive {
+				parts = append(parts, "**Case Insensitive:** true")
+			}
+			if params.Multiline {
+				parts = append(parts, "**Multiline:** true")
+			}
 			return strings.Join(parts, "\n")
 		}
 	case tools.GlobToolName:
@@ -1039,7 +1080,8 @@ func (t *baseToolMessageItem) formatResultForCopy() string {
 		return t.formatWebFetchResultForCopy()
 	case agent.AgentToolName:
 		return t.formatAgentResultForCopy()
-	case tools.DownloadToolName, tools.GrepToolName, tools.GlobToolName, tools.LSToolName, tools.SourcegraphToolName, tools.DiagnosticsToolName, tools.TodosToolName:
+	case tools.DownloadToolName, tools.GrepToolName, tools.GlobToolName, tools.LSToolName, tools.SourcegraphToolName, tools.SymbolsToolName, tools.DiagnosticsToolName, tools.TodosToolName,
+		tools.GitStatusToolName, tools.GitDiffToolName, tools.GitLogToolName, tools.GitBlameToolName, tools.GitCommitToolName:
 		return fmt.Sprintf("```\n%s\n```", t.result.Content)
 	default:
 		return t.result.Content
@@ -1284,6 +1326,15 @@ func (t *baseToolMessageItem) formatFetchResultForCopy() string {
 	if params.Timeout > 0 {
 		result.WriteString(fmt.Sprintf("Timeout: %ds\n", params.Timeout))
 	}
+	if params.Section != "" {
+		result.WriteString(fmt.Sprintf("Section: %s\n", params.Section))
+	}
+	if params.Selector != "" {
+		result.WriteString(fmt.Sprintf("Selector: %s\n", params.Selector))
+	}
+	if params.Page > 0 {
+		result.WriteString(fmt.Sprintf("Page: %d\n", params.Page))
+	}
 	result.WriteString("\n")
 
 	result.WriteString(t.result.Content)
@@ -1363,12 +1414,30 @@ func prettifyToolName(name string) string {
 		return "Job: Output"
 	case tools.JobKillToolName:
 		return "Job: Kill"
+	case tools.JobWaitToolName:
+		return "Job: Wait"
+	case tools.JobListToolName:
+		return "Job: List"
+	case tools.GitStatusToolName:
+		return "Git: Status"
+	case tools.GitDiffToolName:
+		return "Git: Diff"
+	case tools.GitLogToolName:
+		return "Git: Log"
+	case tools.GitBlameToolName:
+		return "Git: Blame"
+	case tools.GitCommitToolName:
+		return "Git: Commit"
 	case tools.DownloadToolName:
 		return "Download"
 	case tools.EditToolName:
 		return "Edit"
 	case tools.MultiEditToolName:
 		return "Multi-Edit"
+	case tools.ApplyPatchToolName:
+		return "Apply Patch"
+	case tools.NotebookEditToolName:
+		return "Notebook Edit"
 	case tools.FetchToolName:
 		return "Fetch"
 	case tools.AgenticFetchToolName:
@@ -1385,6 +1454,8 @@ func prettifyToolName(name string) string {
 		return "List"
 	case tools.SourcegraphToolName:
 		return "Sourcegraph"
+	case tools.SymbolsToolName:
+		return "Symbols"
 	case tools.TodosToolName:
 		return "To-Do"
 	case tools.ViewToolName:
@@ -1,12 +1,15 @@
 package completions
 
 import (
-	"slices"
+	"context"
+	"fmt"
+	"log/slog"
 	"strings"
 
 	"charm.land/bubbles/v2/key"
 	tea "charm.land/bubbletea/v2"
 	"charm.land/lipgloss/v2"
+	"github.com/charmbracelet/crush/internal/codeindex"
 	"github.com/charmbracelet/crush/internal/fsext"
 	"github.com/charmbracelet/crush/internal/ui/list"
 	"github.com/charmbracelet/x/ansi"
@@ -31,7 +34,20 @@ type ClosedMsg struct{}
 
 // FilesLoadedMsg is sent when files have been loaded for completions.
 type FilesLoadedMsg struct {
-	Files []string
+	Files   []string
+	Symbols []codeindex.Symbol
+}
+
+// symbolKinds are the kinds of symbols offered as completions.
+var symbolKinds = []string{
+	codeindex.KindFunction,
+	codeindex.KindMethod,
+	codeindex.KindType,
+	codeindex.KindStruct,
+	codeindex.KindInterface,
+	codeindex.KindClass,
+	codeindex.KindEnum,
+	codeindex.KindTrait,
 }
 
 // Completions represents the completions popup component.
@@ -92,18 +108,31 @@ func (c *Completions) KeyMap() KeyMap {
 	return c.keyMap
 }
 
-// OpenWithFiles opens the completions with file items from the filesystem.
-func (c *Completions) OpenWithFiles(depth, limit int) tea.Cmd {
+// OpenWithFiles opens the completions with file items from the filesystem,
+// including the ones in the additional project directories, and symbol items
+// from the code index, if any, refreshed first if files changed.
+func (c *Completions) OpenWithFiles(additionalDirs []string, depth, limit int, index *codeindex.Index) tea.Cmd {
 	return func() tea.Msg {
-		files, _, _ := fsext.ListDirectory(".", nil, depth, limit)
-		slices.Sort(files)
-		return FilesLoadedMsg{Files: files}
+		if index != nil {
+			if err := index.Refresh(context.Background()); err != nil {
+				slog.Warn("Failed to update the code index", "error", err)
+			}
+		}
+		symbols := index.Symbols(symbolKinds...)
+		if limit > 0 && len(symbols) > limit {
+			symbols = symbols[:limit]
+		}
+		return FilesLoadedMsg{
+			Files:   fsext.ListWorkspace(additionalDirs, depth, limit),
+			Symbols: symbols,
+		}
 	}
 }
 
-// SetFiles sets the file items on the completions popup.
-func (c *Completions) SetFiles(files []string) {
-	items := make([]list.FilterableItem, 0, len(files))
+// SetFiles sets the file and symbol items on the completions popup.
+func (c *Completions) SetFiles(files []string, symbols []codeindex.Symbol) {
+	items := make([]list.FilterableItem, 0, len(files)+len(symbols))
+	texts := make([]string, 0, len(files)+len(symbols))
 	for _, file := range files {
 		file = strings.TrimPrefix(file, "./")
 		item := NewCompletionItem(
@@ -114,6 +143,19 @@ func (c *Completions) SetFiles(files []string) {
 			c.matchStyle,
 		)
 		items = append(items, item)
+		texts = append(texts, file)
+	}
+	for _, symbol := range symbols {
+		text := SymbolText(symbol)
+		item := NewCompletionItem(
+			text,
+			SymbolCompletionValue{Symbol: symbol},
+			c.normalStyle,
+			c.focusedStyle,
+			c.matchStyle,
+		)
+		items = append(items, item)
+		texts = append(texts, text)
 	}
 
 	c.open = true
@@ -132,14 +174,20 @@ func (c *Completions) SetFiles(files []string) {
 	start, end := c.list.VisibleItemIndices()
 	width := 0
 	if end != 0 {
-		for _, file := range files[start : end+1] {
-			width = max(width, ansi.StringWidth(file))
+		for _, text := range texts[start : end+1] {
+			width = max(width, ansi.StringWidth(text))
 		}
 	}
 	c.width = ordered.Clamp(width+2, int(minWidth), int(maxWidth))
 	c.list.SetSize(c.width, c.height)
 }
 
+// SymbolText returns how a symbol is shown in the completions and inserted
+// in the prompt, e.g. "Client.Run (internal/client.go:42)".
+func SymbolText(symbol codeindex.Symbol) string {
+	return fmt.Sprintf("%s (%s:%d)", symbol.QualifiedName(), symbol.Path, symbol.Line)
+}
+
 // Close closes the completions popup.
 func (c *Completions) Close() {
 	c.open = false
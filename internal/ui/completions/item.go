@@ -2,6 +2,7 @@ package completions
 
 import (
 	"charm.land/lipgloss/v2"
+	"github.com/charmbracelet/crush/internal/codeindex"
 	"github.com/charmbracelet/crush/internal/ui/list"
 	"github.com/charmbracelet/x/ansi"
 	"github.com/rivo/uniseg"
@@ -13,6 +14,12 @@ type FileCompletionValue struct {
 	Path string
 }
 
+// SymbolCompletionValue represents a symbol completion value, from the code
+// index.
+type SymbolCompletionValue struct {
+	Symbol codeindex.Symbol
+}
+
 // CompletionItem represents an item in the completions list.
 type CompletionItem struct {
 	text    string
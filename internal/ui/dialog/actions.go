@@ -68,6 +68,11 @@ type (
 		Arguments []commands.Argument
 		Args      map[string]string // Actual argument values
 	}
+	// ActionAddDirectory is a message to add a project directory to the
+	// workspace.
+	ActionAddDirectory struct {
+		Path string
+	}
 	// ActionRunMCPPrompt is a message to run a custom command.
 	ActionRunMCPPrompt struct {
 		Title       string
@@ -442,10 +442,17 @@ func (c *Commands) defaultCommands() []*CommandItem {
 		commands = append(commands, NewCommandItem(c.com.Styles, "open_external_editor", "Open External Editor", "ctrl+o", ActionExternalEditor{}))
 	}
 
+	if len(cfg.MCP) > 0 {
+		commands = append(commands, NewCommandItem(c.com.Styles, "mcp_logs", "View MCP Logs", "", ActionOpenDialog{MCPLogsID}))
+	}
+
+	commands = append(commands, NewCommandItem(c.com.Styles, "jobs", "View Background Jobs", "", ActionOpenDialog{JobsID}))
+
 	return append(commands,
 		NewCommandItem(c.com.Styles, "toggle_yolo", "Toggle Yolo Mode", "", ActionToggleYoloMode{}),
 		NewCommandItem(c.com.Styles, "toggle_help", "Toggle Help", "ctrl+g", ActionToggleHelp{}),
 		NewCommandItem(c.com.Styles, "init", "Initialize Project", "", ActionInitializeProject{}),
+		NewCommandItem(c.com.Styles, "add_dir", "Add Directory", "", ActionAddDirectory{}),
 		NewCommandItem(c.com.Styles, "quit", "Quit", "ctrl+c", tea.QuitMsg{}),
 	)
 }
@@ -0,0 +1,399 @@
+package dialog
+
+import (
+	"cmp"
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"charm.land/bubbles/v2/help"
+	"charm.land/bubbles/v2/key"
+	"charm.land/bubbles/v2/viewport"
+	tea "charm.land/bubbletea/v2"
+	"charm.land/lipgloss/v2"
+	"github.com/charmbracelet/crush/internal/shell"
+	"github.com/charmbracelet/crush/internal/ui/common"
+	"github.com/charmbracelet/crush/internal/uiutil"
+	uv "github.com/charmbracelet/ultraviolet"
+	"github.com/charmbracelet/x/ansi"
+)
+
+const (
+	// JobsID is the identifier for the background jobs dialog.
+	JobsID = "jobs"
+
+	jobsDialogMaxWidth  = 140
+	jobsDialogMaxHeight = 40
+	// jobsListMaxHeight is the maximum number of jobs listed at once.
+	jobsListMaxHeight = 6
+	// jobsOutputMaxLines is the number of output lines kept in the tail.
+	jobsOutputMaxLines = 1000
+	// jobsRefreshInterval is how often the jobs and their output are
+	// refreshed while the dialog is open.
+	jobsRefreshInterval = 500 * time.Millisecond
+)
+
+// JobsTickMsg is sent periodically while the jobs dialog is open to refresh
+// it. Generation tells the dialogs apart, so the ticks of a dialog closed and
+// opened again don't keep running along with the ones of the new dialog.
+type JobsTickMsg struct {
+	Generation uint64
+}
+
+// jobsGeneration is the generation of the last jobs dialog created.
+var jobsGeneration atomic.Uint64
+
+// Jobs represents a dialog that lists the background shells started by the
+// agent, and tails the output of the selected one.
+type Jobs struct {
+	com      *common.Common
+	help     help.Model
+	viewport viewport.Model
+
+	jobs     []*shell.BackgroundShell
+	selected string
+
+	// generation identifies the ticks of this dialog.
+	generation uint64
+
+	// outputLen and outputDone describe the output last rendered, to only
+	// re-render it when it changed.
+	outputLen  int
+	outputDone bool
+	// dirty is true when the viewport content needs to be re-rendered.
+	dirty bool
+	// follow keeps the viewport scrolled to the latest output.
+	follow bool
+
+	keyMap struct {
+		Next,
+		Previous,
+		ScrollUp,
+		ScrollDown,
+		Kill,
+		Close key.Binding
+	}
+}
+
+var _ Dialog = (*Jobs)(nil)
+
+// NewJobs creates a new background jobs dialog.
+func NewJobs(com *common.Common) *Jobs {
+	d := &Jobs{
+		com:        com,
+		dirty:      true,
+		follow:     true,
+		generation: jobsGeneration.Add(1),
+	}
+
+	h := help.New()
+	h.Styles = com.Styles.DialogHelpStyles()
+	d.help = h
+
+	d.keyMap.Next = key.NewBinding(
+		key.WithKeys("tab", "right"),
+		key.WithHelp("tab", "next job"),
+	)
+	d.keyMap.Previous = key.NewBinding(
+		key.WithKeys("shift+tab", "left"),
+		key.WithHelp("shift+tab", "previous job"),
+	)
+	d.keyMap.ScrollUp = key.NewBinding(
+		key.WithKeys("up", "k", "pgup"),
+		key.WithHelp("↑", "scroll up"),
+	)
+	d.keyMap.ScrollDown = key.NewBinding(
+		key.WithKeys("down", "j", "pgdown"),
+		key.WithHelp("↓", "scroll down"),
+	)
+	d.keyMap.Kill = key.NewBinding(
+		key.WithKeys("ctrl+x"),
+		key.WithHelp("ctrl+x", "kill"),
+	)
+	d.keyMap.Close = CloseKey
+
+	vp := viewport.New()
+	vp.KeyMap = viewport.KeyMap{
+		Up:       key.NewBinding(key.WithKeys("up", "k")),
+		Down:     key.NewBinding(key.WithKeys("down", "j")),
+		PageUp:   key.NewBinding(key.WithKeys("pgup")),
+		PageDown: key.NewBinding(key.WithKeys("pgdown")),
+		// Disable other viewport keys to avoid conflicts with dialog shortcuts.
+		Left:         key.NewBinding(key.WithDisabled()),
+		Right:        key.NewBinding(key.WithDisabled()),
+		HalfPageUp:   key.NewBinding(key.WithDisabled()),
+		HalfPageDown: key.NewBinding(key.WithDisabled()),
+	}
+	d.viewport = vp
+
+	d.Refresh()
+	return d
+}
+
+// Tick returns a command sending the next [JobsTickMsg] of the dialog.
+func (d *Jobs) Tick() tea.Cmd {
+	generation := d.generation
+	return tea.Tick(jobsRefreshInterval, func(time.Time) tea.Msg {
+		return JobsTickMsg{Generation: generation}
+	})
+}
+
+// HandleTick refreshes the dialog and schedules the next tick, unless the
+// tick belongs to another dialog.
+func (d *Jobs) HandleTick(msg JobsTickMsg) tea.Cmd {
+	if msg.Generation != d.generation {
+		return nil
+	}
+	d.Refresh()
+	return d.Tick()
+}
+
+// ID implements [Dialog].
+func (*Jobs) ID() string {
+	return JobsID
+}
+
+// Refresh reloads the jobs, and marks the output of the selected one for
+// re-rendering if it changed.
+func (d *Jobs) Refresh() {
+	d.jobs = shell.GetBackgroundShellManager().Shells()
+	job := d.selectedJob()
+	if job == nil {
+		// Select the most recent job when the selected one is gone.
+		if len(d.jobs) > 0 {
+			d.selectJob(len(d.jobs) - 1)
+		} else {
+			d.selected = ""
+			d.dirty = true
+		}
+		return
+	}
+	if done := job.IsDone(); done != d.outputDone {
+		d.dirty = true
+	}
+	if _, _, length := job.ReadOutput(0); length != d.outputLen {
+		d.dirty = true
+	}
+}
+
+// HandleMsg implements [Dialog].
+func (d *Jobs) HandleMsg(msg tea.Msg) Action {
+	switch msg := msg.(type) {
+	case tea.KeyPressMsg:
+		switch {
+		case key.Matches(msg, d.keyMap.Close):
+			return ActionClose{}
+		case key.Matches(msg, d.keyMap.Next):
+			if len(d.jobs) > 0 {
+				d.selectJob((d.selectedIndex() + 1) % len(d.jobs))
+			}
+		case key.Matches(msg, d.keyMap.Previous):
+			if len(d.jobs) > 0 {
+				d.selectJob((d.selectedIndex() + len(d.jobs) - 1) % len(d.jobs))
+			}
+		case key.Matches(msg, d.keyMap.Kill):
+			if job := d.selectedJob(); job != nil {
+				return ActionCmd{d.killJob(job)}
+			}
+		case key.Matches(msg, d.keyMap.ScrollUp, d.keyMap.ScrollDown):
+			d.viewport, _ = d.viewport.Update(msg)
+			d.follow = d.viewport.AtBottom()
+		}
+	case tea.MouseWheelMsg:
+		d.viewport, _ = d.viewport.Update(msg)
+		d.follow = d.viewport.AtBottom()
+	}
+	return nil
+}
+
+// killJob returns a command terminating job and reporting the outcome.
+func (d *Jobs) killJob(job *shell.BackgroundShell) tea.Cmd {
+	return func() tea.Msg {
+		if err := shell.GetBackgroundShellManager().Kill(job.ID); err != nil {
+			return uiutil.NewErrorMsg(err)
+		}
+		return uiutil.NewInfoMsg(fmt.Sprintf("Background job %s terminated", job.ID))
+	}
+}
+
+// Draw implements [Dialog].
+func (d *Jobs) Draw(scr uv.Screen, area uv.Rectangle) *tea.Cursor {
+	t := d.com.Styles
+	width := max(0, min(jobsDialogMaxWidth, area.Dx()))
+	height := max(0, min(jobsDialogMaxHeight, area.Dy()))
+	innerWidth := width - t.Dialog.View.GetHorizontalFrameSize()
+
+	list := d.renderList(innerWidth)
+	status := d.renderStatus(innerWidth)
+	parts := 2
+	if status != "" {
+		parts++
+	}
+	heightOffset := t.Dialog.Title.GetVerticalFrameSize() + titleContentHeight +
+		t.Dialog.HelpView.GetVerticalFrameSize() +
+		t.Dialog.View.GetVerticalFrameSize() +
+		lipgloss.Height(list) + lipgloss.Height(status) +
+		parts + 1 // gaps around the parts
+
+	// Reserve space for the scrollbar.
+	contentWidth := max(0, innerWidth-t.Dialog.ContentPanel.GetHorizontalFrameSize()-1)
+	contentHeight := max(0, height-heightOffset-t.Dialog.ContentPanel.GetVerticalFrameSize())
+	if d.viewport.Width() != contentWidth {
+		d.dirty = true
+	}
+	d.viewport.SetWidth(contentWidth)
+	d.viewport.SetHeight(contentHeight)
+	if d.dirty {
+		d.viewport.SetContent(d.renderOutput(contentWidth))
+		d.dirty = false
+	}
+	if d.follow {
+		d.viewport.GotoBottom()
+	}
+
+	content := d.viewport.View()
+	if scrollbar := common.Scrollbar(t, contentHeight, d.viewport.TotalLineCount(), contentHeight, d.viewport.YOffset()); scrollbar != "" {
+		content = lipgloss.JoinHorizontal(lipgloss.Top, content, scrollbar)
+	}
+
+	d.help.SetWidth(innerWidth)
+	rc := NewRenderContext(t, width)
+	rc.Gap = 1
+	rc.Title = "Background Jobs"
+	if len(d.jobs) > 0 {
+		rc.TitleInfo = t.Dialog.TitleAccent.Render(fmt.Sprintf("%d", len(d.jobs))) + " "
+	}
+	rc.AddPart(list)
+	if status != "" {
+		rc.AddPart(status)
+	}
+	rc.AddPart(t.Dialog.ContentPanel.Width(innerWidth).Render(content))
+	rc.Help = d.help.View(d)
+
+	DrawCenter(scr, area, rc.Render())
+	return nil
+}
+
+func (d *Jobs) selectedIndex() int {
+	for i, job := range d.jobs {
+		if job.ID == d.selected {
+			return i
+		}
+	}
+	return -1
+}
+
+func (d *Jobs) selectedJob() *shell.BackgroundShell {
+	if i := d.selectedIndex(); i >= 0 {
+		return d.jobs[i]
+	}
+	return nil
+}
+
+func (d *Jobs) selectJob(i int) {
+	d.selected = d.jobs[i].ID
+	d.follow = true
+	d.dirty = true
+}
+
+// renderList renders the jobs, scrolled to keep the selected one visible.
+func (d *Jobs) renderList(width int) string {
+	t := d.com.Styles
+	if len(d.jobs) == 0 {
+		return t.Dialog.NormalItem.Render(t.Subtle.Render("No background jobs."))
+	}
+
+	selected := max(d.selectedIndex(), 0)
+	start := max(0, min(selected-jobsListMaxHeight/2, len(d.jobs)-jobsListMaxHeight))
+	end := min(len(d.jobs), start+jobsListMaxHeight)
+	lines := make([]string, 0, end-start)
+	for i := start; i < end; i++ {
+		job := d.jobs[i]
+		icon := t.ItemBusyIcon.String()
+		if job.IsDone() {
+			icon = t.ItemOnlineIcon.String()
+			if shell.ExitCode(job.ExitErr()) != 0 {
+				icon = t.ItemErrorIcon.String()
+			}
+		}
+		label := fmt.Sprintf("%s %s %s", icon, job.ID, strings.ReplaceAll(cmp.Or(job.Description, job.Command), "\n", " "))
+
+		style := t.Dialog.NormalItem
+		if i == selected {
+			style = t.Dialog.SelectedItem
+		}
+		itemWidth := width - style.GetHorizontalFrameSize()
+		lines = append(lines, style.Width(width).Render(ansi.Truncate(label, itemWidth, "…")))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// renderStatus renders the command and the status of the selected job.
+func (d *Jobs) renderStatus(width int) string {
+	t := d.com.Styles
+	job := d.selectedJob()
+	if job == nil {
+		return ""
+	}
+
+	status := fmt.Sprintf("running for %s", time.Since(job.StartedAt).Round(time.Second))
+	if job.IsDone() {
+		status = fmt.Sprintf("exited with code %d", shell.ExitCode(job.ExitErr()))
+	}
+	lines := []string{
+		t.Subtle.Render("Command: ") + strings.ReplaceAll(job.Command, "\n", " "),
+		t.Subtle.Render("Directory: ") + job.WorkingDir,
+		t.Subtle.Render("Status: ") + status,
+	}
+	for i, line := range lines {
+		lines[i] = ansi.Truncate(line, width-t.Dialog.NormalItem.GetHorizontalFrameSize(), "…")
+	}
+	return t.Dialog.NormalItem.Render(strings.Join(lines, "\n"))
+}
+
+// renderOutput renders the tail of the output of the selected job.
+func (d *Jobs) renderOutput(width int) string {
+	t := d.com.Styles
+	job := d.selectedJob()
+	if job == nil {
+		return t.Subtle.Render("Jobs started in the background by the agent show up here.")
+	}
+
+	d.outputDone = job.IsDone()
+	output, _, length := job.ReadOutput(0)
+	d.outputLen = length
+	output = strings.TrimSuffix(output, "\n")
+	if output == "" {
+		return t.Subtle.Render("No output yet.")
+	}
+
+	lines := strings.Split(output, "\n")
+	if len(lines) > jobsOutputMaxLines {
+		lines = lines[len(lines)-jobsOutputMaxLines:]
+	}
+	for i, line := range lines {
+		line = strings.ReplaceAll(ansi.Strip(strings.TrimSuffix(line, "\r")), "\t", "    ")
+		lines[i] = ansi.Truncate(line, width, "…")
+	}
+	return strings.Join(lines, "\n")
+}
+
+// ShortHelp implements [help.KeyMap].
+func (d *Jobs) ShortHelp() []key.Binding {
+	return []key.Binding{
+		d.keyMap.Next,
+		d.keyMap.Kill,
+		d.keyMap.ScrollUp,
+		d.keyMap.ScrollDown,
+		d.keyMap.Close,
+	}
+}
+
+// FullHelp implements [help.KeyMap].
+func (d *Jobs) FullHelp() [][]key.Binding {
+	return [][]key.Binding{
+		{d.keyMap.Next, d.keyMap.Previous, d.keyMap.Kill},
+		{d.keyMap.ScrollUp, d.keyMap.ScrollDown, d.keyMap.Close},
+	}
+}
@@ -0,0 +1,272 @@
+package dialog
+
+import (
+	"fmt"
+	"strings"
+
+	"charm.land/bubbles/v2/help"
+	"charm.land/bubbles/v2/key"
+	"charm.land/bubbles/v2/viewport"
+	tea "charm.land/bubbletea/v2"
+	"charm.land/lipgloss/v2"
+	"github.com/charmbracelet/crush/internal/agent/tools/mcp"
+	"github.com/charmbracelet/crush/internal/ui/common"
+	uv "github.com/charmbracelet/ultraviolet"
+	"github.com/charmbracelet/x/ansi"
+)
+
+const (
+	// MCPLogsID is the identifier for the MCP logs dialog.
+	MCPLogsID = "mcp_logs"
+
+	mcpLogsDialogMaxWidth  = 140
+	mcpLogsDialogMaxHeight = 40
+)
+
+// MCPLogs represents a dialog that shows the logs of the configured MCP
+// servers, one server at a time.
+type MCPLogs struct {
+	com      *common.Common
+	help     help.Model
+	viewport viewport.Model
+
+	names    []string
+	selected int
+
+	// dirty is true when the viewport content needs to be re-rendered.
+	dirty bool
+	// follow keeps the viewport scrolled to the latest entry.
+	follow bool
+
+	keyMap struct {
+		Next,
+		Previous,
+		ScrollUp,
+		ScrollDown,
+		Close key.Binding
+	}
+}
+
+var _ Dialog = (*MCPLogs)(nil)
+
+// NewMCPLogs creates a new MCP logs dialog. If name is not empty, the logs of
+// that server are shown first.
+func NewMCPLogs(com *common.Common, name string) *MCPLogs {
+	d := &MCPLogs{
+		com:    com,
+		dirty:  true,
+		follow: true,
+	}
+
+	h := help.New()
+	h.Styles = com.Styles.DialogHelpStyles()
+	d.help = h
+
+	for i, m := range com.Config().MCP.Sorted() {
+		d.names = append(d.names, m.Name)
+		if m.Name == name {
+			d.selected = i
+		}
+	}
+
+	d.keyMap.Next = key.NewBinding(
+		key.WithKeys("tab", "right"),
+		key.WithHelp("tab", "next server"),
+	)
+	d.keyMap.Previous = key.NewBinding(
+		key.WithKeys("shift+tab", "left"),
+		key.WithHelp("shift+tab", "previous server"),
+	)
+	d.keyMap.ScrollUp = key.NewBinding(
+		key.WithKeys("up", "k", "pgup"),
+		key.WithHelp("↑", "scroll up"),
+	)
+	d.keyMap.ScrollDown = key.NewBinding(
+		key.WithKeys("down", "j", "pgdown"),
+		key.WithHelp("↓", "scroll down"),
+	)
+	d.keyMap.Close = CloseKey
+
+	vp := viewport.New()
+	vp.KeyMap = viewport.KeyMap{
+		Up:       key.NewBinding(key.WithKeys("up", "k")),
+		Down:     key.NewBinding(key.WithKeys("down", "j")),
+		PageUp:   key.NewBinding(key.WithKeys("pgup")),
+		PageDown: key.NewBinding(key.WithKeys("pgdown")),
+		// Disable other viewport keys to avoid conflicts with dialog shortcuts.
+		Left:         key.NewBinding(key.WithDisabled()),
+		Right:        key.NewBinding(key.WithDisabled()),
+		HalfPageUp:   key.NewBinding(key.WithDisabled()),
+		HalfPageDown: key.NewBinding(key.WithDisabled()),
+	}
+	d.viewport = vp
+
+	return d
+}
+
+// ID implements [Dialog].
+func (*MCPLogs) ID() string {
+	return MCPLogsID
+}
+
+// Select shows the logs of the MCP server with the given name, if it's
+// configured.
+func (d *MCPLogs) Select(name string) {
+	for i, n := range d.names {
+		if n == name {
+			d.selected = i
+			d.dirty = true
+			d.follow = true
+			return
+		}
+	}
+}
+
+// Refresh marks the logs as changed so they get re-rendered on the next draw.
+func (d *MCPLogs) Refresh() {
+	d.dirty = true
+}
+
+// HandleMsg implements [Dialog].
+func (d *MCPLogs) HandleMsg(msg tea.Msg) Action {
+	switch msg := msg.(type) {
+	case tea.KeyPressMsg:
+		switch {
+		case key.Matches(msg, d.keyMap.Close):
+			return ActionClose{}
+		case key.Matches(msg, d.keyMap.Next):
+			if len(d.names) > 0 {
+				d.selected = (d.selected + 1) % len(d.names)
+				d.follow = true
+				d.dirty = true
+			}
+		case key.Matches(msg, d.keyMap.Previous):
+			if len(d.names) > 0 {
+				d.selected = (d.selected + len(d.names) - 1) % len(d.names)
+				d.follow = true
+				d.dirty = true
+			}
+		case key.Matches(msg, d.keyMap.ScrollUp, d.keyMap.ScrollDown):
+			d.viewport, _ = d.viewport.Update(msg)
+			d.follow = d.viewport.AtBottom()
+		}
+	case tea.MouseWheelMsg:
+		d.viewport, _ = d.viewport.Update(msg)
+		d.follow = d.viewport.AtBottom()
+	}
+	return nil
+}
+
+// Draw implements [Dialog].
+func (d *MCPLogs) Draw(scr uv.Screen, area uv.Rectangle) *tea.Cursor {
+	t := d.com.Styles
+	width := max(0, min(mcpLogsDialogMaxWidth, area.Dx()))
+	height := max(0, min(mcpLogsDialogMaxHeight, area.Dy()))
+	innerWidth := width - t.Dialog.View.GetHorizontalFrameSize()
+	heightOffset := t.Dialog.Title.GetVerticalFrameSize() + titleContentHeight +
+		t.Dialog.HelpView.GetVerticalFrameSize() +
+		t.Dialog.View.GetVerticalFrameSize() + 2 // gaps around the content
+
+	// Reserve space for the scrollbar.
+	contentWidth := max(0, innerWidth-t.Dialog.ContentPanel.GetHorizontalFrameSize()-1)
+	contentHeight := max(0, height-heightOffset-t.Dialog.ContentPanel.GetVerticalFrameSize())
+	if d.viewport.Width() != contentWidth {
+		d.dirty = true
+	}
+	d.viewport.SetWidth(contentWidth)
+	d.viewport.SetHeight(contentHeight)
+	if d.dirty {
+		d.viewport.SetContent(d.renderLogs(contentWidth))
+		d.dirty = false
+	}
+	if d.follow {
+		d.viewport.GotoBottom()
+	}
+
+	content := d.viewport.View()
+	if scrollbar := common.Scrollbar(t, contentHeight, d.viewport.TotalLineCount(), contentHeight, d.viewport.YOffset()); scrollbar != "" {
+		content = lipgloss.JoinHorizontal(lipgloss.Top, content, scrollbar)
+	}
+
+	d.help.SetWidth(innerWidth)
+	rc := NewRenderContext(t, width)
+	rc.Gap = 1
+	rc.Title = "MCP Logs"
+	if name := d.selectedName(); name != "" {
+		rc.TitleInfo = t.Dialog.TitleAccent.Render(name) + " "
+	}
+	rc.AddPart(t.Dialog.ContentPanel.Width(innerWidth).Render(content))
+	rc.Help = d.help.View(d)
+
+	DrawCenter(scr, area, rc.Render())
+	return nil
+}
+
+func (d *MCPLogs) selectedName() string {
+	if d.selected < 0 || d.selected >= len(d.names) {
+		return ""
+	}
+	return d.names[d.selected]
+}
+
+func (d *MCPLogs) renderLogs(width int) string {
+	t := d.com.Styles
+	name := d.selectedName()
+	if name == "" {
+		return t.Subtle.Render("No MCP servers configured.")
+	}
+	entries := mcp.Logs(name)
+	if len(entries) == 0 {
+		return t.Subtle.Render(fmt.Sprintf("No logs for %s yet.", name))
+	}
+
+	lines := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		var level string
+		switch {
+		case entry.IsError():
+			level = t.Dialog.Logs.Error.Render(strings.ToUpper(entry.Level))
+		case entry.IsWarning():
+			level = t.Dialog.Logs.Warning.Render("WARN")
+		case entry.Level == "debug" || entry.Level == mcp.LogLevelStderr:
+			level = t.Dialog.Logs.Debug.Render(strings.ToUpper(entry.Level))
+		default:
+			level = t.Dialog.Logs.Info.Render(strings.ToUpper(entry.Level))
+		}
+		parts := []string{
+			t.Dialog.Logs.Time.Render(entry.Time.Format("15:04:05")),
+			level,
+		}
+		if entry.Logger != "" {
+			parts = append(parts, t.Dialog.Logs.Source.Render(entry.Logger))
+		}
+		for i, msgLine := range strings.Split(entry.Message, "\n") {
+			msgLine = t.Dialog.Logs.Message.Render(msgLine)
+			if i == 0 {
+				msgLine = strings.Join(append(parts, msgLine), " ")
+			} else {
+				msgLine = "  " + msgLine
+			}
+			lines = append(lines, ansi.Truncate(msgLine, width, "…"))
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// ShortHelp implements [help.KeyMap].
+func (d *MCPLogs) ShortHelp() []key.Binding {
+	return []key.Binding{
+		d.keyMap.Next,
+		d.keyMap.ScrollUp,
+		d.keyMap.ScrollDown,
+		d.keyMap.Close,
+	}
+}
+
+// FullHelp implements [help.KeyMap].
+func (d *MCPLogs) FullHelp() [][]key.Binding {
+	return [][]key.Binding{
+		{d.keyMap.Next, d.keyMap.Previous},
+		{d.keyMap.ScrollUp, d.keyMap.ScrollDown, d.keyMap.Close},
+	}
+}
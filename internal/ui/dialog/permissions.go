@@ -316,7 +316,7 @@ func (p *Permissions) respond(action PermissionAction) tea.Msg {
 
 func (p *Permissions) hasDiffView() bool {
 	switch p.permission.ToolName {
-	case tools.EditToolName, tools.WriteToolName, tools.MultiEditToolName:
+	case tools.EditToolName, tools.WriteToolName, tools.MultiEditToolName, tools.RenameToolName, tools.ApplyPatchToolName, tools.NotebookEditToolName:
 		return true
 	}
 	return false
@@ -481,6 +481,24 @@ func (p *Permissions) renderHeader(contentWidth int) string {
 		if params, ok := p.permission.Params.(tools.LSPermissionsParams); ok {
 			lines = append(lines, p.renderKeyValue("Directory", fsext.PrettyPath(params.Path), contentWidth))
 		}
+	case tools.WebSearchToolName:
+		if params, ok := p.permission.Params.(tools.WebSearchPermissionsParams); ok {
+			lines = append(lines, p.renderKeyValue("Provider", params.Provider, contentWidth))
+		}
+	case tools.GitCommitToolName:
+		if params, ok := p.permission.Params.(tools.GitCommitPermissionsParams); ok {
+			lines = append(lines, p.renderKeyValue("Stage", gitCommitStaging(params), contentWidth))
+		}
+	case tools.NotebookEditToolName:
+		if params, ok := p.permission.Params.(tools.NotebookEditPermissionsParams); ok {
+			lines = append(lines, p.renderKeyValue("File", fsext.PrettyPath(params.FilePath), contentWidth))
+			lines = append(lines, p.renderKeyValue("Desc", params.Description, contentWidth))
+		}
+	case tools.RenameToolName, tools.ApplyPatchToolName:
+		if params, ok := p.permission.Params.(tools.WorkspaceEditPermissionsParams); ok {
+			lines = append(lines, p.renderKeyValue("Desc", params.Description, contentWidth))
+			lines = append(lines, p.renderKeyValue("Files", fmt.Sprintf("%d", len(params.Files)), contentWidth))
+		}
 	}
 
 	return lipgloss.JoinVertical(lipgloss.Left, lines...)
@@ -530,12 +548,20 @@ func (p *Permissions) renderContent(width int) string {
 		return p.renderWriteContent(width)
 	case tools.MultiEditToolName:
 		return p.renderMultiEditContent(width)
+	case tools.RenameToolName, tools.ApplyPatchToolName:
+		return p.renderWorkspaceEditContent(width)
+	case tools.NotebookEditToolName:
+		return p.renderNotebookEditContent(width)
 	case tools.DownloadToolName:
 		return p.renderDownloadContent(width)
 	case tools.FetchToolName:
 		return p.renderFetchContent(width)
 	case tools.AgenticFetchToolName:
 		return p.renderAgenticFetchContent(width)
+	case tools.WebSearchToolName:
+		return p.renderWebSearchContent(width)
+	case tools.GitCommitToolName:
+		return p.renderGitCommitContent(width)
 	case tools.ViewToolName:
 		return p.renderViewContent(width)
 	case tools.LSToolName:
@@ -578,7 +604,32 @@ func (p *Permissions) renderMultiEditContent(contentWidth int) string {
 	return p.renderDiff(params.FilePath, params.OldContent, params.NewContent, contentWidth)
 }
 
+func (p *Permissions) renderNotebookEditContent(contentWidth int) string {
+	params, ok := p.permission.Params.(tools.NotebookEditPermissionsParams)
+	if !ok {
+		return ""
+	}
+	return p.renderDiff(params.FilePath, params.OldContent, params.NewContent, contentWidth)
+}
+
+func (p *Permissions) renderWorkspaceEditContent(contentWidth int) string {
+	params, ok := p.permission.Params.(tools.WorkspaceEditPermissionsParams)
+	if !ok {
+		return ""
+	}
+	return p.renderDiffs(params.Files, contentWidth)
+}
+
 func (p *Permissions) renderDiff(filePath, oldContent, newContent string, contentWidth int) string {
+	return p.renderDiffs([]tools.WorkspaceEditFile{{
+		FilePath:   filePath,
+		OldContent: oldContent,
+		NewContent: newContent,
+	}}, contentWidth)
+}
+
+// renderDiffs renders the diffs of the given files one after the other.
+func (p *Permissions) renderDiffs(files []tools.WorkspaceEditFile, contentWidth int) string {
 	if !p.viewportDirty {
 		if p.isSplitMode() {
 			return p.splitDiffContent
@@ -587,21 +638,26 @@ func (p *Permissions) renderDiff(filePath, oldContent, newContent string, conten
 	}
 
 	isSplitMode := p.isSplitMode()
-	formatter := common.DiffFormatter(p.com.Styles).
-		Before(fsext.PrettyPath(filePath), oldContent).
-		After(fsext.PrettyPath(filePath), newContent).
-		XOffset(p.diffXOffset).
-		Width(contentWidth)
+	diffs := make([]string, 0, len(files))
+	for _, file := range files {
+		formatter := common.DiffFormatter(p.com.Styles).
+			Before(fsext.PrettyPath(file.FilePath), file.OldContent).
+			After(fsext.PrettyPath(file.FilePath), file.NewContent).
+			XOffset(p.diffXOffset).
+			Width(contentWidth)
+		if isSplitMode {
+			formatter = formatter.Split()
+		} else {
+			formatter = formatter.Unified()
+		}
+		diffs = append(diffs, formatter.String())
+	}
 
-	var result string
+	result := strings.Join(diffs, "\n")
 	if isSplitMode {
-		formatter = formatter.Split()
-		p.splitDiffContent = formatter.String()
-		result = p.splitDiffContent
+		p.splitDiffContent = result
 	} else {
-		formatter = formatter.Unified()
-		p.unifiedDiffContent = formatter.String()
-		result = p.unifiedDiffContent
+		p.unifiedDiffContent = result
 	}
 
 	return result
@@ -646,6 +702,39 @@ func (p *Permissions) renderAgenticFetchContent(width int) string {
 	return p.renderContentPanel(content, width)
 }
 
+func (p *Permissions) renderGitCommitContent(width int) string {
+	params, ok := p.permission.Params.(tools.GitCommitPermissionsParams)
+	if !ok {
+		return ""
+	}
+
+	return p.renderContentPanel(params.Message, width)
+}
+
+// gitCommitStaging describes the files a commit stages before committing.
+func gitCommitStaging(params tools.GitCommitPermissionsParams) string {
+	var parts []string
+	if params.All {
+		parts = append(parts, "all tracked files")
+	}
+	for _, file := range params.Files {
+		parts = append(parts, fsext.PrettyPath(file))
+	}
+	if len(parts) == 0 {
+		return "already staged changes only"
+	}
+	return strings.Join(parts, ", ")
+}
+
+func (p *Permissions) renderWebSearchContent(width int) string {
+	params, ok := p.permission.Params.(tools.WebSearchPermissionsParams)
+	if !ok {
+		return ""
+	}
+
+	return p.renderContentPanel(params.Query, width)
+}
+
 func (p *Permissions) renderViewContent(width int) string {
 	params, ok := p.permission.Params.(tools.ViewPermissionsParams)
 	if !ok {
@@ -659,6 +748,9 @@ func (p *Permissions) renderViewContent(width int) string {
 	if params.Limit > 0 && params.Limit != 2000 {
 		content += fmt.Sprintf("\nLines to read: %d", params.Limit)
 	}
+	if params.Pages != "" {
+		content += fmt.Sprintf("\nPages: %s", params.Pages)
+	}
 
 	return p.renderContentPanel(content, width)
 }
@@ -31,11 +31,12 @@ func (m *UI) lspInfo(width, maxItems int, isSection bool) string {
 			continue
 		}
 
-		client, ok := m.com.App.LSPClients.Get(state.Name)
-		if !ok {
-			continue
+		// Clients are only added once ready, but starting ones may report
+		// progress.
+		var counts lsp.DiagnosticCounts
+		if client, ok := m.com.App.LSPClients.Get(state.Name); ok {
+			counts = client.GetDiagnosticCounts()
 		}
-		counts := client.GetDiagnosticCounts()
 		lspErrs := map[protocol.DiagnosticSeverity]int{
 			protocol.SeverityError:       counts.Error,
 			protocol.SeverityWarning:     counts.Warning,
@@ -92,9 +93,16 @@ func lspList(t *styles.Styles, lsps []LSPInfo, width, maxItems int) string {
 		case lsp.StateStarting:
 			icon = t.ItemBusyIcon.String()
 			description = t.Subtle.Render("starting...")
+			if len(l.Progress) > 0 {
+				description = t.Subtle.Render(l.Progress[0].String())
+			}
 		case lsp.StateReady:
 			icon = t.ItemOnlineIcon.String()
 			diagnostics = lspDiagnostics(t, l.Diagnostics)
+			if len(l.Progress) > 0 {
+				icon = t.ItemBusyIcon.String()
+				description = t.Subtle.Render(l.Progress[0].String())
+			}
 		case lsp.StateError:
 			icon = t.ItemErrorIcon.String()
 			description = t.Subtle.Render("error")
@@ -13,15 +13,9 @@ import (
 // mcpInfo renders the MCP status section showing active MCP clients and their
 // tool/prompt counts.
 func (m *UI) mcpInfo(width, maxItems int, isSection bool) string {
-	var mcps []mcp.ClientInfo
+	mcps := m.mcpClients()
 	t := m.com.Styles
 
-	for _, mcp := range m.com.Config().MCP.Sorted() {
-		if state, ok := m.mcpStates[mcp.Name]; ok {
-			mcps = append(mcps, state)
-		}
-	}
-
 	title := t.Subtle.Render("MCPs")
 	if isSection {
 		title = common.Section(t, title, width)
@@ -34,6 +28,18 @@ func (m *UI) mcpInfo(width, maxItems int, isSection bool) string {
 	return lipgloss.NewStyle().Width(width).Render(fmt.Sprintf("%s\n\n%s", title, list))
 }
 
+// mcpClients returns the states of the configured MCP clients, sorted by
+// name.
+func (m *UI) mcpClients() []mcp.ClientInfo {
+	var mcps []mcp.ClientInfo
+	for _, mcp := range m.com.Config().MCP.Sorted() {
+		if state, ok := m.mcpStates[mcp.Name]; ok {
+			mcps = append(mcps, state)
+		}
+	}
+	return mcps
+}
+
 // mcpCounts formats tool and prompt counts for display.
 func mcpCounts(t *styles.Styles, counts mcp.Counts) string {
 	parts := []string{}
@@ -102,6 +102,16 @@ func getDynamicHeightLimits(availableHeight int) (maxFiles, maxLSPs, maxMCPs int
 	return maxFiles, maxLSPs, maxMCPs
 }
 
+// sidebarMCPAt returns the name of the MCP server drawn in the sidebar at
+// the given screen position.
+func (m *UI) sidebarMCPAt(x, y int) (string, bool) {
+	if m.isCompact || !uv.Pos(x, y).In(m.layout.sidebar) {
+		return "", false
+	}
+	name, ok := m.sidebarMCPRows[y]
+	return name, ok
+}
+
 // sidebar renders the chat sidebar containing session title, working
 // directory, model info, file list, LSP status, and MCP status.
 func (m *UI) drawSidebar(scr uv.Screen, area uv.Rectangle) {
@@ -144,6 +154,20 @@ func (m *UI) drawSidebar(scr uv.Screen, area uv.Rectangle) {
 	mcpSection := m.mcpInfo(width, maxMCPs, true)
 	filesSection := m.filesInfo(m.com.Config().WorkingDir(), width, maxFiles, true)
 
+	// Remember where each MCP server is drawn, the list starts after the
+	// title of the section and a blank line.
+	m.sidebarMCPRows = make(map[int]string)
+	mcpListY := area.Min.Y + lipgloss.Height(sidebarHeader) + lipgloss.Height(filesSection) + 1 + lipgloss.Height(lspSection) + 1 + 2
+	mcps := m.mcpClients()
+	if len(mcps) > maxMCPs {
+		mcps = mcps[:max(0, maxMCPs-1)]
+	}
+	for i, info := range mcps {
+		if y := mcpListY + i; y < area.Max.Y {
+			m.sidebarMCPRows[y] = info.Name
+		}
+	}
+
 	uv.NewStyledString(
 		lipgloss.NewStyle().
 			MaxWidth(width).
@@ -179,6 +179,9 @@ type UI struct {
 
 	// sidebarLogo keeps a cached version of the sidebar sidebarLogo.
 	sidebarLogo string
+	// sidebarMCPRows maps the screen rows of the MCP servers listed in the
+	// sidebar to their names, so clicking one opens its logs.
+	sidebarMCPRows map[int]string
 
 	// custom commands & mcp commands
 	customCommands []commands.CustomCommand
@@ -478,6 +481,9 @@ func (m *UI) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		cmds = append(cmds, m.handleFileEvent(msg.Payload))
 	case pubsub.Event[app.LSPEvent]:
 		m.lspStates = app.GetLSPStates()
+		if info, ok := msg.Payload.StatusMsg(); ok {
+			cmds = append(cmds, uiutil.CmdHandler(info))
+		}
 	case pubsub.Event[mcp.Event]:
 		m.mcpStates = mcp.GetStates()
 		// check if all mcps are initialized
@@ -491,6 +497,19 @@ func (m *UI) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		if initialized && m.mcpPrompts == nil {
 			cmds = append(cmds, m.loadMCPrompts())
 		}
+	case pubsub.Event[mcp.LogEntry]:
+		if dia, ok := m.dialog.Dialog(dialog.MCPLogsID).(*dialog.MCPLogs); ok {
+			dia.Refresh()
+		}
+	case dialog.JobsTickMsg:
+		// Keep refreshing the jobs dialog until it is closed.
+		if dia, ok := m.dialog.Dialog(dialog.JobsID).(*dialog.Jobs); ok {
+			cmds = append(cmds, dia.HandleTick(msg))
+		}
+	case pubsub.Event[mcp.ToolProgress]:
+		if item, ok := m.chat.MessageItem(msg.Payload.ToolCallID).(*chat.MCPToolMessageItem); ok {
+			item.SetProgress(msg.Payload)
+		}
 	case pubsub.Event[permission.PermissionRequest]:
 		if cmd := m.openPermissionsDialog(msg.Payload); cmd != nil {
 			cmds = append(cmds, cmd)
@@ -525,6 +544,12 @@ func (m *UI) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 		switch m.state {
 		case uiChat:
+			if name, ok := m.sidebarMCPAt(msg.X, msg.Y); ok {
+				if cmd := m.openMCPLogsDialog(name); cmd != nil {
+					cmds = append(cmds, cmd)
+				}
+				break
+			}
 			x, y := msg.X, msg.Y
 			// Adjust for chat area position
 			x -= m.layout.main.Min.X
@@ -673,7 +698,7 @@ func (m *UI) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case completions.FilesLoadedMsg:
 		// Handle async file loading for completions.
 		if m.completionsOpen {
-			m.completions.SetFiles(msg.Files)
+			m.completions.SetFiles(msg.Files, msg.Symbols)
 		}
 	case uv.KittyGraphicsEvent:
 		if !bytes.HasPrefix(msg.Payload, []byte("OK")) {
@@ -1287,6 +1312,32 @@ func (m *UI) handleDialogMsg(msg tea.Msg) tea.Cmd {
 		}
 		cmds = append(cmds, m.sendMessage(content))
 		m.dialog.CloseFrontDialog()
+	case dialog.ActionAddDirectory:
+		if msg.Path == "" {
+			m.dialog.CloseFrontDialog()
+			argsDialog := dialog.NewArguments(
+				m.com,
+				"Add Directory",
+				"Let the tools and LSPs work in another project directory for this session.",
+				[]commands.Argument{{
+					ID:          "path",
+					Title:       "Path",
+					Description: "Directory to add, relative to the working directory",
+					Required:    true,
+				}},
+				msg, // Pass the action as the result
+			)
+			m.dialog.OpenDialog(argsDialog)
+			break
+		}
+		m.dialog.CloseFrontDialog()
+		cmds = append(cmds, func() tea.Msg {
+			path, err := m.com.App.AddDirectory(context.TODO(), msg.Path)
+			if err != nil {
+				return uiutil.ReportError(err)()
+			}
+			return uiutil.NewInfoMsg("Added directory " + path)
+		})
 	case dialog.ActionRunMCPPrompt:
 		if len(msg.Arguments) > 0 && msg.Args == nil {
 			m.dialog.CloseFrontDialog()
@@ -1446,8 +1497,11 @@ func (m *UI) handleKeyPressMsg(msg tea.KeyPressMsg) tea.Cmd {
 					switch msg := msg.(type) {
 					case completions.SelectionMsg:
 						// Handle file completion selection.
-						if item, ok := msg.Value.(completions.FileCompletionValue); ok {
+						switch item := msg.Value.(type) {
+						case completions.FileCompletionValue:
 							cmds = append(cmds, m.insertFileCompletion(item.Path))
+						case completions.SymbolCompletionValue:
+							m.insertCompletionText(completions.SymbolText(item.Symbol))
 						}
 						if !msg.Insert {
 							m.closeCompletions()
@@ -1558,8 +1612,9 @@ func (m *UI) handleKeyPressMsg(msg tea.KeyPressMsg) tea.Cmd {
 						m.completionsQuery = ""
 						m.completionsStartIndex = curIdx
 						m.completionsPositionStart = m.completionsPosition()
-						depth, limit := m.com.Config().Options.TUI.Completions.Limits()
-						cmds = append(cmds, m.completions.OpenWithFiles(depth, limit))
+						cfg := m.com.Config()
+						depth, limit := cfg.Options.TUI.Completions.Limits()
+						cmds = append(cmds, m.completions.OpenWithFiles(cfg.AdditionalDirectories(), depth, limit, m.com.App.CodeIndex))
 					}
 				}
 
@@ -2393,24 +2448,33 @@ func (m *UI) closeCompletions() {
 	m.completions.Close()
 }
 
-// insertFileCompletion inserts the selected file path into the textarea,
-// replacing the @query, and adds the file as an attachment.
-func (m *UI) insertFileCompletion(path string) tea.Cmd {
+// insertCompletionText replaces the @query in the textarea with the given
+// text. It returns false if the query is no longer there.
+func (m *UI) insertCompletionText(text string) bool {
 	value := m.textarea.Value()
 	word := m.textareaWord()
 
 	// Find the @ and query to replace.
 	if m.completionsStartIndex > len(value) {
-		return nil
+		return false
 	}
 
-	// Build the new value: everything before @, the path, everything after query.
+	// Build the new value: everything before @, the text, everything after query.
 	endIdx := min(m.completionsStartIndex+len(word), len(value))
 
-	newValue := value[:m.completionsStartIndex] + path + value[endIdx:]
+	newValue := value[:m.completionsStartIndex] + text + value[endIdx:]
 	m.textarea.SetValue(newValue)
 	m.textarea.MoveToEnd()
 	m.textarea.InsertRune(' ')
+	return true
+}
+
+// insertFileCompletion inserts the selected file path into the textarea,
+// replacing the @query, and adds the file as an attachment.
+func (m *UI) insertFileCompletion(path string) tea.Cmd {
+	if !m.insertCompletionText(path) {
+		return nil
+	}
 
 	return func() tea.Msg {
 		absPath, _ := filepath.Abs(path)
@@ -2642,6 +2706,14 @@ func (m *UI) openDialog(id string) tea.Cmd {
 		if cmd := m.openQuitDialog(); cmd != nil {
 			cmds = append(cmds, cmd)
 		}
+	case dialog.MCPLogsID:
+		if cmd := m.openMCPLogsDialog(""); cmd != nil {
+			cmds = append(cmds, cmd)
+		}
+	case dialog.JobsID:
+		if cmd := m.openJobsDialog(); cmd != nil {
+			cmds = append(cmds, cmd)
+		}
 	default:
 		// Unknown dialog
 		break
@@ -2662,6 +2734,36 @@ func (m *UI) openQuitDialog() tea.Cmd {
 	return nil
 }
 
+// openMCPLogsDialog opens the MCP logs dialog, showing the logs of the given
+// MCP server first.
+func (m *UI) openMCPLogsDialog(name string) tea.Cmd {
+	if m.dialog.ContainsDialog(dialog.MCPLogsID) {
+		// Bring to front
+		m.dialog.BringToFront(dialog.MCPLogsID)
+		if dia, ok := m.dialog.Dialog(dialog.MCPLogsID).(*dialog.MCPLogs); ok && name != "" {
+			dia.Select(name)
+		}
+		return nil
+	}
+
+	m.dialog.OpenDialog(dialog.NewMCPLogs(m.com, name))
+	return nil
+}
+
+// openJobsDialog opens the background jobs dialog, which refreshes itself
+// while it is open.
+func (m *UI) openJobsDialog() tea.Cmd {
+	if m.dialog.ContainsDialog(dialog.JobsID) {
+		// Bring to front
+		m.dialog.BringToFront(dialog.JobsID)
+		return nil
+	}
+
+	dia := dialog.NewJobs(m.com)
+	m.dialog.OpenDialog(dia)
+	return dia.Tick()
+}
+
 // openModelsDialog opens the models dialog.
 func (m *UI) openModelsDialog() tea.Cmd {
 	if m.dialog.ContainsDialog(dialog.ModelsID) {
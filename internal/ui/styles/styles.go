@@ -277,6 +277,8 @@ type Styles struct {
 		// State message styles
 		StateWaiting   lipgloss.Style // "Waiting for tool response..."
 		StateCancelled lipgloss.Style // "Canceled."
+		ProgressFilled lipgloss.Style // Filled part of the progress bar of MCP tools
+		ProgressEmpty  lipgloss.Style // Empty part of the progress bar of MCP tools
 
 		// Error styles
 		ErrorTag     lipgloss.Style // ERROR tag
@@ -366,6 +368,17 @@ type Styles struct {
 
 		Commands struct{}
 
+		// Logs styles for log viewer dialogs.
+		Logs struct {
+			Time    lipgloss.Style
+			Source  lipgloss.Style
+			Error   lipgloss.Style
+			Warning lipgloss.Style
+			Info    lipgloss.Style
+			Debug   lipgloss.Style
+			Message lipgloss.Style
+		}
+
 		ImagePreview lipgloss.Style
 
 		Sessions struct {
@@ -1125,6 +1138,8 @@ func DefaultStyles() Styles {
 
 	s.Tool.StateWaiting = base.Foreground(fgSubtle)
 	s.Tool.StateCancelled = base.Foreground(fgSubtle)
+	s.Tool.ProgressFilled = base.Foreground(primary)
+	s.Tool.ProgressEmpty = base.Foreground(fgSubtle)
 
 	s.Tool.ErrorTag = base.Padding(0, 1).Background(red).Foreground(white)
 	s.Tool.ErrorMessage = base.Foreground(fgHalfMuted)
@@ -1282,6 +1297,14 @@ func DefaultStyles() Styles {
 
 	s.Dialog.ImagePreview = lipgloss.NewStyle().Padding(0, 1).Foreground(fgSubtle)
 
+	s.Dialog.Logs.Time = base.Foreground(fgMuted)
+	s.Dialog.Logs.Source = base.Foreground(fgSubtle)
+	s.Dialog.Logs.Error = base.Foreground(redDark).Bold(true)
+	s.Dialog.Logs.Warning = base.Foreground(warning).Bold(true)
+	s.Dialog.Logs.Info = base.Foreground(info)
+	s.Dialog.Logs.Debug = base.Foreground(fgHalfMuted)
+	s.Dialog.Logs.Message = base.Foreground(fgBase)
+
 	s.Dialog.Arguments.Content = base.Padding(1)
 	s.Dialog.Arguments.Description = base.MarginBottom(1).MaxHeight(3)
 	s.Dialog.Arguments.InputLabelBlurred = base.Foreground(fgMuted)